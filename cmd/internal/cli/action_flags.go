@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2024, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // Copyright (c) Contributors to the Apptainer project, established as
 //   Apptainer a Series of LF Projects LLC.
 // This software is licensed under a 3-clause BSD license. Please consult the
@@ -118,7 +118,7 @@ var actionDataFlag = cmdline.Flag{
 	Value:        &dataPaths,
 	DefaultValue: []string{},
 	Name:         "data",
-	Usage:        "a data-container bind specification src:dest, where src is the path to the data container, and dest is the destination path in the container. Multiple data container binds can be given as a comma separated list.",
+	Usage:        "a data-container bind specification src:dest, where src is the path or docker:// URI of the data container, and dest is the destination path in the container. Multiple data container binds can be given as a comma separated list.",
 	Tag:          "<spec>",
 }
 
@@ -877,6 +877,7 @@ func init() {
 		cmdManager.RegisterFlagForCmd(&actionOverlayFlag, actionsInstanceCmd...)
 		cmdManager.RegisterFlagForCmd(&commonPromptForPassphraseFlag, actionsInstanceCmd...)
 		cmdManager.RegisterFlagForCmd(&commonPEMFlag, actionsInstanceCmd...)
+		cmdManager.RegisterFlagForCmd(&commonPGPKeyFlag, actionsInstanceCmd...)
 		cmdManager.RegisterFlagForCmd(&actionPidNamespaceFlag, actionsCmd...)
 		cmdManager.RegisterFlagForCmd(&actionNoPidNamespaceFlag, actionsCmd...)
 		cmdManager.RegisterFlagForCmd(&actionCwdFlag, actionsCmd...)
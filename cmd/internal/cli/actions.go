@@ -13,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/sylabs/singularity/v4/docs"
@@ -20,6 +21,7 @@ import (
 	"github.com/sylabs/singularity/v4/internal/pkg/client/library"
 	"github.com/sylabs/singularity/v4/internal/pkg/client/net"
 	"github.com/sylabs/singularity/v4/internal/pkg/client/oci"
+	ocisifclient "github.com/sylabs/singularity/v4/internal/pkg/client/ocisif"
 	"github.com/sylabs/singularity/v4/internal/pkg/client/oras"
 	"github.com/sylabs/singularity/v4/internal/pkg/client/shub"
 	"github.com/sylabs/singularity/v4/internal/pkg/ociimage"
@@ -31,6 +33,8 @@ import (
 	"github.com/sylabs/singularity/v4/internal/pkg/util/uri"
 	bndocisif "github.com/sylabs/singularity/v4/pkg/ocibundle/ocisif"
 	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/bind"
+	"github.com/sylabs/singularity/v4/pkg/util/singularityconf"
 	useragent "github.com/sylabs/singularity/v4/pkg/util/user-agent"
 )
 
@@ -39,10 +43,16 @@ const (
 )
 
 func getCacheHandle(cfg cache.Config) *cache.Handle {
-	h, err := cache.New(cache.Config{
+	cfg = cache.Config{
 		ParentDir: os.Getenv(cache.DirEnv),
 		Disable:   cfg.Disable,
-	})
+	}
+	if conf := singularityconf.GetCurrentConfig(); conf != nil {
+		cfg.MaxSize, cfg.TypeMaxSize = getCacheMaxSizes(conf)
+		cfg.SharedDir = conf.SharedCacheDir
+	}
+
+	h, err := cache.New(cfg)
 	if err != nil {
 		sylog.Fatalf("Failed to create an image cache handle: %s", err)
 	}
@@ -50,6 +60,37 @@ func getCacheHandle(cfg cache.Config) *cache.Handle {
 	return h
 }
 
+// getCacheMaxSizes returns the maximum cache sizes set in singularity.conf.
+// Invalid values are ignored, with a warning, so that they do not prevent use
+// of the cache.
+func getCacheMaxSizes(conf *singularityconf.File) (int64, map[string]int64) {
+	var maxSize int64
+	if conf.CacheMaxSize != "" {
+		var err error
+		maxSize, err = cache.ParseSize(conf.CacheMaxSize)
+		if err != nil {
+			sylog.Warningf("Ignoring invalid 'cache max size' in singularity.conf: %v", err)
+		}
+	}
+
+	typeMaxSize, err := cache.ParseTypeMaxSize(conf.CacheTypeMaxSize)
+	if err != nil {
+		sylog.Warningf("Ignoring invalid 'cache type max size' in singularity.conf: %v", err)
+		typeMaxSize = nil
+	}
+
+	return maxSize, typeMaxSize
+}
+
+// pruneCache removes the least recently used entries from imgCache, if it
+// exceeds any configured maximum size. Failure to prune the cache is not
+// fatal, as the requested operation has already completed.
+func pruneCache(imgCache *cache.Handle) {
+	if err := imgCache.Prune(); err != nil {
+		sylog.Warningf("While pruning cache: %v", err)
+	}
+}
+
 type contextKey string
 
 const (
@@ -99,6 +140,9 @@ func actionPreRun(cmd *cobra.Command, args []string) {
 	localImage, pullTempDir := uriToImage(cmd.Context(), cmd, origImageURI)
 	args[0] = localImage
 
+	// Replace data container URIs in --data specs with local image paths.
+	dataURIsToImages(cmd.Context(), cmd, &pullTempDir)
+
 	// Track the pullTempDir (if set) in the context, so it can be cleaned up on container exit.
 	cmd.SetContext(context.WithValue(cmd.Context(), keyPullTempDir, &pullTempDir))
 }
@@ -118,9 +162,9 @@ func uriToCacheImage(ctx context.Context, refType string, cmd *cobra.Command, im
 	case ociimage.SupportedTransport(refType):
 		return handleOCI(ctx, cmd, imgCache, "", pullFrom)
 	case uri.HTTP:
-		return net.PullToCache(ctx, imgCache, pullFrom)
+		return net.PullToCache(ctx, imgCache, pullFrom, net.PullOptions{})
 	case uri.HTTPS:
-		return net.PullToCache(ctx, imgCache, pullFrom)
+		return net.PullToCache(ctx, imgCache, pullFrom, net.PullOptions{})
 	default:
 		return "", fmt.Errorf("unsupported transport type: %s", refType)
 	}
@@ -148,9 +192,9 @@ func uriToTempImage(ctx context.Context, refType string, cmd *cobra.Command, img
 	case ociimage.SupportedTransport(refType):
 		_, err = handleOCI(ctx, cmd, imgCache, tmpImage, pullFrom)
 	case uri.HTTP:
-		_, err = net.PullToFile(ctx, imgCache, tmpImage, pullFrom)
+		_, err = net.PullToFile(ctx, imgCache, tmpImage, pullFrom, net.PullOptions{})
 	case uri.HTTPS:
-		_, err = net.PullToFile(ctx, imgCache, tmpImage, pullFrom)
+		_, err = net.PullToFile(ctx, imgCache, tmpImage, pullFrom, net.PullOptions{})
 	default:
 		return "", "", fmt.Errorf("unsupported transport type: %s", refType)
 	}
@@ -276,6 +320,56 @@ func uriToImage(ctx context.Context, cmd *cobra.Command, origImageURI string) (i
 	return imagePath, tempDir
 }
 
+// dataURIsToImages pulls data containers specified by docker:// URI with
+// --data to the cache, or to pullTempDir if the cache is disabled, and rewrites
+// their --data specs to refer to the pulled images. If pullTempDir is empty, a
+// temporary directory is created and recorded in it.
+func dataURIsToImages(ctx context.Context, cmd *cobra.Command, pullTempDir *string) {
+	var imgCache *cache.Handle
+	for i, d := range dataPaths {
+		if !strings.HasPrefix(d, "docker://") {
+			continue
+		}
+		bp, err := bind.ParseDataBindPath(d)
+		if err != nil {
+			sylog.Fatalf("While parsing data bind: %v", err)
+		}
+
+		if imgCache == nil {
+			imgCache = getCacheHandle(cache.Config{Disable: disableCache})
+		}
+
+		ociAuth, err := makeOCICredentials(cmd)
+		if err != nil {
+			sylog.Fatalf("While creating Docker credentials: %v", err)
+		}
+		pullOpts := ocisifclient.PullOptions{
+			TmpDir:      tmpDir,
+			OciAuth:     ociAuth,
+			NoHTTPS:     noHTTPS,
+			ReqAuthFile: reqAuthFile,
+		}
+
+		directTo := ""
+		if disableCache {
+			if *pullTempDir == "" {
+				*pullTempDir, err = os.MkdirTemp(tmpDir, "singularity-action-pull-")
+				if err != nil {
+					sylog.Fatalf("Unable to create temporary directory: %v", err)
+				}
+			}
+			directTo = filepath.Join(*pullTempDir, fmt.Sprintf("data-%d", i))
+			sylog.Debugf("Cache disabled, pulling data container to temporary file: %s", directTo)
+		}
+
+		imagePath, err := ocisifclient.PullDataContainer(ctx, imgCache, directTo, bp.Source, pullOpts)
+		if err != nil {
+			sylog.Fatalf("Unable to handle %s uri: %v", bp.Source, err)
+		}
+		dataPaths[i] = imagePath + ":" + bp.Destination
+	}
+}
+
 func pullTempDirFromContext(ctx context.Context) string {
 	pullTempDirPtr := ctx.Value(keyPullTempDir)
 	if pullTempDirPtr != nil {
@@ -407,11 +501,22 @@ func launchContainer(cmd *cobra.Command, ep launcher.ExecParams) error {
 		sylog.Warningf("Resource limits & cgroups configuration are only applied to instances at instance start.")
 	}
 
-	ki, err := getEncryptionMaterial(cmd)
+	keys, err := getEncryptionKeys(cmd)
 	if err != nil {
 		return err
 	}
 
+	// Restart policy and health checks only apply to instance start / run.
+	var healthInterval time.Duration
+	restartPolicy, healthCmd := "", ""
+	if ep.Instance != "" {
+		restartPolicy, healthCmd = instanceRestart, instanceHealthCmd
+		healthInterval, err = time.ParseDuration(instanceHealthInterval)
+		if err != nil {
+			return fmt.Errorf("invalid health check interval %q: %w", instanceHealthInterval, err)
+		}
+	}
+
 	opts := []launcher.Option{
 		launcher.OptWritable(isWritable),
 		launcher.OptWritableTmpfs(isWritableTmpfs),
@@ -461,8 +566,10 @@ func launchContainer(cmd *cobra.Command, ep launcher.ExecParams) error {
 		launcher.OptNoInit(noInit),
 		launcher.OptContain(isContained),
 		launcher.OptContainAll(isContainAll),
+		launcher.OptRestartPolicy(restartPolicy),
+		launcher.OptHealthCheck(healthCmd, healthInterval),
 		launcher.OptAppName(appName),
-		launcher.OptKeyInfo(ki),
+		launcher.OptKeyInfos(keys),
 		launcher.OptSIFFuse(sifFUSE),
 		launcher.OptCacheDisabled(disableCache),
 		launcher.OptDevice(device),
@@ -1,5 +1,5 @@
 // Copyright (c) 2020, Control Command Inc. All rights reserved.
-// Copyright (c) 2018-2023, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // Copyright (c) Contributors to the Apptainer project, established as
 //   Apptainer a Series of LF Projects LLC.
 // This software is licensed under a 3-clause BSD license. Please consult the
@@ -53,6 +53,10 @@ var buildArgs struct {
 	writableTmpfs   bool     // For test section only
 	buildVarArgs    []string // Variables passed to build procedure.
 	buildVarArgFile string   // Variables file passed to build procedure.
+	sbomFormat      string   // Format of SBOM to generate and embed in the image.
+	reproducible    bool
+	fsType          string // Filesystem of the image.
+	compression     string // Squashfs compression, as algorithm[:level].
 }
 
 // -s|--sandbox
@@ -309,6 +313,43 @@ var buildVarArgFileFlag = cmdline.Flag{
 	Usage:        "specifies a file containing variable=value lines to replace '{{ variable }}' with value in build definition files",
 }
 
+// --sbom
+var buildSBOMFlag = cmdline.Flag{
+	ID:           "buildSBOMFlag",
+	Value:        &buildArgs.sbomFormat,
+	DefaultValue: "",
+	Name:         "sbom",
+	Usage:        "generate an SBOM listing the packages installed in the image, and embed it in the image (spdx|cyclonedx)",
+}
+
+// --reproducible
+var buildReproducibleFlag = cmdline.Flag{
+	ID:           "buildReproducibleFlag",
+	Value:        &buildArgs.reproducible,
+	DefaultValue: false,
+	Name:         "reproducible",
+	Usage:        "build a reproducible SIF image, with timestamps set from $SOURCE_DATE_EPOCH (default 0)",
+	EnvKeys:      []string{"REPRODUCIBLE"},
+}
+
+// --fs
+var buildFsFlag = cmdline.Flag{
+	ID:           "buildFsFlag",
+	Value:        &buildArgs.fsType,
+	DefaultValue: "squashfs",
+	Name:         "fs",
+	Usage:        "filesystem of the image, squashfs in a SIF image, or erofs in a bare EROFS image (squashfs|erofs)",
+}
+
+// --compression
+var buildCompressionFlag = cmdline.Flag{
+	ID:           "buildCompressionFlag",
+	Value:        &buildArgs.compression,
+	DefaultValue: "",
+	Name:         "compression",
+	Usage:        "squashfs compression of the image, as algorithm[:level] (gzip|zstd|xz|lz4). Default set in singularity.conf",
+}
+
 func init() {
 	addCmdInit(func(cmdManager *cmdline.CommandManager) {
 		cmdManager.RegisterCmd(buildCmd)
@@ -340,6 +381,7 @@ func init() {
 
 		cmdManager.RegisterFlagForCmd(&commonPromptForPassphraseFlag, buildCmd)
 		cmdManager.RegisterFlagForCmd(&commonPEMFlag, buildCmd)
+		cmdManager.RegisterFlagForCmd(&commonPGPKeyFlag, buildCmd)
 
 		cmdManager.RegisterFlagForCmd(&buildNvFlag, buildCmd)
 		cmdManager.RegisterFlagForCmd(&buildNvCCLIFlag, buildCmd)
@@ -349,6 +391,10 @@ func init() {
 		cmdManager.RegisterFlagForCmd(&buildWritableTmpfsFlag, buildCmd)
 		cmdManager.RegisterFlagForCmd(&buildVarArgsFlag, buildCmd)
 		cmdManager.RegisterFlagForCmd(&buildVarArgFileFlag, buildCmd)
+		cmdManager.RegisterFlagForCmd(&buildSBOMFlag, buildCmd)
+		cmdManager.RegisterFlagForCmd(&buildReproducibleFlag, buildCmd)
+		cmdManager.RegisterFlagForCmd(&buildFsFlag, buildCmd)
+		cmdManager.RegisterFlagForCmd(&buildCompressionFlag, buildCmd)
 
 		cmdManager.RegisterFlagForCmd(&commonOCIFlag, buildCmd)
 		cmdManager.RegisterFlagForCmd(&commonNoOCIFlag, buildCmd)
@@ -1,5 +1,5 @@
 // Copyright (c) 2020, Control Command Inc. All rights reserved.
-// Copyright (c) 2018-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // Copyright (c) Contributors to the Apptainer project, established as
 //   Apptainer a Series of LF Projects LLC.
 // This software is licensed under a 3-clause BSD license. Please consult the
@@ -13,11 +13,14 @@ import (
 	"fmt"
 	"os"
 	osExec "os/exec"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/ccoveille/go-safecast/v2"
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/spf13/cobra"
@@ -31,8 +34,11 @@ import (
 	"github.com/sylabs/singularity/v4/internal/pkg/ociplatform"
 	"github.com/sylabs/singularity/v4/internal/pkg/remote/endpoint"
 	fakerootConfig "github.com/sylabs/singularity/v4/internal/pkg/runtime/engine/fakeroot/config"
+	"github.com/sylabs/singularity/v4/internal/pkg/sbom"
+	"github.com/sylabs/singularity/v4/internal/pkg/sypgp"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/bin"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/fs"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs/squashfs"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/interactive"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/rootless"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/starter"
@@ -42,6 +48,7 @@ import (
 	"github.com/sylabs/singularity/v4/pkg/runtime/engine/config"
 	"github.com/sylabs/singularity/v4/pkg/sylog"
 	"github.com/sylabs/singularity/v4/pkg/util/cryptkey"
+	"github.com/sylabs/singularity/v4/pkg/util/singularityconf"
 )
 
 func fakerootExec() {
@@ -172,6 +179,79 @@ func runBuild(cmd *cobra.Command, args []string) {
 		os.Setenv("SINGULARITY_WRITABLE_TMPFS", "1")
 	}
 
+	if buildArgs.sbomFormat != "" {
+		if buildArgs.remote {
+			sylog.Fatalf("--sbom option is not supported for remote build")
+		}
+		if buildArgs.sandbox {
+			sylog.Fatalf("--sbom option is not supported for sandbox build")
+		}
+		// The SBOM is stored outside of the encrypted filesystem, so would
+		// disclose the content of the container.
+		if encryptionRequested(cmd) {
+			sylog.Fatalf("--sbom option is not supported for encrypted build, as the SBOM would be stored unencrypted")
+		}
+		f, err := sbom.ParseFormat(buildArgs.sbomFormat)
+		if err != nil {
+			sylog.Fatalf("%v", err)
+		}
+		buildArgs.sbomFormat = string(f)
+	}
+
+	if buildArgs.reproducible {
+		if buildArgs.remote {
+			sylog.Fatalf("--reproducible option is not supported for remote build")
+		}
+		if buildArgs.sandbox {
+			sylog.Fatalf("--reproducible option is not supported for sandbox build")
+		}
+		if isOCI {
+			sylog.Fatalf("--reproducible option is not supported for OCI builds from Dockerfiles")
+		}
+		if encryptionRequested(cmd) {
+			sylog.Fatalf("--reproducible option is not supported for encrypted build")
+		}
+	}
+
+	switch buildArgs.fsType {
+	case "squashfs":
+	case "erofs":
+		if buildArgs.remote {
+			sylog.Fatalf("--fs erofs option is not supported for remote build")
+		}
+		if buildArgs.sandbox {
+			sylog.Fatalf("--fs erofs option is not supported for sandbox build")
+		}
+		if isOCI {
+			sylog.Fatalf("--fs erofs option is not supported for OCI builds from Dockerfiles")
+		}
+		if encryptionRequested(cmd) {
+			sylog.Fatalf("--fs erofs option is not supported for encrypted build")
+		}
+		if buildArgs.sbomFormat != "" {
+			sylog.Fatalf("--sbom option is not supported for erofs build, as a bare EROFS image cannot hold an SBOM")
+		}
+	default:
+		sylog.Fatalf("unsupported filesystem %q, must be one of: squashfs, erofs", buildArgs.fsType)
+	}
+
+	if buildArgs.compression != "" {
+		if buildArgs.remote {
+			sylog.Fatalf("--compression option is not supported for remote build")
+		}
+		if buildArgs.sandbox {
+			sylog.Fatalf("--compression option is not supported for sandbox build")
+		}
+		if buildArgs.fsType == "erofs" {
+			sylog.Fatalf("--compression option is not supported for erofs build")
+		}
+		comp, err := squashfs.ParseCompression(buildArgs.compression)
+		if err != nil {
+			sylog.Fatalf("%v", err)
+		}
+		buildArgs.compression = comp.String()
+	}
+
 	if cmd.Flags().Lookup("authfile").Changed && buildArgs.remote {
 		sylog.Fatalf("Custom authfile is not supported for remote build")
 	}
@@ -210,6 +290,21 @@ func runBuild(cmd *cobra.Command, args []string) {
 		sylog.Fatalf("While creating Docker credentials: %v", err)
 	}
 
+	var excludes []string
+	if !buildArgs.sandbox && fs.IsFile(spec) && !isImage(spec) {
+		ignoreFile, err := ignoreFilePath(spec, isOCI)
+		if err != nil {
+			sylog.Fatalf("%v", err)
+		}
+		excludes, err = squashfs.ReadIgnoreFile(ignoreFile)
+		if err != nil {
+			sylog.Fatalf("%v", err)
+		}
+		if len(excludes) > 0 {
+			sylog.Infof("Excluding paths listed in %s from the image", ignoreFile)
+		}
+	}
+
 	if isOCI {
 		if !fs.IsFile(spec) {
 			sylog.Fatalf("When building with --oci the build source must be a Dockerfile.")
@@ -224,26 +319,68 @@ func runBuild(cmd *cobra.Command, args []string) {
 			sylog.Fatalf("While trying to determine current dir: %v", err)
 		}
 
+		// An OCI-SIF image holds the key for each encrypted layer in a
+		// single annotation, so has a single recipient.
+		var keyInfo *cryptkey.KeyInfo
+		if keys := getBuildKeys(cmd); len(keys) > 1 {
+			sylog.Fatalf("Only a single encryption key is supported for OCI builds")
+		} else if len(keys) == 1 {
+			keyInfo = &keys[0]
+		}
+
 		bkOpts := &bkclient.Opts{
-			AuthConf:        authConf,
-			ReqAuthFile:     reqAuthFile,
-			BuildVarArgs:    buildArgs.buildVarArgs,
-			BuildVarArgFile: buildArgs.buildVarArgFile,
-			ReqArch:         reqArch,
-			KeepLayers:      keepLayers,
-			ContextDir:      wd,
-			DisableCache:    disableCache,
+			AuthConf:          authConf,
+			ReqAuthFile:       reqAuthFile,
+			BuildVarArgs:      buildArgs.buildVarArgs,
+			BuildVarArgFile:   buildArgs.buildVarArgFile,
+			ReqArch:           reqArch,
+			KeepLayers:        keepLayers,
+			ContextDir:        wd,
+			DisableCache:      disableCache,
+			EncryptionKeyInfo: keyInfo,
+			SBOMFormat:        buildArgs.sbomFormat,
+			TmpDir:            tmpDir,
+			Compression:       ociCompression(),
+			Excludes:          excludes,
 		}
 		if err := bkclient.Run(cmd.Context(), bkOpts, dest, spec); err != nil {
 			sylog.Fatalf("%v", err)
 		}
 	} else {
-		runBuildLocal(cmd.Context(), authConf, cmd, dest, spec)
+		runBuildLocal(cmd.Context(), authConf, cmd, dest, spec, excludes)
 	}
 
 	sylog.Infof("Build complete: %s", dest)
 }
 
+// ignoreFilePath returns the path of the ignore file for a build from the
+// definition file, or Dockerfile if isOCI is set, at spec. The ignore file of
+// a Dockerfile build is in its build context, the current directory, and that
+// of a definition file build is alongside the definition file.
+func ignoreFilePath(spec string, isOCI bool) (string, error) {
+	dir := filepath.Dir(spec)
+	if isOCI {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("while trying to determine current dir: %w", err)
+		}
+		dir = wd
+	}
+	return filepath.Join(dir, squashfs.IgnoreFile), nil
+}
+
+// ociCompression returns the squashfs compression for OCI-SIF layers, from
+// the --compression flag, or the singularity.conf default.
+func ociCompression() string {
+	if buildArgs.compression != "" {
+		return buildArgs.compression
+	}
+	if conf := singularityconf.GetCurrentConfig(); conf != nil {
+		return conf.MksquashfsCompression
+	}
+	return ""
+}
+
 func runBuildRemote(ctx context.Context, cmd *cobra.Command, dst, spec string) {
 	// building encrypted containers on the remote builder is not currently supported
 	if buildArgs.encrypt {
@@ -368,24 +505,47 @@ func runBuildRemote(ctx context.Context, cmd *cobra.Command, dst, spec string) {
 	}
 }
 
-func runBuildLocal(ctx context.Context, authConf *authn.AuthConfig, cmd *cobra.Command, dst, spec string) {
-	var keyInfo *cryptkey.KeyInfo
-	if buildArgs.encrypt || promptForPassphrase || cmd.Flags().Lookup("pem-path").Changed {
-		if os.Getuid() != 0 {
-			sylog.Fatalf("You must be root to build an encrypted container")
-		}
+// encryptionRequested returns true if encryption of the build output was
+// requested with --encrypt, or by specifying a key with a flag.
+func encryptionRequested(cmd *cobra.Command) bool {
+	return buildArgs.encrypt || promptForPassphrase ||
+		cmd.Flags().Lookup("pem-path").Changed || cmd.Flags().Lookup("pgp-key").Changed
+}
 
-		k, err := getEncryptionMaterial(cmd)
-		if err != nil {
-			sylog.Fatalf("While handling encryption material: %v", err)
-		}
-		keyInfo = k
-	} else {
+// getBuildKeys returns the keys with which to encrypt the build output, or nil
+// if encryption was not requested. Where there are multiple PEM or PGP keys,
+// each is a recipient of the image.
+func getBuildKeys(cmd *cobra.Command) []cryptkey.KeyInfo {
+	if !encryptionRequested(cmd) {
 		_, passphraseEnvOK := os.LookupEnv("SINGULARITY_ENCRYPTION_PASSPHRASE")
 		_, pemPathEnvOK := os.LookupEnv("SINGULARITY_ENCRYPTION_PEM_PATH")
 		if passphraseEnvOK || pemPathEnvOK {
 			sylog.Warningf("Encryption related env vars found, but --encrypt was not specified. NOT encrypting container.")
 		}
+		return nil
+	}
+
+	// Check against the host uid, as an OCI build runs in a user namespace.
+	uid, err := rootless.Getuid()
+	if err != nil {
+		sylog.Fatalf("While fetching uid: %v", err)
+	}
+	if uid != 0 {
+		sylog.Fatalf("You must be root to build an encrypted container")
+	}
+
+	keys, err := getEncryptionKeys(cmd)
+	if err != nil {
+		sylog.Fatalf("While handling encryption material: %v", err)
+	}
+	return keys
+}
+
+func runBuildLocal(ctx context.Context, authConf *authn.AuthConfig, cmd *cobra.Command, dst, spec string, excludes []string) {
+	var keyInfo *cryptkey.KeyInfo
+	var recipients []cryptkey.KeyInfo
+	if keys := getBuildKeys(cmd); len(keys) > 0 {
+		keyInfo, recipients = &keys[0], keys[1:]
 	}
 
 	imgCache := getCacheHandle(cache.Config{Disable: disableCache})
@@ -453,6 +613,8 @@ func runBuildLocal(ctx context.Context, authConf *authn.AuthConfig, cmd *cobra.C
 	if buildArgs.sandbox {
 		buildFormat = "sandbox"
 		sandboxTarget = true
+	} else if buildArgs.fsType == "erofs" {
+		buildFormat = "erofs"
 	}
 
 	dp, err := ociplatform.DefaultPlatform()
@@ -460,6 +622,15 @@ func runBuildLocal(ctx context.Context, authConf *authn.AuthConfig, cmd *cobra.C
 		sylog.Fatalf("%v", err)
 	}
 
+	var sourceDate time.Time
+	if buildArgs.reproducible {
+		sourceDate, err = getSourceDate()
+		if err != nil {
+			sylog.Fatalf("%v", err)
+		}
+		sylog.Infof("Building reproducible image with source date %s", sourceDate.UTC().Format(time.RFC3339))
+	}
+
 	b, err := build.New(
 		defs,
 		build.Config{
@@ -467,23 +638,29 @@ func runBuildLocal(ctx context.Context, authConf *authn.AuthConfig, cmd *cobra.C
 			Format:    buildFormat,
 			NoCleanUp: buildArgs.noCleanUp,
 			Opts: types.Options{
-				ImgCache:          imgCache,
-				TmpDir:            tmpDir,
-				NoCache:           disableCache,
-				Update:            buildArgs.update,
-				Force:             forceOverwrite,
-				Sections:          buildArgs.sections,
-				NoTest:            buildArgs.noTest,
-				NoHTTPS:           noHTTPS,
-				LibraryURL:        buildArgs.libraryURL,
-				LibraryAuthToken:  authToken,
-				KeyServerOpts:     ko,
-				OCIAuthConfig:     authConf,
-				DockerDaemonHost:  dockerHost,
-				DockerAuthFile:    reqAuthFile,
-				EncryptionKeyInfo: keyInfo,
-				FixPerms:          buildArgs.fixPerms,
-				SandboxTarget:     sandboxTarget,
+				ImgCache:             imgCache,
+				TmpDir:               tmpDir,
+				NoCache:              disableCache,
+				Update:               buildArgs.update,
+				Force:                forceOverwrite,
+				Sections:             buildArgs.sections,
+				NoTest:               buildArgs.noTest,
+				NoHTTPS:              noHTTPS,
+				LibraryURL:           buildArgs.libraryURL,
+				LibraryAuthToken:     authToken,
+				KeyServerOpts:        ko,
+				OCIAuthConfig:        authConf,
+				DockerDaemonHost:     dockerHost,
+				DockerAuthFile:       reqAuthFile,
+				EncryptionKeyInfo:    keyInfo,
+				EncryptionRecipients: recipients,
+				FixPerms:             buildArgs.fixPerms,
+				SandboxTarget:        sandboxTarget,
+				SBOMFormat:           buildArgs.sbomFormat,
+				Reproducible:         buildArgs.reproducible,
+				SourceDate:           sourceDate,
+				Compression:          buildArgs.compression,
+				Excludes:             excludes,
 				// Only perform a build with the host DefaultPlatform at present.
 				// TODO: rework --arch handling for remote builds so that local builds can specify --arch and --platform.
 				Platform: *dp,
@@ -496,6 +673,22 @@ func runBuildLocal(ctx context.Context, authConf *authn.AuthConfig, cmd *cobra.C
 	if err = b.Full(ctx); err != nil {
 		sylog.Fatalf("While performing build: %v", err)
 	}
+
+	pruneCache(imgCache)
+}
+
+// getSourceDate returns the time set by the SOURCE_DATE_EPOCH environment
+// variable, as seconds since the Unix epoch, or the Unix epoch if it is not set.
+func getSourceDate() (time.Time, error) {
+	epoch, ok := os.LookupEnv("SOURCE_DATE_EPOCH")
+	if !ok || epoch == "" {
+		return time.Unix(0, 0), nil
+	}
+	secs, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil || secs < 0 {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: must be a non-negative number of seconds", epoch)
+	}
+	return time.Unix(secs, 0), nil
 }
 
 func checkSections() error {
@@ -527,52 +720,76 @@ func isImage(spec string) bool {
 	return err == nil
 }
 
-// getEncryptionMaterial handles the setting of encryption environment and flag parameters to eventually be
+// getEncryptionKeys handles the setting of encryption environment and flag parameters to eventually be
 // passed to the crypt package for handling.
 // This handles the SINGULARITY_ENCRYPTION_PASSPHRASE/PEM_PATH envvars outside of cobra in order to
 // enforce the unique flag/env precedence for the encryption flow
-func getEncryptionMaterial(cmd *cobra.Command) (*cryptkey.KeyInfo, error) {
+//
+// Multiple PEM and PGP keys may be given with the --pem-path and --pgp-key flags. When building or
+// encrypting an image, each is a recipient of the image. Otherwise, each is tried in turn to
+// decrypt the image.
+func getEncryptionKeys(cmd *cobra.Command) ([]cryptkey.KeyInfo, error) {
 	passphraseFlag := cmd.Flags().Lookup("passphrase")
 	PEMFlag := cmd.Flags().Lookup("pem-path")
+	PGPFlag := cmd.Flags().Lookup("pgp-key")
 	passphraseEnv, passphraseEnvOK := os.LookupEnv("SINGULARITY_ENCRYPTION_PASSPHRASE")
 	pemPathEnv, pemPathEnvOK := os.LookupEnv("SINGULARITY_ENCRYPTION_PEM_PATH")
 
 	// checks for no flags/envvars being set
-	if !PEMFlag.Changed && !pemPathEnvOK && !passphraseFlag.Changed && !passphraseEnvOK {
+	if !PEMFlag.Changed && !PGPFlag.Changed && !pemPathEnvOK && !passphraseFlag.Changed && !passphraseEnvOK {
 		return nil, nil
 	}
 
+	// Check public keys, before starting the build (#4173) or encrypting an existing image,
+	// or private keys before launching the engine for actions on a container (#5221).
+	public := cmd.Name() == "build" || cmd.Name() == "encrypt"
+
 	// order of precedence:
-	// 1. PEM flag
+	// 1. PEM and PGP flags
 	// 2. Passphrase flag
 	// 3. PEM envvar
 	// 4. Passphrase envvar
 
-	if PEMFlag.Changed {
-		exists, err := fs.PathExists(encryptionPEMPath)
-		if err != nil {
-			sylog.Fatalf("Unable to verify existence of %s: %v", encryptionPEMPath, err)
-		}
+	if PEMFlag.Changed || PGPFlag.Changed {
+		var keys []cryptkey.KeyInfo
 
-		if !exists {
-			sylog.Fatalf("Specified PEM file %s: does not exist.", encryptionPEMPath)
-		}
+		for _, path := range encryptionPEMPaths {
+			exists, err := fs.PathExists(path)
+			if err != nil {
+				sylog.Fatalf("Unable to verify existence of %s: %v", path, err)
+			}
 
-		sylog.Verbosef("Using pem path flag for encrypted container")
+			if !exists {
+				sylog.Fatalf("Specified PEM file %s: does not exist.", path)
+			}
+
+			sylog.Verbosef("Using pem path flag for encrypted container")
 
-		// Check it's a valid PEM public key we can load, before starting the build (#4173)
-		if cmd.Name() == "build" {
-			if _, err := cryptkey.LoadPEMPublicKey(encryptionPEMPath); err != nil {
-				sylog.Fatalf("Invalid encryption public key: %v", err)
+			if public {
+				if _, err := cryptkey.LoadPEMPublicKey(path); err != nil {
+					sylog.Fatalf("Invalid encryption public key: %v", err)
+				}
+			} else {
+				if _, err := cryptkey.LoadPEMPrivateKey(path); err != nil {
+					sylog.Fatalf("Invalid encryption private key: %v", err)
+				}
 			}
-			// or a valid private key before launching the engine for actions on a container (#5221)
-		} else {
-			if _, err := cryptkey.LoadPEMPrivateKey(encryptionPEMPath); err != nil {
-				sylog.Fatalf("Invalid encryption private key: %v", err)
+
+			keys = append(keys, cryptkey.KeyInfo{Format: cryptkey.PEM, Path: path})
+		}
+
+		for _, fingerprint := range encryptionPGPKeys {
+			sylog.Verbosef("Using PGP key %s for encrypted container", fingerprint)
+
+			e, err := loadPGPEncryptionKey(fingerprint, public)
+			if err != nil {
+				sylog.Fatalf("Invalid encryption PGP key: %v", err)
 			}
+
+			keys = append(keys, cryptkey.KeyInfo{Format: cryptkey.PGP, Entity: e})
 		}
 
-		return &cryptkey.KeyInfo{Format: cryptkey.PEM, Path: encryptionPEMPath}, nil
+		return keys, nil
 	}
 
 	if passphraseFlag.Changed {
@@ -584,7 +801,7 @@ func getEncryptionMaterial(cmd *cobra.Command) (*cryptkey.KeyInfo, error) {
 		if passphrase == "" {
 			sylog.Fatalf("Cannot encrypt container with empty passphrase")
 		}
-		return &cryptkey.KeyInfo{Format: cryptkey.Passphrase, Material: passphrase}, nil
+		return []cryptkey.KeyInfo{{Format: cryptkey.Passphrase, Material: passphrase}}, nil
 	}
 
 	if pemPathEnvOK {
@@ -598,13 +815,47 @@ func getEncryptionMaterial(cmd *cobra.Command) (*cryptkey.KeyInfo, error) {
 		}
 
 		sylog.Verbosef("Using pem path environment variable for encrypted container")
-		return &cryptkey.KeyInfo{Format: cryptkey.PEM, Path: pemPathEnv}, nil
+		return []cryptkey.KeyInfo{{Format: cryptkey.PEM, Path: pemPathEnv}}, nil
 	}
 
 	if passphraseEnvOK {
 		sylog.Verbosef("Using passphrase environment variable for encrypted container")
-		return &cryptkey.KeyInfo{Format: cryptkey.Passphrase, Material: passphraseEnv}, nil
+		return []cryptkey.KeyInfo{{Format: cryptkey.Passphrase, Material: passphraseEnv}}, nil
 	}
 
 	return nil, nil
 }
+
+// loadPGPEncryptionKey returns the entity with the provided fingerprint from the local keyring.
+// If public is false, the entity is loaded from the private keyring, and its private keys are
+// decrypted, prompting the user for a passphrase where needed.
+func loadPGPEncryptionKey(fingerprint string, public bool) (*openpgp.Entity, error) {
+	keyring := sypgp.NewHandle("")
+
+	load := keyring.LoadPubKeyring
+	if !public {
+		load = keyring.LoadPrivKeyring
+	}
+
+	el, err := load()
+	if err != nil {
+		return nil, fmt.Errorf("could not load local keyring: %v", err)
+	}
+
+	e := sypgp.FindKeyByFingerprint(el, fingerprint)
+	if e == nil {
+		return nil, fmt.Errorf("no key matching fingerprint %s found in local keyring", fingerprint)
+	}
+
+	if !public && e.PrivateKey != nil && e.PrivateKey.Encrypted {
+		passphrase, err := interactive.AskQuestionNoEcho("Enter key passphrase : ")
+		if err != nil {
+			return nil, err
+		}
+		if err := e.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("could not decrypt private key: %v", err)
+		}
+	}
+
+	return e, nil
+}
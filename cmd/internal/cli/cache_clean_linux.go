@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2020, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -23,16 +23,18 @@ func init() {
 	addCmdInit(func(cmdManager *cmdline.CommandManager) {
 		cmdManager.RegisterFlagForCmd(&cacheCleanTypesFlag, cacheCleanCmd)
 		cmdManager.RegisterFlagForCmd(&cacheCleanDaysFlag, cacheCleanCmd)
+		cmdManager.RegisterFlagForCmd(&cacheCleanMaxSizeFlag, cacheCleanCmd)
 		cmdManager.RegisterFlagForCmd(&cacheCleanDryFlag, cacheCleanCmd)
 		cmdManager.RegisterFlagForCmd(&cacheCleanForceFlag, cacheCleanCmd)
 	})
 }
 
 var (
-	cacheCleanTypes []string
-	cacheCleanDays  int
-	cacheCleanDry   bool
-	cacheCleanForce bool
+	cacheCleanTypes   []string
+	cacheCleanDays    int
+	cacheCleanMaxSize string
+	cacheCleanDry     bool
+	cacheCleanForce   bool
 
 	// -T|--type
 	cacheCleanTypesFlag = cmdline.Flag{
@@ -54,6 +56,15 @@ var (
 		Usage:        "remove all cache entries older than specified number of days",
 	}
 
+	// --max-size
+	cacheCleanMaxSizeFlag = cmdline.Flag{
+		ID:           "cacheCleanMaxSizeFlag",
+		Value:        &cacheCleanMaxSize,
+		DefaultValue: "",
+		Name:         "max-size",
+		Usage:        "remove least recently used cache entries until the cache is no larger than the specified size (e.g. 20G)",
+	}
+
 	// -n|--dry-run
 	cacheCleanDryFlag = cmdline.Flag{
 		ID:           "cacheCleanDryFlag",
@@ -91,11 +102,23 @@ var (
 )
 
 func cleanCache() error {
+	var maxSize int64
+	if cacheCleanMaxSize != "" {
+		if cacheCleanDays != 0 {
+			return fmt.Errorf("--days and --max-size cannot be used together")
+		}
+		var err error
+		maxSize, err = cache.ParseSize(cacheCleanMaxSize)
+		if err != nil {
+			return fmt.Errorf("invalid --max-size: %v", err)
+		}
+	}
+
 	if cacheCleanDry {
 		fmt.Println("User requested a dry run. Not actually deleting any data!")
 	}
 	if !cacheCleanForce && !cacheCleanDry {
-		ok, err := cleanCachePrompt()
+		ok, err := cleanCachePrompt(cacheCleanMaxSize)
 		if err != nil {
 			return fmt.Errorf("could not prompt user: %v", err)
 		}
@@ -107,16 +130,25 @@ func cleanCache() error {
 
 	// create a handle to access the current image cache
 	imgCache := getCacheHandle(cache.Config{})
-	err := singularity.CleanSingularityCache(imgCache, cacheCleanDry, cacheCleanTypes, cacheCleanDays)
+	var err error
+	if cacheCleanMaxSize != "" {
+		err = singularity.CleanSingularityCacheToSize(imgCache, cacheCleanDry, cacheCleanTypes, maxSize)
+	} else {
+		err = singularity.CleanSingularityCache(imgCache, cacheCleanDry, cacheCleanTypes, cacheCleanDays)
+	}
 	if err != nil {
 		return fmt.Errorf("could not clean cache: %v", err)
 	}
 	return nil
 }
 
-func cleanCachePrompt() (bool, error) {
-	fmt.Print(`This will delete everything in your cache (containers from all sources and OCI blobs).
-Hint: You can see exactly what would be deleted by canceling and using the --dry-run option.
+func cleanCachePrompt(maxSize string) (bool, error) {
+	if maxSize != "" {
+		fmt.Printf("This will delete the least recently used entries in your cache, until it is no larger than %s.\n", maxSize)
+	} else {
+		fmt.Print("This will delete everything in your cache (containers from all sources and OCI blobs).\n")
+	}
+	fmt.Print(`Hint: You can see exactly what would be deleted by canceling and using the --dry-run option.
 Do you want to continue? [y/N] `)
 
 	r := bufio.NewReader(os.Stdin)
@@ -0,0 +1,90 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/v4/docs"
+	"github.com/sylabs/singularity/v4/internal/app/singularity"
+	"github.com/sylabs/singularity/v4/internal/pkg/cache"
+	"github.com/sylabs/singularity/v4/internal/pkg/ociimage"
+	"github.com/sylabs/singularity/v4/internal/pkg/remote/credential/ociauth"
+	"github.com/sylabs/singularity/v4/pkg/cmdline"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	useragent "github.com/sylabs/singularity/v4/pkg/util/user-agent"
+)
+
+func init() {
+	addCmdInit(func(cmdManager *cmdline.CommandManager) {
+		cmdManager.RegisterSubCmd(CacheCmd, cacheExportCmd)
+
+		cmdManager.RegisterFlagForCmd(&cacheExportOutputFlag, cacheExportCmd)
+
+		cmdManager.RegisterFlagForCmd(&commonNoHTTPSFlag, cacheExportCmd)
+		cmdManager.RegisterFlagForCmd(&commonTmpDirFlag, cacheExportCmd)
+
+		cmdManager.RegisterFlagForCmd(&dockerUsernameFlag, cacheExportCmd)
+		cmdManager.RegisterFlagForCmd(&dockerPasswordFlag, cacheExportCmd)
+		cmdManager.RegisterFlagForCmd(&dockerLoginFlag, cacheExportCmd)
+		cmdManager.RegisterFlagForCmd(&commonAuthFileFlag, cacheExportCmd)
+
+		cmdManager.RegisterFlagForCmd(&commonArchFlag, cacheExportCmd)
+		cmdManager.RegisterFlagForCmd(&commonPlatformFlag, cacheExportCmd)
+	})
+}
+
+var (
+	cacheExportOutput string
+
+	// -o|--output
+	cacheExportOutputFlag = cmdline.Flag{
+		ID:           "cacheExportOutputFlag",
+		Value:        &cacheExportOutput,
+		DefaultValue: "",
+		Name:         "output",
+		ShortHand:    "o",
+		Usage:        "path of the bundle file to write",
+		Required:     true,
+	}
+
+	// cacheExportCmd is 'singularity cache export' and will write cached
+	// images to a bundle that can be imported into another cache.
+	cacheExportCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := exportCache(cmd, args); err != nil {
+				sylog.Fatalf("Cache export failed: %v", err)
+			}
+		},
+
+		Use:     docs.CacheExportUse,
+		Short:   docs.CacheExportShort,
+		Long:    docs.CacheExportLong,
+		Example: docs.CacheExportExample,
+	}
+)
+
+func exportCache(cmd *cobra.Command, args []string) error {
+	ociAuth, err := makeOCICredentials(cmd)
+	if err != nil {
+		return fmt.Errorf("while creating docker credentials: %v", err)
+	}
+
+	tOpts := &ociimage.TransportOptions{
+		Insecure:     noHTTPS,
+		AuthConfig:   ociAuth,
+		AuthFilePath: ociauth.ChooseAuthFile(reqAuthFile),
+		TmpDir:       tmpDir,
+		UserAgent:    useragent.Value(),
+		Platform:     getOCIPlatform(),
+	}
+
+	imgCache := getCacheHandle(cache.Config{})
+	return singularity.ExportCache(cmd.Context(), imgCache, tOpts, args, cacheExportOutput)
+}
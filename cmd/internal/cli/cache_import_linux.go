@@ -0,0 +1,39 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/v4/docs"
+	"github.com/sylabs/singularity/v4/internal/app/singularity"
+	"github.com/sylabs/singularity/v4/internal/pkg/cache"
+	"github.com/sylabs/singularity/v4/pkg/cmdline"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+)
+
+func init() {
+	addCmdInit(func(cmdManager *cmdline.CommandManager) {
+		cmdManager.RegisterSubCmd(CacheCmd, cacheImportCmd)
+	})
+}
+
+// cacheImportCmd is 'singularity cache import' and will add the content of a
+// bundle written by 'singularity cache export' to the cache.
+var cacheImportCmd = &cobra.Command{
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		imgCache := getCacheHandle(cache.Config{})
+		if err := singularity.ImportCache(imgCache, args[0]); err != nil {
+			sylog.Fatalf("Cache import failed: %v", err)
+		}
+	},
+
+	Use:     docs.CacheImportUse,
+	Short:   docs.CacheImportShort,
+	Long:    docs.CacheImportLong,
+	Example: docs.CacheImportExample,
+}
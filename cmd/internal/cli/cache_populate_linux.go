@@ -0,0 +1,92 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/v4/docs"
+	"github.com/sylabs/singularity/v4/internal/pkg/cache"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/uri"
+	"github.com/sylabs/singularity/v4/pkg/cmdline"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/singularityconf"
+)
+
+func init() {
+	addCmdInit(func(cmdManager *cmdline.CommandManager) {
+		cmdManager.RegisterSubCmd(CacheCmd, cachePopulateCmd)
+
+		cmdManager.RegisterFlagForCmd(&commonNoHTTPSFlag, cachePopulateCmd)
+		cmdManager.RegisterFlagForCmd(&commonTmpDirFlag, cachePopulateCmd)
+
+		cmdManager.RegisterFlagForCmd(&dockerHostFlag, cachePopulateCmd)
+		cmdManager.RegisterFlagForCmd(&dockerUsernameFlag, cachePopulateCmd)
+		cmdManager.RegisterFlagForCmd(&dockerPasswordFlag, cachePopulateCmd)
+		cmdManager.RegisterFlagForCmd(&dockerLoginFlag, cachePopulateCmd)
+		cmdManager.RegisterFlagForCmd(&commonAuthFileFlag, cachePopulateCmd)
+
+		cmdManager.RegisterFlagForCmd(&commonOCIFlag, cachePopulateCmd)
+		cmdManager.RegisterFlagForCmd(&commonNoOCIFlag, cachePopulateCmd)
+		cmdManager.RegisterFlagForCmd(&commonKeepLayersFlag, cachePopulateCmd)
+
+		cmdManager.RegisterFlagForCmd(&commonArchFlag, cachePopulateCmd)
+		cmdManager.RegisterFlagForCmd(&commonPlatformFlag, cachePopulateCmd)
+	})
+}
+
+// cachePopulateCmd is 'singularity cache populate' and will add images to
+// the shared system cache
+var cachePopulateCmd = &cobra.Command{
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := populateCache(cmd, args); err != nil {
+			sylog.Fatalf("Shared cache populate failed: %v", err)
+		}
+	},
+
+	Use:     docs.CachePopulateUse,
+	Short:   docs.CachePopulateShort,
+	Long:    docs.CachePopulateLong,
+	Example: docs.CachePopulateExample,
+}
+
+func populateCache(cmd *cobra.Command, args []string) (err error) {
+	conf := singularityconf.GetCurrentConfig()
+	if conf == nil || conf.SharedCacheDir == "" {
+		return fmt.Errorf("no 'shared cache dir' is set in singularity.conf")
+	}
+
+	for _, imageURI := range args {
+		if refType, _ := uri.Split(imageURI); refType == "" {
+			return fmt.Errorf("%s is not a remote image URI", imageURI)
+		}
+	}
+
+	sharedCache, err := cache.OpenShared(conf.SharedCacheDir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if releaseErr := sharedCache.Release(); releaseErr != nil && err == nil {
+			err = fmt.Errorf("while releasing shared cache: %w", releaseErr)
+		}
+	}()
+
+	for _, imageURI := range args {
+		refType, _ := uri.Split(imageURI)
+		sylog.Infof("Adding %s to shared cache %s", imageURI, conf.SharedCacheDir)
+		imagePath, err := uriToCacheImage(cmd.Context(), refType, cmd, sharedCache, imageURI)
+		if err != nil {
+			return fmt.Errorf("while pulling %s: %w", imageURI, err)
+		}
+		sylog.Infof("%s cached at %s", imageURI, imagePath)
+	}
+
+	return nil
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2024-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2024-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -17,6 +17,14 @@ func init() {
 	addCmdInit(func(cmdManager *cmdline.CommandManager) {
 		cmdManager.RegisterCmd(DataCmd)
 		cmdManager.RegisterSubCmd(DataCmd, DataPackageCmd)
+		cmdManager.RegisterFlagForCmd(&dataPackageAppendFlag, DataPackageCmd)
+
+		cmdManager.RegisterSubCmd(DataCmd, DataInspectCmd)
+		cmdManager.RegisterFlagForCmd(&dataInspectJSONFlag, DataInspectCmd)
+
+		cmdManager.RegisterSubCmd(DataCmd, DataListCmd)
+
+		cmdManager.RegisterSubCmd(DataCmd, DataExtractCmd)
 	})
 }
 
@@ -0,0 +1,31 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/v4/docs"
+	"github.com/sylabs/singularity/v4/internal/app/singularity"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+)
+
+// DataExtractCmd is the 'data extract' command that extracts a file or
+// directory from a data container, without mounting it.
+var DataExtractCmd = &cobra.Command{
+	Args: cobra.ExactArgs(3),
+	RunE: func(_ *cobra.Command, args []string) error {
+		if err := singularity.DataExtract(args[0], args[1], args[2]); err != nil {
+			sylog.Fatalf("%v", err.Error())
+		}
+		return nil
+	},
+	DisableFlagsInUseLine: true,
+
+	Use:     docs.DataExtractUse,
+	Short:   docs.DataExtractShort,
+	Long:    docs.DataExtractLong,
+	Example: docs.DataExtractExample,
+}
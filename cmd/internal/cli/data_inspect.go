@@ -0,0 +1,46 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/v4/docs"
+	"github.com/sylabs/singularity/v4/internal/app/singularity"
+	"github.com/sylabs/singularity/v4/pkg/cmdline"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+)
+
+var dataInspectJSON bool
+
+// -j|--json
+var dataInspectJSONFlag = cmdline.Flag{
+	ID:           "dataInspectJSONFlag",
+	Value:        &dataInspectJSON,
+	DefaultValue: false,
+	Name:         "json",
+	ShortHand:    "j",
+	Usage:        "print structured json instead of sections",
+}
+
+// DataInspectCmd is the 'data inspect' command that shows the details and
+// content of a data container.
+var DataInspectCmd = &cobra.Command{
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		if err := singularity.DataInspect(os.Stdout, args[0], dataInspectJSON); err != nil {
+			sylog.Fatalf("%v", err.Error())
+		}
+		return nil
+	},
+	DisableFlagsInUseLine: true,
+
+	Use:     docs.DataInspectUse,
+	Short:   docs.DataInspectShort,
+	Long:    docs.DataInspectLong,
+	Example: docs.DataInspectExample,
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/v4/docs"
+	"github.com/sylabs/singularity/v4/internal/app/singularity"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+)
+
+// DataListCmd is the 'data ls' command that lists the content of a data
+// container.
+var DataListCmd = &cobra.Command{
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		dir := "/"
+		if len(args) > 1 {
+			dir = args[1]
+		}
+		if err := singularity.DataList(os.Stdout, args[0], dir); err != nil {
+			sylog.Fatalf("%v", err.Error())
+		}
+		return nil
+	},
+	DisableFlagsInUseLine: true,
+
+	Use:     docs.DataListUse,
+	Short:   docs.DataListShort,
+	Long:    docs.DataListLong,
+	Example: docs.DataListExample,
+}
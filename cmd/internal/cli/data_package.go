@@ -1,4 +1,4 @@
-// Copyright (c) 2024, Sylabs Inc. All rights reserved.
+// Copyright (c) 2024-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -9,14 +9,26 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/sylabs/singularity/v4/docs"
 	"github.com/sylabs/singularity/v4/internal/app/singularity"
+	"github.com/sylabs/singularity/v4/pkg/cmdline"
 	"github.com/sylabs/singularity/v4/pkg/sylog"
 )
 
+var dataPackageAppend bool
+
+// --append
+var dataPackageAppendFlag = cmdline.Flag{
+	ID:           "dataPackageAppendFlag",
+	Value:        &dataPackageAppend,
+	DefaultValue: false,
+	Name:         "append",
+	Usage:        "append to an existing data container, as a new version",
+}
+
 // DataPackageCmd is the 'data package' command to package a file/dir into an OCI-SIF data container.
 var DataPackageCmd = &cobra.Command{
 	Args: cobra.ExactArgs(2),
 	RunE: func(_ *cobra.Command, args []string) error {
-		if err := singularity.DataPackage(args[0], args[1]); err != nil {
+		if err := singularity.DataPackage(args[0], args[1], dataPackageAppend); err != nil {
 			sylog.Fatalf("%v", err.Error())
 		}
 		return nil
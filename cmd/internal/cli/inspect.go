@@ -23,6 +23,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/sylabs/sif/v2/pkg/sif"
 	"github.com/sylabs/singularity/v4/docs"
+	"github.com/sylabs/singularity/v4/internal/app/singularity"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/env"
 	"github.com/sylabs/singularity/v4/pkg/cmdline"
 	"github.com/sylabs/singularity/v4/pkg/image"
@@ -46,6 +47,13 @@ var (
 	labels      bool
 	deffile     bool
 	jsonfmt     bool
+
+	inspectPlatforms bool
+	inspectOCIConfig bool
+	inspectManifest  bool
+	inspectHistory   bool
+	inspectLayers    bool
+	inspectSBOM      bool
 )
 
 // -l|--labels
@@ -156,6 +164,60 @@ var inspectAllFlag = cmdline.Flag{
 	Usage:        "show all available data (imply --json option)",
 }
 
+// --platforms
+var inspectPlatformsFlag = cmdline.Flag{
+	ID:           "inspectPlatformsFlag",
+	Value:        &inspectPlatforms,
+	DefaultValue: false,
+	Name:         "platforms",
+	Usage:        "list the platforms of the images in an OCI-SIF image",
+}
+
+// --oci-config
+var inspectOCIConfigFlag = cmdline.Flag{
+	ID:           "inspectOCIConfigFlag",
+	Value:        &inspectOCIConfig,
+	DefaultValue: false,
+	Name:         "oci-config",
+	Usage:        "show the OCI image config of an OCI-SIF image",
+}
+
+// --manifest
+var inspectManifestFlag = cmdline.Flag{
+	ID:           "inspectManifestFlag",
+	Value:        &inspectManifest,
+	DefaultValue: false,
+	Name:         "manifest",
+	Usage:        "show the OCI image manifest of an OCI-SIF image",
+}
+
+// --history
+var inspectHistoryFlag = cmdline.Flag{
+	ID:           "inspectHistoryFlag",
+	Value:        &inspectHistory,
+	DefaultValue: false,
+	Name:         "history",
+	Usage:        "show the build history of an OCI-SIF image",
+}
+
+// --layers
+var inspectLayersFlag = cmdline.Flag{
+	ID:           "inspectLayersFlag",
+	Value:        &inspectLayers,
+	DefaultValue: false,
+	Name:         "layers",
+	Usage:        "list the layers and signatures of an OCI-SIF image",
+}
+
+// --sbom
+var inspectSBOMFlag = cmdline.Flag{
+	ID:           "inspectSBOMFlag",
+	Value:        &inspectSBOM,
+	DefaultValue: false,
+	Name:         "sbom",
+	Usage:        "show the SBOM embedded in a SIF or OCI-SIF image",
+}
+
 func init() {
 	addCmdInit(func(cmdManager *cmdline.CommandManager) {
 		cmdManager.RegisterCmd(InspectCmd)
@@ -171,6 +233,12 @@ func init() {
 		cmdManager.RegisterFlagForCmd(&inspectTestFlag, InspectCmd)
 		cmdManager.RegisterFlagForCmd(&inspectAppsListFlag, InspectCmd)
 		cmdManager.RegisterFlagForCmd(&inspectAllFlag, InspectCmd)
+		cmdManager.RegisterFlagForCmd(&inspectPlatformsFlag, InspectCmd)
+		cmdManager.RegisterFlagForCmd(&inspectOCIConfigFlag, InspectCmd)
+		cmdManager.RegisterFlagForCmd(&inspectManifestFlag, InspectCmd)
+		cmdManager.RegisterFlagForCmd(&inspectHistoryFlag, InspectCmd)
+		cmdManager.RegisterFlagForCmd(&inspectLayersFlag, InspectCmd)
+		cmdManager.RegisterFlagForCmd(&inspectSBOMFlag, InspectCmd)
 	})
 }
 
@@ -628,6 +696,39 @@ func defaultToLabels() bool {
 	return !helpfile && !deffile && !runscript && !startscript && !testfile && !environment && !listApps
 }
 
+// inspectOCISIF shows the OCI information about an OCI-SIF image requested by
+// the --platforms, --oci-config, --manifest, --history or --layers flags. It is
+// read from the OCI image layout held in the image, without running the
+// container.
+func inspectOCISIF(img *image.Image) error {
+	if img.Type != image.OCISIF {
+		return fmt.Errorf("--platforms, --oci-config, --manifest, --history and --layers are only supported for OCI-SIF images")
+	}
+
+	selected := 0
+	for _, f := range []bool{inspectPlatforms, inspectOCIConfig, inspectManifest, inspectHistory, inspectLayers} {
+		if f {
+			selected++
+		}
+	}
+	if selected > 1 {
+		return fmt.Errorf("only one of --platforms, --oci-config, --manifest, --history and --layers may be specified")
+	}
+
+	switch {
+	case inspectPlatforms:
+		return singularity.InspectOCISIFPlatforms(os.Stdout, img.Path, jsonfmt)
+	case inspectOCIConfig:
+		return singularity.InspectOCISIFConfig(os.Stdout, img.Path)
+	case inspectManifest:
+		return singularity.InspectOCISIFManifest(os.Stdout, img.Path)
+	case inspectHistory:
+		return singularity.InspectOCISIFHistory(os.Stdout, img.Path, jsonfmt)
+	default:
+		return singularity.InspectOCISIFLayers(os.Stdout, img.Path, jsonfmt)
+	}
+}
+
 // InspectCmd represents the 'inspect' command.
 // TODO: This should be in its own package, not cli.
 var InspectCmd = &cobra.Command{
@@ -645,6 +746,23 @@ var InspectCmd = &cobra.Command{
 			sylog.Fatalf("Failed to open image %s: %s", args[0], err)
 		}
 
+		if inspectSBOM {
+			if img.Type != image.SIF && img.Type != image.OCISIF {
+				sylog.Fatalf("--sbom is only supported for SIF and OCI-SIF images")
+			}
+			if err := singularity.InspectSBOM(os.Stdout, img.Path, img.Type == image.OCISIF); err != nil {
+				sylog.Fatalf("Could not inspect %s: %v", img.Path, err)
+			}
+			return
+		}
+
+		if inspectPlatforms || inspectOCIConfig || inspectManifest || inspectHistory || inspectLayers {
+			if err := inspectOCISIF(img); err != nil {
+				sylog.Fatalf("Could not inspect %s: %v", img.Path, err)
+			}
+			return
+		}
+
 		if allData {
 			// display all data in JSON format only
 			jsonfmt = true
@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2024, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // Copyright (c) Contributors to the Apptainer project, established as
 //   Apptainer a Series of LF Projects LLC.
 // This software is licensed under a 3-clause BSD license. Please consult the
@@ -19,6 +19,9 @@ import (
 func init() {
 	addCmdInit(func(cmdManager *cmdline.CommandManager) {
 		cmdManager.RegisterFlagForCmd(&instanceStartPidFileFlag, instanceStartCmd, instanceRunCmd)
+		cmdManager.RegisterFlagForCmd(&instanceRestartFlag, instanceStartCmd, instanceRunCmd)
+		cmdManager.RegisterFlagForCmd(&instanceHealthCmdFlag, instanceStartCmd, instanceRunCmd)
+		cmdManager.RegisterFlagForCmd(&instanceHealthIntervalFlag, instanceStartCmd, instanceRunCmd)
 	})
 }
 
@@ -34,16 +37,48 @@ var instanceStartPidFileFlag = cmdline.Flag{
 	EnvKeys:      []string{"PID_FILE"},
 }
 
+// --restart
+var instanceRestart string
+
+var instanceRestartFlag = cmdline.Flag{
+	ID:           "instanceRestartFlag",
+	Value:        &instanceRestart,
+	DefaultValue: "",
+	Name:         "restart",
+	Usage:        "restart the instance process when it exits (no|on-failure[:max-retries]|always)",
+	EnvKeys:      []string{"RESTART"},
+}
+
+// --health-cmd
+var instanceHealthCmd string
+
+var instanceHealthCmdFlag = cmdline.Flag{
+	ID:           "instanceHealthCmdFlag",
+	Value:        &instanceHealthCmd,
+	DefaultValue: "",
+	Name:         "health-cmd",
+	Usage:        "command run periodically with /bin/sh in the instance to check its health",
+	EnvKeys:      []string{"HEALTH_CMD"},
+}
+
+// --health-interval
+var instanceHealthInterval string
+
+var instanceHealthIntervalFlag = cmdline.Flag{
+	ID:           "instanceHealthIntervalFlag",
+	Value:        &instanceHealthInterval,
+	DefaultValue: "30s",
+	Name:         "health-interval",
+	Usage:        "interval between health checks, e.g. 30s, 5m",
+	EnvKeys:      []string{"HEALTH_INTERVAL"},
+}
+
 // singularity instance start
 var instanceStartCmd = &cobra.Command{
 	Args:                  cobra.MinimumNArgs(2),
 	PreRun:                actionPreRun,
 	DisableFlagsInUseLine: true,
 	Run: func(cmd *cobra.Command, args []string) {
-		if isOCI {
-			sylog.Fatalf("Instances are not yet supported in OCI-mode. Omit --oci, or use --no-oci, to start a non-OCI Singularity container.")
-		}
-
 		ep := launcher.ExecParams{
 			Image:    args[0],
 			Action:   "start",
@@ -74,10 +109,6 @@ var instanceRunCmd = &cobra.Command{
 	PreRun:                actionPreRun,
 	DisableFlagsInUseLine: true,
 	Run: func(cmd *cobra.Command, args []string) {
-		if isOCI {
-			sylog.Fatalf("Instances are not yet supported in OCI-mode. Omit --oci, or use --no-oci, to start a non-OCI Singularity container.")
-		}
-
 		ep := launcher.ExecParams{
 			Image:    args[0],
 			Action:   "run",
@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2020, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -67,10 +67,6 @@ var instanceListLogsFlag = cmdline.Flag{
 var instanceListCmd = &cobra.Command{
 	Args: cobra.RangeArgs(0, 1),
 	Run: func(_ *cobra.Command, args []string) {
-		if isOCI {
-			sylog.Warningf("Instances are not supported in OCI mode. Showing non-OCI Singularity container instances.")
-		}
-
 		name := "*"
 		if len(args) > 0 {
 			name = args[0]
@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2022, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -117,6 +117,7 @@ func init() {
 		cmdManager.RegisterSubCmd(OciCmd, OciResumeCmd)
 		cmdManager.RegisterSubCmd(OciCmd, OciMountCmd)
 		cmdManager.RegisterSubCmd(OciCmd, OciUmountCmd)
+		cmdManager.RegisterSubCmd(OciCmd, OciCleanupCmd)
 
 		cmdManager.SetCmdGroup("create_run", OciCreateCmd, OciRunCmd)
 		createRunCmd := cmdManager.GetCmdGroup("create_run")
@@ -199,6 +200,24 @@ var OciDeleteCmd = &cobra.Command{
 	Example: docs.OciDeleteExample,
 }
 
+// OciCleanupCmd represents oci cleanup command. It is called by conmon when a
+// container exits, and is not intended to be run directly.
+var OciCleanupCmd = &cobra.Command{
+	Args:                  cobra.ExactArgs(1),
+	DisableFlagsInUseLine: true,
+	PreRun:                CheckRoot,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := singularity.OciCleanup(cmd.Context(), args[0]); err != nil {
+			sylog.Fatalf("%s", err)
+		}
+	},
+	Hidden:  true,
+	Use:     docs.OciCleanupUse,
+	Short:   docs.OciCleanupShort,
+	Long:    docs.OciCleanupLong,
+	Example: docs.OciCleanupExample,
+}
+
 // OciKillCmd represents oci kill command.
 var OciKillCmd = &cobra.Command{
 	Args:                  cobra.MinimumNArgs(1),
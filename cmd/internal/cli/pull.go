@@ -1,5 +1,5 @@
 // Copyright (c) 2020, Control Command Inc. All rights reserved.
-// Copyright (c) 2018-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -9,6 +9,7 @@ package cli
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/sylabs/singularity/v4/docs"
@@ -19,6 +20,7 @@ import (
 	"github.com/sylabs/singularity/v4/internal/pkg/client/oras"
 	"github.com/sylabs/singularity/v4/internal/pkg/client/shub"
 	"github.com/sylabs/singularity/v4/internal/pkg/ociimage"
+	"github.com/sylabs/singularity/v4/internal/pkg/ociplatform"
 	"github.com/sylabs/singularity/v4/internal/pkg/remote/endpoint"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/uri"
 	"github.com/sylabs/singularity/v4/pkg/cmdline"
@@ -53,6 +55,8 @@ var (
 	pullDir string
 	// pullWithCosign sets whether cosign signatures are pushed when pushing OCI images.
 	pullWithCosign bool
+	// pullChecksum is the sha256 checksum that an http(s) download must match.
+	pullChecksum string
 )
 
 // --library
@@ -130,6 +134,15 @@ var pullWithCosignFlag = cmdline.Flag{
 	EnvKeys:      []string{"WITH_COSIGN"},
 }
 
+// --checksum
+var pullChecksumFlag = cmdline.Flag{
+	ID:           "pullChecksumFlag",
+	Value:        &pullChecksum,
+	DefaultValue: "",
+	Name:         "checksum",
+	Usage:        "verify an http(s) download against a sha256 checksum (sha256:<hex>)",
+}
+
 func init() {
 	addCmdInit(func(cmdManager *cmdline.CommandManager) {
 		cmdManager.RegisterCmd(PullCmd)
@@ -161,6 +174,7 @@ func init() {
 		cmdManager.RegisterFlagForCmd(&commonAuthFileFlag, PullCmd)
 
 		cmdManager.RegisterFlagForCmd(&pullWithCosignFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullChecksumFlag, PullCmd)
 	})
 }
 
@@ -218,6 +232,21 @@ func pullRun(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// --arch all, or a list of architectures, pulls a multi-platform image.
+	multiPlatform := arch == ociplatform.AllArch || strings.Contains(arch, ",")
+	if multiPlatform {
+		if transport != DockerProtocol {
+			sylog.Fatalf("--arch %s is only supported when pulling from docker:// registries", arch)
+		}
+		if !isOCI {
+			sylog.Fatalf("--arch %s requires an OCI-SIF image, use --oci", arch)
+		}
+	}
+
+	if pullChecksum != "" && transport != HTTPProtocol && transport != HTTPSProtocol {
+		sylog.Fatalf("--checksum is only supported when pulling from http(s) URLs")
+	}
+
 	switch transport {
 	case LibraryProtocol, "":
 		ref, err := library.NormalizeLibraryRef(pullFrom)
@@ -304,7 +333,7 @@ func pullRun(cmd *cobra.Command, args []string) {
 			sylog.Warningf("Pull from http[s]:// is a direct download, --arch and --platform have no effect.")
 		}
 
-		_, err := net.PullToFile(ctx, imgCache, pullTo, pullFrom)
+		_, err := net.PullToFile(ctx, imgCache, pullTo, pullFrom, net.PullOptions{Checksum: pullChecksum})
 		if err != nil {
 			sylog.Fatalf("While pulling from image from http(s): %v\n", err)
 		}
@@ -322,10 +351,18 @@ func pullRun(cmd *cobra.Command, args []string) {
 			NoCleanUp:   buildArgs.noCleanUp,
 			OciSif:      isOCI,
 			KeepLayers:  keepLayers,
-			Platform:    getOCIPlatform(),
 			ReqAuthFile: reqAuthFile,
 			WithCosign:  pullWithCosign,
 		}
+		if multiPlatform {
+			pullOpts.MultiPlatform = true
+			pullOpts.Platforms, err = ociplatform.PlatformsFromArchList(arch)
+			if err != nil {
+				sylog.Fatalf("Invalid --arch: %v", err)
+			}
+		} else {
+			pullOpts.Platform = getOCIPlatform()
+		}
 
 		_, err = oci.PullToFile(ctx, imgCache, pullTo, pullFrom, pullOpts)
 		if err != nil {
@@ -334,4 +371,6 @@ func pullRun(cmd *cobra.Command, args []string) {
 	default:
 		sylog.Fatalf("Unsupported transport type: %s", transport)
 	}
+
+	pruneCache(imgCache)
 }
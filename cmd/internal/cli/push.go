@@ -1,5 +1,5 @@
 // Copyright (c) 2020, Control Command Inc. All rights reserved.
-// Copyright (c) 2018-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -39,6 +39,9 @@ var (
 
 	// pushWithCosign sets whether cosign signatures are pushed when pushing OCI images.
 	pushWithCosign bool
+
+	// pushSubject sets an image that a pushed OCI image is attached to as a referrer.
+	pushSubject string
 )
 
 // --library
@@ -92,6 +95,15 @@ var pushWithCosignFlag = cmdline.Flag{
 	EnvKeys:      []string{"WITH_COSIGN"},
 }
 
+// --subject
+var pushSubjectFlag = cmdline.Flag{
+	ID:           "pushSubjectFlag",
+	Value:        &pushSubject,
+	DefaultValue: "",
+	Name:         "subject",
+	Usage:        "attach the pushed OCI-SIF image to this image in the same repository, as an OCI referrer",
+}
+
 func init() {
 	addCmdInit(func(cmdManager *cmdline.CommandManager) {
 		cmdManager.RegisterCmd(PushCmd)
@@ -109,6 +121,7 @@ func init() {
 
 		cmdManager.RegisterFlagForCmd(&pushLayerFormatFlag, PushCmd)
 		cmdManager.RegisterFlagForCmd(&pushWithCosignFlag, PushCmd)
+		cmdManager.RegisterFlagForCmd(&pushSubjectFlag, PushCmd)
 	})
 }
 
@@ -126,6 +139,9 @@ var PushCmd = &cobra.Command{
 
 		switch transport {
 		case LibraryProtocol, "": // Handle pushing to a library
+			if pushSubject != "" {
+				sylog.Fatalf("--subject is only supported for push to docker / OCI registries")
+			}
 			destRef, err := library.NormalizeLibraryRef(dest)
 			if err != nil {
 				sylog.Fatalf("Malformed library reference: %v", err)
@@ -195,6 +211,9 @@ var PushCmd = &cobra.Command{
 			}
 
 		case OrasProtocol:
+			if pushSubject != "" {
+				sylog.Fatalf("--subject is only supported for push to docker / OCI registries")
+			}
 			if cmd.Flag(pushDescriptionFlag.Name).Changed {
 				sylog.Warningf("Description is not supported for push to oras. Ignoring it.")
 			}
@@ -221,6 +240,7 @@ var PushCmd = &cobra.Command{
 				AuthFile:    reqAuthFile,
 				LayerFormat: pushLayerFormat,
 				WithCosign:  pushWithCosign,
+				Subject:     pushSubject,
 			}
 			if err := oci.Push(cmd.Context(), file, ref, opts); err != nil {
 				sylog.Fatalf("Unable to push image to oci registry: %v", err)
@@ -0,0 +1,303 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"encoding/hex"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/v4/docs"
+	"github.com/sylabs/singularity/v4/internal/app/singularity"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/interactive"
+	"github.com/sylabs/singularity/v4/pkg/cmdline"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/cryptkey"
+)
+
+var (
+	sifRemoveSignatures       bool
+	sifNewPEMPaths            []string
+	sifPromptForNewPassphrase bool
+	sifAddRecipients          []string
+	sifRemoveRecipients       []string
+)
+
+// --remove-signatures
+var sifRemoveSignaturesFlag = cmdline.Flag{
+	ID:           "sifRemoveSignaturesFlag",
+	Value:        &sifRemoveSignatures,
+	DefaultValue: false,
+	Name:         "remove-signatures",
+	Usage:        "remove the signatures of a signed image, which are invalidated by the modification",
+}
+
+// --new-pem-path
+var sifNewPEMPathFlag = cmdline.Flag{
+	ID:           "sifNewPEMPathFlag",
+	Value:        &sifNewPEMPaths,
+	DefaultValue: []string{},
+	Name:         "new-pem-path",
+	Usage:        "path to the PEM formatted RSA public key to re-key the image with (may be specified multiple times)",
+	EnvKeys:      []string{"ENCRYPTION_NEW_PEM_PATH"},
+}
+
+// --new-passphrase
+var sifPromptForNewPassphraseFlag = cmdline.Flag{
+	ID:           "sifPromptForNewPassphraseFlag",
+	Value:        &sifPromptForNewPassphrase,
+	DefaultValue: false,
+	Name:         "new-passphrase",
+	Usage:        "prompt for the passphrase to re-key the image with",
+}
+
+// --add-recipient
+var sifAddRecipientFlag = cmdline.Flag{
+	ID:           "sifAddRecipientFlag",
+	Value:        &sifAddRecipients,
+	DefaultValue: []string{},
+	Name:         "add-recipient",
+	Usage:        "add a recipient to the image, given as the path to a PEM formatted RSA public key, or pgp:<fingerprint> of a key in the local keyring",
+	Tag:          "<recipient>",
+}
+
+// --remove-recipient
+var sifRemoveRecipientFlag = cmdline.Flag{
+	ID:           "sifRemoveRecipientFlag",
+	Value:        &sifRemoveRecipients,
+	DefaultValue: []string{},
+	Name:         "remove-recipient",
+	Usage:        "remove a recipient from the image, given as the path to a PEM formatted RSA key, pgp:<fingerprint>, a recipient ID, or legacy/unnamed for recipients added by earlier versions",
+	Tag:          "<recipient>",
+}
+
+// registerSIFCryptCmds registers the sif encrypt, rekey and decrypt commands
+// under the sif command.
+func registerSIFCryptCmds(cmdManager *cmdline.CommandManager, sifCmd *cobra.Command) {
+	for _, cmd := range []*cobra.Command{SIFEncryptCmd, SIFRekeyCmd, SIFDecryptCmd} {
+		cmdManager.RegisterSubCmd(sifCmd, cmd)
+		cmdManager.RegisterFlagForCmd(&commonPEMFlag, cmd)
+		cmdManager.RegisterFlagForCmd(&commonPGPKeyFlag, cmd)
+		cmdManager.RegisterFlagForCmd(&commonPromptForPassphraseFlag, cmd)
+		cmdManager.RegisterFlagForCmd(&sifRemoveSignaturesFlag, cmd)
+	}
+	cmdManager.RegisterFlagForCmd(&sifNewPEMPathFlag, SIFRekeyCmd)
+	cmdManager.RegisterFlagForCmd(&sifPromptForNewPassphraseFlag, SIFRekeyCmd)
+	cmdManager.RegisterFlagForCmd(&sifAddRecipientFlag, SIFRekeyCmd)
+	cmdManager.RegisterFlagForCmd(&sifRemoveRecipientFlag, SIFRekeyCmd)
+}
+
+// SIFEncryptCmd is the 'sif encrypt' command that encrypts an existing SIF image.
+var SIFEncryptCmd = &cobra.Command{
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if os.Geteuid() != 0 {
+			sylog.Fatalf("You must be root to encrypt a SIF image")
+		}
+		keys := getSIFKeys(cmd)
+
+		opts := singularity.SIFCryptOptions{RemoveSignatures: sifRemoveSignatures}
+		if err := singularity.EncryptSIF(args[0], keys, opts); err != nil {
+			sylog.Fatalf("While encrypting %s: %v", args[0], err)
+		}
+		sylog.Infof("Encrypted %s", args[0])
+	},
+	DisableFlagsInUseLine: true,
+
+	Use:     docs.SIFEncryptUse,
+	Short:   docs.SIFEncryptShort,
+	Long:    docs.SIFEncryptLong,
+	Example: docs.SIFEncryptExample,
+}
+
+// SIFRekeyCmd is the 'sif rekey' command that changes the key of an encrypted SIF image.
+var SIFRekeyCmd = &cobra.Command{
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := singularity.SIFCryptOptions{RemoveSignatures: sifRemoveSignatures}
+
+		if len(sifAddRecipients) > 0 || len(sifRemoveRecipients) > 0 {
+			if len(sifNewPEMPaths) > 0 || sifPromptForNewPassphrase {
+				sylog.Fatalf("--add-recipient / --remove-recipient cannot be used with --new-pem-path or --new-passphrase")
+			}
+			updateSIFRecipients(cmd, args[0], opts)
+			return
+		}
+
+		oldKeys := getSIFKeys(cmd)
+
+		newKeys, err := getSIFNewKeys(cmd)
+		if err != nil {
+			sylog.Fatalf("While handling new encryption material: %v", err)
+		}
+		if len(newKeys) == 0 {
+			sylog.Fatalf("A new key must be specified with --new-pem-path or --new-passphrase")
+		}
+
+		// Re-wrapping a key only modifies SIF descriptors, but a change of
+		// passphrase modifies the LUKS header with cryptsetup.
+		if singularity.RekeyChangesHeader(oldKeys, newKeys) && os.Geteuid() != 0 {
+			sylog.Fatalf("You must be root to change the passphrase of a SIF image")
+		}
+
+		if err := singularity.RekeySIF(args[0], oldKeys, newKeys, opts); err != nil {
+			sylog.Fatalf("While re-keying %s: %v", args[0], err)
+		}
+		sylog.Infof("Re-keyed %s", args[0])
+		if !singularity.RekeyChangesHeader(oldKeys, newKeys) {
+			sylog.Warningf("The filesystem key of %s was re-wrapped, not changed. Replaced recipients that hold it can still decrypt the image.", args[0])
+		}
+	},
+	DisableFlagsInUseLine: true,
+
+	Use:     docs.SIFRekeyUse,
+	Short:   docs.SIFRekeyShort,
+	Long:    docs.SIFRekeyLong,
+	Example: docs.SIFRekeyExample,
+}
+
+// SIFDecryptCmd is the 'sif decrypt' command that decrypts an encrypted SIF image.
+var SIFDecryptCmd = &cobra.Command{
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if os.Geteuid() != 0 {
+			sylog.Fatalf("You must be root to decrypt a SIF image")
+		}
+		keys := getSIFKeys(cmd)
+
+		opts := singularity.SIFCryptOptions{RemoveSignatures: sifRemoveSignatures}
+		if err := singularity.DecryptSIF(args[0], keys, opts); err != nil {
+			sylog.Fatalf("While decrypting %s: %v", args[0], err)
+		}
+		sylog.Infof("Decrypted %s", args[0])
+	},
+	DisableFlagsInUseLine: true,
+
+	Use:     docs.SIFDecryptUse,
+	Short:   docs.SIFDecryptShort,
+	Long:    docs.SIFDecryptLong,
+	Example: docs.SIFDecryptExample,
+}
+
+// getSIFKeys returns the keys given with --pem-path, --pgp-key or --passphrase,
+// or the equivalent environment variables, exiting if none was given.
+func getSIFKeys(cmd *cobra.Command) []cryptkey.KeyInfo {
+	keys, err := getEncryptionKeys(cmd)
+	if err != nil {
+		sylog.Fatalf("While handling encryption material: %v", err)
+	}
+	if len(keys) == 0 {
+		sylog.Fatalf("A key must be specified with --pem-path, --pgp-key or --passphrase")
+	}
+	return keys
+}
+
+// getSIFNewKeys returns the new keys for 'sif rekey', given with
+// --new-pem-path or --new-passphrase, or the SINGULARITY_ENCRYPTION_NEW_PASSPHRASE
+// environment variable.
+func getSIFNewKeys(cmd *cobra.Command) ([]cryptkey.KeyInfo, error) {
+	if len(sifNewPEMPaths) > 0 {
+		var keys []cryptkey.KeyInfo
+		for _, path := range sifNewPEMPaths {
+			if _, err := cryptkey.LoadPEMPublicKey(path); err != nil {
+				sylog.Fatalf("Invalid new encryption public key: %v", err)
+			}
+			keys = append(keys, cryptkey.KeyInfo{Format: cryptkey.PEM, Path: path})
+		}
+		return keys, nil
+	}
+
+	if cmd.Flags().Lookup("new-passphrase").Changed {
+		passphrase, err := interactive.GetPassphrase("Enter new encryption passphrase: ", 3)
+		if err != nil {
+			return nil, err
+		}
+		if passphrase == "" {
+			sylog.Fatalf("Cannot encrypt container with empty passphrase")
+		}
+		return []cryptkey.KeyInfo{{Format: cryptkey.Passphrase, Material: passphrase}}, nil
+	}
+
+	if passphrase, ok := os.LookupEnv("SINGULARITY_ENCRYPTION_NEW_PASSPHRASE"); ok {
+		sylog.Verbosef("Using new passphrase environment variable for encrypted container")
+		return []cryptkey.KeyInfo{{Format: cryptkey.Passphrase, Material: passphrase}}, nil
+	}
+
+	return nil, nil
+}
+
+// updateSIFRecipients adds and removes the recipients given with
+// --add-recipient and --remove-recipient to and from the image at path. A key
+// to decrypt the image is only required when adding recipients.
+func updateSIFRecipients(cmd *cobra.Command, path string, opts singularity.SIFCryptOptions) {
+	var keys []cryptkey.KeyInfo
+	if len(sifAddRecipients) > 0 {
+		keys = getSIFKeys(cmd)
+	}
+
+	var add []cryptkey.KeyInfo
+	for _, r := range sifAddRecipients {
+		k, err := parseRecipient(r)
+		if err != nil {
+			sylog.Fatalf("Invalid recipient %s: %v", r, err)
+		}
+		add = append(add, k)
+	}
+
+	var remove []string
+	for _, r := range sifRemoveRecipients {
+		id, err := parseRecipientID(r)
+		if err != nil {
+			sylog.Fatalf("Invalid recipient %s: %v", r, err)
+		}
+		remove = append(remove, id)
+	}
+
+	if err := singularity.UpdateSIFRecipients(path, keys, add, remove, opts); err != nil {
+		sylog.Fatalf("While updating recipients of %s: %v", path, err)
+	}
+	sylog.Infof("Updated recipients of %s", path)
+	if len(remove) > 0 {
+		sylog.Warningf("Removing a recipient does not change the filesystem key of %s. Removed recipients that hold it can still decrypt the image.", path)
+	}
+}
+
+// parseRecipient returns the public key for a recipient, given as pgp:<fingerprint>
+// of a key in the local keyring, or the path to a PEM formatted RSA public key.
+func parseRecipient(r string) (cryptkey.KeyInfo, error) {
+	if fingerprint, ok := strings.CutPrefix(r, "pgp:"); ok {
+		e, err := loadPGPEncryptionKey(fingerprint, true)
+		if err != nil {
+			return cryptkey.KeyInfo{}, err
+		}
+		return cryptkey.KeyInfo{Format: cryptkey.PGP, Entity: e}, nil
+	}
+
+	if _, err := cryptkey.LoadPEMPublicKey(r); err != nil {
+		return cryptkey.KeyInfo{}, err
+	}
+	return cryptkey.KeyInfo{Format: cryptkey.PEM, Path: r}, nil
+}
+
+// parseRecipientID returns the recipient ID for a recipient given as
+// pgp:<fingerprint>, a recipient ID, cryptkey.LegacyRecipientID, or the path to
+// a PEM formatted RSA key.
+func parseRecipientID(r string) (string, error) {
+	if fingerprint, ok := strings.CutPrefix(r, "pgp:"); ok {
+		fp, err := hex.DecodeString(strings.TrimPrefix(fingerprint, "0x"))
+		if err != nil {
+			return "", err
+		}
+		return cryptkey.PGPRecipientID(fp), nil
+	}
+
+	if strings.HasPrefix(r, "rsa-sha256:") || strings.HasPrefix(r, "openpgp:") || r == cryptkey.LegacyRecipientID {
+		return r, nil
+	}
+
+	return cryptkey.RecipientID(cryptkey.KeyInfo{Format: cryptkey.PEM, Path: r})
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2021, Sylabs Inc. All rights reserved.
+// Copyright (c) 2019-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -25,5 +25,6 @@ func init() {
 		siftool.AddCommands(cmd)
 
 		cmdManager.RegisterCmd(cmd)
+		registerSIFCryptCmds(cmdManager, cmd)
 	})
 }
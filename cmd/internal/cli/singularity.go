@@ -58,11 +58,12 @@ const (
 
 // Top level options on the `singularity` root command.
 var (
-	debug   bool
-	nocolor bool
-	silent  bool
-	verbose bool
-	quiet   bool
+	debug     bool
+	nocolor   bool
+	silent    bool
+	verbose   bool
+	quiet     bool
+	logFormat string
 
 	configurationFile string
 )
@@ -76,7 +77,8 @@ var (
 	noHTTPS     bool
 
 	// Encryption Material
-	encryptionPEMPath   string
+	encryptionPEMPaths  []string
+	encryptionPGPKeys   []string
 	promptForPassphrase bool
 
 	// Paths / file handling
@@ -127,6 +129,16 @@ var singNoColorFlag = cmdline.Flag{
 	Usage:        "print without color output (default False)",
 }
 
+// --log-format
+var singLogFormatFlag = cmdline.Flag{
+	ID:           "singLogFormatFlag",
+	Value:        &logFormat,
+	DefaultValue: sylog.TextFormat,
+	Name:         "log-format",
+	Usage:        "format of log messages: text, or json to write each as a JSON object",
+	EnvKeys:      []string{"LOG_FORMAT"},
+}
+
 // -s|--silent
 var singSilentFlag = cmdline.Flag{
 	ID:           "singSilentFlag",
@@ -249,10 +261,20 @@ var commonPromptForPassphraseFlag = cmdline.Flag{
 // --pem-path
 var commonPEMFlag = cmdline.Flag{
 	ID:           "actionEncryptionPEMPath",
-	Value:        &encryptionPEMPath,
-	DefaultValue: "",
+	Value:        &encryptionPEMPaths,
+	DefaultValue: []string{},
 	Name:         "pem-path",
-	Usage:        "enter an path to a PEM formatted RSA key for an encrypted container",
+	Usage:        "enter an path to a PEM formatted RSA key for an encrypted container (may be specified multiple times)",
+}
+
+// --pgp-key
+var commonPGPKeyFlag = cmdline.Flag{
+	ID:           "commonPGPKeyFlag",
+	Value:        &encryptionPGPKeys,
+	DefaultValue: []string{},
+	Name:         "pgp-key",
+	Usage:        "fingerprint of a PGP key in the local keyring for an encrypted container (may be specified multiple times)",
+	Tag:          "<fingerprint>",
 }
 
 // -F|--force
@@ -392,6 +414,12 @@ func setSylogMessageLevel() {
 	}
 
 	sylog.SetLevel(level, color)
+
+	if err := sylog.SetFormat(logFormat); err != nil {
+		sylog.Fatalf("Invalid --log-format: %v", err)
+	}
+	// Propagate log format to nested `singularity` calls.
+	os.Setenv("SINGULARITY_LOG_FORMAT", logFormat)
 }
 
 // handleRemoteConf will make sure your 'remote.yaml' config file
@@ -523,6 +551,7 @@ func Init(loadPlugins bool) {
 
 	cmdManager.RegisterFlagForCmd(&singDebugFlag, singularityCmd)
 	cmdManager.RegisterFlagForCmd(&singNoColorFlag, singularityCmd)
+	cmdManager.RegisterFlagForCmd(&singLogFormatFlag, singularityCmd)
 	cmdManager.RegisterFlagForCmd(&singSilentFlag, singularityCmd)
 	cmdManager.RegisterFlagForCmd(&singQuietFlag, singularityCmd)
 	cmdManager.RegisterFlagForCmd(&singVerboseFlag, singularityCmd)
@@ -1,5 +1,5 @@
 /*
- * Copyright (c) 2017-2019, SyLabs, Inc. All rights reserved.
+ * Copyright (c) 2017-2026, SyLabs, Inc. All rights reserved.
  *
  * Copyright (c) 2016-2017, The Regents of the University of California,
  * through Lawrence Berkeley National Laboratory (subject to receipt of any
@@ -54,6 +54,7 @@
 #define ANSI_COLOR_RESET        "\x1b[0m"
 
 #define MSGLVL_ENV              "SINGULARITY_MESSAGELEVEL"
+#define LOGFMT_ENV              "SINGULARITY_LOG_FORMAT"
 
 void _print(int level, const char *function, const char *file, char *format, ...) __attribute__ ((__format__(printf, 4, 5)));
 
@@ -1,5 +1,5 @@
 /*
- * Copyright (c) 2017-2019, SyLabs, Inc. All rights reserved.
+ * Copyright (c) 2017-2026, SyLabs, Inc. All rights reserved.
  *
  * Copyright (c) 2016-2017, The Regents of the University of California,
  * through Lawrence Berkeley National Laboratory (subject to receipt of any
@@ -31,10 +31,12 @@
 #include <string.h>
 #include <stdarg.h>
 #include <libgen.h>
+#include <time.h>
 
 #include "include/message.h"
 
 int messagelevel = -99;
+int jsonformat = 0;
 
 extern const char *__progname;
 
@@ -50,6 +52,64 @@ int count_digit(int n) {
     return count;
 }
 
+/*
+ * Write message as a JSON object, with the same fields as the JSON log
+ * messages written by the Go runtime, in pkg/sylog. The object is written
+ * with a single call, so that it isn't interleaved with other output.
+ */
+static void print_json(const char *level, char *message) {
+    /* escaping may expand each character of message to 6 characters */
+    char buffer[6*512+256];
+    char timestamp[64];
+    struct timespec ts;
+    struct tm tm;
+    size_t len;
+    char *c;
+
+    clock_gettime(CLOCK_REALTIME, &ts);
+    gmtime_r(&ts.tv_sec, &tm);
+    len = strftime(timestamp, sizeof(timestamp), "%Y-%m-%dT%H:%M:%S", &tm);
+    snprintf(timestamp+len, sizeof(timestamp)-len, ".%09ldZ", ts.tv_nsec);
+
+    /* strip trailing newlines, as for messages from the Go runtime */
+    len = strlen(message);
+    while ( len > 0 && message[len-1] == '\n' ) {
+        message[--len] = '\0';
+    }
+
+    len = snprintf(buffer, sizeof(buffer), "{\"level\":\"%s\",\"timestamp\":\"%s\",\"pid\":%d,\"component\":\"starter\",\"message\":\"", level, timestamp, getpid());
+    for ( c = message; *c != '\0' && len < sizeof(buffer) - 8; c++ ) {
+        switch (*c) {
+            case '"':
+                buffer[len++] = '\\';
+                buffer[len++] = '"';
+                break;
+            case '\\':
+                buffer[len++] = '\\';
+                buffer[len++] = '\\';
+                break;
+            case '\n':
+                buffer[len++] = '\\';
+                buffer[len++] = 'n';
+                break;
+            case '\t':
+                buffer[len++] = '\\';
+                buffer[len++] = 't';
+                break;
+            default:
+                if ( (unsigned char)*c < 0x20 ) {
+                    len += snprintf(buffer+len, sizeof(buffer)-len, "\\u%04x", (unsigned char)*c);
+                } else {
+                    buffer[len++] = *c;
+                }
+                break;
+        }
+    }
+    snprintf(buffer+len, sizeof(buffer)-len, "\"}\n");
+
+    fputs(buffer, stderr);
+}
+
 void _print(int level, const char *function, const char *file_in, char *format, ...) {
     const char *file = file_in;
     char message[512];
@@ -61,6 +121,11 @@ void _print(int level, const char *function, const char *file_in, char *format,
 
     if ( messagelevel == -99 ) {
         char *messagelevel_string = getenv(MSGLVL_ENV);
+        char *logformat_string = getenv(LOGFMT_ENV);
+
+        if ( logformat_string != NULL && strcmp(logformat_string, "json") == 0 ) {
+            jsonformat = 1;
+        }
 
         if ( messagelevel_string == NULL ) {
             messagelevel = 5;
@@ -156,7 +221,14 @@ void _print(int level, const char *function, const char *file_in, char *format,
             break;
     }
 
-    if ( level <= messagelevel ) {
+    if ( level <= messagelevel && jsonformat ) {
+        /* use the same level names as the Go runtime */
+        if ( level == ABRT ) {
+            prefix = "FATAL";
+        }
+        print_json(prefix, message);
+        fflush(stderr);
+    } else if ( level <= messagelevel ) {
         char header_string[100];
 
         if ( messagelevel >= DEBUG ) {
@@ -1177,14 +1177,16 @@ static void cleanenv(void) {
     }
 
     /*
-     * keep only SINGULARITY_MESSAGELEVEL for GO runtime, set others to empty
-     * string and not NULL (see issue #3703 for why)
+     * keep only SINGULARITY_MESSAGELEVEL and SINGULARITY_LOG_FORMAT for GO
+     * runtime, set others to empty string and not NULL (see issue #3703 for
+     * why)
      *
      * DCT - also keep any GOGC and GODEBUG vars for go runtime
      * debugging purposes.
      */
     for (e = environ; *e != NULL; e++) {
         if ( strncmp(MSGLVL_ENV "=", *e, strlen(MSGLVL_ENV "=")) == 0 ||
+             strncmp(LOGFMT_ENV "=", *e, strlen(LOGFMT_ENV "=")) == 0 ||
              strncmp("GOGC" "=", *e, strlen("GOGC" "=")) == 0 ||
              strncmp("GODEBUG" "=", *e, strlen("GODEBUG" "=")) == 0 ) {
             debugf("Keeping env var %s\n", *e);
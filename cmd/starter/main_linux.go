@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2023, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // Copyright (c) Contributors to the Apptainer project, established as
 //   Apptainer a Series of LF Projects LLC.
 // This software is licensed under a 3-clause BSD license. Please consult the
@@ -43,6 +43,9 @@ func getEngine(jsonConfig []byte) *engine.Engine {
 }
 
 func startup() {
+	// identify messages from the starter in JSON log output, then by stage
+	sylog.SetComponent("starter")
+
 	// global variable defined in cmd/starter/c/starter.c,
 	// C.sconfig points to a shared memory area
 	csconf := unsafe.Pointer(C.sconfig)
@@ -58,9 +61,11 @@ func startup() {
 
 	switch C.goexecute {
 	case C.STAGE1:
+		sylog.SetComponent("starter/stage1")
 		sylog.Verbosef("Execute stage 1\n")
 		starter.StageOne(sconfig, e)
 	case C.STAGE2:
+		sylog.SetComponent("starter/stage2")
 		sylog.Verbosef("Execute stage 2\n")
 		if err := sconfig.Release(); err != nil {
 			sylog.Fatalf("%s", err)
@@ -70,6 +75,7 @@ func startup() {
 			starter.StageTwo(int(C.master_socket[1]), e)
 		})
 	case C.MASTER:
+		sylog.SetComponent("starter/master")
 		sylog.Verbosef("Execute master process\n")
 
 		pid := sconfig.GetContainerPid()
@@ -81,6 +87,7 @@ func startup() {
 
 		starter.Master(int(C.rpc_socket[0]), int(C.master_socket[0]), int(C.post_start_socket[0]), int(C.cleanup_socket[0]), pid, imageFd, e)
 	case C.RPC_SERVER:
+		sylog.SetComponent("starter/rpc-server")
 		sylog.Verbosef("Serve RPC requests\n")
 
 		if err := sconfig.Release(); err != nil {
@@ -89,6 +96,7 @@ func startup() {
 
 		starter.RPCServer(int(C.rpc_socket[1]), e)
 	case C.POST_START_HOST:
+		sylog.SetComponent("starter/post-start-host")
 		sylog.Verbosef("Execute Post Start Host Process")
 
 		if err := sconfig.Release(); err != nil {
@@ -97,6 +105,7 @@ func startup() {
 
 		starter.PostStartHost(int(C.post_start_socket[1]), e)
 	case C.CLEANUP_HOST:
+		sylog.SetComponent("starter/cleanup-host")
 		sylog.Verbosef("Execute Cleanup Host Process")
 
 		if err := sconfig.Release(); err != nil {
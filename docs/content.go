@@ -1,4 +1,4 @@
-// Copyright (c) 2017-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2017-2026, Sylabs Inc. All rights reserved.
 // Copyright (c) Contributors to the Apptainer project, established as
 //   Apptainer a Series of LF Projects LLC.
 // This software is licensed under a 3-clause BSD license. Please consult the
@@ -66,7 +66,53 @@ Enterprise Performance Computing (EPC)`
       oras://     an OCI registry that holds SIF files using ORAS
 
   When run with the --oci flag, the spec must be a valid Dockerfile, and output
-  is always an OCI-SIF image.`
+  is always an OCI-SIF image.
+
+  SBOM:
+
+  The --sbom spdx|cyclonedx flag generates a software bill of materials, listing
+  the packages recorded in the dpkg, rpm and apk databases of the container, and
+  the npm, Python and Ruby packages installed in it. The SBOM is generated from
+  the root filesystem before it is packed, and is embedded in the image: as a
+  data object in a SIF image, or as an OCI artifact referring to the image in an
+  OCI-SIF image. It can be shown with 'singularity inspect --sbom'. The SBOM is
+  not encrypted, so --sbom cannot be used with --encrypt. A bare EROFS image,
+  built with --fs erofs, cannot hold an SBOM.
+
+  REPRODUCIBLE BUILDS:
+
+  The --reproducible flag builds a SIF image that is bit-for-bit identical when
+  the same definition and sources are built again. Timestamps are taken from
+  the SOURCE_DATE_EPOCH environment variable (seconds since the Unix epoch,
+  default 0): file modification times in the container are clamped to it, and
+  it is recorded as the SIF creation time and build-date label. The SIF ID is
+  derived from the content of the image. Encrypted, sandbox, remote and OCI
+  builds cannot be reproducible.
+
+  ROOT FILESYSTEM FORMAT:
+
+  By default, the root filesystem of a SIF image is a squashfs partition. The
+  --fs erofs flag builds a bare EROFS image instead, using mkfs.erofs, as SIF
+  does not yet define an EROFS partition type. EROFS images can be faster to
+  access at random, e.g. for images holding many small Python files. They are
+  mounted with erofsfuse, or by the kernel where 'allow kernel erofs' is set in
+  singularity.conf. Encrypted, sandbox, remote and OCI builds cannot use EROFS,
+  and EROFS images cannot hold an SBOM.
+
+  COMPRESSION AND EXCLUDES:
+
+  The --compression flag sets the squashfs compression of a SIF image, or of
+  the layers of an OCI-SIF image, as algorithm[:level]. The algorithm is one of
+  gzip, zstd, xz or lz4, and a level may be given for gzip (1-9) or zstd
+  (1-22). The default is set by 'mksquashfs compression' in singularity.conf.
+
+  Paths can be left out of the image by listing patterns, one per line, in the
+  %exclude section of a definition file, or in a .singularityignore file. The
+  .singularityignore file is read from the directory holding the definition
+  file, or from the build context, the current directory, of a Dockerfile. A
+  pattern without a slash, e.g. '*.pyc', matches at any depth. Other patterns,
+  e.g. '/var/cache/*', match from the root of the container. Lines starting
+  with # are ignored. Excludes are not applied to sandbox or remote builds.`
 
 	BuildExample string = `
 
@@ -166,7 +212,20 @@ Enterprise Performance Computing (EPC)`
           $ singularity build /tmp/debian2.sif /tmp/debian
 
       Build an OCI-SIF image from a Dockerfile:
-          $ singularity build --oci /tmp/myimage.oci.sif /path/to/Dockerfile`
+          $ singularity build --oci /tmp/myimage.oci.sif /path/to/Dockerfile
+
+      Build a sif image with an embedded SPDX SBOM, then show the SBOM:
+          $ singularity build --sbom spdx /tmp/debian3.sif docker://debian:latest
+          $ singularity inspect --sbom /tmp/debian3.sif
+
+      Build a reproducible sif image, dated from the last git commit:
+          $ SOURCE_DATE_EPOCH=$(git log -1 --format=%ct) singularity build --reproducible /tmp/debian4.sif debian.def
+
+      Build a bare EROFS image:
+          $ singularity build --fs erofs /tmp/debian5.erofs debian.def
+
+      Build a zstd compressed sif image:
+          $ singularity build --compression zstd:19 /tmp/debian6.sif debian.def`
 
 	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 	// Cache
@@ -175,7 +234,9 @@ Enterprise Performance Computing (EPC)`
 	CacheShort string = `Manage the local cache`
 	CacheLong  string = `
   Manage your local Singularity cache. You can list/clean using the specific 
-  types.`
+  types. Administrators can populate a shared, system-wide cache. Cached OCI
+  images can be exported to a bundle, and imported into the cache on a system
+  without network access.`
 	CacheExample string = `
   All group commands have their own help output:
 
@@ -190,7 +251,10 @@ Enterprise Performance Computing (EPC)`
 	CacheCleanLong  string = `
   This will clean your local cache (stored at $HOME/.singularity/cache if
   SINGULARITY_CACHEDIR is not set). By default the entire cache is cleaned, use
-  --days and --type flags to override this behavior. Note: if you use Singularity
+  --days and --type flags to override this behavior. The --max-size flag removes
+  the least recently used entries until the cache is no larger than the
+  specified size. An OCI image in the blob cache is removed whole, keeping any
+  blobs that are shared with other images. Note: if you use Singularity
   as root, cache will be stored in '/root/.singularity/.cache', to clean that
   cache, you will need to run 'cache clean' as root, or with 'sudo'.`
 	CacheCleanExample string = `
@@ -198,6 +262,7 @@ Enterprise Performance Computing (EPC)`
 
   $ singularity help cache clean --days 30
   $ singularity help cache clean --type=library,oci
+  $ singularity cache clean --max-size 20G
   $ singularity cache clean --help`
 
 	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
@@ -215,6 +280,55 @@ Enterprise Performance Computing (EPC)`
   $ singularity help cache list --type=library,oci
   $ singularity cache list --help`
 
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	// Cache populate
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	CachePopulateUse   string = `populate [populate options...] <URI>...`
+	CachePopulateShort string = `Add images to the shared system cache`
+	CachePopulateLong  string = `
+  This will pull the specified images into the shared, read-only cache set by
+  the 'shared cache dir' directive in singularity.conf. Images, OCI-SIF
+  conversions, and OCI blobs in the shared cache are used by all users before
+  their own cache. It must be run by a user who can write to the shared cache
+  directory, usually root. Images are pulled in the same form as when they are
+  run, so use --oci to populate OCI-SIF images for OCI-mode.`
+	CachePopulateExample string = `
+  $ sudo singularity cache populate library://alpine:latest docker://ubuntu:24.04
+  $ sudo singularity cache populate --oci docker://ubuntu:24.04`
+
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	// Cache export
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	CacheExportUse   string = `export [export options...] <URI>...`
+	CacheExportShort string = `Export cached OCI images to a bundle`
+	CacheExportLong  string = `
+  This will write the OCI blobs for the specified docker:// images from the
+  cache to a single tar bundle. Images that are not fully cached are fetched
+  first. The bundle also records the digest that each image reference resolved
+  to, so that after 'singularity cache import' on another system, pulls and
+  builds from the same references can be satisfied from the cache without
+  network access. SIF and OCI-SIF conversions of the images are not exported,
+  as they cannot be verified on import. They are re-created from the OCI blobs
+  when needed.`
+	CacheExportExample string = `
+  $ singularity cache export -o bundle.tar docker://alpine:3.20 docker://ubuntu:24.04
+  $ singularity cache export --platform linux/arm64 -o bundle.tar docker://alpine:3.20`
+
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	// Cache import
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	CacheImportUse   string = `import <bundle>`
+	CacheImportShort string = `Import a bundle written by cache export`
+	CacheImportLong  string = `
+  This will add the OCI blobs and image references held in a bundle written by
+  'singularity cache export' to your cache. All blobs are verified against
+  their digests. When a registry cannot be
+  reached, 'pull', 'build' and action commands using an imported docker://
+  reference will then use the cached image.`
+	CacheImportExample string = `
+  $ singularity cache import bundle.tar
+  $ singularity pull docker://alpine:3.20`
+
 	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 	// key
 	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
@@ -557,6 +671,12 @@ Enterprise Performance Computing (EPC)`
   will be executed with the instance start command as well. You can optionally
   pass arguments to startscript.
 
+  The --restart option sets a policy that restarts the startscript when it
+  exits: 'on-failure[:N]' restarts it, up to N times, when it exits with a
+  non-zero status, and 'always' restarts it whenever it exits. A command given
+  with --health-cmd is run in the instance every --health-interval, and the
+  result is shown by 'instance list --json' and 'instance stats'.
+
   singularity instance start accepts the following container formats` + formats
 	InstanceStartExample string = `
   $ singularity instance start /tmp/my-sql.sif mysql
@@ -572,7 +692,10 @@ Enterprise Performance Computing (EPC)`
   Singularity my-sql.sif>
 
   $ singularity instance stop /tmp/my-sql.sif mysql
-  Stopping /tmp/my-sql.sif mysql`
+  Stopping /tmp/my-sql.sif mysql
+
+  $ singularity instance start --restart on-failure:5 \
+      --health-cmd 'mysqladmin ping' --health-interval 1m /tmp/my-sql.sif mysql`
 
 	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 	// instance run
@@ -681,8 +804,19 @@ Enterprise Performance Computing (EPC)`
   into a singularity native SIF image. If the --oci flag is specified then they
   will be encapsulated in an OCI-SIF image.
 
+  With --oci, '--arch all' or a comma separated list of architectures, e.g.
+  '--arch amd64,arm64', pulls a multi-platform image from a docker URI. The
+  resulting OCI-SIF holds the image index, with an image for each platform. When
+  it is run, the image matching the host platform is used.
+
   Images pulled from a shub/oras/http/https URI are always directly downloaded,
-  in the same format as they were uploaded.`
+  in the same format as they were uploaded.
+
+  Failed http(s) downloads are retried, resuming from the last byte received
+  where the server supports range requests. Large images are fetched in
+  parallel chunks. A sha256 checksum can be given with --checksum, or as a
+  '#sha256=<hex>' URL fragment. The download is verified against it before the
+  image is placed in the cache or written to the output file.`
 	PullExample string = `
   From Sylabs cloud library
   $ singularity pull alpine.sif library://alpine:latest
@@ -693,11 +827,17 @@ Enterprise Performance Computing (EPC)`
   From Docker to an OCI-SIF image
   $ singularity pull --oci tensorflow.oci.sif docker://tensorflow/tensorflow:latest
 
+  From Docker to a multi-platform OCI-SIF image
+  $ singularity pull --oci --arch amd64,arm64 alpine.oci.sif docker://alpine:latest
+
   From Shub
   $ singularity pull singularity-images.sif shub://vsoch/singularity-images
 
   From an OCI registry supporting ORAS / OCI artifacts
-  $ singularity pull image.sif oras://<username>.azurecr.io/namespace/image:tag`
+  $ singularity pull image.sif oras://<username>.azurecr.io/namespace/image:tag
+
+  From a web server, verifying the image checksum
+  $ singularity pull --checksum sha256:<hex> image.sif https://example.com/image.sif`
 
 	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 	// push
@@ -714,6 +854,18 @@ Enterprise Performance Computing (EPC)`
   oras:
       oras://registry/namespace/repo:tag
 
+  docker:
+      docker://registry/namespace/repo:tag
+
+  OCI-SIF images, including data containers, can be pushed to OCI registries
+  with docker://. Data containers are pushed with their data container artifact
+  type. The --subject flag attaches the pushed image to another image in the
+  same repository, as an OCI referrer, so that a data container can be
+  discovered from the application image that uses it.
+
+  A multi-platform OCI-SIF image is pushed to an OCI registry as an image index
+  (manifest list), with an image for each platform.
+
 
   NOTE: It's always good practice to sign your containers before
   pushing them to the library. An auth token is required to push to the library,
@@ -723,7 +875,11 @@ Enterprise Performance Computing (EPC)`
   $ singularity push /home/user/my.sif library://user/collection/my.sif:latest
 
   To supported OCI registry
-  $ singularity push /home/user/my.sif oras://registry/namespace/image:tag`
+  $ singularity push /home/user/my.sif oras://registry/namespace/image:tag
+
+  Data container, as a referrer of an application image
+  $ singularity push --subject docker://registry/namespace/repo:app \
+      /home/user/dataset.oci.sif docker://registry/namespace/repo:dataset`
 
 	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 	// search
@@ -892,9 +1048,29 @@ Enterprise Performance Computing (EPC)`
   Inspect will show you labels, environment variables, apps and scripts associated 
   with the image determined by the flags you pass. By default, they will be shown in 
   plain text. If you would like to list them in json format, you should use the --json flag.
+
+  For OCI-SIF images, the --oci-config, --manifest, --history and --layers flags
+  show the OCI image config, manifest, build history, and layers read directly
+  from the OCI image held in the file, without running the container. Layers are
+  listed with their digests, sizes, media types and formats, together with any
+  writable overlay and cosign signatures. For a multi-platform OCI-SIF, the image
+  for the host platform is inspected.
+
+  The --sbom flag shows the SBOM embedded in a SIF or OCI-SIF image by
+  'singularity build --sbom'.
   `
 	InspectExample string = `
   $ singularity inspect ubuntu.sif
+
+  To list the platforms of the images in a multi-platform OCI-SIF image
+  $ singularity inspect --platforms alpine.oci.sif
+
+  To list the layers of an OCI-SIF image, or show its OCI image config
+  $ singularity inspect --layers alpine.oci.sif
+  $ singularity inspect --oci-config alpine.oci.sif
+
+  To show the SBOM embedded in an image
+  $ singularity inspect --sbom ubuntu.sif
   
   If you want to list the applications (apps) installed in a container (located at
   /scif/apps) you should run inspect command with --list-apps <container-image> flag.
@@ -1029,6 +1205,15 @@ Enterprise Performance Computing (EPC)`
 	OciDeleteExample string = `
   $ singularity oci delete mycontainer`
 
+	OciCleanupUse   string = `cleanup <container_ID>`
+	OciCleanupShort string = `Cleanup resources of an exited container (root user only)`
+	OciCleanupLong  string = `
+  Cleanup is invoked by conmon once a container has exited. It deletes 
+  resources that were created for the container identified by container ID, 
+  and removes the bundle of a container started as an instance.`
+	OciCleanupExample string = `
+  $ singularity oci cleanup mycontainer`
+
 	OciAttachUse   string = `attach <container_ID>`
 	OciAttachShort string = `Attach console to a running container process (root user only)`
 	OciAttachLong  string = `
@@ -1210,13 +1395,55 @@ Enterprise Performance Computing (EPC)`
 	DataPackageLong  string = `
   The data package command creates an OCI-SIF data container, which packages
   a file or directory into a convenient format that can be distributed alongside
-  application container images, and mounted into a container at runtime.`
+  application container images, and mounted into a container at runtime.
+
+  With --append, the file or directory is added to an existing data container
+  as a new layer. Each layer holds a version of the data container content, and
+  the latest version is the one that is mounted, listed, and extracted.`
 	DataPackageExample string = `
   To create a data container that packages the contents of mydir:
   $ singularity data package mydir data.oci.sif
 
   To create a data container that package a single file:
-  $ singularity data package mydir/myfile data.oci.sif`
+  $ singularity data package mydir/myfile data.oci.sif
+
+  To add the updated contents of mydir as a new version of a data container:
+  $ singularity data package --append mydir data.oci.sif`
+
+	DataInspectUse   string = `inspect [inspect options...] <data container>`
+	DataInspectShort string = `Show the details and content of a data container`
+	DataInspectLong  string = `
+  The data inspect command shows the artifact type, digest, and size of a data
+  container, the digest and size of each of its versions, and the file tree of
+  its latest version.`
+	DataInspectExample string = `
+  $ singularity data inspect data.oci.sif
+  $ singularity data inspect --json data.oci.sif`
+
+	DataListUse   string = `ls <data container> [path]`
+	DataListShort string = `List the content of a data container`
+	DataListLong  string = `
+  The data ls command lists the files in a directory of the latest version of
+  a data container, or the root directory if no path is given. The data
+  container is read directly, without mounting it.`
+	DataListExample string = `
+  $ singularity data ls data.oci.sif
+  $ singularity data ls data.oci.sif /mydir`
+
+	DataExtractUse   string = `extract <data container> <path> <destination>`
+	DataExtractShort string = `Extract a file or directory from a data container`
+	DataExtractLong  string = `
+  The data extract command copies a file or directory from the latest version
+  of a data container to the destination path on the host. The data container
+  is read directly, without mounting it. If the destination is an existing
+  directory, the file or directory is extracted into it. Existing files are
+  never overwritten.`
+	DataExtractExample string = `
+  To extract a single file to the current directory:
+  $ singularity data extract data.oci.sif /mydir/myfile .
+
+  To extract a directory to a new directory named out:
+  $ singularity data extract data.oci.sif /mydir out`
 )
 
 // Documentation for sif/siftool command.
@@ -1233,4 +1460,90 @@ const (
 
   $ singularity help sif list
   $ singularity sif list --help`
+
+	SIFEncryptUse   string = `encrypt [encrypt options...] <image path>`
+	SIFEncryptShort string = `Encrypt the root filesystem of an existing SIF image`
+	SIFEncryptLong  string = `
+  The sif encrypt command encrypts the squashfs root filesystem of an existing
+  SIF image, in place. The image is encrypted as by 'singularity build
+  --encrypt', using a passphrase, or an RSA public key in PEM format that
+  wraps a random key stored in the image.
+
+  The image may instead be encrypted for multiple recipients, by giving
+  --pem-path and --pgp-key more than once. The random key is stored in the
+  image wrapped for each RSA public key, and each PGP key, identified by its
+  fingerprint in the local keyring. Any one recipient's private key can then
+  be used to run the image.
+
+  Modifying a signed image invalidates its signatures. The command will fail
+  on a signed image, unless --remove-signatures is given. The image can then
+  be re-signed with 'singularity sign'.
+
+  Encryption is performed with cryptsetup, and must be run as root.`
+	SIFEncryptExample string = `
+  To encrypt an image with a passphrase, entered interactively:
+  $ sudo singularity sif encrypt --passphrase image.sif
+
+  To encrypt an image with an RSA public key:
+  $ sudo singularity sif encrypt --pem-path public.pem image.sif
+
+  To encrypt an image for two RSA public keys, and a PGP key:
+  $ sudo singularity sif encrypt --pem-path alice.pem --pem-path bob.pem \
+      --pgp-key 8883491F4268F173C6E5DC49EDECE4F3F38D871E image.sif`
+
+	SIFRekeyUse   string = `rekey [rekey options...] <image path>`
+	SIFRekeyShort string = `Change the key of an encrypted SIF image`
+	SIFRekeyLong  string = `
+  The sif rekey command changes the key with which the root filesystem of an
+  encrypted SIF image can be decrypted. The current key is given with
+  --pem-path (an RSA private key), --pgp-key (a PGP private key in the local
+  keyring) or --passphrase, and the new key with --new-pem-path (an RSA
+  public key) or --new-passphrase. All existing recipients of the image are
+  replaced by the new keys.
+
+  Where no passphrase is involved, the random key stored in the image is
+  re-wrapped with the new public keys, and the encrypted filesystem is not
+  modified. Otherwise, the key is replaced in the LUKS header of the
+  filesystem, which requires cryptsetup and must be run as root.
+
+  Recipients can also be added to, or removed from, an image encrypted with
+  PEM or PGP keys, leaving the others in place. --add-recipient takes the
+  path to an RSA public key, or pgp:<fingerprint> of a PGP key in the local
+  keyring, and requires a current key to unwrap the random key.
+  --remove-recipient takes an RSA key path, pgp:<fingerprint>, or the
+  recipient ID shown by 'singularity sif info'. Recipients added by earlier
+  versions of Singularity have no recipient ID, and are removed together with
+  --remove-recipient legacy/unnamed.
+
+  Re-wrapping with --new-pem-path, and removing a recipient, do not change the
+  random key, which a removed recipient may already hold, so do not revoke
+  their access to the image. To revoke access, decrypt the image with
+  'singularity sif decrypt', and encrypt it again with 'singularity sif
+  encrypt', which generates a new random key.
+
+  As with 'sif encrypt', --remove-signatures is required to modify a signed
+  image.`
+	SIFRekeyExample string = `
+  To re-wrap the key of an image for a new RSA key pair:
+  $ singularity sif rekey --pem-path old-private.pem --new-pem-path new-public.pem image.sif
+
+  To change the passphrase of an image, entering both interactively:
+  $ sudo singularity sif rekey --passphrase --new-passphrase image.sif
+
+  To add an RSA public key, and remove a PGP key, as recipients of an image:
+  $ singularity sif rekey --pem-path private.pem --add-recipient bob.pem \
+      --remove-recipient pgp:8883491F4268F173C6E5DC49EDECE4F3F38D871E image.sif`
+
+	SIFDecryptUse   string = `decrypt [decrypt options...] <image path>`
+	SIFDecryptShort string = `Decrypt the root filesystem of an encrypted SIF image`
+	SIFDecryptLong  string = `
+  The sif decrypt command decrypts the root filesystem of an encrypted SIF
+  image, in place, leaving an unencrypted squashfs root filesystem. The key is
+  given with --pem-path (an RSA private key), --pgp-key (a PGP private key in
+  the local keyring) or --passphrase.
+
+  As with 'sif encrypt', --remove-signatures is required to modify a signed
+  image. Decryption is performed with cryptsetup, and must be run as root.`
+	SIFDecryptExample string = `
+  $ sudo singularity sif decrypt --pem-path private.pem image.sif`
 )
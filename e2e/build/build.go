@@ -10,6 +10,8 @@ package build
 import (
 	"bufio"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -22,12 +24,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/sylabs/sif/v2/pkg/sif"
 	"github.com/sylabs/singularity/v4/e2e/ecl"
 	"github.com/sylabs/singularity/v4/e2e/internal/e2e"
 	"github.com/sylabs/singularity/v4/e2e/internal/testhelper"
 	"github.com/sylabs/singularity/v4/internal/pkg/test/tool/require"
 	"github.com/sylabs/singularity/v4/internal/pkg/test/tool/tmpl"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/fs"
+	"github.com/sylabs/singularity/v4/pkg/image"
 )
 
 var testFileContent = "Test file content\n"
@@ -2407,6 +2411,207 @@ From: %s
 	)
 }
 
+// buildReproducible checks that building the same definition twice with
+// --reproducible, and the same SOURCE_DATE_EPOCH, gives identical SIF images.
+func (c imgBuildTests) buildReproducible(t *testing.T) {
+	tmpdir, cleanup := c.tempDir(t, "build-reproducible-test")
+	t.Cleanup(func() {
+		if !t.Failed() {
+			cleanup()
+		}
+	})
+
+	definition := `Bootstrap: localimage
+From: %s
+
+%%labels
+    Maintainer e2e
+
+%%post
+    echo "reproducible" > /reproducible.txt
+    mkdir -p /opt/data
+    touch /opt/data/empty`
+
+	definition = fmt.Sprintf(definition, e2e.BusyboxSIF(t))
+	defFile := e2e.RawDefFile(t, tmpdir, strings.NewReader(definition))
+
+	digests := make([]string, 2)
+	for i := range digests {
+		imagePath := filepath.Join(tmpdir, fmt.Sprintf("image-%d.sif", i))
+		c.env.RunSingularity(
+			t,
+			e2e.WithProfile(e2e.RootProfile),
+			e2e.WithCommand("build"),
+			e2e.WithEnv([]string{"SOURCE_DATE_EPOCH=1700000000"}),
+			e2e.WithArgs("--reproducible", imagePath, defFile),
+			e2e.ExpectExit(0),
+		)
+		if t.Failed() {
+			return
+		}
+
+		b, err := os.ReadFile(imagePath)
+		if err != nil {
+			t.Fatalf("while reading image: %v", err)
+		}
+		sum := sha256.Sum256(b)
+		digests[i] = hex.EncodeToString(sum[:])
+
+		// Ensure the second build runs at a different time to the first.
+		time.Sleep(1100 * time.Millisecond)
+	}
+
+	if digests[0] != digests[1] {
+		t.Errorf("reproducible builds differ: sha256:%s != sha256:%s", digests[0], digests[1])
+	}
+}
+
+// buildErofs checks that a bare EROFS image can be built, and run with an
+// erofsfuse mount, as kernel EROFS mounts are not allowed by default.
+func (c imgBuildTests) buildErofs(t *testing.T) {
+	require.Command(t, "mkfs.erofs")
+	require.Command(t, "erofsfuse")
+
+	tmpdir, cleanup := c.tempDir(t, "build-erofs-test")
+	t.Cleanup(func() {
+		if !t.Failed() {
+			cleanup()
+		}
+	})
+
+	definition := `Bootstrap: localimage
+From: %s
+
+%%post
+    echo "erofs" > /erofs.txt`
+
+	definition = fmt.Sprintf(definition, e2e.BusyboxSIF(t))
+	defFile := e2e.RawDefFile(t, tmpdir, strings.NewReader(definition))
+	imagePath := filepath.Join(tmpdir, "image.erofs")
+
+	c.env.RunSingularity(
+		t,
+		e2e.WithProfile(e2e.RootProfile),
+		e2e.WithCommand("build"),
+		e2e.WithArgs("--fs", "erofs", imagePath, defFile),
+		e2e.ExpectExit(0),
+	)
+	if t.Failed() {
+		return
+	}
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("Root"),
+		e2e.WithProfile(e2e.RootProfile),
+		e2e.WithCommand("exec"),
+		e2e.WithArgs(imagePath, "cat", "/erofs.txt"),
+		e2e.ExpectExit(0,
+			e2e.ExpectOutput(e2e.ExactMatch, "erofs"),
+		),
+	)
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("UserNamespace"),
+		e2e.WithProfile(e2e.UserNamespaceProfile),
+		e2e.WithCommand("exec"),
+		e2e.WithArgs(imagePath, "cat", "/erofs.txt"),
+		e2e.ExpectExit(0,
+			e2e.ExpectOutput(e2e.ExactMatch, "erofs"),
+		),
+	)
+}
+
+func (c imgBuildTests) buildCompressionExclude(t *testing.T) {
+	tmpdir, cleanup := c.tempDir(t, "build-compression-exclude-test")
+	t.Cleanup(func() {
+		if !t.Failed() {
+			cleanup()
+		}
+	})
+
+	definition := `Bootstrap: localimage
+From: %s
+
+%%post
+    mkdir -p /excluded /keep
+    touch /excluded/file /keep/file /keep/file.pyc /ignored
+
+%%exclude
+    # removed from anywhere in the image
+    *.pyc
+    /excluded/*`
+
+	definition = fmt.Sprintf(definition, e2e.BusyboxSIF(t))
+	defFile := e2e.RawDefFile(t, tmpdir, strings.NewReader(definition))
+	if err := os.WriteFile(filepath.Join(tmpdir, ".singularityignore"), []byte("/ignored\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	imagePath := filepath.Join(tmpdir, "image.sif")
+
+	c.env.RunSingularity(
+		t,
+		e2e.WithProfile(e2e.RootProfile),
+		e2e.WithCommand("build"),
+		e2e.WithArgs("--compression", "xz", imagePath, defFile),
+		e2e.ExpectExit(0),
+	)
+	if t.Failed() {
+		return
+	}
+
+	checkSquashfsComp(t, imagePath, "xz")
+
+	tests := []struct {
+		name     string
+		path     string
+		exitCode int
+	}{
+		{"Kept", "/keep/file", 0},
+		{"ExcludedDirContent", "/excluded/file", 1},
+		{"ExcludedDir", "/excluded", 0},
+		{"ExcludedPattern", "/keep/file.pyc", 1},
+		{"Ignored", "/ignored", 1},
+	}
+	for _, tt := range tests {
+		c.env.RunSingularity(
+			t,
+			e2e.AsSubtest(tt.name),
+			e2e.WithProfile(e2e.UserProfile),
+			e2e.WithCommand("exec"),
+			e2e.WithArgs(imagePath, "test", "-e", tt.path),
+			e2e.ExpectExit(tt.exitCode),
+		)
+	}
+}
+
+// checkSquashfsComp checks that the primary partition of the SIF image at
+// imagePath is a squashfs filesystem with compression comp.
+func checkSquashfsComp(t *testing.T, imagePath, comp string) {
+	fi, err := sif.LoadContainerFromPath(imagePath, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		t.Fatalf("while loading SIF: %v", err)
+	}
+	defer fi.UnloadContainer()
+
+	d, err := fi.GetDescriptor(sif.WithPartitionType(sif.PartPrimSys))
+	if err != nil {
+		t.Fatalf("while getting primary partition: %v", err)
+	}
+	b, err := d.GetData()
+	if err != nil {
+		t.Fatalf("while reading primary partition: %v", err)
+	}
+	got, err := image.GetSquashfsComp(b)
+	if err != nil {
+		t.Fatalf("while reading squashfs compression: %v", err)
+	}
+	if got != comp {
+		t.Errorf("got squashfs compression %q, want %q", got, comp)
+	}
+}
+
 // E2ETests is the main func to trigger the test suite
 func E2ETests(env e2e.TestEnv) testhelper.Tests {
 	c := imgBuildTests{
@@ -2433,6 +2638,9 @@ func E2ETests(env e2e.TestEnv) testhelper.Tests {
 		"customShebang":                   c.buildCustomShebang,            // build image with custom #! in %test and %runscript
 		"no-setgroups":                    c.buildNoSetgroups,              // build with --fakeroot --no-setgroups
 		"buildArgs":                       c.buildWithBuildArgs,            // builds from definition with build args (build arg file) support
+		"reproducible":                    c.buildReproducible,             // build the same definition twice with --reproducible
+		"erofs":                           c.buildErofs,                    // build and run a bare EROFS image
+		"compression and exclude":         c.buildCompressionExclude,       // build with --compression, %exclude and .singularityignore
 		"dockerfile":                      np(c.buildDockerfile),           // build OCI-SIF image from Dockerfile
 		"auth":                            np(c.buildWithAuth),             // build with custom auth file
 		"buildkitd":                       np(c.buildUseExistingBuildkitd), // build using already-running buildkitd
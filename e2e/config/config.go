@@ -1274,5 +1274,6 @@ func E2ETests(env e2e.TestEnv) testhelper.Tests {
 		"config global combination": np(c.configGlobalCombination), // test various global configuration with combination
 		"config user netns":         np(c.configUserNetns),         // test entering a network namespace as an unpriv user
 		"oci config global":         np(c.ociConfigGlobal),         // test various global configuration for OCI mode
+		"oci config encrypted":      np(c.ociConfigEncrypted),      // test global configuration for encrypted images in OCI mode
 	}
 }
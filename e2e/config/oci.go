@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2023, Sylabs Inc. All rights reserved.
+// Copyright (c) 2019-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -7,9 +7,13 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/sylabs/singularity/v4/e2e/internal/e2e"
+	"github.com/sylabs/singularity/v4/internal/pkg/test/tool/require"
+	"github.com/sylabs/singularity/v4/internal/pkg/test/tool/tmpl"
 )
 
 //nolint:maintidx
@@ -303,3 +307,95 @@ func (c configTests) ociConfigGlobal(t *testing.T) {
 		)
 	}
 }
+
+// ociConfigEncrypted tests the directives that apply to running an encrypted
+// OCI-SIF image.
+func (c configTests) ociConfigEncrypted(t *testing.T) {
+	require.Command(t, "cryptsetup")
+
+	tmpDir, cleanup := e2e.MakeTempDir(t, "", "oci-config-", "CONFIG")
+	t.Cleanup(func() {
+		if !t.Failed() {
+			cleanup(t)
+		}
+	})
+
+	pemPublic, pemPrivate := e2e.GeneratePemFiles(t, tmpDir)
+	tmplValues := struct{ Source string }{Source: strings.TrimPrefix(c.env.TestRegistryImage, "docker://")}
+	dockerfile := tmpl.Execute(t, tmpDir, "Dockerfile-", filepath.Join("..", "test", "defs", "Dockerfile.simple.tmpl"), tmplValues)
+	encryptedImage := filepath.Join(tmpDir, "encrypted.oci.sif")
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("PrepareEncryptedOCISIF"),
+		e2e.WithProfile(e2e.OCIRootProfile),
+		e2e.WithCommand("build"),
+		e2e.WithArgs("--encrypt", "--pem-path", pemPublic, encryptedImage, dockerfile),
+		e2e.ExpectExit(0),
+	)
+	argv := []string{"--pem-path", pemPrivate, "oci-sif:" + encryptedImage, "true"}
+
+	tests := []struct {
+		name           string
+		directive      string
+		directiveValue string
+		exit           int
+		resultOp       e2e.SingularityCmdResultOp
+	}{
+		{
+			name:           "AllowContainerEncryptedNo",
+			directive:      "allow container encrypted",
+			directiveValue: "no",
+			exit:           255,
+			resultOp:       e2e.ExpectError(e2e.ContainMatch, "configuration disallows users from running encrypted SIF containers"),
+		},
+		{
+			name:           "AllowContainerEncryptedYes",
+			directive:      "allow container encrypted",
+			directiveValue: "yes",
+			exit:           0,
+		},
+		// Decrypted layers are mounted with the kernel squashfs driver.
+		{
+			name:           "AllowKernelSquashfsNo",
+			directive:      "allow kernel squashfs",
+			directiveValue: "no",
+			exit:           255,
+			resultOp:       e2e.ExpectError(e2e.ContainMatch, "configuration disallows kernel squashfs mounts"),
+		},
+		{
+			name:           "AllowKernelSquashfsYes",
+			directive:      "allow kernel squashfs",
+			directiveValue: "yes",
+			exit:           0,
+		},
+	}
+
+	for _, tt := range tests {
+		c.env.RunSingularity(
+			t,
+			e2e.AsSubtest(tt.name),
+			e2e.WithProfile(e2e.OCIRootProfile),
+			e2e.PreRun(func(t *testing.T) {
+				c.env.RunSingularity(
+					t,
+					e2e.WithProfile(e2e.RootProfile),
+					e2e.WithCommand("config global"),
+					e2e.WithArgs("--set", tt.directive, tt.directiveValue),
+					e2e.ExpectExit(0),
+				)
+			}),
+			e2e.PostRun(func(t *testing.T) {
+				c.env.RunSingularity(
+					t,
+					e2e.WithProfile(e2e.RootProfile),
+					e2e.WithCommand("config global"),
+					e2e.WithArgs("--reset", tt.directive),
+					e2e.ExpectExit(0),
+				)
+			}),
+			e2e.WithCommand("exec"),
+			e2e.WithArgs(argv...),
+			e2e.ExpectExit(tt.exit, tt.resultOp),
+		)
+	}
+}
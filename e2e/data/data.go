@@ -23,6 +23,7 @@ type ctx struct {
 // Check that `data package` creates a valid data container, that can be used.
 func (c ctx) testDataPackage(t *testing.T) {
 	e2e.EnsureOCISIF(t, c.env)
+	e2e.EnsureImage(t, c.env)
 	// <tmpdir>/innner/file
 	outerDir, cleanup := e2e.MakeTempDir(t, c.env.TestDir, "data-package-", "")
 	defer cleanup(t)
@@ -110,12 +111,203 @@ func (c ctx) testDataPackage(t *testing.T) {
 			),
 		)
 
+		// Verify that `--data` also binds the data container in native mode,
+		// with a kernel mount (setuid), or with squashfuse (user namespace).
+		for _, p := range []e2e.Profile{e2e.UserProfile, e2e.UserNamespaceProfile} {
+			c.env.RunSingularity(
+				t,
+				e2e.AsSubtest(tt.name+"/data/"+p.String()),
+				e2e.WithProfile(p),
+				e2e.WithCommand("exec"),
+				e2e.WithArgs("--data", dataSpec, c.env.ImagePath, "/bin/cat", tt.boundFile),
+				e2e.ExpectExit(0,
+					e2e.ExpectOutput(e2e.ExactMatch, string(content)),
+				),
+			)
+		}
+
 		if err := os.Remove(dcPath); err != nil {
 			t.Error(err)
 		}
 	}
 }
 
+// Check that the data inspect, ls, and extract commands read a data container,
+// and that data package --append adds a new version.
+func (c ctx) testDataCommands(t *testing.T) {
+	e2e.EnsureOCISIF(t, c.env)
+	srcDir, cleanup := e2e.MakeTempDir(t, c.env.TestDir, "data-commands-", "")
+	defer cleanup(t)
+	srcFile := filepath.Join(srcDir, "file")
+	if err := fs.WriteFileNoFollow(srcFile, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dcPath := filepath.Join(srcDir, "data.oci.sif")
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("package"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("data"),
+		e2e.WithArgs("package", srcFile, dcPath),
+		e2e.ExpectExit(0),
+	)
+
+	if err := fs.WriteFileNoFollow(srcFile, []byte("v2"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("append"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("data"),
+		e2e.WithArgs("package", "--append", srcFile, dcPath),
+		e2e.ExpectExit(0),
+	)
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("inspect"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("data"),
+		e2e.WithArgs("inspect", dcPath),
+		e2e.ExpectExit(0,
+			e2e.ExpectOutput(e2e.ContainMatch, "application/vnd.sylabs.data-container.v1"),
+			e2e.ExpectOutput(e2e.RegexMatch, `Versions:\s+2`),
+		),
+	)
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("ls"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("data"),
+		e2e.WithArgs("ls", dcPath, "/"),
+		e2e.ExpectExit(0,
+			e2e.ExpectOutput(e2e.ContainMatch, "/file"),
+		),
+	)
+
+	outFile := filepath.Join(srcDir, "extracted")
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("extract"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("data"),
+		e2e.WithArgs("extract", dcPath, "/file", outFile),
+		e2e.ExpectExit(0),
+	)
+	b, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "v2" {
+		t.Errorf("extracted content %q, expected %q", string(b), "v2")
+	}
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("extractExisting"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("data"),
+		e2e.WithArgs("extract", dcPath, "/file", outFile),
+		e2e.ExpectExit(255),
+	)
+}
+
+// Check that data containers can be pushed to, and pulled from, an OCI
+// registry, and used directly from the registry with --data.
+func (c ctx) testDataRegistry(t *testing.T) {
+	e2e.EnsureOCISIF(t, c.env)
+	e2e.EnsureImage(t, c.env)
+	srcDir, cleanup := e2e.MakeTempDir(t, c.env.TestDir, "data-registry-", "")
+	defer cleanup(t)
+	srcFile := filepath.Join(srcDir, "file")
+	if err := fs.WriteFileNoFollow(srcFile, []byte("registry"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dcPath := filepath.Join(srcDir, "data.oci.sif")
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("package"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("data"),
+		e2e.WithArgs("package", srcFile, dcPath),
+		e2e.ExpectExit(0),
+	)
+
+	appRef := fmt.Sprintf("docker://%s/data-container:app", c.env.TestRegistry)
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("push app"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("push"),
+		e2e.WithArgs(c.env.OCISIFPath, appRef),
+		e2e.ExpectExit(0),
+	)
+
+	dataRef := fmt.Sprintf("docker://%s/data-container:data", c.env.TestRegistry)
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("push"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("push"),
+		e2e.WithArgs("--subject", appRef, dcPath, dataRef),
+		e2e.ExpectExit(0),
+	)
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("push tar"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("push"),
+		e2e.WithArgs("--layer-format", "tar", dcPath, dataRef),
+		e2e.ExpectExit(255),
+	)
+
+	pulled := filepath.Join(srcDir, "pulled.oci.sif")
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("pull"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("pull"),
+		e2e.WithArgs(pulled, dataRef),
+		e2e.ExpectExit(0),
+	)
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("inspect"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("data"),
+		e2e.WithArgs("inspect", pulled),
+		e2e.ExpectExit(0,
+			e2e.ExpectOutput(e2e.ContainMatch, "application/vnd.sylabs.data-container.v1"),
+		),
+	)
+
+	tests := []struct {
+		profile e2e.Profile
+		image   string
+	}{
+		{profile: e2e.UserProfile, image: c.env.ImagePath},
+		{profile: e2e.OCIUserProfile, image: c.env.OCISIFPath},
+	}
+	for _, tt := range tests {
+		c.env.RunSingularity(
+			t,
+			e2e.AsSubtest("exec/"+tt.profile.String()),
+			e2e.WithProfile(tt.profile),
+			e2e.WithCommand("exec"),
+			e2e.WithArgs("--data", dataRef+":/data", tt.image, "/bin/cat", "/data/file"),
+			e2e.ExpectExit(0,
+				e2e.ExpectOutput(e2e.ExactMatch, "registry"),
+			),
+		)
+	}
+}
+
 // E2ETests is the main func to trigger the test suite
 func E2ETests(env e2e.TestEnv) testhelper.Tests {
 	c := ctx{
@@ -123,6 +315,8 @@ func E2ETests(env e2e.TestEnv) testhelper.Tests {
 	}
 
 	return testhelper.Tests{
-		"package": c.testDataPackage,
+		"package":  c.testDataPackage,
+		"commands": c.testDataCommands,
+		"registry": c.testDataRegistry,
 	}
 }
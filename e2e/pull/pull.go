@@ -18,6 +18,10 @@ import (
 	"testing"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	ggcrmutate "github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/sylabs/sif/v2/pkg/sif"
@@ -999,6 +1003,129 @@ func (c ctx) testCosignRoundTrip(t *testing.T) {
 	)
 }
 
+// testPullMultiPlatform pushes a multi-platform image index to the local
+// registry, and checks that it can be pulled to a multi-platform OCI-SIF with
+// --arch, inspected, and pushed back as an index.
+func (c ctx) testPullMultiPlatform(t *testing.T) {
+	e2e.EnsureOCISIF(t, c.env)
+
+	tmpDir, cleanup := e2e.MakeTempDir(t, c.env.TestDir, "pull-multi-platform-", "")
+	defer cleanup(t)
+
+	platforms := []ggcrv1.Platform{
+		{OS: "linux", Architecture: "amd64"},
+		{OS: "linux", Architecture: "arm64"},
+		{OS: "linux", Architecture: "ppc64le"},
+	}
+	var ii ggcrv1.ImageIndex = empty.Index
+	for _, p := range platforms {
+		img, err := random.Image(1024, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cf, err := img.ConfigFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cf.OS = p.OS
+		cf.Architecture = p.Architecture
+		img, err = ggcrmutate.ConfigFile(img, cf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ii = ggcrmutate.AppendManifests(ii, ggcrmutate.IndexAddendum{
+			Add:        img,
+			Descriptor: ggcrv1.Descriptor{Platform: &p},
+		})
+	}
+
+	srcRef := fmt.Sprintf("%s/multi-platform:src", c.env.TestRegistry)
+	ref, err := name.ParseReference(srcRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(ref, ii, remote.WithUserAgent("singularity e2e-test")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		arch            string
+		expectExit      int
+		expectPlatforms []string
+	}{
+		{
+			name:            "all",
+			arch:            "all",
+			expectPlatforms: []string{"linux/amd64", "linux/arm64", "linux/ppc64le"},
+		},
+		{
+			name:            "list",
+			arch:            "amd64,arm64",
+			expectPlatforms: []string{"linux/amd64", "linux/arm64"},
+		},
+		{
+			name:       "missing",
+			arch:       "amd64,s390x",
+			expectExit: 255,
+		},
+	}
+
+	for _, tt := range tests {
+		dest := filepath.Join(tmpDir, tt.name+".oci.sif")
+		c.env.RunSingularity(
+			t,
+			e2e.AsSubtest(tt.name+"/pull"),
+			e2e.WithProfile(e2e.OCIUserProfile),
+			e2e.WithCommand("pull"),
+			e2e.WithArgs("--arch", tt.arch, dest, "docker://"+srcRef),
+			e2e.ExpectExit(tt.expectExit),
+		)
+		if tt.expectExit != 0 {
+			continue
+		}
+
+		c.env.RunSingularity(
+			t,
+			e2e.AsSubtest(tt.name+"/inspect"),
+			e2e.WithProfile(e2e.OCIUserProfile),
+			e2e.WithCommand("inspect"),
+			e2e.WithArgs("--platforms", dest),
+			e2e.ExpectExit(0,
+				e2e.ExpectOutput(e2e.ExactMatch, strings.Join(tt.expectPlatforms, "\n")),
+			),
+		)
+
+		pushRef := fmt.Sprintf("%s/multi-platform:%s", c.env.TestRegistry, tt.name)
+		c.env.RunSingularity(
+			t,
+			e2e.AsSubtest(tt.name+"/push"),
+			e2e.WithProfile(e2e.OCIUserProfile),
+			e2e.WithCommand("push"),
+			e2e.WithArgs(dest, "docker://"+pushRef),
+			e2e.ExpectExit(0),
+		)
+
+		t.Run(tt.name+"/checkIndex", func(t *testing.T) {
+			ref, err := name.ParseReference(pushRef)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pushed, err := remote.Index(ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			im, err := pushed.IndexManifest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(im.Manifests) != len(tt.expectPlatforms) {
+				t.Errorf("pushed index has %d manifests, expected %d", len(im.Manifests), len(tt.expectPlatforms))
+			}
+		})
+	}
+}
+
 // E2ETests is the main func to trigger the test suite
 func E2ETests(env e2e.TestEnv) testhelper.Tests {
 	c := ctx{
@@ -1018,6 +1145,7 @@ func E2ETests(env e2e.TestEnv) testhelper.Tests {
 			t.Run("concurrencyConfig", c.testConcurrencyConfig)
 			t.Run("concurrentPulls", c.testConcurrentPulls)
 			t.Run("oci overlay", c.testPullOCIOverlay)
+			t.Run("multi platform", c.testPullMultiPlatform)
 		},
 		"cosign":    c.testCosignRoundTrip,
 		"issue1087": c.issue1087,
@@ -1,4 +1,4 @@
-// Copyright (c) 2020, Sylabs Inc. All rights reserved.
+// Copyright (c) 2020-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -192,6 +192,225 @@ func (c ctx) testRunPassphraseEncrypted(t *testing.T) {
 	)
 }
 
+// testRunSIFCrypt tests running an existing image that has been encrypted,
+// re-keyed, and decrypted with the sif encrypt / rekey / decrypt commands.
+func (c ctx) testRunSIFCrypt(t *testing.T) {
+	err := e2e.CheckCryptsetupVersion()
+	if err != nil {
+		t.Skip("cryptsetup is not compatible, skipping test")
+	}
+
+	pemPubFile, pemPrivFile := e2e.GeneratePemFiles(t, c.env.TestDir)
+	passphraseEnvVar := fmt.Sprintf("%s=%s", "SINGULARITY_ENCRYPTION_PASSPHRASE", e2e.Passphrase)
+	newPassphraseEnvVar := fmt.Sprintf("%s=%s", "SINGULARITY_ENCRYPTION_NEW_PASSPHRASE", e2e.Passphrase)
+
+	tempDir, cleanup := e2e.MakeTempDir(t, c.env.TestDir, "", "")
+	defer cleanup(t)
+
+	imgPath := filepath.Join(tempDir, "sif_crypt.sif")
+	c.env.RunSingularity(
+		t,
+		e2e.WithProfile(e2e.RootProfile),
+		e2e.WithCommand("build"),
+		e2e.WithArgs(imgPath, e2e.BusyboxSIF(t)),
+		e2e.ExpectExit(0),
+	)
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("encrypt pem"),
+		e2e.WithProfile(e2e.RootProfile),
+		e2e.WithCommand("sif encrypt"),
+		e2e.WithArgs("--pem-path", pemPubFile, imgPath),
+		e2e.ExpectExit(0),
+	)
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("encrypt already encrypted"),
+		e2e.WithProfile(e2e.RootProfile),
+		e2e.WithCommand("sif encrypt"),
+		e2e.WithArgs("--pem-path", pemPubFile, imgPath),
+		e2e.ExpectExit(255,
+			e2e.ExpectError(e2e.ContainMatch, "is already encrypted"),
+		),
+	)
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("run pem"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("exec"),
+		e2e.WithArgs("--pem-path", pemPrivFile, imgPath, "/bin/true"),
+		e2e.ExpectExit(0),
+	)
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("rekey pem to passphrase"),
+		e2e.WithProfile(e2e.RootProfile),
+		e2e.WithCommand("sif rekey"),
+		e2e.WithArgs("--pem-path", pemPrivFile, imgPath),
+		e2e.WithEnv(append(os.Environ(), newPassphraseEnvVar)),
+		e2e.ExpectExit(0),
+	)
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("run old pem"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("exec"),
+		e2e.WithArgs("--pem-path", pemPrivFile, imgPath, "/bin/true"),
+		e2e.ExpectExit(255),
+	)
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("run passphrase"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("exec"),
+		e2e.WithArgs(imgPath, "/bin/true"),
+		e2e.WithEnv(append(os.Environ(), passphraseEnvVar)),
+		e2e.ExpectExit(0),
+	)
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("decrypt passphrase"),
+		e2e.WithProfile(e2e.RootProfile),
+		e2e.WithCommand("sif decrypt"),
+		e2e.WithArgs(imgPath),
+		e2e.WithEnv(append(os.Environ(), passphraseEnvVar)),
+		e2e.ExpectExit(0),
+	)
+
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("run decrypted"),
+		e2e.WithProfile(e2e.UserProfile),
+		e2e.WithCommand("exec"),
+		e2e.WithArgs(imgPath, "/bin/true"),
+		e2e.ExpectExit(0),
+	)
+}
+
+// testRunMultipleRecipients checks that an image encrypted for multiple PEM
+// key recipients can be run with any of their private keys, and that
+// recipients can be added and removed.
+func (c ctx) testRunMultipleRecipients(t *testing.T) {
+	err := e2e.CheckCryptsetupVersion()
+	if err != nil {
+		t.Skip("cryptsetup is not compatible, skipping test")
+	}
+
+	pubA, privA := e2e.GeneratePemFiles(t, c.env.TestDir)
+	pubB, privB := e2e.GeneratePemFiles(t, c.env.TestDir)
+	pubC, privC := e2e.GeneratePemFiles(t, c.env.TestDir)
+
+	tempDir, cleanup := e2e.MakeTempDir(t, c.env.TestDir, "", "")
+	defer cleanup(t)
+
+	imgPath := filepath.Join(tempDir, "encrypted_multiple_recipients.sif")
+	c.env.RunSingularity(
+		t,
+		e2e.AsSubtest("build"),
+		e2e.WithProfile(e2e.RootProfile),
+		e2e.WithCommand("build"),
+		e2e.WithArgs("--encrypt", "--pem-path", pubA, "--pem-path", pubB, imgPath, e2e.BusyboxSIF(t)),
+		e2e.ExpectExit(0),
+	)
+
+	tests := []struct {
+		name     string
+		command  string
+		args     []string
+		exit     int
+		errMatch string
+	}{
+		{
+			name:    "run recipient A",
+			command: "exec",
+			args:    []string{"--pem-path", privA, imgPath, "/bin/true"},
+		},
+		{
+			name:    "run recipient B",
+			command: "exec",
+			args:    []string{"--pem-path", privB, imgPath, "/bin/true"},
+		},
+		{
+			name:    "run non-recipient C",
+			command: "exec",
+			args:    []string{"--pem-path", privC, imgPath, "/bin/true"},
+			exit:    255,
+		},
+		{
+			name:    "run non-recipient C then recipient B",
+			command: "exec",
+			args:    []string{"--pem-path", privC, "--pem-path", privB, imgPath, "/bin/true"},
+		},
+		{
+			name:    "add recipient C",
+			command: "sif rekey",
+			args:    []string{"--pem-path", privA, "--add-recipient", pubC, imgPath},
+		},
+		{
+			name:     "add existing recipient C",
+			command:  "sif rekey",
+			args:     []string{"--pem-path", privA, "--add-recipient", pubC, imgPath},
+			exit:     255,
+			errMatch: "is already a recipient",
+		},
+		{
+			name:    "run added recipient C",
+			command: "exec",
+			args:    []string{"--pem-path", privC, imgPath, "/bin/true"},
+		},
+		{
+			name:    "remove recipient A",
+			command: "sif rekey",
+			args:    []string{"--remove-recipient", privA, imgPath},
+		},
+		{
+			name:    "run removed recipient A",
+			command: "exec",
+			args:    []string{"--pem-path", privA, imgPath, "/bin/true"},
+			exit:    255,
+		},
+		{
+			name:     "remove all recipients",
+			command:  "sif rekey",
+			args:     []string{"--remove-recipient", pubB, "--remove-recipient", pubC, imgPath},
+			exit:     255,
+			errMatch: "no recipients",
+		},
+		{
+			name:    "run remaining recipient B",
+			command: "exec",
+			args:    []string{"--pem-path", privB, imgPath, "/bin/true"},
+		},
+	}
+
+	for _, tt := range tests {
+		exitFunc := []e2e.SingularityCmdResultOp{}
+		if tt.errMatch != "" {
+			exitFunc = append(exitFunc, e2e.ExpectError(e2e.ContainMatch, tt.errMatch))
+		}
+		// Rewriting the image, which is owned by root, requires root.
+		profile := e2e.UserProfile
+		if tt.command != "exec" {
+			profile = e2e.RootProfile
+		}
+		c.env.RunSingularity(
+			t,
+			e2e.AsSubtest(tt.name),
+			e2e.WithProfile(profile),
+			e2e.WithCommand(tt.command),
+			e2e.WithArgs(tt.args...),
+			e2e.ExpectExit(tt.exit, exitFunc...),
+		)
+	}
+}
+
 // E2ETests is the main func to trigger the test suite
 func E2ETests(env e2e.TestEnv) testhelper.Tests {
 	c := ctx{
@@ -202,5 +421,7 @@ func E2ETests(env e2e.TestEnv) testhelper.Tests {
 		"0555 cache":           c.testRun555Cache,
 		"passphrase encrypted": c.testRunPassphraseEncrypted,
 		"PEM encrypted":        c.testRunPEMEncrypted,
+		"sif encrypt":          c.testRunSIFCrypt,
+		"multiple recipients":  c.testRunMultipleRecipients,
 	}
 }
@@ -0,0 +1,91 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package singularity
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sylabs/singularity/v4/internal/pkg/cache"
+	"github.com/sylabs/singularity/v4/internal/pkg/ociimage"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+)
+
+// ExportCache writes a bundle, holding the cached OCI blobs for the OCI
+// registry (docker://) image URIs uris, to the file dest. Images that are not
+// fully cached are fetched into the cache first, for the platform in tOpts.
+func ExportCache(ctx context.Context, imgCache *cache.Handle, tOpts *ociimage.TransportOptions, uris []string, dest string) (err error) {
+	if imgCache == nil || imgCache.IsDisabled() {
+		return errInvalidCacheHandle
+	}
+
+	refs := make([]string, 0, len(uris))
+	for _, u := range uris {
+		srcType, srcRef, err := ociimage.URItoSourceSinkRef(u)
+		if err != nil {
+			return err
+		}
+		if srcType != ociimage.RegistrySourceSink {
+			return fmt.Errorf("%s is not an OCI registry (docker://) image", u)
+		}
+		ref, ok := srcType.Reference(srcRef, tOpts)
+		if !ok {
+			return fmt.Errorf("invalid reference %q", srcRef)
+		}
+
+		// Resolve the reference, which records it in the cache, and ensure
+		// that the image is fully cached.
+		sylog.Infof("Caching %s", u)
+		if _, err := ociimage.ImageDigest(ctx, tOpts, imgCache, u); err != nil {
+			return fmt.Errorf("while resolving %s: %w", u, err)
+		}
+		if _, err := ociimage.LocalImage(ctx, tOpts, imgCache, u, tOpts.TmpDir); err != nil {
+			return fmt.Errorf("while caching %s: %w", u, err)
+		}
+		refs = append(refs, ref.Name())
+	}
+
+	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(dest)
+		}
+	}()
+
+	sylog.Infof("Writing cache bundle to %s", dest)
+	if err := imgCache.Export(f, refs); err != nil {
+		return fmt.Errorf("while exporting cache: %w", err)
+	}
+	return f.Close()
+}
+
+// ImportCache adds the content of a bundle, written by ExportCache, from the
+// file src to the cache.
+func ImportCache(imgCache *cache.Handle, src string) error {
+	if imgCache == nil || imgCache.IsDisabled() {
+		return errInvalidCacheHandle
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	refs, err := imgCache.Import(f)
+	if err != nil {
+		return fmt.Errorf("while importing cache bundle: %w", err)
+	}
+	for _, ref := range refs {
+		sylog.Infof("Imported %s", ref)
+	}
+	return nil
+}
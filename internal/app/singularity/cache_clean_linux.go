@@ -54,3 +54,21 @@ func CleanSingularityCache(imgCache *cache.Handle, dryRun bool, cacheCleanTypes
 
 	return nil
 }
+
+// CleanSingularityCacheToSize removes the least recently used entries from
+// the cache types listed in cacheCleanTypes, until their total size is no
+// more than maxSize bytes. The special value "all" is interpreted as "all
+// types of entries". If dryRun is true, only provide a summary of what would
+// have been done.
+func CleanSingularityCacheToSize(imgCache *cache.Handle, dryRun bool, cacheCleanTypes []string, maxSize int64) error {
+	if imgCache == nil {
+		return errInvalidCacheHandle
+	}
+
+	cachesToClean := cache.AllCacheTypes
+	if len(cacheCleanTypes) > 0 && !slices.Contains(cacheCleanTypes, "all") {
+		cachesToClean = cacheCleanTypes
+	}
+
+	return imgCache.CleanCacheToSize(cachesToClean, dryRun, maxSize)
+}
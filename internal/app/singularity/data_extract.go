@@ -0,0 +1,96 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package singularity
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/sylabs/singularity/v4/internal/pkg/ocisif"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"golang.org/x/sys/unix"
+)
+
+// DataExtract extracts the file or directory at name, in the latest version
+// of the data container at src, to dst. The content is read directly from the
+// squashfs layer of the data container, which is not mounted. If dst is an
+// existing directory, the content is extracted into it. Existing files are
+// never overwritten.
+func DataExtract(src, name, dst string) error {
+	dc, err := ocisif.OpenDataContainer(src)
+	if err != nil {
+		return err
+	}
+	defer dc.Close()
+
+	fsys, err := dc.FS()
+	if err != nil {
+		return err
+	}
+
+	name = dataPath(name)
+	if _, err := fs.Stat(fsys, name); err != nil {
+		return fmt.Errorf("while reading %s from data container: %w", name, err)
+	}
+
+	if fi, err := os.Stat(dst); err == nil && fi.IsDir() {
+		dst = filepath.Join(dst, path.Base(name))
+	}
+
+	return fs.WalkDir(fsys, name, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(name, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			sylog.Debugf("Creating directory %s", target)
+			if err := os.Mkdir(target, fi.Mode().Perm()|0o700); err != nil && !(os.IsExist(err) && p == name) {
+				return err
+			}
+			return nil
+		case fi.Mode().IsRegular():
+			sylog.Debugf("Extracting %s to %s", p, target)
+			return extractDataFile(fsys, p, target, fi.Mode().Perm())
+		default:
+			sylog.Warningf("Skipping %s, unsupported file type %s", p, fi.Mode().Type())
+			return nil
+		}
+	})
+}
+
+// extractDataFile copies the regular file name in fsys to a new file target.
+func extractDataFile(fsys fs.FS, name, target string, perm fs.FileMode) error {
+	in, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL|unix.O_NOFOLLOW, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("while extracting %s: %w", name, err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package singularity
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"text/tabwriter"
+
+	units "github.com/docker/go-units"
+	"github.com/sylabs/singularity/v4/internal/pkg/ocisif"
+)
+
+type dataVersion struct {
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+}
+
+type dataFile struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+	Size int64  `json:"size"`
+}
+
+type dataInfo struct {
+	ArtifactType string        `json:"artifactType"`
+	Digest       string        `json:"digest"`
+	Size         int64         `json:"size"`
+	Versions     []dataVersion `json:"versions"`
+	Files        []dataFile    `json:"files"`
+}
+
+// DataInspect writes the artifact type, digest, size, versions, and the file
+// tree of the latest version, of the data container at src to w.
+func DataInspect(w io.Writer, src string, jsonFormat bool) error {
+	dc, err := ocisif.OpenDataContainer(src)
+	if err != nil {
+		return err
+	}
+	defer dc.Close()
+
+	img := dc.Image()
+	digest, err := img.Digest()
+	if err != nil {
+		return fmt.Errorf("while getting digest: %w", err)
+	}
+	info := dataInfo{
+		ArtifactType: ocisif.DataContainerArtifactType,
+		Digest:       digest.String(),
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return fmt.Errorf("while getting layers: %w", err)
+	}
+	for _, l := range layers {
+		d, err := l.Digest()
+		if err != nil {
+			return fmt.Errorf("while getting layer digest: %w", err)
+		}
+		size, err := l.Size()
+		if err != nil {
+			return fmt.Errorf("while getting layer size: %w", err)
+		}
+		info.Versions = append(info.Versions, dataVersion{Digest: d.String(), Size: size})
+		info.Size += size
+	}
+
+	fsys, err := dc.FS()
+	if err != nil {
+		return err
+	}
+	info.Files, err = dataFiles(fsys, ".", true)
+	if err != nil {
+		return err
+	}
+
+	if jsonFormat {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Artifact Type:\t%s\n", info.ArtifactType)
+	fmt.Fprintf(tw, "Digest:\t%s\n", info.Digest)
+	fmt.Fprintf(tw, "Size:\t%s\n", units.BytesSize(float64(info.Size)))
+	fmt.Fprintf(tw, "Versions:\t%d\n", len(info.Versions))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\nVersions:")
+	tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for i, v := range info.Versions {
+		latest := ""
+		if i == len(info.Versions)-1 {
+			latest = "(latest)"
+		}
+		fmt.Fprintf(tw, "  %d\t%s\t%s\t%s\n", i+1, v.Digest, units.BytesSize(float64(v.Size)), latest)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\nFiles:")
+	return writeDataFiles(w, info.Files, "  ")
+}
+
+// DataList writes the entries of the directory at dir, or the file at dir, in
+// the latest version of the data container at src to w.
+func DataList(w io.Writer, src, dir string) error {
+	dc, err := ocisif.OpenDataContainer(src)
+	if err != nil {
+		return err
+	}
+	defer dc.Close()
+
+	fsys, err := dc.FS()
+	if err != nil {
+		return err
+	}
+	files, err := dataFiles(fsys, dataPath(dir), false)
+	if err != nil {
+		return err
+	}
+	return writeDataFiles(w, files, "")
+}
+
+// dataPath converts a path in a data container, which may be absolute, to a
+// path that is valid for an fs.FS.
+func dataPath(p string) string {
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+// dataFiles returns the entry at name in fsys, and its children. If recursive
+// is true, all descendants of name are returned.
+func dataFiles(fsys fs.FS, name string, recursive bool) ([]dataFile, error) {
+	files := []dataFile{}
+	err := fs.WalkDir(fsys, name, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, dataFile{
+			Path: path.Join("/", p),
+			Mode: fi.Mode().String(),
+			Size: fi.Size(),
+		})
+		if d.IsDir() && p != name && !recursive {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("while reading data container: %w", err)
+	}
+	return files, nil
+}
+
+// writeDataFiles writes files to w, one per line, with their mode and size.
+func writeDataFiles(w io.Writer, files []dataFile, indent string) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	for _, f := range files {
+		fmt.Fprintf(tw, "%s%s\t%d\t %s\n", indent, f.Mode, f.Size, f.Path)
+	}
+	return tw.Flush()
+}
@@ -1,7 +1,7 @@
 // Copyright (c) 2024-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
-// rights to use or distribute this software.package singularity
+// rights to use or distribute this software.
 
 package singularity
 
@@ -13,9 +13,15 @@ import (
 	"github.com/sylabs/singularity/v4/pkg/sylog"
 )
 
-// DataPackage packages src into a data container at dst.
-func DataPackage(src, dst string) error {
-	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+// DataPackage packages src into a data container at dst. If appendLayer is
+// true, src is appended to the existing data container at dst as a new layer,
+// which becomes its latest version.
+func DataPackage(src, dst string, appendLayer bool) error {
+	_, err := os.Stat(dst)
+	if appendLayer && err != nil {
+		return fmt.Errorf("cannot append to %s: %w", dst, err)
+	}
+	if !appendLayer && !os.IsNotExist(err) {
 		return fmt.Errorf("%s already exists - will not overwrite", dst)
 	}
 
@@ -31,5 +37,8 @@ func DataPackage(src, dst string) error {
 		}
 	}()
 
+	if appendLayer {
+		return ocisif.AppendDataContainerFromPath(src, dst, tmpDir)
+	}
 	return ocisif.WriteDataContainerFromPath(src, dst, tmpDir)
 }
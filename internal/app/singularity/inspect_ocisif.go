@@ -0,0 +1,296 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package singularity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	units "github.com/docker/go-units"
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
+	cosignremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	ocitsif "github.com/sylabs/oci-tools/pkg/sif"
+	"github.com/sylabs/sif/v2/pkg/sif"
+	"github.com/sylabs/singularity/v4/internal/pkg/ocisif"
+)
+
+// InspectOCISIFPlatforms writes the platforms of the images in the OCI-SIF at
+// src to w, one per line. A multi-platform OCI-SIF holds an image for each of
+// its platforms.
+func InspectOCISIFPlatforms(w io.Writer, src string, jsonFormat bool) error {
+	fi, err := sif.LoadContainerFromPath(src, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		return fmt.Errorf("while loading SIF: %w", err)
+	}
+	defer fi.UnloadContainer()
+
+	platforms, err := ocisif.Platforms(fi)
+	if err != nil {
+		return fmt.Errorf("while reading platforms: %w", err)
+	}
+
+	if jsonFormat {
+		ps := make([]string, 0, len(platforms))
+		for _, p := range platforms {
+			ps = append(ps, p.String())
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(map[string][]string{"platforms": ps})
+	}
+
+	for _, p := range platforms {
+		fmt.Fprintln(w, p.String())
+	}
+	return nil
+}
+
+type ociSIFLayer struct {
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+	MediaType string `json:"mediaType"`
+	Format    string `json:"format"`
+}
+
+type ociSIFSignature struct {
+	RefName   string `json:"refName"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+	MediaType string `json:"mediaType"`
+}
+
+type ociSIFLayers struct {
+	Manifest   string            `json:"manifest"`
+	Layers     []ociSIFLayer     `json:"layers"`
+	Overlay    bool              `json:"overlay"`
+	Signatures []ociSIFSignature `json:"signatures"`
+}
+
+type ociSIFHistory struct {
+	Created    string `json:"created,omitempty"`
+	CreatedBy  string `json:"createdBy,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+	EmptyLayer bool   `json:"emptyLayer"`
+}
+
+// loadOCISIFImage loads the OCI-SIF at src, and returns it with the image that
+// would be run on this host. The caller must unload the returned FileImage.
+func loadOCISIFImage(src string) (*sif.FileImage, ggcrv1.Image, error) {
+	fi, err := sif.LoadContainerFromPath(src, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		return nil, nil, fmt.Errorf("while loading SIF: %w", err)
+	}
+	img, err := ocisif.GetRuntimeImage(fi)
+	if err != nil {
+		fi.UnloadContainer()
+		return nil, nil, fmt.Errorf("while getting image: %w", err)
+	}
+	return fi, img, nil
+}
+
+// writeIndentedJSON writes the JSON document raw to w, indented.
+func writeIndentedJSON(w io.Writer, raw []byte) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "\t"); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// InspectOCISIFManifest writes the image manifest of the OCI-SIF at src to w.
+// For a multi-platform OCI-SIF, the manifest of the image for the host platform
+// is written.
+func InspectOCISIFManifest(w io.Writer, src string) error {
+	fi, img, err := loadOCISIFImage(src)
+	if err != nil {
+		return err
+	}
+	defer fi.UnloadContainer()
+
+	raw, err := img.RawManifest()
+	if err != nil {
+		return fmt.Errorf("while reading manifest: %w", err)
+	}
+	return writeIndentedJSON(w, raw)
+}
+
+// InspectOCISIFConfig writes the image config of the OCI-SIF at src to w. For a
+// multi-platform OCI-SIF, the config of the image for the host platform is
+// written.
+func InspectOCISIFConfig(w io.Writer, src string) error {
+	fi, img, err := loadOCISIFImage(src)
+	if err != nil {
+		return err
+	}
+	defer fi.UnloadContainer()
+
+	raw, err := img.RawConfigFile()
+	if err != nil {
+		return fmt.Errorf("while reading config: %w", err)
+	}
+	return writeIndentedJSON(w, raw)
+}
+
+// InspectOCISIFHistory writes the history recorded in the image config of the
+// OCI-SIF at src to w, oldest first.
+func InspectOCISIFHistory(w io.Writer, src string, jsonFormat bool) error {
+	fi, img, err := loadOCISIFImage(src)
+	if err != nil {
+		return err
+	}
+	defer fi.UnloadContainer()
+
+	cf, err := img.ConfigFile()
+	if err != nil {
+		return fmt.Errorf("while reading config: %w", err)
+	}
+	history := make([]ociSIFHistory, 0, len(cf.History))
+	for _, h := range cf.History {
+		oh := ociSIFHistory{
+			CreatedBy:  h.CreatedBy,
+			Comment:    h.Comment,
+			EmptyLayer: h.EmptyLayer,
+		}
+		if !h.Created.IsZero() {
+			oh.Created = h.Created.UTC().Format(time.RFC3339)
+		}
+		history = append(history, oh)
+	}
+
+	if jsonFormat {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(map[string][]ociSIFHistory{"history": history})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "CREATED\tCREATED BY\tEMPTY LAYER\tCOMMENT")
+	for _, h := range history {
+		fmt.Fprintf(tw, "%s\t%s\t%t\t%s\n", h.Created, strings.Join(strings.Fields(h.CreatedBy), " "), h.EmptyLayer, h.Comment)
+	}
+	return tw.Flush()
+}
+
+// InspectOCISIFLayers writes the layers of the image in the OCI-SIF at src to w,
+// with their digests, sizes, media types and formats, whether the final layer
+// is a writable overlay, and any cosign signatures held in the OCI-SIF.
+func InspectOCISIFLayers(w io.Writer, src string, jsonFormat bool) error {
+	fi, img, err := loadOCISIFImage(src)
+	if err != nil {
+		return err
+	}
+	defer fi.UnloadContainer()
+
+	digest, err := img.Digest()
+	if err != nil {
+		return fmt.Errorf("while getting digest: %w", err)
+	}
+	mf, err := img.Manifest()
+	if err != nil {
+		return fmt.Errorf("while reading manifest: %w", err)
+	}
+	info := ociSIFLayers{
+		Manifest:   digest.String(),
+		Layers:     make([]ociSIFLayer, 0, len(mf.Layers)),
+		Signatures: []ociSIFSignature{},
+	}
+	for _, l := range mf.Layers {
+		info.Layers = append(info.Layers, ociSIFLayer{
+			Digest:    l.Digest.String(),
+			Size:      l.Size,
+			MediaType: string(l.MediaType),
+			Format:    layerFormat(l.MediaType),
+		})
+	}
+	if n := len(mf.Layers); n > 0 {
+		info.Overlay = mf.Layers[n-1].MediaType == ocisif.Ext3LayerMediaType
+	}
+
+	ofi, err := ocitsif.FromFileImage(fi)
+	if err != nil {
+		return err
+	}
+	ri, err := ofi.RootIndex()
+	if err != nil {
+		return err
+	}
+	im, err := ri.IndexManifest()
+	if err != nil {
+		return fmt.Errorf("while reading root index: %w", err)
+	}
+	for _, d := range im.Manifests {
+		// Other cosign artifacts, such as an SBOM, are not signatures.
+		refName := d.Annotations[imagespec.AnnotationRefName]
+		if ocisif.SkipCosignMatcher(d) || !strings.HasSuffix(refName, "."+cosignremote.SignatureTagSuffix) {
+			continue
+		}
+		info.Signatures = append(info.Signatures, ociSIFSignature{
+			RefName:   refName,
+			Digest:    d.Digest.String(),
+			Size:      d.Size,
+			MediaType: string(d.MediaType),
+		})
+	}
+
+	if jsonFormat {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(info)
+	}
+
+	fmt.Fprintf(w, "Manifest: %s\n", info.Manifest)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "LAYER\tDIGEST\tSIZE\tFORMAT\tMEDIA TYPE")
+	for i, l := range info.Layers {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", i+1, l.Digest, units.BytesSize(float64(l.Size)), l.Format, l.MediaType)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if info.Overlay {
+		fmt.Fprintln(w, "The final layer is a writable overlay.")
+	}
+	if len(info.Signatures) > 0 {
+		fmt.Fprintln(w, "Signatures:")
+		tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		for _, s := range info.Signatures {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", s.Digest, units.BytesSize(float64(s.Size)), s.RefName)
+		}
+		return tw.Flush()
+	}
+	return nil
+}
+
+// layerFormat returns a short description of the format of a layer with media
+// type mt.
+func layerFormat(mt types.MediaType) string {
+	switch mt {
+	case ocisif.SquashfsLayerMediaType:
+		return "squashfs"
+	case ocisif.EncryptedSquashfsLayerMediaType:
+		return "squashfs (encrypted)"
+	case ocisif.Ext3LayerMediaType:
+		return "ext3 overlay"
+	case types.OCILayer, types.DockerLayer:
+		return "tar+gzip"
+	case types.OCIUncompressedLayer, types.DockerUncompressedLayer:
+		return "tar"
+	case types.OCILayerZStd:
+		return "tar+zstd"
+	default:
+		return "unknown"
+	}
+}
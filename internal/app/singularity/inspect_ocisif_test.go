@@ -0,0 +1,507 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package singularity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	units "github.com/docker/go-units"
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
+	ocitsif "github.com/sylabs/oci-tools/pkg/sif"
+	"github.com/sylabs/oci-tools/pkg/sourcesink"
+	"github.com/sylabs/singularity/v4/internal/pkg/ociplatform"
+	"github.com/sylabs/singularity/v4/internal/pkg/ocisif"
+)
+
+// testInspectImage returns an image for platform p, with a squashfs layer and
+// a writable overlay layer. If withHistory is set, its config records a build
+// history.
+func testInspectImage(t *testing.T, p ggcrv1.Platform, withHistory bool) ggcrv1.Image {
+	t.Helper()
+
+	img := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	img = mutate.ConfigMediaType(img, types.OCIConfigJSON)
+	img, err := mutate.AppendLayers(img,
+		static.NewLayer([]byte("squashfs"), ocisif.SquashfsLayerMediaType),
+		static.NewLayer([]byte("ext3"), ocisif.Ext3LayerMediaType),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cf, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf = cf.DeepCopy()
+	cf.OS = p.OS
+	cf.Architecture = p.Architecture
+	cf.Variant = p.Variant
+	cf.History = nil
+	if withHistory {
+		cf.History = []ggcrv1.History{
+			{
+				Created:   ggcrv1.Time{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+				CreatedBy: "/bin/sh -c   #(nop) ADD file:abc in /",
+			},
+			{
+				CreatedBy:  "ENV A=b",
+				EmptyLayer: true,
+			},
+			{
+				Created:   ggcrv1.Time{Time: time.Date(2026, 1, 2, 3, 4, 6, 0, time.UTC)},
+				CreatedBy: "singularity overlay create",
+				Comment:   "writable overlay",
+			},
+		}
+	}
+	img, err = mutate.ConfigFile(img, cf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// writeTestOCISIF writes an OCI-SIF holding the content of ii to a file in dir.
+func writeTestOCISIF(t *testing.T, dir, name string, ii ggcrv1.ImageIndex) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := ocitsif.Write(path, ii); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// testOCISIFs holds the OCI-SIFs used in the inspect tests, and the images
+// that they are expected to report.
+type testOCISIFs struct {
+	// single holds img, with a signature and an SBOM.
+	single string
+	img    ggcrv1.Image
+	sig    ggcrv1.Image
+	sigRef string
+	// noHistory holds imgNoHistory, which has no build history.
+	noHistory    string
+	imgNoHistory ggcrv1.Image
+	// multi holds a multi-platform image index, with hostImg for the host
+	// platform.
+	multi   string
+	hostImg ggcrv1.Image
+	// notSIF is not a SIF file.
+	notSIF string
+}
+
+func newTestOCISIFs(t *testing.T) testOCISIFs {
+	t.Helper()
+
+	dir := t.TempDir()
+	hostPlatform, err := ociplatform.DefaultPlatform()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPlatform := ggcrv1.Platform{OS: "linux", Architecture: "s390x"}
+	if hostPlatform.Architecture == otherPlatform.Architecture {
+		otherPlatform.Architecture = "ppc64le"
+	}
+
+	var s testOCISIFs
+	s.img = testInspectImage(t, *hostPlatform, true)
+	digest, err := s.img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.sig, err = random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sbom, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag := sourcesink.CosignPlaceholderRepo + ":" + digest.Algorithm + "-" + digest.Hex
+	s.sigRef = tag + ".sig"
+	ii := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: s.img},
+		mutate.IndexAddendum{
+			Add: s.sig,
+			Descriptor: ggcrv1.Descriptor{
+				Annotations: map[string]string{imagespec.AnnotationRefName: s.sigRef},
+			},
+		},
+		// An SBOM is not a signature.
+		mutate.IndexAddendum{
+			Add: sbom,
+			Descriptor: ggcrv1.Descriptor{
+				Annotations: map[string]string{imagespec.AnnotationRefName: tag + ".sbom"},
+			},
+		},
+	)
+	s.single = writeTestOCISIF(t, dir, "single.oci.sif", ii)
+
+	s.imgNoHistory = testInspectImage(t, *hostPlatform, false)
+	s.noHistory = writeTestOCISIF(t, dir, "nohistory.oci.sif",
+		mutate.AppendManifests(empty.Index, mutate.IndexAddendum{Add: s.imgNoHistory}))
+
+	s.hostImg = testInspectImage(t, *hostPlatform, false)
+	otherImg := testInspectImage(t, otherPlatform, true)
+	platformIndex := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: otherImg, Descriptor: ggcrv1.Descriptor{Platform: &otherPlatform}},
+		mutate.IndexAddendum{Add: s.hostImg, Descriptor: ggcrv1.Descriptor{Platform: hostPlatform}},
+	)
+	s.multi = writeTestOCISIF(t, dir, "multi.oci.sif",
+		mutate.AppendManifests(empty.Index, mutate.IndexAddendum{Add: platformIndex}))
+
+	s.notSIF = filepath.Join(dir, "not.sif")
+	if err := os.WriteFile(s.notSIF, []byte("not a SIF"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// indentJSON returns raw indented, as written by the inspect functions.
+func indentJSON(t *testing.T, raw []byte) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "\t"); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String() + "\n"
+}
+
+// checkJSONEqual checks that got and want are equivalent JSON documents.
+func checkJSONEqual(t *testing.T, got, want string) {
+	t.Helper()
+
+	var g, w any
+	if err := json.Unmarshal([]byte(got), &g); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, got)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("got JSON:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// normalizeTable returns the lines of text output, with runs of whitespace
+// between columns collapsed to a single space.
+func normalizeTable(s string) []string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.Join(strings.Fields(l), " ")
+	}
+	return lines
+}
+
+func TestInspectOCISIFManifest(t *testing.T) {
+	s := newTestOCISIFs(t)
+
+	rawManifest := func(img ggcrv1.Image) []byte {
+		raw, err := img.RawManifest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+
+	tests := []struct {
+		name    string
+		src     string
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "Single",
+			src:  s.single,
+			want: rawManifest(s.img),
+		},
+		{
+			// The manifest of the image for the host platform is written.
+			name: "MultiPlatform",
+			src:  s.multi,
+			want: rawManifest(s.hostImg),
+		},
+		{
+			name:    "NotSIF",
+			src:     s.notSIF,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := InspectOCISIFManifest(&buf, tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InspectOCISIFManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got, want := buf.String(), indentJSON(t, tt.want); got != want {
+				t.Errorf("got output:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestInspectOCISIFConfig(t *testing.T) {
+	s := newTestOCISIFs(t)
+
+	rawConfig := func(img ggcrv1.Image) []byte {
+		raw, err := img.RawConfigFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+
+	tests := []struct {
+		name    string
+		src     string
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "Single",
+			src:  s.single,
+			want: rawConfig(s.img),
+		},
+		{
+			// The config of the image for the host platform is written.
+			name: "MultiPlatform",
+			src:  s.multi,
+			want: rawConfig(s.hostImg),
+		},
+		{
+			name:    "NotSIF",
+			src:     s.notSIF,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := InspectOCISIFConfig(&buf, tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InspectOCISIFConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got, want := buf.String(), indentJSON(t, tt.want); got != want {
+				t.Errorf("got output:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestInspectOCISIFHistory(t *testing.T) {
+	s := newTestOCISIFs(t)
+
+	tests := []struct {
+		name       string
+		src        string
+		jsonFormat bool
+		want       string
+		wantLines  []string
+		wantErr    bool
+	}{
+		{
+			name: "Text",
+			src:  s.single,
+			wantLines: []string{
+				"CREATED CREATED BY EMPTY LAYER COMMENT",
+				"2026-01-02T03:04:05Z /bin/sh -c #(nop) ADD file:abc in / false",
+				"ENV A=b true",
+				"2026-01-02T03:04:06Z singularity overlay create false writable overlay",
+			},
+		},
+		{
+			name:       "JSON",
+			src:        s.single,
+			jsonFormat: true,
+			want: `{"history": [
+				{"created": "2026-01-02T03:04:05Z", "createdBy": "/bin/sh -c   #(nop) ADD file:abc in /", "emptyLayer": false},
+				{"createdBy": "ENV A=b", "emptyLayer": true},
+				{"created": "2026-01-02T03:04:06Z", "createdBy": "singularity overlay create", "comment": "writable overlay", "emptyLayer": false}
+			]}`,
+		},
+		{
+			name:      "NoHistoryText",
+			src:       s.noHistory,
+			wantLines: []string{"CREATED CREATED BY EMPTY LAYER COMMENT"},
+		},
+		{
+			name:       "NoHistoryJSON",
+			src:        s.noHistory,
+			jsonFormat: true,
+			want:       `{"history": []}`,
+		},
+		{
+			name:    "NotSIF",
+			src:     s.notSIF,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := InspectOCISIFHistory(&buf, tt.src, tt.jsonFormat)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InspectOCISIFHistory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.jsonFormat {
+				checkJSONEqual(t, buf.String(), tt.want)
+				return
+			}
+			if got := normalizeTable(buf.String()); !reflect.DeepEqual(got, tt.wantLines) {
+				t.Errorf("got output:\n%s\nwant lines %q", buf.String(), tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestInspectOCISIFLayers(t *testing.T) {
+	s := newTestOCISIFs(t)
+
+	digest := func(img ggcrv1.Image) string {
+		d, err := img.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d.String()
+	}
+	layers, err := s.img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	layerDigests := make([]string, 0, len(layers))
+	for _, l := range layers {
+		d, err := l.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		layerDigests = append(layerDigests, d.String())
+	}
+	sigSize, err := s.sig.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigMediaType, err := s.sig.MediaType()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		src        string
+		jsonFormat bool
+		want       string
+		wantLines  []string
+		wantErr    bool
+	}{
+		{
+			name: "Text",
+			src:  s.single,
+			wantLines: []string{
+				"Manifest: " + digest(s.img),
+				"LAYER DIGEST SIZE FORMAT MEDIA TYPE",
+				fmt.Sprintf("1 %s 8B squashfs %s", layerDigests[0], ocisif.SquashfsLayerMediaType),
+				fmt.Sprintf("2 %s 4B ext3 overlay %s", layerDigests[1], ocisif.Ext3LayerMediaType),
+				"The final layer is a writable overlay.",
+				"Signatures:",
+				fmt.Sprintf("%s %s %s", digest(s.sig), units.BytesSize(float64(sigSize)), s.sigRef),
+			},
+		},
+		{
+			name:       "JSON",
+			src:        s.single,
+			jsonFormat: true,
+			want: fmt.Sprintf(`{
+				"manifest": %q,
+				"layers": [
+					{"digest": %q, "size": 8, "mediaType": %q, "format": "squashfs"},
+					{"digest": %q, "size": 4, "mediaType": %q, "format": "ext3 overlay"}
+				],
+				"overlay": true,
+				"signatures": [
+					{"refName": %q, "digest": %q, "size": %d, "mediaType": %q}
+				]
+			}`,
+				digest(s.img),
+				layerDigests[0], ocisif.SquashfsLayerMediaType,
+				layerDigests[1], ocisif.Ext3LayerMediaType,
+				s.sigRef, digest(s.sig), sigSize, sigMediaType,
+			),
+		},
+		{
+			name:       "NoSignaturesJSON",
+			src:        s.noHistory,
+			jsonFormat: true,
+			want: fmt.Sprintf(`{
+				"manifest": %q,
+				"layers": [
+					{"digest": %q, "size": 8, "mediaType": %q, "format": "squashfs"},
+					{"digest": %q, "size": 4, "mediaType": %q, "format": "ext3 overlay"}
+				],
+				"overlay": true,
+				"signatures": []
+			}`,
+				digest(s.imgNoHistory),
+				layerDigests[0], ocisif.SquashfsLayerMediaType,
+				layerDigests[1], ocisif.Ext3LayerMediaType,
+			),
+		},
+		{
+			name:    "NotSIF",
+			src:     s.notSIF,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := InspectOCISIFLayers(&buf, tt.src, tt.jsonFormat)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InspectOCISIFLayers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.jsonFormat {
+				checkJSONEqual(t, buf.String(), tt.want)
+				return
+			}
+			if got := normalizeTable(buf.String()); !reflect.DeepEqual(got, tt.wantLines) {
+				t.Errorf("got output:\n%s\nwant lines %q", buf.String(), tt.wantLines)
+			}
+		})
+	}
+}
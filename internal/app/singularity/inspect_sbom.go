@@ -0,0 +1,61 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package singularity
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sylabs/sif/v2/pkg/sif"
+	"github.com/sylabs/singularity/v4/internal/pkg/ocisif"
+)
+
+// ErrNoSBOM is returned by InspectSBOM when the image holds no SBOM.
+var ErrNoSBOM = errors.New("image does not contain an SBOM")
+
+// InspectSBOM writes the SBOM embedded in the SIF or OCI-SIF image at src to w.
+// In a native SIF image, the SBOM is held in a data object. In an OCI-SIF
+// image, it is held as an OCI artifact referring to the image that would be
+// run on this host.
+func InspectSBOM(w io.Writer, src string, ociSIF bool) error {
+	fi, err := sif.LoadContainerFromPath(src, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		return fmt.Errorf("while loading SIF: %w", err)
+	}
+	defer fi.UnloadContainer()
+
+	var data []byte
+	if ociSIF {
+		data, _, err = ocisif.SBOM(fi)
+		if errors.Is(err, ocisif.ErrNoSBOM) {
+			return ErrNoSBOM
+		}
+		if err != nil {
+			return fmt.Errorf("while reading SBOM: %w", err)
+		}
+	} else {
+		d, err := fi.GetDescriptor(sif.WithDataType(sif.DataSBOM))
+		if errors.Is(err, sif.ErrObjectNotFound) {
+			return ErrNoSBOM
+		}
+		if err != nil {
+			return fmt.Errorf("while reading SBOM: %w", err)
+		}
+		if data, err = d.GetData(); err != nil {
+			return fmt.Errorf("while reading SBOM: %w", err)
+		}
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		_, err = io.WriteString(w, "\n")
+	}
+	return err
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // Copyright (c) Contributors to the Apptainer project, established as
 //   Apptainer a Series of LF Projects LLC.
 // This software is licensed under a 3-clause BSD license. Please consult the
@@ -17,6 +17,8 @@ import (
 	"io"
 	"math"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"syscall"
 	"text/tabwriter"
@@ -28,6 +30,7 @@ import (
 	libcgroups "github.com/opencontainers/cgroups"
 	"github.com/sylabs/singularity/v4/internal/pkg/cgroups"
 	"github.com/sylabs/singularity/v4/internal/pkg/instance"
+	"github.com/sylabs/singularity/v4/internal/pkg/runtime/launcher/oci"
 	"github.com/sylabs/singularity/v4/pkg/sylog"
 	"github.com/sylabs/singularity/v4/pkg/util/fs/proc"
 )
@@ -39,6 +42,10 @@ type instanceInfo struct {
 	IP         string `json:"ip"`
 	LogErrPath string `json:"logErrPath"`
 	LogOutPath string `json:"logOutPath"`
+	// RestartPolicy and Health are only present for instances started with
+	// a restart policy, or a health check command.
+	RestartPolicy string           `json:"restartPolicy,omitempty"`
+	Health        *instance.Health `json:"health,omitempty"`
 }
 
 // PrintInstanceList fetches instance list, applying name and
@@ -96,6 +103,8 @@ func PrintInstanceList(w io.Writer, name, user string, formatJSON bool, showLogs
 		instances[i].IP = ii[i].IP
 		instances[i].LogErrPath = ii[i].LogErrPath
 		instances[i].LogOutPath = ii[i].LogOutPath
+		instances[i].RestartPolicy = ii[i].RestartPolicy
+		instances[i].Health = ii[i].Health
 	}
 
 	enc := json.NewEncoder(w)
@@ -211,6 +220,9 @@ func InstanceStats(ctx context.Context, name, instanceUser string, formatJSON bo
 	i := ii[0]
 	if !formatJSON {
 		sylog.Infof("Stats for %s instance of %s (PID=%d)\n", i.Name, i.Image, i.Pid)
+		if i.Health != nil {
+			sylog.Infof("Health of %s instance is %s (failing streak %d)\n", i.Name, i.Health.Status, i.Health.FailingStreak)
+		}
 	}
 
 	// If asking for json and not nostream, not possible
@@ -307,6 +319,20 @@ func InstanceStats(ctx context.Context, name, instanceUser string, formatJSON bo
 	}
 }
 
+// stopKillTimeout is how long StopInstance waits for OCI-mode instances to
+// exit, once they have been forcibly killed.
+var stopKillTimeout = 10 * time.Second
+
+// signalInstance sends sig to the process of instance i. OCI-mode instances
+// are signalled through the OCI runtime, which is able to deliver the signal
+// into the container's PID namespace.
+var signalInstance = func(i *instance.File, sig syscall.Signal) error {
+	if i.IsOCI() {
+		return oci.Kill(i.ContainerID, strconv.Itoa(int(sig)))
+	}
+	return syscall.Kill(i.Pid, sig)
+}
+
 // StopInstance fetches instance list, applying name and
 // user filters, and stops them by sending a signal sig. If an instance
 // is still running after a grace period defined by timeout is expired,
@@ -316,7 +342,11 @@ func StopInstance(name, user string, sig syscall.Signal, timeout time.Duration)
 	if err != nil {
 		return err
 	}
-	stoppedPID := make(chan int, 1)
+	return stopInstances(ii, sig, timeout)
+}
+
+func stopInstances(ii []*instance.File, sig syscall.Signal, timeout time.Duration) error {
+	stoppedPID := make(chan int, len(ii))
 	stopped := make([]int, 0)
 
 	for _, i := range ii {
@@ -340,22 +370,61 @@ func StopInstance(name, user string, sig syscall.Signal, timeout time.Duration)
 				}
 
 				sylog.Infof("Killing %s instance of %s (PID=%d) (Timeout)\n", i.Name, i.Image, i.Pid)
-				syscall.Kill(i.Pid, syscall.SIGKILL)
+				if err := signalInstance(i, syscall.SIGKILL); err != nil && i.IsOCI() {
+					sylog.Warningf("Could not kill %s instance: %v", i.Name, err)
+				}
 			}
-			return nil
+
+			// Wait for killed OCI-mode instances to exit, so that they have
+			// been cleaned up by conmon when we return. Native instances are
+			// not waited for, as before.
+			deadline := time.After(stopKillTimeout)
+			for {
+				running := ociRunning(ii, stopped)
+				if running == 0 {
+					return nil
+				}
+				select {
+				case pid := <-stoppedPID:
+					stopped = append(stopped, pid)
+				case <-deadline:
+					return fmt.Errorf("%d OCI-mode instance(s) still running after being killed", running)
+				}
+			}
+		}
+	}
+}
+
+// ociRunning returns the number of OCI-mode instances in ii whose PID is not
+// in stopped.
+func ociRunning(ii []*instance.File, stopped []int) int {
+	running := 0
+	for _, i := range ii {
+		if i.IsOCI() && !slices.Contains(stopped, i.Pid) {
+			running++
 		}
 	}
+	return running
 }
 
 func killInstance(i *instance.File, sig syscall.Signal, stoppedPID chan<- int) {
 	sylog.Infof("Stopping %s instance of %s (PID=%d)\n", i.Name, i.Image, i.Pid)
-	syscall.Kill(i.Pid, sig)
+	if err := signalInstance(i, sig); err != nil {
+		sylog.Debugf("Could not signal %s instance: %v", i.Name, err)
+	}
 
 	for {
 		if err := syscall.Kill(i.PPid, 0); err == syscall.ESRCH {
 			stoppedPID <- i.Pid
 			break
 		}
+		// The process of an OCI-mode instance is the container payload
+		// itself, which is monitored by conmon. Leave it to handle the signal,
+		// StopInstance will escalate to SIGKILL after the timeout.
+		if i.IsOCI() {
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
 		if childs, err := proc.CountChildren(i.Pid); childs == 0 {
 			if err == nil {
 				syscall.Kill(i.Pid, syscall.SIGKILL)
@@ -0,0 +1,139 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package singularity
+
+import (
+	"os/exec"
+	"slices"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sylabs/singularity/v4/internal/pkg/instance"
+)
+
+// startOCIInstance starts a shell running script, standing in for both the
+// container process and conmon of an OCI-mode instance.
+func startOCIInstance(t *testing.T, script string) *instance.File {
+	t.Helper()
+
+	cmd := exec.Command("/bin/sh", "-c", script)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	// Reap the process, so that it no longer exists once it has exited.
+	go cmd.Wait()
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+	})
+
+	// Give the shell time to install any signal handler.
+	time.Sleep(100 * time.Millisecond)
+
+	return &instance.File{
+		Name:        "test",
+		Pid:         cmd.Process.Pid,
+		PPid:        cmd.Process.Pid,
+		ContainerID: "test-container",
+	}
+}
+
+func TestStopInstancesOCI(t *testing.T) {
+	tests := []struct {
+		name        string
+		script      string
+		sig         syscall.Signal
+		wantSignals []syscall.Signal
+	}{
+		{
+			name:        "Signal",
+			script:      "sleep 60 & wait",
+			sig:         syscall.SIGTERM,
+			wantSignals: []syscall.Signal{syscall.SIGTERM},
+		},
+		{
+			name:        "Ignored",
+			script:      "trap '' INT; while true; do sleep 0.1; done",
+			sig:         syscall.SIGINT,
+			wantSignals: []syscall.Signal{syscall.SIGINT, syscall.SIGKILL},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var signals []syscall.Signal
+
+			origSignal := signalInstance
+			t.Cleanup(func() { signalInstance = origSignal })
+			signalInstance = func(i *instance.File, sig syscall.Signal) error {
+				mu.Lock()
+				signals = append(signals, sig)
+				mu.Unlock()
+				return syscall.Kill(i.Pid, sig)
+			}
+
+			i := startOCIInstance(t, tt.script)
+
+			if err := stopInstances([]*instance.File{i}, tt.sig, time.Second); err != nil {
+				t.Fatalf("stopInstances() error = %v", err)
+			}
+
+			if err := syscall.Kill(i.Pid, 0); err != syscall.ESRCH {
+				t.Errorf("instance process still exists (err = %v)", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if !slices.Equal(signals, tt.wantSignals) {
+				t.Errorf("signals = %v, want %v", signals, tt.wantSignals)
+			}
+		})
+	}
+}
+
+func TestStopInstancesOCIKillFailure(t *testing.T) {
+	origSignal := signalInstance
+	origTimeout := stopKillTimeout
+	t.Cleanup(func() {
+		signalInstance = origSignal
+		stopKillTimeout = origTimeout
+	})
+	// The runtime fails to deliver any signal to the container.
+	signalInstance = func(*instance.File, syscall.Signal) error {
+		return syscall.EPERM
+	}
+	stopKillTimeout = 200 * time.Millisecond
+
+	i := startOCIInstance(t, "sleep 60")
+
+	if err := stopInstances([]*instance.File{i}, syscall.SIGTERM, 200*time.Millisecond); err == nil {
+		t.Errorf("stopInstances() succeeded, but instance was not stopped")
+	}
+}
+
+func TestStopInstancesNativeNoWait(t *testing.T) {
+	origSignal := signalInstance
+	t.Cleanup(func() { signalInstance = origSignal })
+	// The instance never receives any signal, and has a child, so it is not
+	// killed by killInstance either.
+	signalInstance = func(*instance.File, syscall.Signal) error {
+		return syscall.EPERM
+	}
+
+	i := startOCIInstance(t, "sleep 60 & wait")
+	i.ContainerID = ""
+
+	start := time.Now()
+	if err := stopInstances([]*instance.File{i}, syscall.SIGTERM, 200*time.Millisecond); err != nil {
+		t.Errorf("stopInstances() error = %v", err)
+	}
+	// Native instances are not waited for after being killed.
+	if elapsed := time.Since(start); elapsed >= stopKillTimeout {
+		t.Errorf("stopInstances() took %v, waited for native instance", elapsed)
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2022, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -70,6 +70,16 @@ func OciDelete(ctx context.Context, containerID string) error {
 	return oci.Delete(ctx, containerID, systemdCgroups)
 }
 
+// OciCleanup deletes container resources after the container has exited,
+// including the bundle of an instance
+func OciCleanup(ctx context.Context, containerID string) error {
+	systemdCgroups, err := systemdCgroups()
+	if err != nil {
+		return err
+	}
+	return oci.Cleanup(ctx, containerID, systemdCgroups)
+}
+
 // OciExec executes a command in a container
 func OciExec(containerID string, cmdArgs []string) error {
 	systemdCgroups, err := systemdCgroups()
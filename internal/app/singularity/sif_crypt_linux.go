@@ -0,0 +1,595 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package singularity
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/ccoveille/go-safecast/v2"
+	"github.com/sylabs/sif/v2/pkg/sif"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/crypt"
+	"github.com/sylabs/singularity/v4/pkg/image"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/cryptkey"
+)
+
+// SIFCryptOptions holds options for the encryption, re-keying, and decryption
+// of an existing SIF image.
+type SIFCryptOptions struct {
+	// RemoveSignatures permits the modification of a signed image, by
+	// removing its signatures, which would otherwise be invalidated.
+	RemoveSignatures bool
+}
+
+// EncryptSIF encrypts the squashfs primary system partition of the SIF image
+// at path. The image is encrypted with a passphrase, or for one or more PEM or
+// PGP key recipients, as given by keys.
+func EncryptSIF(path string, keys []cryptkey.KeyInfo, opts SIFCryptOptions) error {
+	if err := checkRecipients(keys); err != nil {
+		return err
+	}
+
+	f, prim, err := loadPrimaryPartition(path, sif.FsSquash)
+	if err != nil {
+		return err
+	}
+	defer f.UnloadContainer()
+
+	if err := checkSignatures(path, f, opts); err != nil {
+		return err
+	}
+
+	plainPath, err := extractPartition(prim)
+	if err != nil {
+		return fmt.Errorf("while extracting primary system partition: %w", err)
+	}
+	defer os.Remove(plainPath)
+
+	plaintext, err := cryptkey.NewPlaintextKey(keys[0])
+	if err != nil {
+		return fmt.Errorf("unable to obtain encryption key: %w", err)
+	}
+
+	cryptDev := &crypt.Device{}
+	cryptPath, err := cryptDev.EncryptFilesystem(plainPath, plaintext)
+	if err != nil {
+		return fmt.Errorf("unable to encrypt primary system partition: %w", err)
+	}
+	defer os.Remove(cryptPath)
+
+	return rewriteSIF(path, f, prim, sifEdit{
+		partition: cryptPath,
+		fsType:    sif.FsEncryptedSquashfs,
+		keys:      keys,
+		plaintext: plaintext,
+	})
+}
+
+// DecryptSIF decrypts the encrypted primary system partition of the SIF image
+// at path, using the first of keys that succeeds, leaving an unencrypted
+// squashfs partition.
+func DecryptSIF(path string, keys []cryptkey.KeyInfo, opts SIFCryptOptions) error {
+	f, prim, err := loadPrimaryPartition(path, sif.FsEncryptedSquashfs)
+	if err != nil {
+		return err
+	}
+	defer f.UnloadContainer()
+
+	if err := checkSignatures(path, f, opts); err != nil {
+		return err
+	}
+
+	plaintext, err := cryptkey.PlaintextKeyFromKeys(keys, path)
+	if err != nil {
+		return err
+	}
+
+	offset, size, err := partitionBounds(prim)
+	if err != nil {
+		return err
+	}
+
+	cryptDev := &crypt.Device{}
+	plainPath, err := cryptDev.DecryptFilesystem(path, offset, size, plaintext)
+	if err != nil {
+		return fmt.Errorf("unable to decrypt primary system partition: %w", err)
+	}
+	defer os.Remove(plainPath)
+
+	// The encrypted volume is larger than the squashfs it holds.
+	if err := truncateSquashfs(plainPath); err != nil {
+		return fmt.Errorf("while reading decrypted partition: %w", err)
+	}
+
+	return rewriteSIF(path, f, prim, sifEdit{
+		partition: plainPath,
+		fsType:    sif.FsSquash,
+	})
+}
+
+// RekeySIF changes the key of the encrypted primary system partition of the
+// SIF image at path, using the first of oldKeys that succeeds, to newKeys. All
+// existing recipients are replaced by newKeys.
+//
+// Where no passphrase is involved, the volume key is re-wrapped for newKeys,
+// and the encrypted data is left untouched, so replaced recipients that hold
+// the volume key are not prevented from decrypting the image. Otherwise, the
+// volume key is replaced in the LUKS header of the partition.
+func RekeySIF(path string, oldKeys, newKeys []cryptkey.KeyInfo, opts SIFCryptOptions) error {
+	if err := checkRecipients(newKeys); err != nil {
+		return err
+	}
+
+	f, prim, err := loadPrimaryPartition(path, sif.FsEncryptedSquashfs)
+	if err != nil {
+		return err
+	}
+	defer f.UnloadContainer()
+
+	if err := checkSignatures(path, f, opts); err != nil {
+		return err
+	}
+
+	plaintext, err := cryptkey.PlaintextKeyFromKeys(oldKeys, path)
+	if err != nil {
+		return err
+	}
+
+	edit := sifEdit{
+		fsType:    sif.FsEncryptedSquashfs,
+		keys:      newKeys,
+		plaintext: plaintext,
+	}
+
+	if RekeyChangesHeader(oldKeys, newKeys) {
+		newPlaintext, err := cryptkey.NewPlaintextKey(newKeys[0])
+		if err != nil {
+			return fmt.Errorf("unable to obtain encryption key: %w", err)
+		}
+
+		// The LUKS header is changed in the rewritten image, so that the
+		// original is intact if anything fails.
+		edit.finalize = func(path string, prim sif.Descriptor) error {
+			offset, size, err := partitionBounds(prim)
+			if err != nil {
+				return err
+			}
+			cryptDev := &crypt.Device{}
+			if err := cryptDev.ChangeKey(path, offset, size, plaintext, newPlaintext); err != nil {
+				return fmt.Errorf("unable to change key of primary system partition: %w", err)
+			}
+			return nil
+		}
+		edit.plaintext = newPlaintext
+	}
+
+	return rewriteSIF(path, f, prim, edit)
+}
+
+// RekeyChangesHeader returns true if RekeySIF, from oldKeys to newKeys,
+// changes the LUKS header of the partition, rather than only re-wrapping the
+// volume key. This is the case where a passphrase is involved.
+func RekeyChangesHeader(oldKeys, newKeys []cryptkey.KeyInfo) bool {
+	return hasPassphrase(oldKeys) || hasPassphrase(newKeys)
+}
+
+// UpdateSIFRecipients adds and removes recipients of the encrypted primary
+// system partition of the SIF image at path, leaving the encrypted data, and
+// any other recipients, untouched. Recipients to add are PEM or PGP keys, and
+// the first of keys that succeeds is used to obtain the volume key for them.
+// Recipients to remove are identified by their cryptkey.RecipientID, or by
+// cryptkey.LegacyRecipientID for all recipients that are not identified.
+//
+// Removing a recipient does not change the volume key, which the recipient
+// may already hold, so does not revoke their access to the image.
+func UpdateSIFRecipients(path string, keys, add []cryptkey.KeyInfo, remove []string, opts SIFCryptOptions) error {
+	f, prim, err := loadPrimaryPartition(path, sif.FsEncryptedSquashfs)
+	if err != nil {
+		return err
+	}
+	defer f.UnloadContainer()
+
+	if err := checkSignatures(path, f, opts); err != nil {
+		return err
+	}
+
+	msgs, err := f.GetDescriptors(
+		sif.WithLinkedID(prim.ID()),
+		sif.WithDataType(sif.DataCryptoMessage),
+	)
+	if err != nil {
+		return err
+	}
+	if len(msgs) == 0 {
+		return fmt.Errorf("%s is encrypted with a passphrase, and has no recipients", path)
+	}
+
+	var current []string
+	for _, d := range msgs {
+		current = append(current, recipientID(d))
+	}
+
+	for _, id := range remove {
+		if !slices.Contains(current, id) {
+			recipients := slices.Compact(slices.Sorted(slices.Values(current)))
+			return fmt.Errorf("%s is not a recipient of %s, which has recipients: %s", id, path, strings.Join(recipients, ", "))
+		}
+	}
+
+	edit := sifEdit{
+		fsType: sif.FsEncryptedSquashfs,
+		keys:   add,
+		keepKey: func(d sif.Descriptor) bool {
+			return !slices.Contains(remove, recipientID(d))
+		},
+	}
+
+	if len(add) > 0 {
+		for _, k := range add {
+			if k.Format == cryptkey.Passphrase {
+				return fmt.Errorf("a passphrase cannot be added as a recipient")
+			}
+			id, err := cryptkey.RecipientID(k)
+			if err != nil {
+				return err
+			}
+			if slices.Contains(current, id) && !slices.Contains(remove, id) {
+				return fmt.Errorf("%s is already a recipient of %s", id, path)
+			}
+		}
+
+		edit.plaintext, err = cryptkey.PlaintextKeyFromKeys(keys, path)
+		if err != nil {
+			return err
+		}
+	}
+
+	remaining, legacy := 0, 0
+	for _, id := range current {
+		if slices.Contains(remove, id) {
+			continue
+		}
+		remaining++
+		if id == cryptkey.LegacyRecipientID {
+			legacy++
+		}
+	}
+	if remaining+len(add) <= 0 {
+		return fmt.Errorf("no recipients of %s would remain: use 'singularity sif decrypt' to decrypt it", path)
+	}
+
+	if err := rewriteSIF(path, f, prim, edit); err != nil {
+		return err
+	}
+
+	if len(remove) > 0 && legacy > 0 {
+		sylog.Warningf("%s has %d recipient(s), added by an earlier version of Singularity, that are not identified and were not removed. Remove them with the recipient ID %s.", path, legacy, cryptkey.LegacyRecipientID)
+	}
+	return nil
+}
+
+// recipientID returns the recipient ID recorded as the name of the key message
+// d, or cryptkey.LegacyRecipientID if it has no name.
+func recipientID(d sif.Descriptor) string {
+	if d.Name() == "" {
+		return cryptkey.LegacyRecipientID
+	}
+	return d.Name()
+}
+
+// checkRecipients returns an error if keys, with which to encrypt an image,
+// are empty, or combine a passphrase with other keys.
+func checkRecipients(keys []cryptkey.KeyInfo) error {
+	if len(keys) == 0 {
+		return cryptkey.ErrNoKey
+	}
+	if len(keys) > 1 && hasPassphrase(keys) {
+		return fmt.Errorf("a passphrase cannot be combined with other keys")
+	}
+	return nil
+}
+
+// hasPassphrase returns true if any of keys is a passphrase.
+func hasPassphrase(keys []cryptkey.KeyInfo) bool {
+	return slices.ContainsFunc(keys, func(k cryptkey.KeyInfo) bool {
+		return k.Format == cryptkey.Passphrase
+	})
+}
+
+// loadPrimaryPartition loads the SIF image at path, read-only, and returns it
+// with its primary system partition, which must be of filesystem type fs.
+func loadPrimaryPartition(path string, fs sif.FSType) (*sif.FileImage, sif.Descriptor, error) {
+	f, err := sif.LoadContainerFromPath(path, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		return nil, sif.Descriptor{}, fmt.Errorf("failed to load SIF image %s: %w", path, err)
+	}
+
+	prim, err := f.GetDescriptor(sif.WithPartitionType(sif.PartPrimSys))
+	if err != nil {
+		f.UnloadContainer()
+		return nil, sif.Descriptor{}, fmt.Errorf("could not retrieve primary system partition from %s: %w", path, err)
+	}
+
+	primFs, _, _, err := prim.PartitionMetadata()
+	if err != nil {
+		f.UnloadContainer()
+		return nil, sif.Descriptor{}, err
+	}
+
+	if primFs != fs {
+		f.UnloadContainer()
+		switch {
+		case primFs == sif.FsEncryptedSquashfs:
+			return nil, sif.Descriptor{}, fmt.Errorf("primary system partition of %s is already encrypted", path)
+		case fs == sif.FsEncryptedSquashfs:
+			return nil, sif.Descriptor{}, fmt.Errorf("primary system partition of %s is not encrypted", path)
+		default:
+			return nil, sif.Descriptor{}, fmt.Errorf("primary system partition of %s is not a squashfs filesystem", path)
+		}
+	}
+
+	return f, prim, nil
+}
+
+// checkSignatures returns an error if the image f at path is signed, unless
+// opts permits the signatures to be removed.
+func checkSignatures(path string, f *sif.FileImage, opts SIFCryptOptions) error {
+	sigs, err := f.GetDescriptors(sif.WithDataType(sif.DataSignature))
+	if err != nil {
+		return err
+	}
+	if len(sigs) == 0 {
+		return nil
+	}
+
+	if !opts.RemoveSignatures {
+		return fmt.Errorf("%s is signed, and modifying it would invalidate its signatures: use --remove-signatures to proceed", path)
+	}
+	sylog.Warningf("Removing %d signature(s) from %s. Use 'singularity sign' to sign the modified image.", len(sigs), path)
+	return nil
+}
+
+// partitionBounds returns the offset and size of the partition d, within its
+// image file.
+func partitionBounds(d sif.Descriptor) (uint64, uint64, error) {
+	offset, err := safecast.Convert[uint64](d.Offset())
+	if err != nil {
+		return 0, 0, err
+	}
+	size, err := safecast.Convert[uint64](d.Size())
+	if err != nil {
+		return 0, 0, err
+	}
+	return offset, size, nil
+}
+
+// extractPartition copies the content of the partition d to a temporary file,
+// and returns its path.
+func extractPartition(d sif.Descriptor) (string, error) {
+	f, err := os.CreateTemp("", "rootfs-")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(f, d.GetReader()); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// truncateSquashfs truncates the file at path to the size of the squashfs
+// filesystem at its start.
+func truncateSquashfs(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	b := make([]byte, 4096)
+	if _, err := io.ReadFull(f, b); err != nil {
+		return err
+	}
+
+	size, err := image.GetSquashfsSize(b)
+	if err != nil {
+		return err
+	}
+	sSize, err := safecast.Convert[int64](size)
+	if err != nil {
+		return err
+	}
+
+	return os.Truncate(path, sSize)
+}
+
+// sifEdit describes the changes made to the primary system partition of an
+// image by rewriteSIF.
+type sifEdit struct {
+	// partition, if set, is the path to a file holding the new content of the
+	// primary system partition.
+	partition string
+	// fsType is the filesystem type recorded for the primary system
+	// partition.
+	fsType sif.FSType
+	// keys are the recipients for which plaintext, the key of the primary
+	// system partition, is stored in the image. A passphrase has no stored
+	// key.
+	keys      []cryptkey.KeyInfo
+	plaintext []byte
+	// keepKey, if set, returns whether an existing key d, linked to the
+	// primary system partition, is retained. Otherwise, all are removed.
+	keepKey func(d sif.Descriptor) bool
+	// finalize, if set, is called with the path to, and primary system
+	// partition of, the rewritten image before it replaces the original.
+	finalize func(path string, prim sif.Descriptor) error
+}
+
+// rawMetadata holds the unparsed metadata of a descriptor, so that it can be
+// copied to a new descriptor unchanged.
+type rawMetadata []byte
+
+func (m *rawMetadata) UnmarshalBinary(b []byte) error {
+	*m = slices.Clone(b)
+	return nil
+}
+
+func (m rawMetadata) MarshalBinary() ([]byte, error) {
+	return m, nil
+}
+
+// rewriteSIF rewrites the SIF image f, at path, applying edit to its primary
+// system partition prim. All other data objects are copied, in order, except
+// for signatures, which are invalidated by the change. The image is rewritten
+// to a temporary file that then replaces the original, so that no unused space
+// is left behind.
+func rewriteSIF(path string, f *sif.FileImage, prim sif.Descriptor, edit sifEdit) error {
+	ds, err := f.GetDescriptors()
+	if err != nil {
+		return err
+	}
+
+	// IDs are assigned in order on creation, so links must be remapped.
+	ids := make(map[uint32]uint32)
+	var dis []sif.DescriptorInput
+
+	for _, d := range ds {
+		linkID, isGroup := d.LinkedID()
+
+		if d.DataType() == sif.DataSignature {
+			continue
+		}
+		if d.DataType() == sif.DataCryptoMessage && !isGroup && linkID == prim.ID() &&
+			(edit.keepKey == nil || !edit.keepKey(d)) {
+			continue
+		}
+
+		var r io.Reader = d.GetReader()
+		var opts []sif.DescriptorInputOpt
+
+		if d.Name() != "" {
+			opts = append(opts, sif.OptObjectName(d.Name()))
+		}
+		if d.GroupID() == 0 {
+			opts = append(opts, sif.OptNoGroup())
+		} else {
+			opts = append(opts, sif.OptGroupID(d.GroupID()))
+		}
+		if linkID != 0 {
+			if isGroup {
+				opts = append(opts, sif.OptLinkedGroupID(linkID))
+			} else if id, ok := ids[linkID]; ok {
+				opts = append(opts, sif.OptLinkedID(id))
+			} else {
+				return fmt.Errorf("object %d is linked to object %d, which follows it", d.ID(), linkID)
+			}
+		}
+
+		if d.ID() == prim.ID() {
+			_, pt, arch, err := d.PartitionMetadata()
+			if err != nil {
+				return err
+			}
+			opts = append(opts, sif.OptPartitionMetadata(edit.fsType, pt, arch))
+
+			if edit.partition != "" {
+				pf, err := os.Open(edit.partition)
+				if err != nil {
+					return fmt.Errorf("while opening partition file: %w", err)
+				}
+				defer pf.Close()
+				r = pf
+			}
+		} else {
+			var md rawMetadata
+			if err := d.GetMetadata(&md); err != nil {
+				return err
+			}
+			opts = append(opts, sif.OptMetadata(md), sif.OptObjectTime(d.CreatedAt()))
+		}
+
+		di, err := sif.NewDescriptorInput(d.DataType(), r, opts...)
+		if err != nil {
+			return err
+		}
+		dis = append(dis, di)
+
+		id, err := safecast.Convert[uint32](len(dis))
+		if err != nil {
+			return err
+		}
+		ids[d.ID()] = id
+
+		if d.ID() == prim.ID() {
+			msgs, err := cryptkey.KeyMessages(edit.keys, edit.plaintext, id)
+			if err != nil {
+				return fmt.Errorf("while encrypting filesystem key: %w", err)
+			}
+			dis = append(dis, msgs...)
+		}
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return fmt.Errorf("while creating temporary image: %w", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	nf, err := sif.CreateContainerAtPath(tmp.Name(),
+		sif.OptCreateWithLaunchScript(f.LaunchScript()),
+		sif.OptCreateWithID(f.ID()),
+		sif.OptCreateWithDescriptorCapacity(max(f.DescriptorsTotal(), int64(len(dis)))),
+		sif.OptCreateWithDescriptors(dis...),
+	)
+	if err != nil {
+		return fmt.Errorf("while creating container: %w", err)
+	}
+
+	newPrim, err := nf.GetDescriptor(sif.WithPartitionType(sif.PartPrimSys))
+	if err != nil {
+		nf.UnloadContainer()
+		return err
+	}
+	if err := nf.UnloadContainer(); err != nil {
+		return fmt.Errorf("while unloading container: %w", err)
+	}
+
+	if edit.finalize != nil {
+		if err := edit.finalize(tmp.Name(), newPrim); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Chmod(tmp.Name(), fi.Mode().Perm()); err != nil {
+		return err
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		if err := os.Chown(tmp.Name(), int(st.Uid), int(st.Gid)); err != nil {
+			return fmt.Errorf("while changing image ownership: %w", err)
+		}
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
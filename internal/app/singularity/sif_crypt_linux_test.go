@@ -0,0 +1,356 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package singularity
+
+import (
+	"bytes"
+	"crypto"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sylabs/sif/v2/pkg/sif"
+	"github.com/sylabs/singularity/v4/pkg/util/cryptkey"
+)
+
+// newPEMKey generates an RSA key pair, saved in dir with the provided name, and
+// returns the paths to the public and private keys.
+func newPEMKey(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+
+	key, err := cryptkey.GenerateRSAKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPath := filepath.Join(dir, name+".pub.pem")
+	if err := cryptkey.SavePublicPEM(pubPath, key); err != nil {
+		t.Fatal(err)
+	}
+	privPath := filepath.Join(dir, name+".pem")
+	if err := cryptkey.SavePrivatePEM(privPath, key); err != nil {
+		t.Fatal(err)
+	}
+	return pubPath, privPath
+}
+
+func TestRewriteSIF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sif")
+	keyDir := t.TempDir()
+	pubA, privA := newPEMKey(t, keyDir, "a")
+	pubB, privB := newPEMKey(t, keyDir, "b")
+	plaintext := []byte("0123456789abcdef")
+
+	def, err := sif.NewDescriptorInput(sif.DataDeffile, bytes.NewReader([]byte("bootstrap: scratch")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	part, err := sif.NewDescriptorInput(sif.DataPartition, bytes.NewReader(make([]byte, 8192)),
+		sif.OptPartitionMetadata(sif.FsSquash, sif.PartPrimSys, "amd64"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := sif.NewDescriptorInput(sif.DataCryptoMessage, bytes.NewReader([]byte("old key")),
+		sif.OptLinkedID(2),
+		sif.OptCryptoMessageMetadata(sif.FormatPEM, sif.MessageRSAOAEP),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sbom, err := sif.NewDescriptorInput(sif.DataSBOM, bytes.NewReader([]byte("{}")),
+		sif.OptSBOMMetadata(sif.SBOMFormatSPDXJSON),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := sif.NewDescriptorInput(sif.DataSignature, bytes.NewReader([]byte("signature")),
+		sif.OptNoGroup(),
+		sif.OptLinkedGroupID(1),
+		sif.OptSignatureMetadata(crypto.SHA256, make([]byte, 20)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := sif.CreateContainerAtPath(path,
+		sif.OptCreateWithLaunchScript("#!/usr/bin/env run-singularity\n"),
+		sif.OptCreateWithDescriptors(def, part, msg, sbom, sig),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := f.ID()
+	if err := f.UnloadContainer(); err != nil {
+		t.Fatal(err)
+	}
+
+	newPart := filepath.Join(t.TempDir(), "part")
+	if err := os.WriteFile(newPart, bytes.Repeat([]byte("x"), 12288), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, prim, err := loadPrimaryPartition(path, sif.FsSquash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = rewriteSIF(path, f, prim, sifEdit{
+		partition: newPart,
+		fsType:    sif.FsEncryptedSquashfs,
+		keys: []cryptkey.KeyInfo{
+			{Format: cryptkey.PEM, Path: pubA},
+			{Format: cryptkey.PEM, Path: pubB},
+		},
+		plaintext: plaintext,
+	})
+	f.UnloadContainer()
+	if err != nil {
+		t.Fatalf("rewriteSIF() error = %v", err)
+	}
+
+	f, err = sif.LoadContainerFromPath(path, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.UnloadContainer()
+
+	if f.ID() != id {
+		t.Errorf("image ID = %s, want %s", f.ID(), id)
+	}
+
+	var types []sif.DataType
+	ds, err := f.GetDescriptors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range ds {
+		types = append(types, d.DataType())
+	}
+	want := []sif.DataType{sif.DataDeffile, sif.DataPartition, sif.DataCryptoMessage, sif.DataCryptoMessage, sif.DataSBOM}
+	if !slices.Equal(types, want) {
+		t.Errorf("data types = %v, want %v", types, want)
+	}
+
+	prim, err = f.GetDescriptor(sif.WithPartitionType(sif.PartPrimSys))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fs, _, arch, _ := prim.PartitionMetadata(); fs != sif.FsEncryptedSquashfs || arch != "amd64" {
+		t.Errorf("partition metadata = %v %v, want %v amd64", fs, arch, sif.FsEncryptedSquashfs)
+	}
+	if prim.Size() != 12288 {
+		t.Errorf("partition size = %d, want 12288", prim.Size())
+	}
+
+	// The plaintext key is stored for each recipient.
+	for _, priv := range []string{privA, privB} {
+		got, err := cryptkey.PlaintextKey(cryptkey.KeyInfo{Format: cryptkey.PEM, Path: priv}, path)
+		if err != nil {
+			t.Fatalf("failed to decrypt key with %s: %v", priv, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("plaintext key = %q, want %q", got, plaintext)
+		}
+	}
+
+	d, err := f.GetDescriptor(sif.WithDataType(sif.DataSBOM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format, err := d.SBOMMetadata(); err != nil || format != sif.SBOMFormatSPDXJSON {
+		t.Errorf("SBOM metadata = %v, %v, want %v", format, err, sif.SBOMFormatSPDXJSON)
+	}
+}
+
+func TestRewriteSIFKeepKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sif")
+	keyDir := t.TempDir()
+	pubA, _ := newPEMKey(t, keyDir, "a")
+	pubB, privB := newPEMKey(t, keyDir, "b")
+	pubC, privC := newPEMKey(t, keyDir, "c")
+	plaintext := []byte("0123456789abcdef")
+
+	part, err := sif.NewDescriptorInput(sif.DataPartition, bytes.NewReader(make([]byte, 8192)),
+		sif.OptPartitionMetadata(sif.FsEncryptedSquashfs, sif.PartPrimSys, "amd64"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := cryptkey.KeyMessages([]cryptkey.KeyInfo{
+		{Format: cryptkey.PEM, Path: pubA},
+		{Format: cryptkey.PEM, Path: pubB},
+	}, plaintext, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := sif.CreateContainerAtPath(path, sif.OptCreateWithDescriptors(append([]sif.DescriptorInput{part}, msgs...)...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.UnloadContainer(); err != nil {
+		t.Fatal(err)
+	}
+
+	idA, err := cryptkey.RecipientID(cryptkey.KeyInfo{Format: cryptkey.PEM, Path: pubA})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Remove recipient A, retain B, and add C.
+	f, prim, err := loadPrimaryPartition(path, sif.FsEncryptedSquashfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = rewriteSIF(path, f, prim, sifEdit{
+		fsType:    sif.FsEncryptedSquashfs,
+		keys:      []cryptkey.KeyInfo{{Format: cryptkey.PEM, Path: pubC}},
+		plaintext: plaintext,
+		keepKey: func(d sif.Descriptor) bool {
+			return d.Name() != idA
+		},
+	})
+	f.UnloadContainer()
+	if err != nil {
+		t.Fatalf("rewriteSIF() error = %v", err)
+	}
+
+	f, err = sif.LoadContainerFromPath(path, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.UnloadContainer()
+
+	ds, err := f.GetDescriptors(sif.WithLinkedID(1), sif.WithDataType(sif.DataCryptoMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, d := range ds {
+		names = append(names, d.Name())
+	}
+	var want []string
+	for _, pub := range []string{pubC, pubB} {
+		id, err := cryptkey.RecipientID(cryptkey.KeyInfo{Format: cryptkey.PEM, Path: pub})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, id)
+	}
+	if !slices.Equal(names, want) {
+		t.Errorf("recipients = %v, want %v", names, want)
+	}
+
+	for _, priv := range []string{privB, privC} {
+		got, err := cryptkey.PlaintextKey(cryptkey.KeyInfo{Format: cryptkey.PEM, Path: priv}, path)
+		if err != nil {
+			t.Fatalf("failed to decrypt key with %s: %v", priv, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("plaintext key = %q, want %q", got, plaintext)
+		}
+	}
+}
+
+func TestUpdateSIFRecipientsLegacy(t *testing.T) {
+	keyDir := t.TempDir()
+	pubA, _ := newPEMKey(t, keyDir, "a")
+	pubB, _ := newPEMKey(t, keyDir, "b")
+	plaintext := []byte("0123456789abcdef")
+
+	idA, err := cryptkey.RecipientID(cryptkey.KeyInfo{Format: cryptkey.PEM, Path: pubA})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		remove    []string
+		wantErr   bool
+		wantNames []string
+	}{
+		{
+			name:      "RemoveNamed",
+			remove:    []string{idA},
+			wantNames: []string{""},
+		},
+		{
+			name:      "RemoveLegacy",
+			remove:    []string{cryptkey.LegacyRecipientID},
+			wantNames: []string{idA},
+		},
+		{
+			name:    "RemoveAll",
+			remove:  []string{idA, cryptkey.LegacyRecipientID},
+			wantErr: true,
+		},
+		{
+			name:    "NotRecipient",
+			remove:  []string{"rsa-sha256:0000"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// An image with a recipient A, and a recipient B without a name,
+			// as written by earlier versions.
+			path := filepath.Join(t.TempDir(), "test.sif")
+			part, err := sif.NewDescriptorInput(sif.DataPartition, bytes.NewReader(make([]byte, 8192)),
+				sif.OptPartitionMetadata(sif.FsEncryptedSquashfs, sif.PartPrimSys, "amd64"),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			msgs, err := cryptkey.KeyMessages([]cryptkey.KeyInfo{{Format: cryptkey.PEM, Path: pubA}}, plaintext, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := cryptkey.EncryptKey(cryptkey.KeyInfo{Format: cryptkey.PEM, Path: pubB}, plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			legacy, err := sif.NewDescriptorInput(sif.DataCryptoMessage, bytes.NewReader(data),
+				sif.OptLinkedID(1),
+				sif.OptCryptoMessageMetadata(sif.FormatPEM, sif.MessageRSAOAEP),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := sif.CreateContainerAtPath(path, sif.OptCreateWithDescriptors(part, msgs[0], legacy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := f.UnloadContainer(); err != nil {
+				t.Fatal(err)
+			}
+
+			err = UpdateSIFRecipients(path, nil, nil, tt.remove, SIFCryptOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateSIFRecipients() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			f, err = sif.LoadContainerFromPath(path, sif.OptLoadWithFlag(os.O_RDONLY))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.UnloadContainer()
+			ds, err := f.GetDescriptors(sif.WithLinkedID(1), sif.WithDataType(sif.DataCryptoMessage))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, d := range ds {
+				names = append(names, d.Name())
+			}
+			if !slices.Equal(names, tt.wantNames) {
+				t.Errorf("recipients = %q, want %q", names, tt.wantNames)
+			}
+		})
+	}
+}
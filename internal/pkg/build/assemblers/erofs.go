@@ -0,0 +1,59 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package assemblers
+
+import (
+	"fmt"
+	"syscall"
+
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs/erofs"
+	"github.com/sylabs/singularity/v4/pkg/build/types"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+)
+
+// ErofsAssembler assembles a bare EROFS image, as SIF does not yet define an
+// EROFS partition type.
+type ErofsAssembler struct{}
+
+// Assemble creates an EROFS image from a Bundle.
+func (a *ErofsAssembler) Assemble(b *types.Bundle, path string) error {
+	sylog.Infof("Creating EROFS image...")
+
+	if b.Opts.Reproducible {
+		sylog.Verbosef("Clamping rootfs modification times to %s", b.Opts.SourceDate.UTC())
+		if err := fs.ClampMtimes(b.RootfsPath, b.Opts.SourceDate); err != nil {
+			return fmt.Errorf("while clamping modification times: %v", err)
+		}
+	}
+
+	excludes, err := excludePatterns(b)
+	if err != nil {
+		return err
+	}
+	if len(excludes) > 0 {
+		return fmt.Errorf("exclude patterns are not supported when building an erofs image")
+	}
+
+	return createErofs(b, path)
+}
+
+// createErofs creates an EROFS image at path from the rootfs of the bundle b.
+func createErofs(b *types.Bundle, path string) error {
+	// As with squashfs, squash ownership of files to root when called as
+	// non-root.
+	allroot := syscall.Getuid() != 0
+	opts := []erofs.MkfsOpt{erofs.OptAllRoot(allroot)}
+
+	if b.Opts.Reproducible {
+		opts = append(opts, erofs.OptReproducible(b.Opts.SourceDate))
+	}
+
+	if err := erofs.Mkfs(b.RootfsPath, path, opts...); err != nil {
+		return fmt.Errorf("while creating erofs: %v", err)
+	}
+	return nil
+}
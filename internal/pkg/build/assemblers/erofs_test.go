@@ -0,0 +1,77 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package assemblers_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sylabs/singularity/v4/internal/pkg/build/assemblers"
+	"github.com/sylabs/singularity/v4/internal/pkg/test/tool/require"
+	"github.com/sylabs/singularity/v4/pkg/build/types"
+	"github.com/sylabs/singularity/v4/pkg/image"
+)
+
+func TestErofsAssembler(t *testing.T) {
+	require.Command(t, "mkfs.erofs")
+
+	tests := []struct {
+		name     string
+		excludes []string
+		exclude  string
+		wantErr  bool
+	}{
+		{
+			name: "NoExcludes",
+		},
+		{
+			name:     "Excludes",
+			excludes: []string{"/tmp/*"},
+			wantErr:  true,
+		},
+		{
+			name:    "ExcludeSection",
+			exclude: "/tmp/*\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := types.NewBundle(t.TempDir(), t.TempDir())
+			if err != nil {
+				t.Fatalf("unable to make bundle: %v", err)
+			}
+			defer b.Remove()
+
+			if err := os.WriteFile(filepath.Join(b.RootfsPath, "file.txt"), []byte("erofs"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			b.Opts.Excludes = tt.excludes
+			b.Recipe.BuildData.Exclude.Script = tt.exclude
+
+			path := filepath.Join(t.TempDir(), "image.erofs")
+			a := &assemblers.ErofsAssembler{}
+			err = a.Assemble(b, path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Assemble() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			img, err := image.Init(path, false)
+			if err != nil {
+				t.Fatalf("could not open image: %v", err)
+			}
+			defer img.File.Close()
+			if img.Type != image.EROFS {
+				t.Errorf("got image type %d, want %d", img.Type, image.EROFS)
+			}
+		})
+	}
+}
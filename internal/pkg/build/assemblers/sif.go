@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -7,17 +7,25 @@ package assemblers
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
 	"syscall"
 
 	"github.com/ccoveille/go-safecast/v2"
+	"github.com/google/uuid"
 	"github.com/sylabs/sif/v2/pkg/sif"
+	"github.com/sylabs/singularity/v4/internal/pkg/sbom"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/crypt"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/fs/squashfs"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/machine"
 	"github.com/sylabs/singularity/v4/pkg/build/types"
@@ -29,11 +37,16 @@ import (
 type SIFAssembler struct{}
 
 type encryptionOptions struct {
-	keyInfo   cryptkey.KeyInfo
+	keyInfos  []cryptkey.KeyInfo
 	plaintext []byte
 }
 
-func createSIF(path string, b *types.Bundle, squashfile string, encOpts *encryptionOptions, arch string) (err error) {
+type sbomDocument struct {
+	format sbom.Format
+	data   []byte
+}
+
+func createSIF(path string, b *types.Bundle, squashfile string, encOpts *encryptionOptions, sbomDoc *sbomDocument, arch string) (err error) {
 	var dis []sif.DescriptorInput
 
 	// data we need to create a definition file descriptor
@@ -74,14 +87,14 @@ func createSIF(path string, b *types.Bundle, squashfile string, encOpts *encrypt
 	}
 	defer fp.Close()
 
-	fs := sif.FsSquash
+	fsType := sif.FsSquash
 	if encOpts != nil {
-		fs = sif.FsEncryptedSquashfs
+		fsType = sif.FsEncryptedSquashfs
 	}
 
 	// data we need to create a system partition descriptor
 	parinput, err := sif.NewDescriptorInput(sif.DataPartition, fp,
-		sif.OptPartitionMetadata(fs, sif.PartPrimSys, arch),
+		sif.OptPartitionMetadata(fsType, sif.PartPrimSys, arch),
 	)
 	if err != nil {
 		return err
@@ -91,35 +104,53 @@ func createSIF(path string, b *types.Bundle, squashfile string, encOpts *encrypt
 	dis = append(dis, parinput)
 
 	if encOpts != nil {
-		data, err := cryptkey.EncryptKey(encOpts.keyInfo, encOpts.plaintext)
+		syspartID, err := safecast.Convert[uint32](len(dis))
+		if err != nil {
+			return err
+		}
+
+		// The filesystem key is stored encrypted for each recipient.
+		parts, err := cryptkey.KeyMessages(encOpts.keyInfos, encOpts.plaintext, syspartID)
 		if err != nil {
 			return fmt.Errorf("while encrypting filesystem key: %s", err)
 		}
 
-		if data != nil {
-			syspartID, err := safecast.Convert[uint32](len(dis))
-			if err != nil {
-				return err
-			}
-			part, err := sif.NewDescriptorInput(sif.DataCryptoMessage, bytes.NewReader(data),
-				sif.OptLinkedID(syspartID),
-				sif.OptCryptoMessageMetadata(sif.FormatPEM, sif.MessageRSAOAEP),
-			)
-			if err != nil {
-				return err
-			}
+		dis = append(dis, parts...)
+	}
+
+	if sbomDoc != nil {
+		in, err := sif.NewDescriptorInput(sif.DataSBOM, bytes.NewReader(sbomDoc.data),
+			sif.OptSBOMMetadata(sifSBOMFormat(sbomDoc.format)),
+		)
+		if err != nil {
+			return err
+		}
+
+		dis = append(dis, in)
+	}
 
-			dis = append(dis, part)
+	opts := []sif.CreateOpt{
+		sif.OptCreateWithLaunchScript("#!/usr/bin/env run-singularity\n"),
+		sif.OptCreateWithDescriptors(dis...),
+	}
+
+	// A reproducible SIF records the source date, rather than the current
+	// time, and an ID derived from its content, rather than a random ID.
+	if b.Opts.Reproducible {
+		id, err := reproducibleID(b, squashfile, sbomDoc)
+		if err != nil {
+			return fmt.Errorf("while generating SIF ID: %w", err)
 		}
+		opts = append(opts,
+			sif.OptCreateWithID(id),
+			sif.OptCreateWithTime(b.Opts.SourceDate),
+		)
 	}
 
 	// remove anything that may exist at the build destination at last moment
 	os.RemoveAll(path)
 
-	f, err := sif.CreateContainerAtPath(path,
-		sif.OptCreateWithLaunchScript("#!/usr/bin/env run-singularity\n"),
-		sif.OptCreateWithDescriptors(dis...),
-	)
+	f, err := sif.CreateContainerAtPath(path, opts...)
 	if err != nil {
 		return fmt.Errorf("while creating container: %w", err)
 	}
@@ -158,13 +189,36 @@ func (a *SIFAssembler) Assemble(b *types.Bundle, path string) error {
 	}
 	sylog.Verbosef("Set SIF container architecture to %s", arch)
 
-	// Squash ownership of squashfs files to root when called as non-root, so we
-	// don't have container files owned by a uid that might not exist on other
-	// systems.
-	allroot := syscall.Getuid() != 0
+	// The SBOM must be generated from the rootfs before it is packed.
+	var sbomDoc *sbomDocument
 
-	if err := squashfs.Mksquashfs([]string{b.RootfsPath}, fsPath, squashfs.OptAllRoot(allroot)); err != nil {
-		return fmt.Errorf("while creating squashfs: %v", err)
+	if b.Opts.SBOMFormat != "" {
+		// The SBOM object is not encrypted, so would disclose the content of
+		// an encrypted image.
+		if b.Opts.EncryptionKeyInfo != nil {
+			return fmt.Errorf("an SBOM cannot be added to an encrypted image")
+		}
+		doc, err := generateSBOM(b, sbom.Format(b.Opts.SBOMFormat), filepath.Base(path))
+		if err != nil {
+			return fmt.Errorf("while generating SBOM: %v", err)
+		}
+		sbomDoc = doc
+	}
+
+	if b.Opts.Reproducible {
+		sylog.Verbosef("Clamping rootfs modification times to %s", b.Opts.SourceDate.UTC())
+		if err := fs.ClampMtimes(b.RootfsPath, b.Opts.SourceDate); err != nil {
+			return fmt.Errorf("while clamping modification times: %v", err)
+		}
+	}
+
+	excludes, err := excludePatterns(b)
+	if err != nil {
+		return err
+	}
+
+	if err := createSquashfs(b, fsPath, excludes); err != nil {
+		return err
 	}
 
 	var encOpts *encryptionOptions
@@ -178,10 +232,9 @@ func (a *SIFAssembler) Assemble(b *types.Bundle, path string) error {
 		// A dm-crypt device needs to be created with squashfs
 		cryptDev := &crypt.Device{}
 
-		// TODO (schebro): Fix #3876
-		// Detach the following code from the squashfs creation. SIF can be
-		// created first and encrypted after. This gives the flexibility to
-		// encrypt an existing SIF
+		// An existing SIF can be encrypted with 'singularity sif encrypt'.
+		// At build time, the squashfs is encrypted before the SIF is created,
+		// so that the image is not written twice.
 		loopPath, err := cryptDev.EncryptFilesystem(fsPath, plaintext)
 		if err != nil {
 			return fmt.Errorf("unable to encrypt filesystem at %s: %+v", fsPath, err)
@@ -191,12 +244,12 @@ func (a *SIFAssembler) Assemble(b *types.Bundle, path string) error {
 		fsPath = loopPath
 
 		encOpts = &encryptionOptions{
-			keyInfo:   *b.Opts.EncryptionKeyInfo,
+			keyInfos:  append([]cryptkey.KeyInfo{*b.Opts.EncryptionKeyInfo}, b.Opts.EncryptionRecipients...),
 			plaintext: plaintext,
 		}
 	}
 
-	err = createSIF(path, b, fsPath, encOpts, arch)
+	err = createSIF(path, b, fsPath, encOpts, sbomDoc, arch)
 	if err != nil {
 		return fmt.Errorf("while creating SIF: %v", err)
 	}
@@ -204,6 +257,111 @@ func (a *SIFAssembler) Assemble(b *types.Bundle, path string) error {
 	return nil
 }
 
+// excludePatterns returns the patterns of paths to exclude from the root
+// filesystem of the bundle b, from the build options and the %exclude section
+// of its definition.
+func excludePatterns(b *types.Bundle) ([]string, error) {
+	patterns, err := squashfs.ParseExcludes(strings.NewReader(b.Recipe.BuildData.Exclude.Script))
+	if err != nil {
+		return nil, fmt.Errorf("while parsing %%exclude section: %v", err)
+	}
+	return append(slices.Clone(b.Opts.Excludes), patterns...), nil
+}
+
+// createSquashfs creates a squashfs image at path from the rootfs of the
+// bundle b, omitting paths that match the exclude patterns.
+func createSquashfs(b *types.Bundle, path string, excludes []string) error {
+	// Squash ownership of squashfs files to root when called as non-root, so we
+	// don't have container files owned by a uid that might not exist on other
+	// systems.
+	allroot := syscall.Getuid() != 0
+	sqOpts := []squashfs.MksquashfsOpt{squashfs.OptAllRoot(allroot)}
+
+	if b.Opts.Compression != "" {
+		comp, err := squashfs.ParseCompression(b.Opts.Compression)
+		if err != nil {
+			return err
+		}
+		sqOpts = append(sqOpts, squashfs.OptCompression(comp))
+	}
+
+	if len(excludes) > 0 {
+		sylog.Verbosef("Excluding paths matching %q from root filesystem", excludes)
+		sqOpts = append(sqOpts, squashfs.OptWildcards(true), squashfs.OptExcludes(excludes))
+	}
+
+	if b.Opts.Reproducible {
+		sqOpts = append(sqOpts, squashfs.OptReproducible(b.Opts.SourceDate))
+	}
+
+	if err := squashfs.Mksquashfs([]string{b.RootfsPath}, path, sqOpts...); err != nil {
+		return fmt.Errorf("while creating squashfs: %v", err)
+	}
+	return nil
+}
+
+// generateSBOM returns an SBOM in format f, listing the packages installed in
+// the rootfs of the bundle b, for the image name.
+func generateSBOM(b *types.Bundle, f sbom.Format, name string) (*sbomDocument, error) {
+	sylog.Infof("Generating %s SBOM...", f)
+
+	pkgs, err := sbom.Scan(b.RootfsPath)
+	if err != nil {
+		return nil, err
+	}
+	opts := sbom.Options{Name: name}
+	if b.Opts.Reproducible {
+		opts.Created = b.Opts.SourceDate
+	}
+	data, err := sbom.Generate(f, pkgs, opts)
+	if err != nil {
+		return nil, err
+	}
+	sylog.Verbosef("SBOM lists %d packages", len(pkgs))
+	return &sbomDocument{format: f, data: data}, nil
+}
+
+// reproducibleID returns a SIF ID that is derived from the definition, JSON
+// objects, SBOM and rootfs partition that are written to the SIF, so that
+// identical builds are assigned the same ID.
+func reproducibleID(b *types.Bundle, squashfile string, sbomDoc *sbomDocument) (string, error) {
+	h := sha256.New()
+	h.Write(b.Recipe.FullRaw)
+
+	names := make([]string, 0, len(b.JSONObjects))
+	for name := range b.JSONObjects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write(b.JSONObjects[name])
+	}
+
+	if sbomDoc != nil {
+		h.Write(sbomDoc.data)
+	}
+
+	f, err := os.Open(squashfile)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return uuid.NewSHA1(uuid.Nil, h.Sum(nil)).String(), nil
+}
+
+// sifSBOMFormat returns the SIF SBOM format of documents in format f.
+func sifSBOMFormat(f sbom.Format) sif.SBOMFormat {
+	if f == sbom.FormatCycloneDX {
+		return sif.SBOMFormatCycloneDXJSON
+	}
+	return sif.SBOMFormatSPDXJSON
+}
+
 // changeOwner check the command being called with sudo with the environment
 // variable SUDO_COMMAND. Pattern match that for the singularity bin.
 func changeOwner() (int, int, bool) {
@@ -189,6 +189,8 @@ func newBuild(defs []types.Definition, conf Config) (*Build, error) {
 		b.stages[lastStageIndex].a = &assemblers.SandboxAssembler{Copy: sandboxCopy}
 	case "sif":
 		b.stages[lastStageIndex].a = &assemblers.SIFAssembler{}
+	case "erofs":
+		b.stages[lastStageIndex].a = &assemblers.ErofsAssembler{}
 	default:
 		return nil, fmt.Errorf("unrecognized output format %s", conf.Format)
 	}
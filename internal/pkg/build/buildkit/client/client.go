@@ -35,6 +35,8 @@ import (
 
 	"github.com/blang/semver/v4"
 	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	moby_buildkit_v1 "github.com/moby/buildkit/api/services/control"
 	"github.com/moby/buildkit/client"
 	dockerfile "github.com/moby/buildkit/frontend/dockerfile/builder"
@@ -45,12 +47,16 @@ import (
 	bkauth "github.com/sylabs/singularity/v4/internal/pkg/build/buildkit/auth"
 	"github.com/sylabs/singularity/v4/internal/pkg/client/ocisif"
 	"github.com/sylabs/singularity/v4/internal/pkg/ociplatform"
+	ocisifimage "github.com/sylabs/singularity/v4/internal/pkg/ocisif"
 	"github.com/sylabs/singularity/v4/internal/pkg/remote/credential/ociauth"
+	"github.com/sylabs/singularity/v4/internal/pkg/sbom"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/bin"
 	fsoverlay "github.com/sylabs/singularity/v4/internal/pkg/util/fs/overlay"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs/squashfs"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/rootless"
 	"github.com/sylabs/singularity/v4/pkg/syfs"
 	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/cryptkey"
 	"github.com/tonistiigi/fsutil"
 	"golang.org/x/sync/errgroup"
 )
@@ -82,6 +88,16 @@ type Opts struct {
 	ContextDir string
 	// Disable buildkitd's internal caching mechanism
 	DisableCache bool
+	// Optional key material, with which to encrypt the OCI-SIF layers
+	EncryptionKeyInfo *cryptkey.KeyInfo
+	// Optional SBOM format, in which to generate an SBOM for the built image
+	SBOMFormat string
+	// Optional squashfs compression of the OCI-SIF layers, as algorithm[:level]
+	Compression string
+	// Optional mksquashfs wildcard patterns of paths to omit from the OCI-SIF layers
+	Excludes []string
+	// Optional directory in which to create temporary files
+	TmpDir string
 }
 
 func Run(ctx context.Context, opts *Opts, dest, spec string) error {
@@ -99,7 +115,7 @@ func Run(ctx context.Context, opts *Opts, dest, spec string) error {
 		defer bkCleanup()
 	}
 
-	tarFile, err := os.CreateTemp("", "singularity-buildkit-tar-")
+	tarFile, err := os.CreateTemp(opts.TmpDir, "singularity-buildkit-tar-")
 	if err != nil {
 		return fmt.Errorf("while creating temporary tar file: %w", err)
 	}
@@ -118,7 +134,17 @@ func Run(ctx context.Context, opts *Opts, dest, spec string) error {
 	tarFile.Close()
 
 	pullOpts := ocisif.PullOptions{
-		KeepLayers: opts.KeepLayers,
+		KeepLayers:        opts.KeepLayers,
+		EncryptionKeyInfo: opts.EncryptionKeyInfo,
+		Excludes:          opts.Excludes,
+		TmpDir:            opts.TmpDir,
+	}
+	if opts.Compression != "" {
+		comp, err := squashfs.ParseCompression(opts.Compression)
+		if err != nil {
+			return err
+		}
+		pullOpts.SquashfsCompression = comp
 	}
 	if opts.ReqArch != "" {
 		platform, err := ociplatform.PlatformFromArch(opts.ReqArch)
@@ -131,9 +157,38 @@ func Run(ctx context.Context, opts *Opts, dest, spec string) error {
 		return fmt.Errorf("while converting OCI tar image to OCI-SIF: %w", err)
 	}
 
+	if opts.SBOMFormat != "" {
+		if err := addSBOM(sbom.Format(opts.SBOMFormat), tarFile.Name(), dest, opts.TmpDir); err != nil {
+			return fmt.Errorf("while generating SBOM: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// addSBOM generates an SBOM in format f, listing the packages installed in the
+// docker-archive image at tarPath, and adds it to the OCI-SIF at dest. The
+// files scanned are extracted to a temporary directory in tmpDir.
+func addSBOM(f sbom.Format, tarPath, dest, tmpDir string) error {
+	sylog.Infof("Generating %s SBOM...", f)
+
+	img, err := tarball.ImageFromPath(tarPath, nil)
+	if err != nil {
+		return err
+	}
+	pkgs, err := sbom.ScanImage(img, tmpDir)
+	if err != nil {
+		return err
+	}
+	data, err := sbom.Generate(f, pkgs, sbom.Options{Name: filepath.Base(dest)})
+	if err != nil {
+		return err
+	}
+	sylog.Verbosef("SBOM lists %d packages", len(pkgs))
+
+	return ocisifimage.AddSBOM(dest, data, types.MediaType(f.MediaType()))
+}
+
 // ensureBuildkitd checks if a buildkitd daemon is already running, and if not,
 // launches one. The trySocket argument is the address at which to look for an
 // already-running daemon. The bkSocket returned is the address of the running
@@ -199,7 +254,7 @@ func startBuildkitd(ctx context.Context, opts *Opts) (bkSocket string, cleanup f
 	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	cmd.Env = append(os.Environ(), sylog.GetEnvVar())
+	cmd.Env = append(os.Environ(), sylog.GetEnvVar(), sylog.GetFormatEnvVar())
 
 	cleanup = func() {
 		if err := cmd.Cancel(); err != nil {
@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // Copyright (c) Contributors to the Apptainer project, established as
 //   Apptainer a Series of LF Projects LLC.
 // This software is licensed under a 3-clause BSD license. Please consult the
@@ -55,6 +55,8 @@ func NewConveyorPacker(def types.Definition) (ConveyorPacker, error) {
 		return &sources.DebootstrapConveyorPacker{}, nil
 	case "arch":
 		return &sources.ArchConveyorPacker{}, nil
+	case "apk":
+		return &sources.ApkConveyorPacker{}, nil
 	case "localimage":
 		return &sources.LocalConveyorPacker{}, nil
 	case "yum", "dnf":
@@ -0,0 +1,324 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package sources
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// apkPackage is a package entry in an Alpine APKINDEX.
+type apkPackage struct {
+	// Name is the package name (P:)
+	Name string
+	// Version is the package version (V:)
+	Version string
+	// Checksum is the Q1 prefixed, base64 encoded, SHA1 checksum of the
+	// package control segment (C:)
+	Checksum string
+	// Depends lists the package dependencies (D:)
+	Depends []string
+	// Provides lists the additional names provided by the package (p:)
+	Provides []string
+	// ProviderPriority is the priority of the package, where multiple
+	// packages provide the same name (k:)
+	ProviderPriority int
+	// Fields holds all of the index entry lines, in order, for use in the
+	// installed package database.
+	Fields []string
+}
+
+// Filename returns the name of the .apk file for the package in a repository.
+func (p *apkPackage) Filename() string {
+	return p.Name + "-" + p.Version + ".apk"
+}
+
+// readAPKIndex reads package entries from an APKINDEX.tar.gz, read from r.
+func readAPKIndex(r io.Reader) ([]*apkPackage, error) {
+	// APKINDEX.tar.gz may be signed, in which case it consists of a signature
+	// gzip stream followed by the index stream.
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("while reading APKINDEX: %w", err)
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("APKINDEX not found in index archive")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("while reading APKINDEX: %w", err)
+		}
+		if hdr.Name == "APKINDEX" {
+			return parseAPKIndex(tr)
+		}
+	}
+}
+
+// errAPKIndexUnsigned is returned when an APKINDEX.tar.gz does not begin with
+// a signature.
+var errAPKIndexUnsigned = errors.New("APKINDEX is not signed")
+
+// verifyAPKIndex checks the signature of the APKINDEX.tar.gz in b, against
+// the RSA public keys in keysDir. A signed index begins with a gzip stream
+// holding a .SIGN.RSA.<key> (SHA1) or .SIGN.RSA256.<key> (SHA256) entry, which
+// is a PKCS#1 v1.5 signature over the remainder of the file. <key> is the
+// name of the public key in keysDir.
+func verifyAPKIndex(b []byte, keysDir string) error {
+	cr := &countingByteReader{r: bufio.NewReader(bytes.NewReader(b))}
+	gzr, err := gzip.NewReader(cr)
+	if err != nil {
+		return fmt.Errorf("while reading APKINDEX: %w", err)
+	}
+	defer gzr.Close()
+	gzr.Multistream(false)
+
+	tr := tar.NewReader(gzr)
+	hdr, err := tr.Next()
+	if err != nil {
+		return fmt.Errorf("while reading APKINDEX signature: %w", err)
+	}
+
+	var hash crypto.Hash
+	var keyName string
+	switch {
+	case strings.HasPrefix(hdr.Name, ".SIGN.RSA256."):
+		hash, keyName = crypto.SHA256, strings.TrimPrefix(hdr.Name, ".SIGN.RSA256.")
+	case strings.HasPrefix(hdr.Name, ".SIGN.RSA."):
+		hash, keyName = crypto.SHA1, strings.TrimPrefix(hdr.Name, ".SIGN.RSA.")
+	default:
+		return errAPKIndexUnsigned
+	}
+	if keyName == "" || filepath.Base(keyName) != keyName {
+		return fmt.Errorf("invalid APKINDEX signing key name %q", keyName)
+	}
+
+	sig, err := io.ReadAll(tr)
+	if err != nil {
+		return fmt.Errorf("while reading APKINDEX signature: %w", err)
+	}
+	// Read to the end of the signature gzip stream, to find where the signed
+	// data begins.
+	if _, err := io.Copy(io.Discard, gzr); err != nil {
+		return fmt.Errorf("while reading APKINDEX signature: %w", err)
+	}
+
+	pub, err := readAPKKey(filepath.Join(keysDir, keyName))
+	if err != nil {
+		return fmt.Errorf("APKINDEX signing key %s is not trusted: %w", keyName, err)
+	}
+
+	// The signature covers all data following the signature gzip stream.
+	h := hash.New()
+	h.Write(b[cr.n:])
+	if err := rsa.VerifyPKCS1v15(pub, hash, h.Sum(nil), sig); err != nil {
+		return fmt.Errorf("APKINDEX signature verification failed: %w", err)
+	}
+	return nil
+}
+
+// readAPKKey reads a PEM encoded RSA public key, as found in /etc/apk/keys,
+// from path.
+func readAPKKey(path string) (*rsa.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an RSA public key", path)
+	}
+	return pub, nil
+}
+
+// parseAPKIndex parses package entries from the text of an APKINDEX, read from
+// r. Entries are separated by blank lines.
+func parseAPKIndex(r io.Reader) ([]*apkPackage, error) {
+	pkgs := []*apkPackage{}
+	var p *apkPackage
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := s.Text()
+		if line == "" {
+			if p != nil {
+				if p.Name == "" || p.Version == "" {
+					return nil, fmt.Errorf("APKINDEX entry is missing package name or version")
+				}
+				pkgs = append(pkgs, p)
+				p = nil
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || len(key) != 1 {
+			return nil, fmt.Errorf("invalid APKINDEX line %q", line)
+		}
+		if p == nil {
+			p = &apkPackage{}
+		}
+		p.Fields = append(p.Fields, line)
+
+		switch key {
+		case "P":
+			p.Name = value
+		case "V":
+			p.Version = value
+		case "C":
+			p.Checksum = value
+		case "D":
+			p.Depends = strings.Fields(value)
+		case "p":
+			p.Provides = strings.Fields(value)
+		case "k":
+			prio, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid provider priority %q for %s", value, p.Name)
+			}
+			p.ProviderPriority = prio
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if p != nil {
+		if p.Name == "" || p.Version == "" {
+			return nil, fmt.Errorf("APKINDEX entry is missing package name or version")
+		}
+		pkgs = append(pkgs, p)
+	}
+	return pkgs, nil
+}
+
+// apkDepName returns the name from an APKINDEX dependency or provides entry,
+// without any version constraint. Conflicts, prefixed with `!`, return an
+// empty name.
+func apkDepName(dep string) string {
+	if strings.HasPrefix(dep, "!") {
+		return ""
+	}
+	if i := strings.IndexAny(dep, "<>=~"); i >= 0 {
+		return dep[:i]
+	}
+	return dep
+}
+
+// apkIndex allows package names, and names provided by packages, to be
+// resolved against a set of APKINDEX entries.
+type apkIndex struct {
+	packages  map[string]*apkPackage
+	providers map[string][]*apkPackage
+}
+
+// newAPKIndex returns an apkIndex for pkgs. Where a package name appears more
+// than once, the first entry is used.
+func newAPKIndex(pkgs []*apkPackage) *apkIndex {
+	idx := &apkIndex{
+		packages:  map[string]*apkPackage{},
+		providers: map[string][]*apkPackage{},
+	}
+	for _, p := range pkgs {
+		if _, ok := idx.packages[p.Name]; ok {
+			continue
+		}
+		idx.packages[p.Name] = p
+		for _, prov := range p.Provides {
+			name := apkDepName(prov)
+			idx.providers[name] = append(idx.providers[name], p)
+		}
+	}
+	return idx
+}
+
+// errAPKNotFound is returned when a package or dependency cannot be found in
+// the index.
+var errAPKNotFound = errors.New("not found in APKINDEX")
+
+// lookup returns the package with the given name, or the package with the
+// highest provider priority that provides name.
+func (idx *apkIndex) lookup(name string) (*apkPackage, error) {
+	if p, ok := idx.packages[name]; ok {
+		return p, nil
+	}
+	var best *apkPackage
+	for _, p := range idx.providers[name] {
+		if best == nil || p.ProviderPriority > best.ProviderPriority {
+			best = p
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("%s: %w", name, errAPKNotFound)
+	}
+	return best, nil
+}
+
+// resolve returns the packages named, together with all of their
+// dependencies, ordered so that each package follows its dependencies.
+// Version constraints are not considered, as an APKINDEX holds a single
+// version of each package.
+func (idx *apkIndex) resolve(names []string) ([]*apkPackage, error) {
+	ordered := []*apkPackage{}
+	visited := map[*apkPackage]bool{}
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		p, err := idx.lookup(name)
+		if err != nil {
+			return err
+		}
+		// A package is marked as visited before its dependencies, so that
+		// dependency cycles terminate.
+		if visited[p] {
+			return nil
+		}
+		visited[p] = true
+
+		for _, dep := range p.Depends {
+			depName := apkDepName(dep)
+			if depName == "" {
+				continue
+			}
+			if err := visit(depName); err != nil {
+				return fmt.Errorf("dependency of %s: %w", p.Name, err)
+			}
+		}
+		ordered = append(ordered, p)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(apkDepName(name)); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,277 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package sources
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testAPKIndex = `C:Q1aaaa=
+P:musl
+V:1.2.5-r9
+D:
+k:10
+
+C:Q1bbbb=
+P:busybox
+V:1.37.0-r12
+D:so:libc.musl-x86_64.so.1
+p:/bin/sh cmd:busybox=1.37.0-r12
+
+C:Q1cccc=
+P:dash-binsh
+V:0.5.12-r3
+D:dash
+p:/bin/sh
+k:60
+
+C:Q1dddd=
+P:dash
+V:0.5.12-r3
+D:so:libc.musl-x86_64.so.1 !busybox-binsh
+
+C:Q1eeee=
+P:musl-so
+V:1.2.5-r9
+p:so:libc.musl-x86_64.so.1=1
+`
+
+func TestParseAPKIndex(t *testing.T) {
+	pkgs, err := parseAPKIndex(strings.NewReader(testAPKIndex))
+	if err != nil {
+		t.Fatalf("parseAPKIndex() error = %v", err)
+	}
+	if len(pkgs) != 5 {
+		t.Fatalf("parseAPKIndex() returned %d packages, want 5", len(pkgs))
+	}
+
+	want := &apkPackage{
+		Name:     "busybox",
+		Version:  "1.37.0-r12",
+		Checksum: "Q1bbbb=",
+		Depends:  []string{"so:libc.musl-x86_64.so.1"},
+		Provides: []string{"/bin/sh", "cmd:busybox=1.37.0-r12"},
+		Fields: []string{
+			"C:Q1bbbb=",
+			"P:busybox",
+			"V:1.37.0-r12",
+			"D:so:libc.musl-x86_64.so.1",
+			"p:/bin/sh cmd:busybox=1.37.0-r12",
+		},
+	}
+	if !reflect.DeepEqual(pkgs[1], want) {
+		t.Errorf("parseAPKIndex() = %+v, want %+v", pkgs[1], want)
+	}
+	if got := pkgs[1].Filename(); got != "busybox-1.37.0-r12.apk" {
+		t.Errorf("Filename() = %q, want %q", got, "busybox-1.37.0-r12.apk")
+	}
+
+	if _, err := parseAPKIndex(strings.NewReader("P:foo\ninvalid\n")); err == nil {
+		t.Errorf("parseAPKIndex() expected error for invalid line")
+	}
+	if _, err := parseAPKIndex(strings.NewReader("P:foo\n\n")); err == nil {
+		t.Errorf("parseAPKIndex() expected error for missing version")
+	}
+}
+
+func TestAPKIndexResolve(t *testing.T) {
+	pkgs, err := parseAPKIndex(strings.NewReader(testAPKIndex))
+	if err != nil {
+		t.Fatalf("parseAPKIndex() error = %v", err)
+	}
+	idx := newAPKIndex(pkgs)
+
+	tests := []struct {
+		name    string
+		names   []string
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "Single",
+			names: []string{"musl"},
+			want:  []string{"musl"},
+		},
+		{
+			name:  "Dependencies",
+			names: []string{"busybox"},
+			want:  []string{"musl-so", "busybox"},
+		},
+		{
+			name:  "ProviderPriority",
+			names: []string{"/bin/sh"},
+			want:  []string{"musl-so", "dash", "dash-binsh"},
+		},
+		{
+			name:  "Constraint",
+			names: []string{"busybox>=1.36", "busybox"},
+			want:  []string{"musl-so", "busybox"},
+		},
+		{
+			name:    "NotFound",
+			names:   []string{"musl", "bash"},
+			wantErr: errAPKNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := idx.resolve(tt.names)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("resolve() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			gotNames := []string{}
+			for _, p := range got {
+				gotNames = append(gotNames, p.Name)
+			}
+			if !reflect.DeepEqual(gotNames, tt.want) {
+				t.Errorf("resolve() = %v, want %v", gotNames, tt.want)
+			}
+		})
+	}
+}
+
+// apkTarGz returns a gzip compressed tar stream holding a single file. If
+// trailer is false, the end of archive marker is omitted, as for the signature
+// stream of an APKINDEX.tar.gz.
+func apkTarGz(t *testing.T, name string, content []byte, trailer bool) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	closeTar := tw.Flush
+	if trailer {
+		closeTar = tw.Close
+	}
+	if err := closeTar(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// signedAPKIndex returns an APKINDEX.tar.gz holding testAPKIndex, signed with
+// key, using the signature entry prefix and hash provided.
+func signedAPKIndex(t *testing.T, key *rsa.PrivateKey, prefix, keyName string, hash crypto.Hash) []byte {
+	t.Helper()
+
+	index := apkTarGz(t, "APKINDEX", []byte(testAPKIndex), true)
+	h := hash.New()
+	h.Write(index)
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, hash, h.Sum(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(apkTarGz(t, prefix+keyName, sig, false), index...)
+}
+
+func TestVerifyAPKIndex(t *testing.T) {
+	keysDir := t.TempDir()
+	const keyName = "test@example.com-00000000.rsa.pub"
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(keysDir, keyName), pemKey, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := signedAPKIndex(t, key, ".SIGN.RSA256.", keyName, crypto.SHA256)
+	tampered = append(tampered[:len(tampered)-1], tampered[len(tampered)-1]^0xff)
+
+	tests := []struct {
+		name    string
+		index   []byte
+		wantErr bool
+	}{
+		{
+			name:  "RSA256",
+			index: signedAPKIndex(t, key, ".SIGN.RSA256.", keyName, crypto.SHA256),
+		},
+		{
+			name:  "RSA",
+			index: signedAPKIndex(t, key, ".SIGN.RSA.", keyName, crypto.SHA1),
+		},
+		{
+			name:    "Tampered",
+			index:   tampered,
+			wantErr: true,
+		},
+		{
+			name:    "WrongKey",
+			index:   signedAPKIndex(t, otherKey, ".SIGN.RSA256.", keyName, crypto.SHA256),
+			wantErr: true,
+		},
+		{
+			name:    "UnknownKey",
+			index:   signedAPKIndex(t, key, ".SIGN.RSA256.", "unknown.rsa.pub", crypto.SHA256),
+			wantErr: true,
+		},
+		{
+			name:    "KeyPath",
+			index:   signedAPKIndex(t, key, ".SIGN.RSA256.", "../"+keyName, crypto.SHA256),
+			wantErr: true,
+		},
+		{
+			name:    "Unsigned",
+			index:   apkTarGz(t, "APKINDEX", []byte(testAPKIndex), true),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyAPKIndex(tt.index, keysDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifyAPKIndex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			pkgs, err := readAPKIndex(bytes.NewReader(tt.index))
+			if err != nil {
+				t.Fatalf("readAPKIndex() error = %v", err)
+			}
+			if len(pkgs) != 5 {
+				t.Errorf("readAPKIndex() returned %d packages, want 5", len(pkgs))
+			}
+		})
+	}
+}
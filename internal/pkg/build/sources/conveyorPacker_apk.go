@@ -0,0 +1,592 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package sources
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	mobyarchive "github.com/moby/go-archive"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/bin"
+	"github.com/sylabs/singularity/v4/pkg/build/types"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/archive"
+)
+
+const (
+	apkHostKeysDir   = "/etc/apk/keys"
+	apkInstalledDB   = "lib/apk/db/installed"
+	apkWorldFile     = "etc/apk/world"
+	apkReposFile     = "etc/apk/repositories"
+	apkArchFile      = "etc/apk/arch"
+	apkPostInstall   = ".post-install"
+	apkScriptTmpName = "apk-post-install"
+)
+
+// apkArchs is a map of GO Archs to Alpine architectures
+// https://wiki.alpinelinux.org/wiki/Architecture
+var apkArchs = map[string]string{
+	"386":     "x86",
+	"amd64":   "x86_64",
+	"arm":     "armv7",
+	"arm64":   "aarch64",
+	"ppc64le": "ppc64le",
+	"riscv64": "riscv64",
+	"s390x":   "s390x",
+}
+
+// Default list of packages to install when bootstrapping Alpine, matching the
+// content of the Alpine minirootfs.
+var apkInstList = []string{"alpine-baselayout", "alpine-keys", "apk-tools", "busybox", "/bin/sh"}
+
+// ApkConveyorPacker holds stuff that needs to be packed into the bundle
+type ApkConveyorPacker struct {
+	b         *types.Bundle
+	mirrorurl string
+	osversion string
+	arch      string
+	include   []string
+	// keysDir is the host directory holding the public keys that repository
+	// index signatures are verified against.
+	keysDir string
+	// allowUntrusted permits installation without signature verification,
+	// when no keys are available.
+	allowUntrusted bool
+}
+
+// Get downloads and installs the requested Alpine packages into the bundle
+// rootfs. When apk.static, or apk, is available on the host it is used to
+// perform the installation. Otherwise, the packages are resolved against the
+// repository APKINDEX, and extracted directly.
+func (cp *ApkConveyorPacker) Get(ctx context.Context, b *types.Bundle) (err error) {
+	cp.b = b
+
+	var ok bool
+	cp.arch, ok = apkArchs[runtime.GOARCH]
+	if !ok {
+		return fmt.Errorf("%v architecture is not supported", runtime.GOARCH)
+	}
+
+	if err := cp.getBootstrapOptions(); err != nil {
+		return err
+	}
+
+	sylog.Debugf("\n\tMirrorURL: %s\n\tOSVersion: %s\n\tArch: %s\n\tInclude: %v\n", cp.mirrorurl, cp.osversion, cp.arch, cp.include)
+
+	apkPath, err := findApk()
+	if err == nil {
+		sylog.Infof("Installing packages with %s", apkPath)
+		err = cp.apkInstall(ctx, apkPath)
+	} else {
+		sylog.Infof("apk.static not found, installing packages directly from %s", cp.mirrorurl)
+		err = cp.directInstall(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	return cp.b.Rootfs.WriteFile(apkReposFile, []byte(cp.mirrorurl+"\n"), 0o644)
+}
+
+// Pack puts relevant objects in a Bundle.
+func (cp *ApkConveyorPacker) Pack(context.Context) (*types.Bundle, error) {
+	if err := makeBaseEnv(cp.b, true); err != nil {
+		return nil, fmt.Errorf("while inserting base environment: %v", err)
+	}
+
+	if err := cp.b.Rootfs.WriteFile(".singularity.d/runscript", []byte("#!/bin/sh\n"), 0o755); err != nil {
+		return nil, fmt.Errorf("while inserting runscript: %v", err)
+	}
+
+	return cp.b, nil
+}
+
+// CleanUp removes any tmpfs owned by the conveyorPacker on the filesystem
+func (cp *ApkConveyorPacker) CleanUp() {
+	cp.b.Remove()
+}
+
+func (cp *ApkConveyorPacker) getBootstrapOptions() error {
+	var ok bool
+
+	cp.mirrorurl, ok = cp.b.Recipe.Header["mirrorurl"]
+	if !ok {
+		return fmt.Errorf("invalid apk header, no mirrorurl specified")
+	}
+
+	// look for an OS version if a mirror specifies it
+	regex := regexp.MustCompile(`(?i)%{OSVERSION}`)
+	if regex.MatchString(cp.mirrorurl) {
+		cp.osversion, ok = cp.b.Recipe.Header["osversion"]
+		if !ok {
+			return fmt.Errorf("invalid apk header, osversion referenced in mirror but no osversion specified")
+		}
+		cp.mirrorurl = regex.ReplaceAllString(cp.mirrorurl, cp.osversion)
+	}
+	cp.mirrorurl = strings.TrimSuffix(cp.mirrorurl, "/")
+
+	include := cp.b.Recipe.Header["include"]
+
+	// check for include environment variable and add it to requires string
+	include += ` ` + os.Getenv("INCLUDE")
+
+	cp.include = append(slices.Clone(apkInstList), strings.Fields(include)...)
+
+	return cp.getKeysDir()
+}
+
+// getKeysDir sets the directory of trusted public keys, from the KeysDir
+// header, or the host /etc/apk/keys. If no keys are available, building fails
+// unless AllowUntrusted is set to true, as signatures cannot be verified.
+func (cp *ApkConveyorPacker) getKeysDir() error {
+	if v, ok := cp.b.Recipe.Header["allowuntrusted"]; ok {
+		allow, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid apk header, allowuntrusted must be true or false: %v", err)
+		}
+		cp.allowUntrusted = allow
+	}
+
+	if dir, ok := cp.b.Recipe.Header["keysdir"]; ok {
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			return fmt.Errorf("invalid apk header, keysdir %q is not a directory", dir)
+		}
+		cp.keysDir = dir
+		return nil
+	}
+
+	if fi, err := os.Stat(apkHostKeysDir); err == nil && fi.IsDir() {
+		cp.keysDir = apkHostKeysDir
+		return nil
+	}
+
+	if !cp.allowUntrusted {
+		return fmt.Errorf("%s not found on host, specify a directory of trusted Alpine keys with the keysdir header, or set allowuntrusted: true to install without verifying signatures", apkHostKeysDir)
+	}
+	sylog.Warningf("No apk keys available, repository signatures will not be verified")
+	return nil
+}
+
+// findApk returns the path to apk.static, or apk, on the host.
+func findApk() (string, error) {
+	path, err := bin.FindBin("apk.static")
+	if err == nil {
+		return path, nil
+	}
+	return bin.FindBin("apk")
+}
+
+// apkInstall installs packages into the rootfs with the host apk.static.
+func (cp *ApkConveyorPacker) apkInstall(ctx context.Context, apkPath string) error {
+	args := []string{
+		"--root", cp.b.RootfsPath,
+		"--initdb",
+		"--update-cache",
+		"--no-progress",
+		"--arch", cp.arch,
+		"--repository", cp.mirrorurl,
+	}
+	// Without keys, the repository index signature cannot be checked. This is
+	// only permitted with an explicit allowuntrusted.
+	if cp.keysDir != "" {
+		args = append(args, "--keys-dir", cp.keysDir)
+	} else {
+		args = append(args, "--allow-untrusted")
+	}
+	args = append(args, "add")
+	args = append(args, cp.include...)
+
+	cmd := exec.CommandContext(ctx, apkPath, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	sylog.Debugf("Running %s %s", apkPath, strings.Join(args, " "))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("while installing packages with apk: %v", err)
+	}
+	return nil
+}
+
+// directInstall resolves the requested packages against the repository
+// APKINDEX, and installs them into the rootfs without the use of apk. The
+// signature of the index is verified, and the checksum of each package is
+// verified against the index. Package post-install scripts are run, chrooted
+// into the rootfs, after all packages have been extracted.
+func (cp *ApkConveyorPacker) directInstall(ctx context.Context) error {
+	repoURL := cp.mirrorurl + "/" + cp.arch
+	body, err := apkFetch(ctx, repoURL+"/APKINDEX.tar.gz")
+	if err != nil {
+		return err
+	}
+	index, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return fmt.Errorf("while downloading APKINDEX: %w", err)
+	}
+
+	if cp.keysDir != "" {
+		if err := verifyAPKIndex(index, cp.keysDir); err != nil {
+			return err
+		}
+	}
+
+	pkgs, err := readAPKIndex(bytes.NewReader(index))
+	if err != nil {
+		return err
+	}
+
+	install, err := newAPKIndex(pkgs).resolve(cp.include)
+	if err != nil {
+		return fmt.Errorf("while resolving packages: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp(cp.b.TmpDir, "apk-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	installed := bytes.Buffer{}
+	scripts := map[string][]byte{}
+	for _, p := range install {
+		sylog.Infof("Installing %s (%s)", p.Name, p.Version)
+		block, script, err := cp.installAPK(ctx, repoURL, tmpDir, p)
+		if err != nil {
+			return fmt.Errorf("while installing %s: %w", p.Name, err)
+		}
+		installed.Write(block)
+		if script != nil {
+			scripts[p.Name] = script
+		}
+	}
+
+	if err := cp.b.Rootfs.MkdirAll(filepath.Dir(apkInstalledDB), 0o755); err != nil {
+		return err
+	}
+	if err := cp.b.Rootfs.WriteFile(apkInstalledDB, installed.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("while writing apk database: %w", err)
+	}
+	if err := cp.b.Rootfs.MkdirAll(filepath.Dir(apkWorldFile), 0o755); err != nil {
+		return err
+	}
+	if err := cp.b.Rootfs.WriteFile(apkWorldFile, []byte(strings.Join(cp.include, "\n")+"\n"), 0o644); err != nil {
+		return fmt.Errorf("while writing apk world: %w", err)
+	}
+	if err := cp.b.Rootfs.WriteFile(apkArchFile, []byte(cp.arch+"\n"), 0o644); err != nil {
+		return fmt.Errorf("while writing apk arch: %w", err)
+	}
+
+	for _, p := range install {
+		if script, ok := scripts[p.Name]; ok {
+			if err := cp.runScript(ctx, p.Name, script); err != nil {
+				sylog.Warningf("post-install script for %s failed: %v", p.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// apkFetch performs an HTTP GET of url, returning the response body.
+func apkFetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("while performing http request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("while fetching %s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// installAPK downloads the package p from repoURL to tmpDir, verifies it, and
+// extracts its data into the rootfs. The entry for the package in the apk
+// installed database, and its post-install script, if any, are returned.
+func (cp *ApkConveyorPacker) installAPK(ctx context.Context, repoURL, tmpDir string, p *apkPackage) (dbEntry, postInstall []byte, err error) {
+	body, err := apkFetch(ctx, repoURL+"/"+p.Filename())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer body.Close()
+
+	f, err := os.CreateTemp(tmpDir, p.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	size, err := io.Copy(f, body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("while downloading package: %w", err)
+	}
+
+	ctl, err := readAPKControl(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	control := io.NewSectionReader(f, ctl.start, ctl.end-ctl.start)
+	data := io.NewSectionReader(f, ctl.end, size-ctl.end)
+	if err := verifyAPK(p, ctl, control, data); err != nil {
+		return nil, nil, err
+	}
+
+	files, err := listAPKData(io.NewSectionReader(f, ctl.end, size-ctl.end))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gzr, err := gzip.NewReader(io.NewSectionReader(f, ctl.end, size-ctl.end))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer gzr.Close()
+	opts := &mobyarchive.TarOptions{NoLchown: os.Geteuid() != 0}
+	if err := archive.UnpackWithRoot(gzr, cp.b.RootfsPath, cp.b.RootfsPath, opts); err != nil {
+		return nil, nil, fmt.Errorf("while extracting package: %w", err)
+	}
+
+	return apkDBEntry(p, files), ctl.scripts[apkPostInstall], nil
+}
+
+// apkControl holds the location and content of the control segment of an
+// .apk file.
+type apkControl struct {
+	// start and end are the offsets of the control segment gzip stream.
+	start, end int64
+	// pkginfo holds the values from .PKGINFO
+	pkginfo map[string]string
+	// scripts holds the install scripts, by name, e.g. .post-install
+	scripts map[string][]byte
+}
+
+// countingByteReader counts the bytes read from a bufio.Reader. As it
+// implements io.ByteReader, a gzip.Reader will not read beyond the end of
+// each gzip stream, allowing the offsets of the streams to be found.
+type countingByteReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countingByteReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingByteReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+// readAPKControl finds and reads the control segment of the .apk in r. An
+// .apk is a concatenation of gzip streams, holding an optional signature,
+// the control segment, and then the data segment.
+func readAPKControl(r io.ReaderAt) (*apkControl, error) {
+	cr := &countingByteReader{r: bufio.NewReader(io.NewSectionReader(r, 0, 1<<62))}
+	gzr, err := gzip.NewReader(cr)
+	if err != nil {
+		return nil, fmt.Errorf("while reading package: %w", err)
+	}
+	defer gzr.Close()
+
+	var start int64
+	for {
+		gzr.Multistream(false)
+		ctl := &apkControl{
+			start:   start,
+			pkginfo: map[string]string{},
+			scripts: map[string][]byte{},
+		}
+		isControl := false
+
+		tr := tar.NewReader(gzr)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("while reading package: %w", err)
+			}
+			switch {
+			case hdr.Name == ".PKGINFO":
+				isControl = true
+				if err := parsePKGINFO(tr, ctl.pkginfo); err != nil {
+					return nil, err
+				}
+			case strings.HasPrefix(hdr.Name, ".pre-") || strings.HasPrefix(hdr.Name, ".post-"):
+				b, err := io.ReadAll(tr)
+				if err != nil {
+					return nil, err
+				}
+				ctl.scripts[hdr.Name] = b
+			}
+		}
+		if _, err := io.Copy(io.Discard, gzr); err != nil {
+			return nil, fmt.Errorf("while reading package: %w", err)
+		}
+
+		if isControl {
+			ctl.end = cr.n
+			return ctl, nil
+		}
+
+		start = cr.n
+		if err := gzr.Reset(cr); err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("package has no .PKGINFO")
+			}
+			return nil, fmt.Errorf("while reading package: %w", err)
+		}
+	}
+}
+
+// parsePKGINFO reads `key = value` lines from a .PKGINFO into info. Where a key
+// is repeated, only the first value is kept.
+func parsePKGINFO(r io.Reader, info map[string]string) error {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := s.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, v, ok := strings.Cut(line, " = ")
+		if !ok {
+			continue
+		}
+		if _, ok := info[k]; !ok {
+			info[k] = v
+		}
+	}
+	return s.Err()
+}
+
+// verifyAPK checks the control segment of a package against the checksum in
+// the APKINDEX, and the data segment against the datahash in the (verified)
+// control segment.
+func verifyAPK(p *apkPackage, ctl *apkControl, control, data io.Reader) error {
+	if !strings.HasPrefix(p.Checksum, "Q1") {
+		return fmt.Errorf("unsupported checksum %q in APKINDEX", p.Checksum)
+	}
+	h := sha1.New() //nolint:gosec
+	if _, err := io.Copy(h, control); err != nil {
+		return err
+	}
+	if got := "Q1" + base64.StdEncoding.EncodeToString(h.Sum(nil)); got != p.Checksum {
+		return fmt.Errorf("control checksum %s does not match APKINDEX checksum %s", got, p.Checksum)
+	}
+
+	dataHash, ok := ctl.pkginfo["datahash"]
+	if !ok {
+		return fmt.Errorf("package has no datahash")
+	}
+	dh := sha256.New()
+	if _, err := io.Copy(dh, data); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(dh.Sum(nil)); got != dataHash {
+		return fmt.Errorf("data checksum %s does not match datahash %s", got, dataHash)
+	}
+
+	return nil
+}
+
+// listAPKData returns the names of all entries in the data segment read from
+// r.
+func listAPKData(r io.Reader) ([]*tar.Header, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gzr.Close()
+
+	hdrs := []*tar.Header{}
+	tr := tar.NewReader(gzr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return hdrs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("while reading package data: %w", err)
+		}
+		hdrs = append(hdrs, hdr)
+	}
+}
+
+// apkDBEntry returns the entry for package p in the apk installed database,
+// which consists of the APKINDEX fields for the package, followed by its
+// directories (F:) and the files they contain (R:).
+func apkDBEntry(p *apkPackage, hdrs []*tar.Header) []byte {
+	b := bytes.Buffer{}
+	for _, f := range p.Fields {
+		b.WriteString(f + "\n")
+	}
+
+	dir := ""
+	for _, hdr := range hdrs {
+		name := strings.TrimSuffix(path.Clean(hdr.Name), "/")
+		if hdr.Typeflag == tar.TypeDir {
+			dir = name
+			fmt.Fprintf(&b, "F:%s\n", dir)
+			continue
+		}
+		if d := path.Dir(name); d != dir && d != "." {
+			dir = d
+			fmt.Fprintf(&b, "F:%s\n", dir)
+		}
+		fmt.Fprintf(&b, "R:%s\n", path.Base(name))
+	}
+	b.WriteString("\n")
+	return b.Bytes()
+}
+
+// runScript runs a package install script, chrooted into the rootfs.
+func (cp *ApkConveyorPacker) runScript(ctx context.Context, name string, script []byte) error {
+	if err := cp.b.Rootfs.MkdirAll("tmp", 0o1777); err != nil {
+		return err
+	}
+	scriptPath := filepath.Join("tmp", apkScriptTmpName)
+	if err := cp.b.Rootfs.WriteFile(scriptPath, script, 0o755); err != nil {
+		return err
+	}
+	defer cp.b.Rootfs.Remove(scriptPath)
+
+	sylog.Debugf("Running post-install script for %s", name)
+	cmd := exec.CommandContext(ctx, "/bin/sh", "/"+scriptPath)
+	cmd.Dir = "/"
+	cmd.Env = []string{"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Chroot: cp.b.RootfsPath}
+	return cmd.Run()
+}
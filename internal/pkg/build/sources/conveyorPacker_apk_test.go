@@ -0,0 +1,152 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package sources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sylabs/singularity/v4/internal/pkg/test"
+	"github.com/sylabs/singularity/v4/internal/pkg/test/tool/require"
+	"github.com/sylabs/singularity/v4/pkg/build/types"
+	"github.com/sylabs/singularity/v4/pkg/build/types/parser"
+)
+
+const apkDef = "../../../../examples/alpine/Singularity"
+
+func TestApkConveyorPacker(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	require.ArchIn(t, []string{"amd64", "arm64"})
+
+	// Post-install scripts are run in a chroot.
+	test.EnsurePrivilege(t)
+
+	defFile, err := os.Open(apkDef)
+	if err != nil {
+		t.Fatalf("unable to open file %s: %v\n", apkDef, err)
+	}
+	defer defFile.Close()
+
+	tmpDir := t.TempDir()
+	b, err := types.NewBundle(filepath.Join(tmpDir, "sbuild-apk"), tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create bundle: %v", err)
+	}
+
+	b.Recipe, err = parser.ParseDefinitionFile(defFile)
+	if err != nil {
+		t.Fatalf("failed to parse definition file %s: %v\n", apkDef, err)
+	}
+
+	// Repository signatures can only be verified if the host has Alpine keys.
+	if _, err := os.Stat(apkHostKeysDir); err != nil {
+		b.Recipe.Header["allowuntrusted"] = "true"
+	}
+
+	cp := &ApkConveyorPacker{}
+
+	err = cp.Get(t.Context(), b)
+	// clean up tmpfs since assembler isn't called
+	defer cp.CleanUp()
+	if err != nil {
+		t.Fatalf("failed to Get from %s: %v\n", apkDef, err)
+	}
+
+	for _, f := range []string{apkInstalledDB, "bin/busybox", "bin/bash"} {
+		if _, err := os.Lstat(filepath.Join(b.RootfsPath, f)); err != nil {
+			t.Errorf("expected %s in rootfs: %v", f, err)
+		}
+	}
+
+	_, err = cp.Pack(t.Context())
+	if err != nil {
+		t.Fatalf("failed to Pack from %s: %v\n", apkDef, err)
+	}
+}
+
+func TestApkGetKeysDir(t *testing.T) {
+	keysDir := t.TempDir()
+	keyFile := filepath.Join(keysDir, "key.rsa.pub")
+	if err := os.WriteFile(keyFile, []byte{}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a keysdir header, the result depends on the host keys.
+	_, err := os.Stat(apkHostKeysDir)
+	hostKeys := err == nil
+
+	tests := []struct {
+		name               string
+		header             map[string]string
+		wantKeysDir        string
+		wantAllowUntrusted bool
+		wantErr            bool
+	}{
+		{
+			name:        "KeysDir",
+			header:      map[string]string{"keysdir": keysDir},
+			wantKeysDir: keysDir,
+		},
+		{
+			name:    "KeysDirNotDir",
+			header:  map[string]string{"keysdir": keyFile},
+			wantErr: true,
+		},
+		{
+			name:    "KeysDirMissing",
+			header:  map[string]string{"keysdir": filepath.Join(keysDir, "missing")},
+			wantErr: true,
+		},
+		{
+			name:    "AllowUntrustedInvalid",
+			header:  map[string]string{"keysdir": keysDir, "allowuntrusted": "maybe"},
+			wantErr: true,
+		},
+		{
+			name:               "AllowUntrustedWithKeys",
+			header:             map[string]string{"keysdir": keysDir, "allowuntrusted": "true"},
+			wantKeysDir:        keysDir,
+			wantAllowUntrusted: true,
+		},
+		{
+			name:    "NoKeysDir",
+			header:  map[string]string{},
+			wantErr: !hostKeys,
+		},
+		{
+			name:               "NoKeysDirAllowUntrusted",
+			header:             map[string]string{"allowuntrusted": "true"},
+			wantAllowUntrusted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := &ApkConveyorPacker{
+				b: &types.Bundle{Recipe: types.Definition{Header: tt.header}},
+			}
+			err := cp.getKeysDir()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getKeysDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			wantKeysDir := tt.wantKeysDir
+			if wantKeysDir == "" && hostKeys {
+				wantKeysDir = apkHostKeysDir
+			}
+			if cp.keysDir != wantKeysDir {
+				t.Errorf("keysDir = %q, want %q", cp.keysDir, wantKeysDir)
+			}
+			if cp.allowUntrusted != tt.wantAllowUntrusted {
+				t.Errorf("allowUntrusted = %v, want %v", cp.allowUntrusted, tt.wantAllowUntrusted)
+			}
+		})
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cache
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+)
+
+const (
+	bundleLayoutFile = "oci-layout"
+	bundleIndexFile  = "index.json"
+	bundleBlobsDir   = "blobs"
+	// bundleFilesDir may hold file cache entries, which cannot be verified
+	// against a digest, and are not imported.
+	bundleFilesDir = "files"
+)
+
+// cacheBundle accumulates the content of a bundle written by Export.
+type cacheBundle struct {
+	h     *Handle
+	index v1.IndexManifest
+	// blobs lists the OCI blobs in the bundle, mapped to their paths in the cache.
+	blobs     []v1.Hash
+	blobPaths map[v1.Hash]string
+}
+
+// Export writes a bundle, holding the cached OCI blobs for the image references
+// refs, to w. The references must have been recorded with
+// PutOciCacheReference. The bundle is a tar archive of an OCI image layout,
+// whose index records the references. File cache entries, such as SIF and
+// OCI-SIF conversions, are not exported. They cannot be verified on import,
+// and are re-created from the OCI blobs when needed.
+func (h *Handle) Export(w io.Writer, refs []string) error {
+	if h.disabled {
+		return errCacheDisabled
+	}
+
+	b := &cacheBundle{
+		h: h,
+		index: v1.IndexManifest{
+			SchemaVersion: 2,
+			MediaType:     types.OCIImageIndex,
+		},
+		blobPaths: map[v1.Hash]string{},
+	}
+	for _, ref := range refs {
+		desc, err := h.GetOciCacheReference(OciBlobCacheType, ref)
+		if err != nil {
+			return err
+		}
+		sylog.Debugf("Exporting %s (%s)", ref, desc.Digest)
+		if err := b.addManifest(desc.Digest); err != nil {
+			return fmt.Errorf("while exporting %s: %w", ref, err)
+		}
+		b.index.Manifests = append(b.index.Manifests, desc)
+	}
+	return b.write(w)
+}
+
+// addManifest adds the image manifest or image index with digest d, and the
+// content it references, to the bundle. For an image index, only the images
+// that are present in the cache are added.
+func (b *cacheBundle) addManifest(d v1.Hash) error {
+	if _, ok := b.blobPaths[d]; ok {
+		return nil
+	}
+	raw, err := b.h.readOciCacheBlob(OciBlobCacheType, d)
+	if err != nil {
+		return err
+	}
+
+	// An image manifest must have a config, which an image index does not.
+	if mf, err := v1.ParseManifest(bytes.NewReader(raw)); err == nil && mf.Config.Digest.Hex != "" {
+		if err := b.addBlob(d); err != nil {
+			return err
+		}
+		if err := b.addBlob(mf.Config.Digest); err != nil {
+			return err
+		}
+		for _, l := range mf.Layers {
+			if err := b.addBlob(l.Digest); err != nil {
+				return fmt.Errorf("image %s is not fully cached: %w", d, err)
+			}
+		}
+		mediaType := mf.MediaType
+		if mediaType == "" {
+			mediaType = types.OCIManifestSchema1
+		}
+		b.index.Manifests = append(b.index.Manifests, v1.Descriptor{
+			MediaType: mediaType,
+			Size:      int64(len(raw)),
+			Digest:    d,
+		})
+		return nil
+	}
+
+	ix, err := v1.ParseIndexManifest(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("while parsing %s: %w", d, err)
+	}
+	if err := b.addBlob(d); err != nil {
+		return err
+	}
+	cached := 0
+	for _, m := range ix.Manifests {
+		if _, err := b.h.ociCacheBlobPath(OciBlobCacheType, m.Digest); err != nil {
+			sylog.Debugf("Skipping %s (%v), not cached", m.Digest, m.Platform)
+			continue
+		}
+		if err := b.addManifest(m.Digest); err != nil {
+			return err
+		}
+		cached++
+	}
+	if cached == 0 {
+		return fmt.Errorf("no image from index %s is cached", d)
+	}
+	return nil
+}
+
+// addBlob adds the cached OCI blob with digest d to the bundle.
+func (b *cacheBundle) addBlob(d v1.Hash) error {
+	if _, ok := b.blobPaths[d]; ok {
+		return nil
+	}
+	p, err := b.h.ociCacheBlobPath(OciBlobCacheType, d)
+	if err != nil {
+		return err
+	}
+	b.blobs = append(b.blobs, d)
+	b.blobPaths[d] = p
+	return nil
+}
+
+// write writes the bundle to w, as a tar archive.
+func (b *cacheBundle) write(w io.Writer) error {
+	tw := tar.NewWriter(w)
+
+	ociLayout, err := json.Marshal(imagespec.ImageLayout{Version: imagespec.ImageLayoutVersion})
+	if err != nil {
+		return err
+	}
+	if err := writeTarBytes(tw, bundleLayoutFile, ociLayout); err != nil {
+		return err
+	}
+	index, err := json.MarshalIndent(b.index, "", "   ")
+	if err != nil {
+		return err
+	}
+	if err := writeTarBytes(tw, bundleIndexFile, index); err != nil {
+		return err
+	}
+
+	for _, d := range b.blobs {
+		name := path.Join(bundleBlobsDir, d.Algorithm, d.Hex)
+		if err := writeTarFile(tw, name, b.blobPaths[d]); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func writeTarBytes(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{
+		Name: name,
+		Mode: 0o644,
+		Size: int64(len(data)),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+func writeTarFile(tw *tar.Writer, name, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    fi.Size(),
+		ModTime: fi.ModTime(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// Import adds the OCI blobs and image references held in a bundle written by
+// Export, read from r, to the cache. OCI blobs are verified against their
+// digests. File cache entries, which cannot be verified, are not imported.
+// Existing cache content is not replaced, but the references in the bundle
+// replace any existing records. The imported references are returned.
+func (h *Handle) Import(r io.Reader) ([]string, error) {
+	if h.disabled {
+		return nil, errCacheDisabled
+	}
+	layoutDir, err := h.GetOciCacheDir(OciBlobCacheType)
+	if err != nil {
+		return nil, err
+	}
+
+	var index *v1.IndexManifest
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("while reading bundle: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("unexpected bundle entry %s, of type %c", hdr.Name, hdr.Typeflag)
+		}
+
+		name := path.Clean(hdr.Name)
+		dir, base := path.Split(name)
+		switch {
+		case name == bundleLayoutFile:
+			continue
+		case name == bundleIndexFile:
+			index, err = v1.ParseIndexManifest(tr)
+			if err != nil {
+				return nil, fmt.Errorf("while reading bundle index: %w", err)
+			}
+		case strings.HasPrefix(dir, bundleBlobsDir+"/"):
+			d, err := v1.NewHash(path.Base(dir) + ":" + base)
+			if err != nil {
+				return nil, fmt.Errorf("invalid blob %s in bundle: %w", name, err)
+			}
+			if err := putVerifiedBlob(layoutDir, d, tr); err != nil {
+				return nil, fmt.Errorf("while importing blob %s: %w", d, err)
+			}
+		case strings.HasPrefix(dir, bundleFilesDir+"/"):
+			sylog.Warningf("Not importing cache entry %s, which cannot be verified against a digest", name)
+		default:
+			sylog.Warningf("Ignoring unexpected bundle entry %s", hdr.Name)
+		}
+	}
+	if index == nil {
+		return nil, fmt.Errorf("bundle has no %s", bundleIndexFile)
+	}
+
+	for _, d := range index.Manifests {
+		if _, err := h.ociCacheBlobPath(OciBlobCacheType, d.Digest); err != nil {
+			return nil, fmt.Errorf("bundle does not hold %s: %w", d.Digest, err)
+		}
+	}
+
+	refs := []string{}
+	err = updateOciCacheIndex(layoutDir, func(im *v1.IndexManifest) error {
+		inLayout := map[v1.Hash]bool{}
+		for _, d := range im.Manifests {
+			if _, ok := d.Annotations[imagespec.AnnotationRefName]; !ok {
+				inLayout[d.Digest] = true
+			}
+		}
+		for _, d := range index.Manifests {
+			if ref, ok := d.Annotations[imagespec.AnnotationRefName]; ok {
+				sylog.Debugf("Importing reference %s (%s)", ref, d.Digest)
+				putReference(im, ref, d)
+				refs = append(refs, ref)
+				continue
+			}
+			// Images are listed in the layout index, so that they can be
+			// retrieved by digest.
+			if !inLayout[d.Digest] {
+				im.Manifests = append(im.Manifests, d)
+				inLayout[d.Digest] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// putVerifiedBlob writes a blob, with content read from r, to the OCI layout at
+// layoutDir, if it is not already present. The blob is only placed in the
+// layout if its content matches the digest d.
+func putVerifiedBlob(layoutDir string, d v1.Hash, r io.Reader) error {
+	if d.Algorithm != "sha256" {
+		return fmt.Errorf("unsupported digest algorithm %s", d.Algorithm)
+	}
+	blobDir := filepath.Join(layoutDir, "blobs", d.Algorithm)
+	dest := filepath.Join(blobDir, d.Hex)
+	if fs.IsFile(dest) {
+		return nil
+	}
+	if err := os.MkdirAll(blobDir, 0o755); err != nil {
+		return err
+	}
+
+	f, err := fs.MakeTmpFile(blobDir, "tmp_", 0o644)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != d.Hex {
+		return fmt.Errorf("digest mismatch, content has digest sha256:%s", got)
+	}
+	return os.Rename(f.Name(), dest)
+}
+
+// readOciCacheBlob returns the content of a blob from an OCI layout cache.
+func (h *Handle) readOciCacheBlob(cacheType string, d v1.Hash) ([]byte, error) {
+	rc, err := h.GetOciCacheBlob(cacheType, d)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// ociCacheBlobPath returns the path of a blob in an OCI layout cache. The
+// shared cache is consulted before the user's cache.
+func (h *Handle) ociCacheBlobPath(cacheType string, d v1.Hash) (string, error) {
+	layoutDir, err := h.GetOciCacheDir(cacheType)
+	if err != nil {
+		return "", err
+	}
+	paths := []string{filepath.Join(layoutDir, "blobs", d.Algorithm, d.Hex)}
+	if h.sharedDir != "" {
+		paths = append([]string{filepath.Join(h.sharedDir, cacheType, "blobs", d.Algorithm, d.Hex)}, paths...)
+	}
+	for _, p := range paths {
+		if fs.IsFile(p) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("blob %s: %w", d, os.ErrNotExist)
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cache
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// putTestBlob places content in the blob cache of h, returning its descriptor.
+func putTestBlob(t *testing.T, h *Handle, content []byte) v1.Descriptor {
+	t.Helper()
+	d, size, err := v1.SHA256(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.PutOciCacheBlob(OciBlobCacheType, d, io.NopCloser(bytes.NewReader(content))); err != nil {
+		t.Fatal(err)
+	}
+	return v1.Descriptor{Digest: d, Size: size}
+}
+
+// addTarEntry returns a copy of the tar archive tarball, with a file entry
+// holding content added.
+func addTarEntry(t *testing.T, tarball []byte, name string, content []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tr := tar.NewReader(bytes.NewReader(tarball))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writeTarBytes(tw, name, content); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestHandle_ExportImport(t *testing.T) {
+	const ref = "index.docker.io/library/alpine:latest"
+
+	src, err := New(Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := putTestBlob(t, src, []byte(`{"architecture":"amd64","os":"linux"}`))
+	config.MediaType = types.OCIConfigJSON
+	layer := putTestBlob(t, src, []byte("LAYER CONTENT"))
+	layer.MediaType = types.OCILayer
+	raw, err := json.Marshal(v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		Config:        config,
+		Layers:        []v1.Descriptor{layer},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := putTestBlob(t, src, raw)
+	manifest.MediaType = types.OCIManifestSchema1
+	if err := src.PutOciCacheReference(OciBlobCacheType, ref, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	entryName := manifest.Digest.String() + ".ocisif"
+	e, err := src.GetEntry(OciSifCacheType, entryName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(e.TmpPath, []byte("OCI-SIF CONTENT"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	var bundle bytes.Buffer
+	if err := src.Export(&bundle, []string{ref}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if err := src.Export(io.Discard, []string{"index.docker.io/library/busybox:latest"}); err == nil {
+		t.Errorf("Export() of unknown reference succeeded")
+	}
+
+	dst, err := New(Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs, err := dst.Import(bytes.NewReader(bundle.Bytes()))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(refs) != 1 || refs[0] != ref {
+		t.Errorf("Import() refs = %v, want [%s]", refs, ref)
+	}
+
+	desc, err := dst.GetOciCacheReference(OciBlobCacheType, ref)
+	if err != nil {
+		t.Fatalf("GetOciCacheReference() error = %v", err)
+	}
+	if desc.Digest != manifest.Digest {
+		t.Errorf("reference resolved to %s, want %s", desc.Digest, manifest.Digest)
+	}
+	for _, d := range []v1.Descriptor{manifest, config, layer} {
+		rc, err := dst.GetOciCacheBlob(OciBlobCacheType, d.Digest)
+		if err != nil {
+			t.Errorf("blob %s was not imported: %v", d.Digest, err)
+			continue
+		}
+		rc.Close()
+	}
+
+	// File cache entries cannot be verified, so are neither exported, nor
+	// imported from a bundle that holds them.
+	withEntry := addTarEntry(t, bundle.Bytes(), "files/"+OciSifCacheType+"/"+entryName, []byte("OCI-SIF CONTENT"))
+	for _, b := range [][]byte{bundle.Bytes(), withEntry} {
+		dst, err := New(Config{ParentDir: t.TempDir()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := dst.Import(bytes.NewReader(b)); err != nil {
+			t.Fatalf("Import() error = %v", err)
+		}
+		e, err := dst.GetEntry(OciSifCacheType, entryName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Exists {
+			t.Errorf("cache entry %s was imported", entryName)
+		}
+		e.CleanTmp()
+	}
+
+	// A bundle holding a corrupt blob must be rejected.
+	corrupt := bytes.Replace(bundle.Bytes(), []byte("LAYER CONTENT"), []byte("LAYER CONTENX"), 1)
+	other, err := New(Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Import(bytes.NewReader(corrupt)); err == nil {
+		t.Errorf("Import() of corrupt bundle succeeded")
+	}
+	layoutDir, err := other.GetOciCacheDir(OciBlobCacheType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(layoutDir, "blobs", layer.Digest.Algorithm, layer.Digest.Hex)); !os.IsNotExist(err) {
+		t.Errorf("corrupt blob was placed in the cache")
+	}
+}
@@ -54,6 +54,8 @@ const (
 	NetCacheType = "net"
 	// OciSifCachetType specifies cache holds OCI-SIF conversions of OCI sources.
 	OciSifCacheType = "oci-sif"
+	// DataCacheType specifies the cache holds OCI-SIF data containers pulled from OCI registries.
+	DataCacheType = "data"
 
 	// OciBlobCacheType specifies the cache holds OCI blobs (layers) pulled from OCI sources
 	OciBlobCacheType = "blob"
@@ -68,6 +70,7 @@ var (
 		OrasCacheType,
 		NetCacheType,
 		OciSifCacheType,
+		DataCacheType,
 	}
 	// OciCacheTypes lists the OCI layout cache types, that store OCI blob content in a single OCI layout directory.
 	OciCacheTypes = []string{
@@ -84,6 +87,15 @@ type Config struct {
 	ParentDir string
 	// Disable specifies whether the user request the cache to be disabled by default.
 	Disable bool
+	// MaxSize is the maximum total size of the cache, in bytes, enforced by
+	// Prune. Zero specifies no limit.
+	MaxSize int64
+	// TypeMaxSize holds the maximum size of individual cache types, in
+	// bytes, enforced by Prune.
+	TypeMaxSize map[string]int64
+	// SharedDir is the root of a read-only, system-wide cache, which is
+	// consulted before the user's cache.
+	SharedDir string
 }
 
 // Handle is an structure representing the image cache, it's location and subdirectories
@@ -98,6 +110,21 @@ type Handle struct {
 	rootDir string
 	// If the cache is disabled
 	disabled bool
+	// maxSize is the maximum total size of the cache, 0 for no limit
+	maxSize int64
+	// typeMaxSize holds the maximum sizes of individual cache types
+	typeMaxSize map[string]int64
+	// sharedDir is the root of a read-only shared cache, consulted before
+	// rootDir
+	sharedDir string
+	// shared is true if this handle is writing to a shared cache, opened
+	// with OpenShared
+	shared bool
+	// lockFd is the lock held on a shared cache opened with OpenShared
+	lockFd int
+	// sharedBefore holds the content of a shared cache opened with
+	// OpenShared, as it was before being populated
+	sharedBefore map[string]os.FileInfo
 }
 
 func (h *Handle) GetFileCacheDir(cacheType string) (cacheDir string, err error) {
@@ -126,11 +153,19 @@ func (h *Handle) GetOciCacheBlob(cacheType string, blobDigest v1.Hash) (io.ReadC
 	if err != nil {
 		return nil, err
 	}
+	if rc, ok := h.sharedOciCacheBlob(cacheType, hash); ok {
+		return rc, nil
+	}
 	layout, err := layout.FromPath(layoutDir)
 	if err != nil {
 		return nil, err
 	}
-	return layout.Blob(hash)
+	rc, err := layout.Blob(hash)
+	if err != nil {
+		return nil, err
+	}
+	touch(filepath.Join(layoutDir, "blobs", hash.Algorithm, hash.Hex))
+	return rc, nil
 }
 
 func (h *Handle) PutOciCacheBlob(cacheType string, blobDigest v1.Hash, r io.ReadCloser) (err error) {
@@ -158,13 +193,20 @@ func (h *Handle) GetEntry(cacheType string, hash string) (e *Entry, err error) {
 		return nil, nil
 	}
 
-	e = &Entry{}
-
 	cacheDir, err := h.GetFileCacheDir(cacheType)
 	if err != nil {
 		return nil, fmt.Errorf("cannot get '%s' cache directory: %v", cacheType, err)
 	}
 
+	if e, ok := h.sharedEntry(cacheType, hash); ok {
+		return e, nil
+	}
+
+	e = &Entry{}
+	if h.shared {
+		e.mode = sharedFileMode
+	}
+
 	e.Path = filepath.Join(cacheDir, hash)
 
 	// If there is a directory it's from an older version of Singularity
@@ -206,6 +248,7 @@ func (h *Handle) GetEntry(cacheType string, hash string) (e *Entry, err error) {
 
 	// It exists in the cache and it's a file. Caller can use the Path directly
 	e.Exists = true
+	touch(e.Path)
 	return e, nil
 }
 
@@ -283,6 +326,16 @@ func New(cfg Config) (h *Handle, err error) {
 	if cacheDisabled || cfg.Disable {
 		h.disabled = true
 	}
+	h.maxSize = cfg.MaxSize
+	if envMaxSize := os.Getenv(MaxSizeEnv); envMaxSize != "" {
+		h.maxSize, err = ParseSize(envMaxSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse environment variable %s: %s", MaxSizeEnv, err)
+		}
+	}
+	h.typeMaxSize = cfg.TypeMaxSize
+	h.sharedDir = cfg.SharedDir
+
 	// If the cache is disabled, we stop here. Basically we return a valid handle that is not fully initialized
 	// since it would create the directories required by an enabled cache.
 	if h.disabled {
@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2020, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -27,10 +27,19 @@ type Entry struct {
 	// tmpPath is the temporary location that should be used for a new cache entry as it
 	// is created
 	TmpPath string
+	// mode, if set, is applied to the entry when it is finalized
+	mode os.FileMode
 }
 
 // Finalize an entry by renaming it to its permanent path atomically
 func (e *Entry) Finalize() error {
+	// Entries in a shared cache must be readable by all users before they
+	// are visible at their permanent path.
+	if e.mode != 0 {
+		if err := os.Chmod(e.TmpPath, e.mode); err != nil {
+			return fmt.Errorf("could not set permissions on cached file: %v", err)
+		}
+	}
 	// Try to rename the temporary file to its permanent path
 	// This is a file, so we won't have an IsExist error since...
 	//   If newpath already exists and is not a directory, Rename replaces it.
@@ -0,0 +1,388 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/docker/go-units"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+)
+
+// MaxSizeEnv specifies the maximum total size of the cache, overriding any
+// value set in singularity.conf.
+const MaxSizeEnv = "SINGULARITY_CACHE_MAX_SIZE"
+
+// ParseSize parses a human readable size, e.g. 20G, into bytes. Suffixes are
+// interpreted as binary multiples, so that 1K is 1024 bytes.
+func ParseSize(size string) (int64, error) {
+	n, err := units.RAMInBytes(size)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("invalid size: %s", size)
+	}
+	return n, nil
+}
+
+// ParseTypeMaxSize parses a list of per-type maximum cache sizes, in the form
+// `<type>=<size>`, e.g. `blob=10G`.
+func ParseTypeMaxSize(entries []string) (map[string]int64, error) {
+	sizes := map[string]int64{}
+	for _, e := range entries {
+		cacheType, size, ok := strings.Cut(e, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid cache type max size %q, must be <type>=<size>", e)
+		}
+		cacheType = strings.TrimSpace(cacheType)
+		if !stringInSlice(cacheType, AllCacheTypes) {
+			return nil, fmt.Errorf("%w: %s", errInvalidCacheType, cacheType)
+		}
+		n, err := ParseSize(strings.TrimSpace(size))
+		if err != nil {
+			return nil, fmt.Errorf("invalid max size for %s cache: %w", cacheType, err)
+		}
+		sizes[cacheType] = n
+	}
+	return sizes, nil
+}
+
+// cacheFile is a file in the cache.
+type cacheFile struct {
+	size       int64
+	lastAccess time.Time
+}
+
+// cacheEntry is a unit of the cache, which is removed as a whole when the cache
+// is pruned. In a file cache, an entry is a single file. In an OCI layout cache,
+// an entry is an image recorded in the index of the layout, with the blobs that
+// make it up, or a single blob that is not part of any recorded image.
+type cacheEntry struct {
+	cacheType string
+	name      string
+	// paths lists the files that make up the entry.
+	paths      []string
+	lastAccess time.Time
+	// layoutDir and desc identify the record of an image in the index of an
+	// OCI layout cache.
+	layoutDir string
+	desc      *v1.Descriptor
+}
+
+// touch records an access of the cache entry at path, by setting its access
+// time. The modification time, which is used by `cache clean --days`, is not
+// changed. The access time is set explicitly, as the cache may be on a
+// filesystem mounted with noatime or relatime.
+func touch(path string) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		sylog.Debugf("Could not record access of cache entry %s: %v", path, err)
+		return
+	}
+	if err := os.Chtimes(path, time.Now(), fi.ModTime()); err != nil {
+		sylog.Debugf("Could not record access of cache entry %s: %v", path, err)
+	}
+}
+
+// lastAccess returns the access time from fi, falling back to the
+// modification time if it is not available.
+func lastAccess(fi os.FileInfo) time.Time {
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(st.Atim.Unix())
+	}
+	return fi.ModTime()
+}
+
+// entryDir returns the directory holding the entries for cacheType. For OCI
+// layout caches, this is the blobs directory of the layout.
+func (h *Handle) entryDir(cacheType string) string {
+	dir := h.getCacheTypeDir(cacheType)
+	if stringInSlice(cacheType, OciCacheTypes) {
+		dir = filepath.Join(dir, "blobs", "sha256")
+	}
+	return dir
+}
+
+// files returns all of the files in the cache of type cacheType, keyed by
+// path. Temporary files, for entries that are in the process of being created,
+// are not included.
+func (h *Handle) files(cacheType string) (map[string]cacheFile, error) {
+	dir := h.entryDir(cacheType)
+	des, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("while reading %s cache: %w", cacheType, err)
+	}
+
+	files := make(map[string]cacheFile, len(des))
+	for _, de := range des {
+		if strings.HasPrefix(de.Name(), "tmp_") {
+			continue
+		}
+		fi, err := de.Info()
+		if err != nil {
+			// Allow IsNotExist in case a concurrent process removed the entry
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("could not get info for cache entry '%s': %w", de.Name(), err)
+		}
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+		files[filepath.Join(dir, de.Name())] = cacheFile{
+			size:       fi.Size(),
+			lastAccess: lastAccess(fi),
+		}
+	}
+	return files, nil
+}
+
+// entries returns all of the entries in the cache of type cacheType, made up
+// of files. An image recorded in the index of an OCI layout cache was last
+// accessed when any of its blobs was last accessed.
+func (h *Handle) entries(cacheType string, files map[string]cacheFile) ([]cacheEntry, error) {
+	var entries []cacheEntry
+	inImage := map[string]bool{}
+
+	if stringInSlice(cacheType, OciCacheTypes) {
+		layoutDir := h.getCacheTypeDir(cacheType)
+		im, err := layoutIndex(layoutDir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("while reading %s cache index: %w", cacheType, err)
+		}
+		if im != nil {
+			for _, d := range im.Manifests {
+				e := cacheEntry{
+					cacheType: cacheType,
+					name:      d.Annotations[imagespec.AnnotationRefName],
+					layoutDir: layoutDir,
+					desc:      &d,
+				}
+				if e.name == "" {
+					e.name = d.Digest.String()
+				}
+				for _, p := range layoutImageBlobs(layoutDir, d.Digest) {
+					f, ok := files[p]
+					if !ok {
+						continue
+					}
+					e.paths = append(e.paths, p)
+					if f.lastAccess.After(e.lastAccess) {
+						e.lastAccess = f.lastAccess
+					}
+					inImage[p] = true
+				}
+				entries = append(entries, e)
+			}
+		}
+	}
+
+	for p, f := range files {
+		if inImage[p] {
+			continue
+		}
+		entries = append(entries, cacheEntry{
+			cacheType:  cacheType,
+			name:       filepath.Base(p),
+			paths:      []string{p},
+			lastAccess: f.lastAccess,
+		})
+	}
+	return entries, nil
+}
+
+// layoutImageBlobs returns the paths of the blobs, in the OCI layout at
+// layoutDir, that make up the image manifest or image index with digest d,
+// including the manifest or index itself. Blobs that cannot be read are
+// omitted, as are the blobs they reference.
+func layoutImageBlobs(layoutDir string, d v1.Hash) []string {
+	p := filepath.Join(layoutDir, "blobs", d.Algorithm, d.Hex)
+	raw, err := os.ReadFile(p)
+	if err != nil {
+		sylog.Debugf("Could not read cached manifest %s: %v", d, err)
+		return nil
+	}
+	paths := []string{p}
+
+	// An image manifest must have a config, which an image index does not.
+	if mf, err := v1.ParseManifest(bytes.NewReader(raw)); err == nil && mf.Config.Digest.Hex != "" {
+		paths = append(paths, filepath.Join(layoutDir, "blobs", mf.Config.Digest.Algorithm, mf.Config.Digest.Hex))
+		for _, l := range mf.Layers {
+			paths = append(paths, filepath.Join(layoutDir, "blobs", l.Digest.Algorithm, l.Digest.Hex))
+		}
+		return paths
+	}
+
+	ix, err := v1.ParseIndexManifest(bytes.NewReader(raw))
+	if err != nil {
+		sylog.Debugf("Could not parse cached manifest %s: %v", d, err)
+		return paths
+	}
+	for _, m := range ix.Manifests {
+		paths = append(paths, layoutImageBlobs(layoutDir, m.Digest)...)
+	}
+	return paths
+}
+
+// remove removes the files at paths, which belong to the entry and no other
+// entry, from the cache. The record of an image is removed from the index of
+// its OCI layout first, so that the index never refers to a removed blob. Any
+// blob that an image recorded in the index by a concurrent process still uses
+// is not removed.
+func (e cacheEntry) remove(paths []string) error {
+	inUse := map[string]bool{}
+	if e.desc != nil {
+		err := updateOciCacheIndex(e.layoutDir, func(im *v1.IndexManifest) error {
+			im.Manifests = slices.DeleteFunc(im.Manifests, func(d v1.Descriptor) bool {
+				return d.Digest == e.desc.Digest &&
+					d.Annotations[imagespec.AnnotationRefName] == e.desc.Annotations[imagespec.AnnotationRefName]
+			})
+			for _, d := range im.Manifests {
+				for _, p := range layoutImageBlobs(e.layoutDir, d.Digest) {
+					inUse[p] = true
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("while removing %s from cache index: %w", e.name, err)
+		}
+	}
+
+	for _, p := range paths {
+		if inUse[p] {
+			continue
+		}
+		// Allow IsNotExist in case a concurrent process already removed it
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// CleanCacheToSize removes the least recently used entries from the caches of
+// the types listed in cacheTypes, until their total size is no more than
+// maxSize bytes. An image in an OCI layout cache is removed as a whole, with
+// the blobs that no other image in the cache uses. If dryRun is true, the
+// entries that would be removed are reported, but not removed.
+func (h *Handle) CleanCacheToSize(cacheTypes []string, dryRun bool, maxSize int64) error {
+	if h.disabled {
+		return errCacheDisabled
+	}
+
+	files := map[string]cacheFile{}
+	all := []cacheEntry{}
+	for _, ct := range cacheTypes {
+		if !stringInSlice(ct, AllCacheTypes) {
+			return fmt.Errorf("%w: %s", errInvalidCacheType, ct)
+		}
+		ctFiles, err := h.files(ct)
+		if err != nil {
+			return err
+		}
+		entries, err := h.entries(ct, ctFiles)
+		if err != nil {
+			return err
+		}
+		maps.Copy(files, ctFiles)
+		all = append(all, entries...)
+	}
+
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	if total <= maxSize {
+		sylog.Debugf("Cache size %s is within limit %s for %v", fs.FindSize(total), fs.FindSize(maxSize), cacheTypes)
+		return nil
+	}
+
+	// users counts the entries that each file is part of. A blob shared by
+	// images is only removed with the last of them.
+	users := map[string]int{}
+	for _, e := range all {
+		for _, p := range e.paths {
+			users[p]++
+		}
+	}
+
+	slices.SortStableFunc(all, func(a, b cacheEntry) int {
+		if c := a.lastAccess.Compare(b.lastAccess); c != 0 {
+			return c
+		}
+		return strings.Compare(a.name, b.name)
+	})
+
+	errCount := 0
+	for _, e := range all {
+		if total <= maxSize {
+			break
+		}
+		var paths []string
+		var size int64
+		for _, p := range e.paths {
+			users[p]--
+			if users[p] == 0 {
+				paths = append(paths, p)
+				size += files[p].size
+			}
+		}
+		sylog.Infof("Removing %s cache entry: %s (%s)", e.cacheType, e.name, fs.FindSize(size))
+		if !dryRun {
+			if err := e.remove(paths); err != nil {
+				sylog.Errorf("Could not remove cache entry '%s': %v", e.name, err)
+				errCount++
+				continue
+			}
+		}
+		total -= size
+	}
+
+	if errCount > 0 {
+		return fmt.Errorf("failed to remove %d cache entries", errCount)
+	}
+	return nil
+}
+
+// Prune applies the maximum cache sizes configured for the handle, removing
+// the least recently used entries from any cache type that exceeds its own
+// limit, and then from all cache types if the total limit is exceeded.
+func (h *Handle) Prune() error {
+	if h.disabled {
+		return nil
+	}
+
+	for _, ct := range AllCacheTypes {
+		maxSize, ok := h.typeMaxSize[ct]
+		if !ok {
+			continue
+		}
+		if err := h.CleanCacheToSize([]string{ct}, false, maxSize); err != nil {
+			return err
+		}
+	}
+
+	if h.maxSize > 0 {
+		return h.CleanCacheToSize(AllCacheTypes, false, h.maxSize)
+	}
+	return nil
+}
@@ -0,0 +1,343 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// writeEntry creates a cache entry of size bytes, last accessed age ago.
+func writeEntry(t *testing.T, h *Handle, cacheType, name string, size int, age time.Duration) {
+	t.Helper()
+	if err := os.MkdirAll(h.entryDir(cacheType), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(h.entryDir(cacheType), name)
+	if err := os.WriteFile(path, make([]byte, size), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Now().Add(-age)
+	if err := os.Chtimes(path, ts, ts); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// remainingEntries returns the names of all entries in the cache.
+func remainingEntries(t *testing.T, h *Handle) []string {
+	t.Helper()
+	names := []string{}
+	for _, ct := range AllCacheTypes {
+		des, err := os.ReadDir(h.entryDir(ct))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, de := range des {
+			if de.Type().IsRegular() {
+				names = append(names, de.Name())
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestHandle_CleanCacheToSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		cacheTypes []string
+		dryRun     bool
+		maxSize    int64
+		want       []string
+	}{
+		{
+			name:       "WithinLimit",
+			cacheTypes: AllCacheTypes,
+			maxSize:    400,
+			want:       []string{"blob1", "blob2", "lib1", "lib2", "tmp_1"},
+		},
+		{
+			name:       "DryRun",
+			cacheTypes: AllCacheTypes,
+			dryRun:     true,
+			maxSize:    0,
+			want:       []string{"blob1", "blob2", "lib1", "lib2", "tmp_1"},
+		},
+		{
+			name:       "LeastRecentlyUsed",
+			cacheTypes: AllCacheTypes,
+			maxSize:    250,
+			want:       []string{"blob2", "lib2", "tmp_1"},
+		},
+		{
+			name:       "Type",
+			cacheTypes: []string{LibraryCacheType},
+			maxSize:    100,
+			want:       []string{"blob1", "blob2", "lib2", "tmp_1"},
+		},
+		{
+			name:       "Empty",
+			cacheTypes: AllCacheTypes,
+			maxSize:    0,
+			want:       []string{"tmp_1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := New(Config{ParentDir: t.TempDir()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeEntry(t, h, LibraryCacheType, "lib1", 100, 4*time.Hour)
+			writeEntry(t, h, OciBlobCacheType, "blob1", 100, 3*time.Hour)
+			writeEntry(t, h, LibraryCacheType, "lib2", 100, 2*time.Hour)
+			writeEntry(t, h, OciBlobCacheType, "blob2", 100, 1*time.Hour)
+			// In-progress temporary files must never be removed.
+			writeEntry(t, h, LibraryCacheType, "tmp_1", 100, 5*time.Hour)
+
+			if err := h.CleanCacheToSize(tt.cacheTypes, tt.dryRun, tt.maxSize); err != nil {
+				t.Fatalf("CleanCacheToSize() error = %v", err)
+			}
+			if got := remainingEntries(t, h); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("remaining entries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// putTestImage places an image manifest, with a config and layers, in the blob
+// cache of h, and records it as ref. It returns the paths of the blobs of the
+// image.
+func putTestImage(t *testing.T, h *Handle, ref string, layers ...v1.Descriptor) []string {
+	t.Helper()
+	config := putTestBlob(t, h, []byte(`{"architecture":"amd64","os":"linux","author":"`+ref+`"}`))
+	config.MediaType = types.OCIConfigJSON
+	raw, err := json.Marshal(v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		Config:        config,
+		Layers:        layers,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := putTestBlob(t, h, raw)
+	manifest.MediaType = types.OCIManifestSchema1
+	if err := h.PutOciCacheReference(OciBlobCacheType, ref, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := []string{}
+	for _, d := range append([]v1.Descriptor{manifest, config}, layers...) {
+		paths = append(paths, filepath.Join(h.entryDir(OciBlobCacheType), d.Digest.Hex))
+	}
+	return paths
+}
+
+// setAge sets the access and modification times of the files at paths to age
+// ago.
+func setAge(t *testing.T, age time.Duration, paths ...string) {
+	t.Helper()
+	ts := time.Now().Add(-age)
+	for _, p := range paths {
+		if err := os.Chtimes(p, ts, ts); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHandle_CleanCacheToSizeImages(t *testing.T) {
+	const (
+		oldRef = "index.docker.io/library/old:latest"
+		newRef = "index.docker.io/library/new:latest"
+	)
+
+	h, err := New(Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shared := putTestBlob(t, h, make([]byte, 1000))
+	shared.MediaType = types.OCILayer
+	oldLayer := putTestBlob(t, h, []byte("OLD LAYER"))
+	oldLayer.MediaType = types.OCILayer
+	newLayer := putTestBlob(t, h, []byte("NEW LAYER"))
+	newLayer.MediaType = types.OCILayer
+	oldPaths := putTestImage(t, h, oldRef, shared, oldLayer)
+	newPaths := putTestImage(t, h, newRef, shared, newLayer)
+	orphan := filepath.Join(h.entryDir(OciBlobCacheType), "orphan")
+	if err := os.WriteFile(orphan, make([]byte, 100), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The shared layer is the least recently accessed blob, but the new image
+	// that uses it was accessed most recently, so must be kept whole.
+	setAge(t, 4*time.Hour, oldPaths...)
+	setAge(t, 3*time.Hour, orphan)
+	setAge(t, 1*time.Hour, newPaths...)
+	setAge(t, 5*time.Hour, filepath.Join(h.entryDir(OciBlobCacheType), shared.Digest.Hex))
+
+	var maxSize int64
+	for _, p := range newPaths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		maxSize += fi.Size()
+	}
+
+	if err := h.CleanCacheToSize([]string{OciBlobCacheType}, false, maxSize); err != nil {
+		t.Fatalf("CleanCacheToSize() error = %v", err)
+	}
+
+	want := []string{}
+	for _, p := range newPaths {
+		want = append(want, filepath.Base(p))
+	}
+	slices.Sort(want)
+	if got := remainingEntries(t, h); !reflect.DeepEqual(got, want) {
+		t.Errorf("remaining entries = %v, want %v", got, want)
+	}
+
+	// No reference in the index may refer to a removed blob.
+	layoutDir := h.getCacheTypeDir(OciBlobCacheType)
+	im, err := layoutIndex(layoutDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := []string{}
+	for _, d := range im.Manifests {
+		refs = append(refs, d.Annotations[imagespec.AnnotationRefName])
+		raw, err := os.ReadFile(filepath.Join(layoutDir, "blobs", d.Digest.Algorithm, d.Digest.Hex))
+		if err != nil {
+			t.Fatalf("index refers to missing manifest: %v", err)
+		}
+		var mf v1.Manifest
+		if err := json.Unmarshal(raw, &mf); err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range append([]v1.Descriptor{mf.Config}, mf.Layers...) {
+			if _, err := os.Stat(filepath.Join(layoutDir, "blobs", b.Digest.Algorithm, b.Digest.Hex)); err != nil {
+				t.Errorf("%s refers to missing blob: %v", d.Annotations[imagespec.AnnotationRefName], err)
+			}
+		}
+	}
+	if want := []string{newRef}; !reflect.DeepEqual(refs, want) {
+		t.Errorf("index references = %v, want %v", refs, want)
+	}
+}
+
+func TestHandle_Prune(t *testing.T) {
+	h, err := New(Config{
+		ParentDir:   t.TempDir(),
+		MaxSize:     200,
+		TypeMaxSize: map[string]int64{OciBlobCacheType: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeEntry(t, h, LibraryCacheType, "lib1", 100, 4*time.Hour)
+	writeEntry(t, h, OciBlobCacheType, "blob1", 100, 3*time.Hour)
+	writeEntry(t, h, LibraryCacheType, "lib2", 100, 2*time.Hour)
+	writeEntry(t, h, OciBlobCacheType, "blob2", 100, 1*time.Hour)
+
+	if err := h.Prune(); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	// blob1 exceeds the blob limit, then lib1 exceeds the total limit.
+	want := []string{"blob2", "lib2"}
+	if got := remainingEntries(t, h); !reflect.DeepEqual(got, want) {
+		t.Errorf("remaining entries = %v, want %v", got, want)
+	}
+}
+
+func TestHandle_GetEntryAccess(t *testing.T) {
+	h, err := New(Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeEntry(t, h, LibraryCacheType, "lib1", 100, 4*time.Hour)
+	writeEntry(t, h, LibraryCacheType, "lib2", 100, 2*time.Hour)
+
+	e, err := h.GetEntry(LibraryCacheType, "lib1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Exists {
+		t.Fatalf("expected lib1 entry to exist")
+	}
+
+	// lib1 was accessed most recently, so lib2 must be removed.
+	if err := h.CleanCacheToSize([]string{LibraryCacheType}, false, 100); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"lib1"}
+	if got := remainingEntries(t, h); !reflect.DeepEqual(got, want) {
+		t.Errorf("remaining entries = %v, want %v", got, want)
+	}
+
+	// The modification time, used by --days, must not change.
+	fi, err := os.Stat(e.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(fi.ModTime()) < 3*time.Hour {
+		t.Errorf("modification time of entry was changed")
+	}
+}
+
+func TestParseTypeMaxSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    map[string]int64
+		wantErr bool
+	}{
+		{
+			name:    "Valid",
+			entries: []string{"blob=10G", " library = 512M"},
+			want:    map[string]int64{OciBlobCacheType: 10 << 30, LibraryCacheType: 512 << 20},
+		},
+		{
+			name:    "NoSeparator",
+			entries: []string{"blob"},
+			wantErr: true,
+		},
+		{
+			name:    "BadType",
+			entries: []string{"foo=1G"},
+			wantErr: true,
+		},
+		{
+			name:    "BadSize",
+			entries: []string{"blob=lots"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTypeMaxSize(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTypeMaxSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTypeMaxSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
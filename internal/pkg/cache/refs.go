@@ -0,0 +1,152 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs"
+	"github.com/sylabs/singularity/v4/pkg/util/fs/lock"
+)
+
+// PutOciCacheReference records, in the index of an OCI layout cache, that the
+// image reference ref resolved to the image manifest or image index desc. The
+// reference is held in the org.opencontainers.image.ref.name annotation, and
+// replaces any existing record for ref.
+func (h *Handle) PutOciCacheReference(cacheType, ref string, desc v1.Descriptor) error {
+	if h.disabled {
+		return errCacheDisabled
+	}
+	layoutDir, err := h.GetOciCacheDir(cacheType)
+	if err != nil {
+		return err
+	}
+	return updateOciCacheIndex(layoutDir, func(im *v1.IndexManifest) error {
+		putReference(im, ref, desc)
+		return nil
+	})
+}
+
+// putReference records, in the index im, that the image reference ref resolved
+// to desc, replacing any existing record for ref.
+func putReference(im *v1.IndexManifest, ref string, desc v1.Descriptor) {
+	im.Manifests = slices.DeleteFunc(im.Manifests, func(d v1.Descriptor) bool {
+		return d.Annotations[imagespec.AnnotationRefName] == ref
+	})
+	desc.Annotations = map[string]string{imagespec.AnnotationRefName: ref}
+	desc.Platform = nil
+	im.Manifests = append(im.Manifests, desc)
+}
+
+// updateOciCacheIndex applies update to the index of the OCI layout at
+// layoutDir. The layout directory is locked while the index is read, updated
+// and written, so that concurrent updates, from other processes sharing the
+// cache, are not lost. The index is replaced by rename, so that it is never
+// seen partially written.
+func updateOciCacheIndex(layoutDir string, update func(*v1.IndexManifest) error) error {
+	fd, err := lock.Exclusive(layoutDir)
+	if err != nil {
+		return fmt.Errorf("while locking %s: %w", layoutDir, err)
+	}
+	defer lock.Release(fd)
+
+	indexPath := filepath.Join(layoutDir, "index.json")
+	fi, err := os.Stat(indexPath)
+	if err != nil {
+		return err
+	}
+	im, err := layoutIndex(layoutDir)
+	if err != nil {
+		return fmt.Errorf("while reading %s: %w", indexPath, err)
+	}
+	if err := update(im); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(im, "", "   ")
+	if err != nil {
+		return err
+	}
+
+	f, err := fs.MakeTmpFile(layoutDir, "tmp_index_", fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), indexPath); err != nil {
+		return fmt.Errorf("while replacing %s: %w", indexPath, err)
+	}
+	return nil
+}
+
+// GetOciCacheReference returns the image manifest or image index descriptor
+// that the image reference ref last resolved to, as recorded with
+// PutOciCacheReference. The shared cache is consulted before the user's cache.
+// An error wrapping os.ErrNotExist is returned if there is no record for ref.
+func (h *Handle) GetOciCacheReference(cacheType, ref string) (v1.Descriptor, error) {
+	if h.disabled {
+		return v1.Descriptor{}, errCacheDisabled
+	}
+	layoutDir, err := h.GetOciCacheDir(cacheType)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+
+	dirs := []string{layoutDir}
+	if h.sharedDir != "" {
+		dirs = []string{filepath.Join(h.sharedDir, cacheType), layoutDir}
+	}
+	for _, dir := range dirs {
+		desc, err := layoutReference(dir, ref)
+		if err == nil {
+			return desc, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return v1.Descriptor{}, err
+		}
+	}
+	return v1.Descriptor{}, fmt.Errorf("no cached reference for %s: %w", ref, os.ErrNotExist)
+}
+
+// layoutReference returns the descriptor annotated with ref in the index of the
+// OCI layout at dir.
+func layoutReference(dir, ref string) (v1.Descriptor, error) {
+	im, err := layoutIndex(dir)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	// The last matching descriptor is the most recent record.
+	for i := len(im.Manifests) - 1; i >= 0; i-- {
+		d := im.Manifests[i]
+		if d.Annotations[imagespec.AnnotationRefName] == ref {
+			return d, nil
+		}
+	}
+	return v1.Descriptor{}, os.ErrNotExist
+}
+
+// layoutIndex reads the index of the OCI layout at dir.
+func layoutIndex(dir string) (*v1.IndexManifest, error) {
+	f, err := os.Open(filepath.Join(dir, "index.json"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return v1.ParseIndexManifest(f)
+}
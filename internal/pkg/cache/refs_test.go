@@ -0,0 +1,93 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestHandle_OciCacheReference(t *testing.T) {
+	h, err := New(Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	descs := make([]v1.Descriptor, 2)
+	for i := range descs {
+		descs[i] = putTestBlob(t, h, []byte(fmt.Sprintf("MANIFEST %d", i)))
+		descs[i].MediaType = types.OCIManifestSchema1
+	}
+
+	const ref = "index.docker.io/library/alpine:latest"
+	if _, err := h.GetOciCacheReference(OciBlobCacheType, ref); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("GetOciCacheReference() error = %v, want %v", err, os.ErrNotExist)
+	}
+
+	// A later record replaces an earlier one.
+	for _, d := range descs {
+		if err := h.PutOciCacheReference(OciBlobCacheType, ref, d); err != nil {
+			t.Fatalf("PutOciCacheReference() error = %v", err)
+		}
+		got, err := h.GetOciCacheReference(OciBlobCacheType, ref)
+		if err != nil {
+			t.Fatalf("GetOciCacheReference() error = %v", err)
+		}
+		if got.Digest != d.Digest {
+			t.Errorf("reference resolved to %s, want %s", got.Digest, d.Digest)
+		}
+	}
+
+	layoutDir, err := h.GetOciCacheDir(OciBlobCacheType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	im, err := layoutIndex(layoutDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(im.Manifests) != 1 {
+		t.Errorf("got %d index entries, want 1", len(im.Manifests))
+	}
+}
+
+func TestHandle_OciCacheReferenceConcurrent(t *testing.T) {
+	h, err := New(Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := putTestBlob(t, h, []byte("MANIFEST"))
+	d.MediaType = types.OCIManifestSchema1
+
+	// Concurrent records of different references must not be lost.
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = h.PutOciCacheReference(OciBlobCacheType, fmt.Sprintf("example.com/image:%d", i), d)
+		}()
+	}
+	wg.Wait()
+
+	for i := range n {
+		if errs[i] != nil {
+			t.Errorf("PutOciCacheReference() error = %v", errs[i])
+		}
+		ref := fmt.Sprintf("example.com/image:%d", i)
+		if _, err := h.GetOciCacheReference(OciBlobCacheType, ref); err != nil {
+			t.Errorf("reference %s was lost: %v", ref, err)
+		}
+	}
+}
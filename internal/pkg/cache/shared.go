@@ -0,0 +1,203 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cache
+
+import (
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/fs/lock"
+)
+
+const (
+	// sharedLockFile is the file, at the root of a shared cache, that is
+	// locked while the shared cache is being populated.
+	sharedLockFile = ".lock"
+	// sharedDirMode is the permission applied to shared cache directories.
+	sharedDirMode = 0o755
+	// sharedFileMode is the permission applied to shared cache entries.
+	sharedFileMode = 0o644
+)
+
+// OpenShared opens the shared cache at dir for writing, creating it if
+// necessary, so that it can be populated with entries. Entries and blobs added
+// to the shared cache are made readable by all users. An exclusive lock is held
+// on the shared cache, so that concurrent population is serialized, until
+// Release is called. Readers of the shared cache do not take the lock, as
+// entries are only made visible, by an atomic rename, once complete.
+func OpenShared(dir string) (h *Handle, err error) {
+	if dir == "" {
+		return nil, fmt.Errorf("shared cache directory not specified")
+	}
+	if !filepath.IsAbs(dir) {
+		return nil, fmt.Errorf("shared cache directory %s must be an absolute path", dir)
+	}
+
+	h = &Handle{
+		parentDir: dir,
+		rootDir:   dir,
+		shared:    true,
+		lockFd:    -1,
+	}
+
+	if err := initSharedDir(dir); err != nil {
+		return nil, fmt.Errorf("failed initializing shared cache directory: %s", err)
+	}
+
+	lockPath := filepath.Join(dir, sharedLockFile)
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDONLY, sharedFileMode)
+	if err != nil {
+		return nil, fmt.Errorf("while creating shared cache lock: %w", err)
+	}
+	f.Close()
+
+	sylog.Debugf("Acquiring lock on shared cache %s", dir)
+	h.lockFd, err = lock.Exclusive(lockPath)
+	if err != nil {
+		return nil, fmt.Errorf("while locking shared cache: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			lock.Release(h.lockFd)
+		}
+	}()
+
+	// Record the existing content, so that Release leaves its permissions
+	// alone.
+	if h.sharedBefore, err = sharedContent(dir); err != nil {
+		return nil, fmt.Errorf("while reading shared cache: %w", err)
+	}
+
+	for _, ct := range AllCacheTypes {
+		dir := h.getCacheTypeDir(ct)
+		if err = initSharedDir(dir); err != nil {
+			return nil, fmt.Errorf("failed initializing %s shared cache directory: %s", ct, err)
+		}
+		if stringInSlice(ct, OciCacheTypes) {
+			if err = initLayout(dir); err != nil {
+				return nil, fmt.Errorf("failed initializing %s shared cache oci layout: %s", ct, err)
+			}
+		}
+	}
+
+	return h, nil
+}
+
+// Release makes content added to a shared cache, opened with OpenShared,
+// readable by all users, and releases the lock held on it. The permissions of
+// content that existed before the shared cache was opened are not changed.
+func (h *Handle) Release() error {
+	if !h.shared || h.lockFd < 0 {
+		return nil
+	}
+	defer func() {
+		lock.Release(h.lockFd)
+		h.lockFd = -1
+	}()
+
+	// OCI blobs are written by go-containerregistry with restrictive
+	// permissions, which must be relaxed so that users can read them.
+	return filepath.WalkDir(h.rootDir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&^iofs.ModeDir != 0 {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		// Entries that are replaced, rather than modified in place, are new.
+		if before, ok := h.sharedBefore[path]; ok && os.SameFile(before, fi) {
+			return nil
+		}
+		if d.IsDir() {
+			return os.Chmod(path, sharedDirMode)
+		}
+		return os.Chmod(path, sharedFileMode)
+	})
+}
+
+// initSharedDir creates the shared cache directory dir, if it does not exist.
+// An existing directory is left as it is, as its permissions may have been set
+// by the administrator.
+func initSharedDir(dir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		sylog.Debugf("Creating shared cache directory: %s", dir)
+		if err := fs.MkdirAll(dir, sharedDirMode); err != nil {
+			return fmt.Errorf("couldn't create shared cache directory %v: %v", dir, err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("unable to stat %s: %s", dir, err)
+	}
+	return nil
+}
+
+// sharedContent returns the directories and regular files under dir.
+func sharedContent(dir string) (map[string]os.FileInfo, error) {
+	content := map[string]os.FileInfo{}
+	err := filepath.WalkDir(dir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&^iofs.ModeDir != 0 {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		content[path] = fi
+		return nil
+	})
+	return content, err
+}
+
+// sharedEntry returns an existing entry from the shared cache, for the
+// specified file cache type and hash, if present.
+func (h *Handle) sharedEntry(cacheType, hash string) (*Entry, bool) {
+	if h.sharedDir == "" {
+		return nil, false
+	}
+	path := filepath.Join(h.sharedDir, cacheType, hash)
+	fi, err := os.Stat(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			sylog.Debugf("Could not check shared cache entry %s: %v", path, err)
+		}
+		return nil, false
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, false
+	}
+	sylog.Debugf("Using shared cache entry %s", path)
+	return &Entry{Exists: true, Path: path}, true
+}
+
+// sharedOciCacheBlob opens the blob with digest hash from the shared OCI
+// layout cache, if present.
+func (h *Handle) sharedOciCacheBlob(cacheType string, hash v1.Hash) (io.ReadCloser, bool) {
+	if h.sharedDir == "" {
+		return nil, false
+	}
+	path := filepath.Join(h.sharedDir, cacheType, "blobs", hash.Algorithm, hash.Hex)
+	f, err := os.Open(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			sylog.Debugf("Could not open shared cache blob %s: %v", path, err)
+		}
+		return nil, false
+	}
+	sylog.Debugf("Using shared cache blob %s", path)
+	return f, true
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cache
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+func TestSharedCache(t *testing.T) {
+	sharedDir := filepath.Join(t.TempDir(), "shared")
+	content := "BLOB CONTENT"
+	contentDigest, _, err := v1.SHA256(bytes.NewBufferString(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Populate the shared cache with a file entry and a blob.
+	sh, err := OpenShared(sharedDir)
+	if err != nil {
+		t.Fatalf("OpenShared() error = %v", err)
+	}
+	e, err := sh.GetEntry(LibraryCacheType, "sharedhash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Exists {
+		t.Fatalf("unexpected existing entry in new shared cache")
+	}
+	if err := os.WriteFile(e.TmpPath, []byte("SIF"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sh.PutOciCacheBlob(OciBlobCacheType, contentDigest, io.NopCloser(bytes.NewBufferString(content))); err != nil {
+		t.Fatal(err)
+	}
+	if err := sh.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	// All shared cache content must be readable by other users.
+	sharedEntryPath := filepath.Join(sharedDir, LibraryCacheType, "sharedhash")
+	sharedBlobPath := filepath.Join(sharedDir, OciBlobCacheType, "blobs", contentDigest.Algorithm, contentDigest.Hex)
+	for _, p := range []string{sharedEntryPath, sharedBlobPath} {
+		fi, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != sharedFileMode {
+			t.Errorf("%s has mode %#o, expected %#o", p, fi.Mode().Perm(), sharedFileMode)
+		}
+	}
+
+	// A user handle must use the shared cache before its own cache.
+	h, err := New(Config{ParentDir: t.TempDir(), SharedDir: sharedDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err = h.GetEntry(LibraryCacheType, "sharedhash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Exists || e.Path != sharedEntryPath {
+		t.Errorf("GetEntry() = %+v, expected existing shared entry %s", e, sharedEntryPath)
+	}
+
+	e, err = h.GetEntry(LibraryCacheType, "userhash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.CleanTmp()
+	if e.Exists || filepath.Dir(e.Path) == filepath.Join(sharedDir, LibraryCacheType) {
+		t.Errorf("GetEntry() = %+v, expected new user cache entry", e)
+	}
+
+	r, err := h.GetOciCacheBlob(OciBlobCacheType, contentDigest)
+	if err != nil {
+		t.Fatalf("GetOciCacheBlob() error = %v", err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != content {
+		t.Errorf("GetOciCacheBlob() content = %q, expected %q", got, content)
+	}
+}
+
+func TestOpenSharedRelative(t *testing.T) {
+	if _, err := OpenShared("relative/dir"); err == nil {
+		t.Errorf("OpenShared() expected error for relative path")
+	}
+}
+
+func TestSharedCacheExistingPermissions(t *testing.T) {
+	// An existing shared cache, with permissions set by the administrator.
+	sharedDir := filepath.Join(t.TempDir(), "shared")
+	if err := os.Mkdir(sharedDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	existingPath := filepath.Join(sharedDir, "existing")
+	if err := os.WriteFile(existingPath, []byte("EXISTING"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	sh, err := OpenShared(sharedDir)
+	if err != nil {
+		t.Fatalf("OpenShared() error = %v", err)
+	}
+	e, err := sh.GetEntry(LibraryCacheType, "sharedhash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(e.TmpPath, []byte("SIF"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sh.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		mode os.FileMode
+	}{
+		{
+			name: "ExistingRoot",
+			path: sharedDir,
+			mode: 0o750,
+		},
+		{
+			name: "ExistingFile",
+			path: existingPath,
+			mode: 0o600,
+		},
+		{
+			name: "NewDir",
+			path: filepath.Join(sharedDir, LibraryCacheType),
+			mode: sharedDirMode,
+		},
+		{
+			name: "NewEntry",
+			path: filepath.Join(sharedDir, LibraryCacheType, "sharedhash"),
+			mode: sharedFileMode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fi, err := os.Stat(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fi.Mode().Perm() != tt.mode {
+				t.Errorf("%s has mode %#o, expected %#o", tt.path, fi.Mode().Perm(), tt.mode)
+			}
+		})
+	}
+}
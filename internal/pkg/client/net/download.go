@@ -0,0 +1,550 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+//revive:disable:var-naming
+package net
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sylabs/singularity/v4/internal/pkg/client/progress"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	useragent "github.com/sylabs/singularity/v4/pkg/util/user-agent"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// Number of attempts made to fetch an image, or a chunk of an image.
+	downloadRetries = 5
+	// Number of chunks fetched concurrently.
+	chunkConcurrency = 4
+)
+
+var (
+	// Delay before the first retry of a failed request, doubled for each
+	// subsequent retry.
+	downloadBackoff = 2 * time.Second
+	// Files at least this large are fetched as parallel ranged chunks, if the
+	// server supports range requests.
+	parallelMinSize int64 = 512 << 20
+	// Size of the chunks fetched in parallel.
+	chunkSize int64 = 64 << 20
+	// A request is abandoned, and retried, if no data is received for this
+	// long.
+	downloadStallTimeout = 60 * time.Second
+)
+
+// errStalled is returned when no data has been received for downloadStallTimeout.
+var errStalled = errors.New("download stalled")
+
+// DownloadOptions holds options for DownloadImage.
+type DownloadOptions struct {
+	// Checksum is an optional sha256 digest, as "sha256:<hex>" or "<hex>", that
+	// the downloaded file must match. It may also be provided as a #sha256=<hex>
+	// fragment on the URL.
+	Checksum string
+}
+
+// permanentError is a download error that will not be resolved by retrying.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// DownloadImage will retrieve an image from an http(s) URI, saving it into the
+// specified file. Failed requests are retried with backoff, resuming from the
+// last byte received where the server supports range requests. Large files are
+// fetched as parallel ranged chunks. If a checksum is provided, the file is
+// verified before DownloadImage returns.
+//
+// Data is received into a partial file, alongside filePath, which is named for
+// the URL and the version of the file on the server. If the download fails, or
+// is interrupted, the partial file is kept so that a later DownloadImage of the
+// same file resumes from it.
+func DownloadImage(ctx context.Context, filePath string, netURL string, opts DownloadOptions) error {
+	if !IsNetPullRef(netURL) {
+		return fmt.Errorf("not a valid url reference: %s", netURL)
+	}
+	fetchURL, checksum, err := splitChecksum(netURL, opts.Checksum)
+	if err != nil {
+		return err
+	}
+	if filePath == "" {
+		refParts := strings.Split(fetchURL, "/")
+		filePath = refParts[len(refParts)-1]
+		sylog.Infof("Download filename not provided. Downloading to: %s\n", filePath)
+	}
+
+	sylog.Debugf("Pulling from URL: %s\n", fetchURL)
+
+	// Requests are not subject to an overall timeout, as a large download may
+	// take any amount of time. Stalled requests are abandoned by fetch.
+	d := &downloader{
+		client: &http.Client{},
+		url:    fetchURL,
+	}
+	info := d.probe(ctx)
+
+	partialPath, resumable := partialPath(filePath, fetchURL, checksum, info.validator)
+	// Perms are 777 *prior* to umask
+	out, err := os.OpenFile(partialPath, os.O_CREATE|os.O_RDWR|unix.O_NOFOLLOW, 0o777)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	// A concurrent download of the same file would write the same partial file.
+	if err := unix.Flock(int(out.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		return fmt.Errorf("while locking %s, is another download of %s in progress?: %w", partialPath, fetchURL, err)
+	}
+	if !resumable {
+		// Without a checksum or validator, the partial file may not be from
+		// the same version of the file on the server.
+		if err := out.Truncate(0); err != nil {
+			return err
+		}
+	}
+
+	if err := d.download(ctx, out, info, checksum); err != nil {
+		keepPartial(out, resumable, err)
+		return err
+	}
+
+	if err := os.Rename(partialPath, filePath); err != nil {
+		return fmt.Errorf("while moving download to %s: %w", filePath, err)
+	}
+
+	sylog.Debugf("Download complete\n")
+
+	return nil
+}
+
+// partialPath returns the path of the partial file for a download of fetchURL
+// to filePath. The partial file is named for fetchURL, and the checksum of the
+// file, or a validator identifying the version of the file on the server. It
+// is only resumable if one of these is known.
+func partialPath(filePath, fetchURL, checksum, validator string) (string, bool) {
+	h := sha256.New()
+	if checksum != "" {
+		h.Write([]byte(checksum))
+	} else {
+		h.Write([]byte(fetchURL + "\x00" + validator))
+	}
+	name := "." + hex.EncodeToString(h.Sum(nil)) + ".partial"
+	return filepath.Join(filepath.Dir(filePath), name), checksum != "" || validator != ""
+}
+
+// keepPartial keeps the partial file out, following the download error err, so
+// that the download can be resumed. It is removed if it is not resumable, holds
+// no data, or the data does not match the expected checksum.
+func keepPartial(out *os.File, resumable bool, err error) {
+	var cErr *checksumError
+	fi, statErr := out.Stat()
+	if resumable && statErr == nil && fi.Size() > 0 && !errors.As(err, &cErr) {
+		sylog.Infof("Keeping incomplete download %s, to be resumed by a later pull", out.Name())
+		return
+	}
+	sylog.Infof("Cleaning up incomplete download: %s", out.Name())
+	if err := os.Remove(out.Name()); err != nil {
+		sylog.Errorf("Error while removing incomplete download: %v", err)
+	}
+}
+
+// splitChecksum returns netURL without any #sha256= fragment, and the lower
+// case hex sha256 checksum given by the fragment or by checksum. An error is
+// returned if a checksum is malformed, or the fragment and checksum conflict.
+func splitChecksum(netURL, checksum string) (string, string, error) {
+	if checksum != "" {
+		var err error
+		checksum, err = parseChecksum(checksum)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	u, err := url.Parse(netURL)
+	if err != nil {
+		return "", "", fmt.Errorf("not a valid url reference: %s: %w", netURL, err)
+	}
+	fragment, ok := strings.CutPrefix(u.Fragment, "sha256=")
+	if !ok {
+		return netURL, checksum, nil
+	}
+	fragment, err = parseChecksum(fragment)
+	if err != nil {
+		return "", "", err
+	}
+	if checksum != "" && checksum != fragment {
+		return "", "", fmt.Errorf("checksum sha256:%s conflicts with URL fragment sha256:%s", checksum, fragment)
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String(), fragment, nil
+}
+
+// parseChecksum validates a sha256 checksum, as "sha256:<hex>" or "<hex>",
+// returning its lower case hex value.
+func parseChecksum(checksum string) (string, error) {
+	h := strings.ToLower(strings.TrimPrefix(checksum, "sha256:"))
+	if _, err := hex.DecodeString(h); err != nil || len(h) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid sha256 checksum: %q", checksum)
+	}
+	return h, nil
+}
+
+// downloader fetches a single http(s) URL.
+type downloader struct {
+	client *http.Client
+	url    string
+}
+
+// remoteInfo holds information about the file at a URL, from a HEAD request.
+type remoteInfo struct {
+	// size is the size of the file, or -1 if unknown.
+	size int64
+	// ranges is true if the server supports range requests.
+	ranges bool
+	// validator is the ETag or Last-Modified date of the file, if known.
+	validator string
+}
+
+// download fetches d.url into out, verifying it against checksum if set. If out
+// already holds data, from an earlier download of the same file, the download
+// resumes from its end.
+func (d *downloader) download(ctx context.Context, out *os.File, info remoteInfo, checksum string) error {
+	fi, err := out.Stat()
+	if err != nil {
+		return err
+	}
+	offset := fi.Size()
+	if info.size >= 0 && offset > info.size {
+		offset = 0
+	}
+	if offset > 0 {
+		sylog.Infof("Resuming download from byte %d", offset)
+	}
+
+	bar := &progress.DownloadBar{}
+	bar.Init(info.size)
+	bar.SetCurrent(offset)
+
+	if info.ranges && info.size >= parallelMinSize {
+		sylog.Debugf("Downloading %d bytes in %d byte chunks", info.size, chunkSize)
+		err = d.parallel(ctx, out, offset, info.size, bar)
+	} else {
+		err = d.sequential(ctx, out, offset, info.size, bar)
+	}
+	if err != nil {
+		bar.Abort(true)
+		return err
+	}
+	if info.size <= 0 {
+		// Must ensure bar is complete for a download with unknown size, or it will hang.
+		fi, err := out.Stat()
+		if err != nil {
+			bar.Abort(true)
+			return err
+		}
+		bar.SetTotal(fi.Size(), true)
+	}
+	bar.Wait()
+
+	if checksum == "" {
+		return nil
+	}
+	return verifyChecksum(out, checksum)
+}
+
+// probe makes a HEAD request to find the size and version of the file at
+// d.url, and whether the server supports range requests. If the HEAD request
+// fails, the size is reported as unknown (-1), without range support.
+func (d *downloader) probe(ctx context.Context) remoteInfo {
+	info := remoteInfo{size: -1}
+
+	ctx, cancel := context.WithTimeout(ctx, downloadStallTimeout)
+	defer cancel()
+	req, err := d.newRequest(ctx, http.MethodHead)
+	if err != nil {
+		return info
+	}
+	res, err := d.client.Do(req)
+	if err != nil {
+		sylog.Debugf("HEAD request failed: %v", err)
+		return info
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		sylog.Debugf("HEAD request returned %d", res.StatusCode)
+		return info
+	}
+	info.size = res.ContentLength
+	info.ranges = res.Header.Get("Accept-Ranges") == "bytes"
+	// A weak ETag does not guarantee that the content is byte-for-byte
+	// identical, so cannot be used to resume.
+	if etag := res.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		info.validator = etag
+	} else {
+		info.validator = res.Header.Get("Last-Modified")
+	}
+	sylog.Debugf("HEAD reports size %d, range support %t, validator %q", info.size, info.ranges, info.validator)
+	return info
+}
+
+// sequential fetches the file in a single request, from offset, resuming from
+// the last byte written if a retry is required.
+func (d *downloader) sequential(ctx context.Context, out *os.File, offset, size int64, bar *progress.DownloadBar) error {
+	pos := offset
+	if size > 0 && pos == size {
+		return nil
+	}
+	err := retry(ctx, "Download", func() error {
+		var err error
+		pos, err = d.fetch(ctx, out, pos, -1, bar)
+		if err == nil && size > 0 && pos < size {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	})
+	// Any data beyond pos, from an earlier download, is discarded.
+	if tErr := out.Truncate(pos); err == nil {
+		err = tErr
+	}
+	return err
+}
+
+// parallel fetches the file, from offset, as concurrent ranged chunks. Each
+// chunk is retried independently, resuming from the last byte written. If the
+// download fails, out is truncated to the data received contiguously from the
+// start of the file, so that the download can be resumed.
+func (d *downloader) parallel(ctx context.Context, out *os.File, offset, size int64, bar *progress.DownloadBar) error {
+	if err := out.Truncate(size); err != nil {
+		return err
+	}
+
+	// pos holds the offset following the last byte written for each chunk.
+	pos := make([]int64, (size-offset+chunkSize-1)/chunkSize)
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(chunkConcurrency)
+	for i := range pos {
+		start := offset + int64(i)*chunkSize
+		end := min(start+chunkSize, size) - 1
+		pos[i] = start
+		g.Go(func() error {
+			return retry(gctx, fmt.Sprintf("Download of bytes %d-%d", start, end), func() error {
+				var err error
+				pos[i], err = d.fetch(gctx, out, pos[i], end, bar)
+				if err == nil && pos[i] <= end {
+					err = io.ErrUnexpectedEOF
+				}
+				return err
+			})
+		})
+	}
+	err := g.Wait()
+	if err == nil {
+		return nil
+	}
+
+	received := offset
+	for i := range pos {
+		received = pos[i]
+		if pos[i] < min(offset+int64(i+1)*chunkSize, size) {
+			break
+		}
+	}
+	if tErr := out.Truncate(received); tErr != nil {
+		sylog.Debugf("Couldn't truncate incomplete download: %v", tErr)
+	}
+	return err
+}
+
+// fetch requests bytes start to end (inclusive) of d.url, writing them at the
+// same offset in out. If end is negative, the request is to the end of the file.
+// The offset following the last byte written is returned, including on error,
+// so that the fetch can be resumed. The request is abandoned if no data is
+// received for downloadStallTimeout.
+func (d *downloader) fetch(ctx context.Context, out *os.File, start, end int64, bar *progress.DownloadBar) (int64, error) {
+	reqCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	stall := time.AfterFunc(downloadStallTimeout, func() { cancel(errStalled) })
+	defer stall.Stop()
+
+	n, err := d.fetchRange(reqCtx, out, start, end, bar, stall)
+	if err != nil && ctx.Err() == nil && errors.Is(context.Cause(reqCtx), errStalled) {
+		err = fmt.Errorf("%w: no data received for %v", errStalled, downloadStallTimeout)
+	}
+	return n, err
+}
+
+// fetchRange implements fetch, resetting the stall timer as data is received.
+func (d *downloader) fetchRange(ctx context.Context, out *os.File, start, end int64, bar *progress.DownloadBar, stall *time.Timer) (int64, error) {
+	req, err := d.newRequest(ctx, http.MethodGet)
+	if err != nil {
+		return start, &permanentError{err}
+	}
+	if end >= 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	} else if start > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", start))
+	}
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return start, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusPartialContent:
+	case res.StatusCode == http.StatusOK && end >= 0:
+		return start, &permanentError{fmt.Errorf("server did not honor range request")}
+	case res.StatusCode == http.StatusRequestedRangeNotSatisfiable && start > 0 && end < 0:
+		// The file is no larger than the data already received. Restart, as
+		// the size of the file is unknown.
+		sylog.Debugf("Server cannot resume from byte %d, restarting download", start)
+		if err := out.Truncate(0); err != nil {
+			return start, &permanentError{err}
+		}
+		bar.SetCurrent(0)
+		return 0, fmt.Errorf("cannot resume download from byte %d", start)
+	case res.StatusCode == http.StatusOK:
+		if start > 0 {
+			// The server ignored our request to resume, and is sending the
+			// whole file.
+			sylog.Debugf("Server does not support resume, restarting download")
+			if err := out.Truncate(0); err != nil {
+				return start, &permanentError{err}
+			}
+			start = 0
+			bar.SetCurrent(0)
+		}
+	default:
+		return start, statusError(res)
+	}
+
+	if start == 0 && end < 0 {
+		sylog.Debugf("OK response received, beginning body download\n")
+	}
+
+	w := &progressWriter{w: io.NewOffsetWriter(out, start), bar: bar}
+	r := readerFunc(func(p []byte) (int, error) {
+		n, err := res.Body.Read(p)
+		if n > 0 {
+			stall.Reset(downloadStallTimeout)
+		}
+		return n, err
+	})
+	n, err := progress.CopyWithContext(ctx, w, r)
+	return start + n, err
+}
+
+func (d *downloader) newRequest(ctx context.Context, method string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, d.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", useragent.Value())
+	return req, nil
+}
+
+// statusError returns an error for an unsuccessful response. Client errors,
+// other than timeouts and rate limiting, are permanent.
+func statusError(res *http.Response) error {
+	if res.StatusCode == http.StatusNotFound {
+		return &permanentError{fmt.Errorf("the requested image was not found")}
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	err := fmt.Errorf("download did not succeed: %d %s", res.StatusCode, body)
+	if res.StatusCode >= 400 && res.StatusCode < 500 &&
+		res.StatusCode != http.StatusRequestTimeout && res.StatusCode != http.StatusTooManyRequests {
+		return &permanentError{err}
+	}
+	return err
+}
+
+// retry calls fn until it succeeds, returns a permanent error, the context is
+// canceled, or downloadRetries attempts have been made. The delay between
+// attempts doubles from downloadBackoff.
+func retry(ctx context.Context, desc string, fn func() error) error {
+	backoff := downloadBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		var pErr *permanentError
+		if errors.As(err, &pErr) || ctx.Err() != nil || attempt == downloadRetries {
+			return err
+		}
+
+		sylog.Warningf("%s failed (attempt %d of %d), retrying in %v: %v", desc, attempt, downloadRetries, backoff, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// checksumError is returned when a download does not match its checksum.
+type checksumError struct {
+	expected, got string
+}
+
+func (e *checksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch: expected sha256:%s, got sha256:%s", e.expected, e.got)
+}
+
+// verifyChecksum checks that the sha256 digest of f matches checksum.
+func verifyChecksum(f *os.File, checksum string) error {
+	sylog.Debugf("Verifying sha256 checksum of downloaded image")
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(f, 0, 1<<63-1)); err != nil {
+		return fmt.Errorf("while computing checksum: %w", err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != checksum {
+		return &checksumError{expected: checksum, got: got}
+	}
+	return nil
+}
+
+// progressWriter advances a progress bar as data is written.
+type progressWriter struct {
+	w   io.Writer
+	bar *progress.DownloadBar
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.bar.IncrBy(n)
+	return n, err
+}
+
+// readerFunc adapts a function to an io.Reader.
+type readerFunc func(p []byte) (int, error)
+
+func (rf readerFunc) Read(p []byte) (int, error) {
+	return rf(p)
+}
@@ -0,0 +1,394 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+//revive:disable:var-naming
+package net
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSplitChecksum(t *testing.T) {
+	sum := "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+
+	tests := []struct {
+		name         string
+		url          string
+		checksum     string
+		wantURL      string
+		wantChecksum string
+		wantErr      bool
+	}{
+		{
+			name:    "NoChecksum",
+			url:     "https://example.com/image.sif",
+			wantURL: "https://example.com/image.sif",
+		},
+		{
+			name:         "Flag",
+			url:          "https://example.com/image.sif",
+			checksum:     "sha256:" + sum,
+			wantURL:      "https://example.com/image.sif",
+			wantChecksum: sum,
+		},
+		{
+			name:         "FlagNoPrefix",
+			url:          "https://example.com/image.sif",
+			checksum:     sum,
+			wantURL:      "https://example.com/image.sif",
+			wantChecksum: sum,
+		},
+		{
+			name:         "Fragment",
+			url:          "https://example.com/image.sif?x=y#sha256=" + sum,
+			wantURL:      "https://example.com/image.sif?x=y",
+			wantChecksum: sum,
+		},
+		{
+			name:         "FragmentAndFlag",
+			url:          "https://example.com/image.sif#sha256=" + sum,
+			checksum:     "sha256:" + sum,
+			wantURL:      "https://example.com/image.sif",
+			wantChecksum: sum,
+		},
+		{
+			name:     "Conflict",
+			url:      "https://example.com/image.sif#sha256=" + sum,
+			checksum: "sha256:" + sum[1:] + "0",
+			wantErr:  true,
+		},
+		{
+			name:     "BadFlag",
+			url:      "https://example.com/image.sif",
+			checksum: "sha256:abcd",
+			wantErr:  true,
+		},
+		{
+			name:    "BadFragment",
+			url:     "https://example.com/image.sif#sha256=xyz",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotURL, gotChecksum, err := splitChecksum(tt.url, tt.checksum)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotURL != tt.wantURL {
+				t.Errorf("splitChecksum() url = %q, want %q", gotURL, tt.wantURL)
+			}
+			if gotChecksum != tt.wantChecksum {
+				t.Errorf("splitChecksum() checksum = %q, want %q", gotChecksum, tt.wantChecksum)
+			}
+		})
+	}
+}
+
+// contentHandler serves content with range support, and an ETag if etag is
+// set. If failures is non-zero, that many GET requests fail, with a 503 for
+// range requests, or a truncated body otherwise. If stall is set, a truncated
+// body is held open until the client gives up.
+type contentHandler struct {
+	content  []byte
+	etag     string
+	failures int32
+	stall    bool
+	gets     atomic.Int32
+	ranges   atomic.Int32
+}
+
+func (h *contentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.etag != "" {
+		w.Header().Set("ETag", h.etag)
+	}
+	if r.Method == http.MethodGet {
+		n := h.gets.Add(1)
+		isRange := r.Header.Get("Range") != ""
+		if isRange {
+			h.ranges.Add(1)
+		}
+		if n <= h.failures {
+			if isRange {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Length", "1048576")
+			w.WriteHeader(http.StatusOK)
+			w.Write(h.content[:1024])
+			w.(http.Flusher).Flush()
+			if h.stall {
+				<-r.Context().Done()
+				return
+			}
+			panic(http.ErrAbortHandler)
+		}
+	}
+	http.ServeContent(w, r, "image.sif", time.Time{}, bytes.NewReader(h.content))
+}
+
+func TestDownloadImage(t *testing.T) {
+	origBackoff, origMin, origChunk, origStall := downloadBackoff, parallelMinSize, chunkSize, downloadStallTimeout
+	t.Cleanup(func() {
+		downloadBackoff, parallelMinSize, chunkSize, downloadStallTimeout = origBackoff, origMin, origChunk, origStall
+	})
+	downloadBackoff = time.Millisecond
+	downloadStallTimeout = 100 * time.Millisecond
+
+	content := make([]byte, 1<<20)
+	if _, err := rand.Read(content); err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(content)
+	sum := hex.EncodeToString(digest[:])
+
+	tests := []struct {
+		name       string
+		failures   int32
+		stall      bool
+		parallel   bool
+		checksum   string
+		fragment   string
+		notFound   bool
+		wantErr    bool
+		wantGets   int32
+		wantRanges int32
+	}{
+		{
+			name:     "Simple",
+			wantGets: 1,
+		},
+		{
+			name:       "Resume",
+			failures:   1,
+			wantGets:   2,
+			wantRanges: 1,
+		},
+		{
+			name:       "Stall",
+			failures:   1,
+			stall:      true,
+			wantGets:   2,
+			wantRanges: 1,
+		},
+		{
+			name:       "Retry",
+			failures:   3,
+			wantGets:   4,
+			wantRanges: 3,
+		},
+		{
+			name:       "TooManyFailures",
+			failures:   downloadRetries,
+			wantErr:    true,
+			wantGets:   downloadRetries,
+			wantRanges: downloadRetries - 1,
+		},
+		{
+			name:       "Parallel",
+			parallel:   true,
+			failures:   1,
+			wantGets:   17,
+			wantRanges: 17,
+		},
+		{
+			name:     "ChecksumFlag",
+			checksum: "sha256:" + sum,
+			wantGets: 1,
+		},
+		{
+			name:     "ChecksumFragment",
+			fragment: "#sha256=" + sum,
+			wantGets: 1,
+		},
+		{
+			name:     "ChecksumMismatch",
+			checksum: sum[1:] + "0",
+			wantErr:  true,
+			wantGets: 1,
+		},
+		{
+			name:     "NotFound",
+			notFound: true,
+			wantErr:  true,
+			wantGets: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parallelMinSize, chunkSize = origMin, origChunk
+			if tt.parallel {
+				parallelMinSize, chunkSize = 1, 64<<10
+			}
+
+			h := &contentHandler{content: content, failures: tt.failures, stall: tt.stall}
+			var handler http.Handler = h
+			if tt.notFound {
+				handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.Method == http.MethodGet {
+						h.gets.Add(1)
+					}
+					http.NotFound(w, r)
+				})
+			}
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			dir := t.TempDir()
+			dest := filepath.Join(dir, "image.sif")
+			opts := DownloadOptions{Checksum: tt.checksum}
+			err := DownloadImage(t.Context(), dest, srv.URL+"/image.sif"+tt.fragment, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DownloadImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := h.gets.Load(); got != tt.wantGets {
+				t.Errorf("got %d GET requests, want %d", got, tt.wantGets)
+			}
+			if got := h.ranges.Load(); got != tt.wantRanges {
+				t.Errorf("got %d range requests, want %d", got, tt.wantRanges)
+			}
+
+			// The server provides no ETag or Last-Modified date, so an
+			// incomplete download cannot be resumed, and is removed.
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				if len(entries) > 0 {
+					t.Errorf("incomplete download %s was not removed", entries[0].Name())
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Errorf("got %d files in download directory, want 1", len(entries))
+			}
+			got, err := os.ReadFile(dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Errorf("downloaded content does not match")
+			}
+		})
+	}
+}
+
+func TestDownloadImageResume(t *testing.T) {
+	origBackoff := downloadBackoff
+	t.Cleanup(func() {
+		downloadBackoff = origBackoff
+	})
+	downloadBackoff = time.Millisecond
+
+	content := make([]byte, 1<<20)
+	if _, err := rand.Read(content); err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(content)
+	sum := hex.EncodeToString(digest[:])
+
+	tests := []struct {
+		name       string
+		etag       string
+		checksum   string
+		wantResume bool
+	}{
+		{
+			name:       "ETag",
+			etag:       `"v1"`,
+			wantResume: true,
+		},
+		{
+			name:       "Checksum",
+			checksum:   sum,
+			wantResume: true,
+		},
+		{
+			// A weak ETag does not identify the exact content of the file.
+			name:       "WeakETag",
+			etag:       `W/"v1"`,
+			wantResume: false,
+		},
+		{
+			name:       "NoValidator",
+			wantResume: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current atomic.Pointer[contentHandler]
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				current.Load().ServeHTTP(w, r)
+			}))
+			defer srv.Close()
+
+			dir := t.TempDir()
+			dest := filepath.Join(dir, "image.sif")
+			url := srv.URL + "/image.sif"
+			opts := DownloadOptions{Checksum: tt.checksum}
+
+			// The first download fails, after receiving 1024 bytes.
+			current.Store(&contentHandler{content: content, etag: tt.etag, failures: downloadRetries})
+			if err := DownloadImage(t.Context(), dest, url, opts); err == nil {
+				t.Fatalf("unexpected success")
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantEntries := 0
+			if tt.wantResume {
+				wantEntries = 1
+			}
+			if len(entries) != wantEntries {
+				t.Fatalf("got %d files after failed download, want %d", len(entries), wantEntries)
+			}
+
+			// The second download resumes from the data received by the first,
+			// if it was kept.
+			h := &contentHandler{content: content, etag: tt.etag}
+			current.Store(h)
+			if err := DownloadImage(t.Context(), dest, url, opts); err != nil {
+				t.Fatalf("DownloadImage() error = %v", err)
+			}
+			if got := h.gets.Load(); got != 1 {
+				t.Errorf("got %d GET requests, want 1", got)
+			}
+			if got := h.ranges.Load() == 1; got != tt.wantResume {
+				t.Errorf("download resumed = %v, want %v", got, tt.wantResume)
+			}
+
+			got, err := os.ReadFile(dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Errorf("downloaded content does not match")
+			}
+			entries, err = os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("got %d files in download directory, want 1", len(entries))
+			}
+		})
+	}
+}
@@ -7,28 +7,19 @@
 package net
 
 import (
-	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"net/http"
-	"os"
 	"regexp"
-	"strings"
 	"time"
 
 	"github.com/sylabs/singularity/v4/internal/pkg/cache"
-	"github.com/sylabs/singularity/v4/internal/pkg/client/progress"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/fs"
 	"github.com/sylabs/singularity/v4/pkg/sylog"
-	useragent "github.com/sylabs/singularity/v4/pkg/util/user-agent"
-	"golang.org/x/sys/unix"
 )
 
-// Timeout for an image pull in seconds - could be a large download...
-const pullTimeout = 1800
-
 // IsNetPullRef returns true if the provided string is a valid url
 // reference for a pull operation.
 func IsNetPullRef(netRef string) bool {
@@ -36,115 +27,41 @@ func IsNetPullRef(netRef string) bool {
 	return match
 }
 
-// DownloadImage will retrieve an image from an http(s) URI,
-// saving it into the specified file
-func DownloadImage(ctx context.Context, filePath string, netURL string) error {
-	if !IsNetPullRef(netURL) {
-		return fmt.Errorf("not a valid url reference: %s", netURL)
-	}
-	if filePath == "" {
-		refParts := strings.Split(netURL, "/")
-		filePath = refParts[len(refParts)-1]
-		sylog.Infof("Download filename not provided. Downloading to: %s\n", filePath)
-	}
-
-	url := netURL
-	sylog.Debugf("Pulling from URL: %s\n", url)
-
-	httpClient := &http.Client{
-		Timeout: pullTimeout * time.Second,
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("User-Agent", useragent.Value())
-
-	res, err := httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode == http.StatusNotFound {
-		return fmt.Errorf("the requested image was not found")
-	}
-
-	if res.StatusCode != http.StatusOK {
-		buf := new(bytes.Buffer)
-		buf.ReadFrom(res.Body)
-		s := buf.String()
-		return fmt.Errorf("download did not succeed: %d %s\n\t",
-			res.StatusCode, s)
-	}
-
-	sylog.Debugf("OK response received, beginning body download\n")
-
-	// Perms are 777 *prior* to umask
-	out, err := os.OpenFile(filePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|unix.O_NOFOLLOW, 0o777)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	pb := progress.BarCallback(ctx)
-
-	err = pb(res.ContentLength, res.Body, out)
-	if err != nil {
-		// Delete incomplete image file in the event of failure
-		// we get here e.g. if the context is canceled by Ctrl-C
-		res.Body.Close()
-		out.Close()
-		sylog.Infof("Cleaning up incomplete download: %s", filePath)
-		if err := os.Remove(filePath); err != nil {
-			sylog.Errorf("Error while removing incomplete download: %v", err)
-		}
-		return err
-	}
-
-	sylog.Debugf("Download complete\n")
-
-	return nil
+// PullOptions holds options for pulling an http(s) image.
+type PullOptions struct {
+	// Checksum is an optional sha256 digest, as "sha256:<hex>" or "<hex>", that
+	// the image must match.
+	Checksum string
 }
 
 // pull will pull a http(s) image into the cache if directTo="", or a specific file if directTo is set.
-func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string) (imagePath string, err error) {
-	// We will cache using a sha256 over the URL and the date of the file that
-	// is to be fetched, as returned by an HTTP HEAD call and the Last-Modified
-	// header. If no date is available, use the current date-time, which will
-	// effectively result in no caching.
-	imageDate := time.Now().String()
-
-	req, err := http.NewRequest("HEAD", pullFrom, nil)
+func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string, opts PullOptions) (imagePath string, err error) {
+	pullFrom, checksum, err := splitChecksum(pullFrom, opts.Checksum)
 	if err != nil {
-		sylog.Fatalf("Error constructing http request: %v\n", err)
+		return "", err
 	}
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		sylog.Fatalf("Error making http request: %v\n", err)
-	}
-	defer res.Body.Close()
-
-	headerDate := res.Header.Get("Last-Modified")
-	sylog.Debugf("HTTP Last-Modified header is: %s", headerDate)
-	if headerDate != "" {
-		imageDate = headerDate
-	}
-
-	h := sha256.New()
-	h.Write([]byte(pullFrom + imageDate))
-	hash := hex.EncodeToString(h.Sum(nil))
-	sylog.Debugf("Image hash for cache is: %s", hash)
+	dlOpts := DownloadOptions{Checksum: checksum}
 
 	if directTo != "" {
 		sylog.Infof("Downloading network image")
-		if err := DownloadImage(ctx, directTo, pullFrom); err != nil {
+		if err := DownloadImage(ctx, directTo, pullFrom, dlOpts); err != nil {
 			return "", fmt.Errorf("unable to Download Image: %v", err)
 		}
 		imagePath = directTo
 	} else {
+		// Where a checksum is provided, the image is cached by its content.
+		hash := checksum
+		if hash == "" {
+			hash, err = urlHash(ctx, pullFrom)
+			if err != nil {
+				// The download is retried, so a failure here need not be
+				// fatal, but the image cannot be found in the cache.
+				sylog.Warningf("Unable to check date of image, it will be downloaded again: %v", err)
+				hash = dateHash(pullFrom, time.Now().String())
+			}
+		}
+		sylog.Debugf("Image hash for cache is: %s", hash)
+
 		cacheEntry, err := imgCache.GetEntry(cache.NetCacheType, hash)
 		if err != nil {
 			return "", fmt.Errorf("unable to check if %v exists in cache: %v", hash, err)
@@ -153,9 +70,9 @@ func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string
 
 		if !cacheEntry.Exists {
 			sylog.Infof("Downloading network image")
-			err := DownloadImage(ctx, cacheEntry.TmpPath, pullFrom)
+			err := DownloadImage(ctx, cacheEntry.TmpPath, pullFrom, dlOpts)
 			if err != nil {
-				sylog.Fatalf("%v\n", err)
+				return "", fmt.Errorf("unable to Download Image: %v", err)
 			}
 
 			err = cacheEntry.Finalize()
@@ -173,23 +90,23 @@ func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string
 }
 
 // PullToCache will pull a http(s) image to the cache, returning the path to the cached image.
-func PullToCache(ctx context.Context, imgCache *cache.Handle, pullFrom string) (imagePath string, err error) {
+func PullToCache(ctx context.Context, imgCache *cache.Handle, pullFrom string, opts PullOptions) (imagePath string, err error) {
 	if imgCache.IsDisabled() {
 		return "", fmt.Errorf("cache is disabled, cannot pull to cache")
 	}
 
-	return pull(ctx, imgCache, "", pullFrom)
+	return pull(ctx, imgCache, "", pullFrom, opts)
 }
 
 // PullToFile will pull an http(s) image to the specified location, through the cache, or directly if cache is disabled
-func PullToFile(ctx context.Context, imgCache *cache.Handle, pullTo, pullFrom string) (imagePath string, err error) {
+func PullToFile(ctx context.Context, imgCache *cache.Handle, pullTo, pullFrom string, opts PullOptions) (imagePath string, err error) {
 	directTo := ""
 	if imgCache.IsDisabled() {
 		directTo = pullTo
 		sylog.Debugf("Cache disabled, pulling directly to: %s", directTo)
 	}
 
-	src, err := pull(ctx, imgCache, directTo, pullFrom)
+	src, err := pull(ctx, imgCache, directTo, pullFrom, opts)
 	if err != nil {
 		return "", fmt.Errorf("error fetching image: %v", err)
 	}
@@ -204,3 +121,36 @@ func PullToFile(ctx context.Context, imgCache *cache.Handle, pullTo, pullFrom st
 
 	return pullTo, nil
 }
+
+// urlHash returns a sha256 over pullFrom and the date of the file that is to
+// be fetched, as returned by an HTTP HEAD call and the Last-Modified header. If
+// no date is available, the current date-time is used, which will effectively
+// result in no caching. An error is returned if the HEAD call fails.
+func urlHash(ctx context.Context, pullFrom string) (string, error) {
+	imageDate := time.Now().String()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, pullFrom, nil)
+	if err != nil {
+		return "", fmt.Errorf("while constructing http request: %w", err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("while making http request: %w", err)
+	}
+	defer res.Body.Close()
+
+	headerDate := res.Header.Get("Last-Modified")
+	sylog.Debugf("HTTP Last-Modified header is: %s", headerDate)
+	if headerDate != "" {
+		imageDate = headerDate
+	}
+
+	return dateHash(pullFrom, imageDate), nil
+}
+
+// dateHash returns a sha256 over pullFrom and imageDate.
+func dateHash(pullFrom, imageDate string) string {
+	h := sha256.New()
+	h.Write([]byte(pullFrom + imageDate))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+//revive:disable:var-naming
+package net
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/sylabs/singularity/v4/internal/pkg/cache"
+)
+
+func TestPullToCacheHeadFailure(t *testing.T) {
+	content := []byte("IMAGE CONTENT")
+
+	// HEAD requests fail, but the image can be downloaded.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			panic(http.ErrAbortHandler)
+		}
+		w.Write(content)
+	}))
+	defer srv.Close()
+
+	imgCache, err := cache.New(cache.Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := PullToCache(t.Context(), imgCache, srv.URL+"/image.sif", PullOptions{})
+	if err != nil {
+		t.Fatalf("PullToCache() error = %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("pulled content = %q, want %q", got, content)
+	}
+}
@@ -1,5 +1,5 @@
 // Copyright (c) 2020, Control Command Inc. All rights reserved.
-// Copyright (c) 2018-2023, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -12,6 +12,7 @@ import (
 
 	"github.com/sylabs/singularity/v4/internal/pkg/build"
 	"github.com/sylabs/singularity/v4/internal/pkg/cache"
+	"github.com/sylabs/singularity/v4/internal/pkg/client/ocisif"
 	"github.com/sylabs/singularity/v4/internal/pkg/ociimage"
 	"github.com/sylabs/singularity/v4/internal/pkg/ociplatform"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/machine"
@@ -23,10 +24,20 @@ import (
 func pullNativeSIF(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string, opts PullOptions) (imagePath string, err error) {
 	to := transportOptions(opts)
 
-	hash, err := ociimage.ImageDigest(ctx, to, imgCache, pullFrom)
+	hash, mf, err := ociimage.ImageManifest(ctx, to, imgCache, pullFrom)
 	if err != nil {
 		return "", fmt.Errorf("failed to get checksum for %s: %s", pullFrom, err)
 	}
+	// Data containers are pulled as-is, to an OCI-SIF, as they cannot be
+	// converted to a native SIF.
+	isData, err := ocisif.IsDataContainer(pullFrom, mf)
+	if err != nil {
+		return "", err
+	}
+	if isData {
+		sylog.Infof("Pulling data container")
+		return ocisif.PullDataContainer(ctx, imgCache, directTo, pullFrom, ocisifPullOptions(opts))
+	}
 
 	if directTo != "" {
 		sylog.Infof("Converting OCI blobs to SIF format")
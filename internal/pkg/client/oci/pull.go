@@ -1,5 +1,5 @@
 // Copyright (c) 2020, Control Command Inc. All rights reserved.
-// Copyright (c) 2018-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -32,6 +32,11 @@ type PullOptions struct {
 	WithCosign  bool
 	Platform    gccrv1.Platform
 	ReqAuthFile string
+	// MultiPlatform, if set, pulls a multi-platform image index to an
+	// OCI-SIF, holding the images for Platforms, or for all platforms if
+	// Platforms is empty.
+	MultiPlatform bool
+	Platforms     []gccrv1.Platform
 }
 
 // transportOptions maps PullOptions to OCI image transport options
@@ -47,25 +52,35 @@ func transportOptions(opts PullOptions) *ociimage.TransportOptions {
 	}
 }
 
+// ocisifPullOptions maps PullOptions to OCI-SIF pull options.
+func ocisifPullOptions(opts PullOptions) ocisif.PullOptions {
+	return ocisif.PullOptions{
+		TmpDir:      opts.TmpDir,
+		OciAuth:     opts.OciAuth,
+		DockerHost:  opts.DockerHost,
+		NoHTTPS:     opts.NoHTTPS,
+		NoCleanUp:   opts.NoCleanUp,
+		Platform:    opts.Platform,
+		ReqAuthFile: opts.ReqAuthFile,
+		KeepLayers:  opts.KeepLayers,
+		WithCosign:  opts.WithCosign,
+
+		MultiPlatform: opts.MultiPlatform,
+		Platforms:     opts.Platforms,
+	}
+}
+
 // PullToCache will create a SIF / OCI-SIF image in the cache, and return the path to the cached image.
 func PullToCache(ctx context.Context, imgCache *cache.Handle, pullFrom string, opts PullOptions) (imagePath string, err error) {
 	if imgCache.IsDisabled() {
 		return "", fmt.Errorf("cache is disabled, cannot pull to cache")
 	}
+	if opts.MultiPlatform {
+		return "", fmt.Errorf("multi-platform images cannot be pulled to cache")
+	}
 
 	if opts.OciSif {
-		ocisifOpts := ocisif.PullOptions{
-			TmpDir:      opts.TmpDir,
-			OciAuth:     opts.OciAuth,
-			DockerHost:  opts.DockerHost,
-			NoHTTPS:     opts.NoHTTPS,
-			NoCleanUp:   opts.NoCleanUp,
-			Platform:    opts.Platform,
-			ReqAuthFile: opts.ReqAuthFile,
-			KeepLayers:  opts.KeepLayers,
-			WithCosign:  opts.WithCosign,
-		}
-		return ocisif.PullOCISIF(ctx, imgCache, "", pullFrom, ocisifOpts)
+		return ocisif.PullOCISIF(ctx, imgCache, "", pullFrom, ocisifPullOptions(opts))
 	}
 
 	return pullNativeSIF(ctx, imgCache, "", pullFrom, opts)
@@ -82,20 +97,16 @@ func PullToFile(ctx context.Context, imgCache *cache.Handle, pullTo, pullFrom st
 		directTo = pullTo
 		sylog.Infof("cosign signature functionality does not support SIF caching, pulling directly to: %s", directTo)
 	}
+	if opts.MultiPlatform {
+		if !opts.OciSif {
+			return "", fmt.Errorf("multi-platform images can only be pulled to OCI-SIF")
+		}
+		directTo = pullTo
+		sylog.Debugf("Multi-platform images are not cached, pulling directly to: %s", directTo)
+	}
 	src := ""
 	if opts.OciSif {
-		ocisifOpts := ocisif.PullOptions{
-			TmpDir:      opts.TmpDir,
-			OciAuth:     opts.OciAuth,
-			DockerHost:  opts.DockerHost,
-			NoHTTPS:     opts.NoHTTPS,
-			NoCleanUp:   opts.NoCleanUp,
-			Platform:    opts.Platform,
-			ReqAuthFile: opts.ReqAuthFile,
-			KeepLayers:  opts.KeepLayers,
-			WithCosign:  opts.WithCosign,
-		}
-		src, err = ocisif.PullOCISIF(ctx, imgCache, directTo, pullFrom, ocisifOpts)
+		src, err = ocisif.PullOCISIF(ctx, imgCache, directTo, pullFrom, ocisifPullOptions(opts))
 	} else {
 		src, err = pullNativeSIF(ctx, imgCache, directTo, pullFrom, opts)
 	}
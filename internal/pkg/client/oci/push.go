@@ -1,4 +1,4 @@
-// Copyright (c) 2023-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2023-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -31,6 +31,9 @@ type PushOptions struct {
 	// WithCosign sets whether to push any associated cosign signatures when
 	// pushing an OCI-SIF to a registry.
 	WithCosign bool
+	// Subject, if set, is a reference to an image in the same repository as
+	// the destination. The pushed image is attached to it as an OCI referrer.
+	Subject string
 }
 
 // Push pushes an image into an OCI registry, as an OCI image (not an ORAS artifact).
@@ -50,6 +53,7 @@ func Push(ctx context.Context, sourceFile string, destRef string, opts PushOptio
 			LayerFormat: opts.LayerFormat,
 			TmpDir:      opts.TmpDir,
 			WithCosign:  opts.WithCosign,
+			Subject:     opts.Subject,
 		}
 		return ocisif.PushOCISIF(ctx, sourceFile, destRef, ocisifOpts)
 	case image.SIF:
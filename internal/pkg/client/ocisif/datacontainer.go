@@ -0,0 +1,124 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package ocisif
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/sylabs/singularity/v4/internal/pkg/cache"
+	"github.com/sylabs/singularity/v4/internal/pkg/ociimage"
+	"github.com/sylabs/singularity/v4/internal/pkg/ocisif"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+)
+
+// IsDataContainer returns true if the image at pullFrom, with the raw image
+// manifest mf, is a data container. The manifest is that returned by
+// ociimage.ImageManifest, so that no additional requests are made to the
+// registry. Only OCI registry (docker://) references are data containers.
+func IsDataContainer(pullFrom string, mf []byte) (bool, error) {
+	srcType, _, err := ociimage.URItoSourceSinkRef(pullFrom)
+	if err != nil {
+		return false, err
+	}
+	// Data containers are pushed as a single manifest, never in an index, so
+	// the manifest is always present for a data container.
+	if srcType != ociimage.RegistrySourceSink || mf == nil {
+		return false, nil
+	}
+	return ocisif.IsDataContainerManifest(mf)
+}
+
+// PullDataContainer will write a data container from the OCI registry
+// (docker://) reference pullFrom to the data container cache if directTo="",
+// or a specific file if directTo is set. The data container is written as-is,
+// with its squashfs layers and manifest unmodified.
+func PullDataContainer(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string, opts PullOptions) (imagePath string, err error) {
+	tOpts := transportOptions(opts)
+	srcType, srcRef, err := ociimage.URItoSourceSinkRef(pullFrom)
+	if err != nil {
+		return "", err
+	}
+	if srcType != ociimage.RegistrySourceSink {
+		return "", fmt.Errorf("data containers can only be pulled from OCI registries")
+	}
+
+	if directTo != "" {
+		img, err := fetchDataContainer(ctx, tOpts, srcType, srcRef)
+		if err != nil {
+			return "", err
+		}
+		if err := writeDataContainer(img, directTo, opts.TmpDir); err != nil {
+			return "", fmt.Errorf("while writing data container: %w", err)
+		}
+		return directTo, nil
+	}
+
+	digest, err := ociimage.ImageDigest(ctx, tOpts, imgCache, pullFrom)
+	if err != nil {
+		return "", fmt.Errorf("failed to get digest for %s: %s", pullFrom, err)
+	}
+	cacheEntry, err := imgCache.GetEntry(cache.DataCacheType, digest.String())
+	if err != nil {
+		return "", fmt.Errorf("unable to check if %v exists in cache: %v", digest, err)
+	}
+	defer cacheEntry.CleanTmp()
+	if cacheEntry.Exists {
+		sylog.Infof("Using cached data container")
+		return cacheEntry.Path, nil
+	}
+
+	img, err := fetchDataContainer(ctx, tOpts, srcType, srcRef)
+	if err != nil {
+		return "", err
+	}
+	if err := writeDataContainer(img, cacheEntry.TmpPath, opts.TmpDir); err != nil {
+		return "", fmt.Errorf("while writing data container: %w", err)
+	}
+	if err := cacheEntry.Finalize(); err != nil {
+		return "", err
+	}
+	return cacheEntry.Path, nil
+}
+
+// fetchDataContainer returns the data container at srcRef, or an error if the
+// image at srcRef is not a data container.
+func fetchDataContainer(ctx context.Context, tOpts *ociimage.TransportOptions, srcType ociimage.SourceSink, srcRef string) (ggcrv1.Image, error) {
+	img, err := srcType.Image(ctx, srcRef, tOpts, nil)
+	if err != nil {
+		return nil, fmt.Errorf("while fetching data container: %w", err)
+	}
+	ok, err := ocisif.IsDataContainer(img)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s is not a data container", srcRef)
+	}
+	return img, nil
+}
+
+// writeDataContainer writes the data container img to an OCI-SIF at dest.
+func writeDataContainer(img ggcrv1.Image, dest, tmpDir string) error {
+	workDir, err := os.MkdirTemp(tmpDir, "data-container-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := fs.ForceRemoveAll(workDir); err != nil {
+			sylog.Warningf("Couldn't remove temporary directory %q: %v", workDir, err)
+		}
+	}()
+
+	w, err := ocisif.NewImageWriter(img, dest, workDir)
+	if err != nil {
+		return err
+	}
+	return w.Write()
+}
@@ -0,0 +1,304 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package ocisif
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	ggcrmutate "github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	ocimutate "github.com/sylabs/oci-tools/pkg/mutate"
+	"github.com/sylabs/sif/v2/pkg/sif"
+	"github.com/sylabs/singularity/v4/internal/pkg/cache"
+	"github.com/sylabs/singularity/v4/internal/pkg/ocisif"
+)
+
+// dataContainerImage returns a data container image, with a single squashfs
+// layer.
+func dataContainerImage(t *testing.T) ggcrv1.Image {
+	t.Helper()
+
+	img := ggcrmutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	img, err := ggcrmutate.AppendLayers(img, static.NewLayer([]byte("squashfs"), ocisif.SquashfsLayerMediaType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err = ocimutate.Apply(img,
+		ocimutate.SetConfig(struct{}{}, ocisif.EmptyConfigMediaType),
+		ocimutate.SetArtifactType(ocisif.DataContainerArtifactType),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// testRegistry starts a registry holding a data container, and an image that
+// is not a data container. It returns the host of the registry, and counters
+// of the manifest GET and HEAD requests made to it.
+func testRegistry(t *testing.T) (host string, gets, heads *atomic.Int64) {
+	t.Helper()
+
+	gets = &atomic.Int64{}
+	heads = &atomic.Int64{}
+	reg := registry.New()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/manifests/") {
+			switch r.Method {
+			case http.MethodGet:
+				gets.Add(1)
+			case http.MethodHead:
+				heads.Add(1)
+			}
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	im, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for repo, img := range map[string]ggcrv1.Image{
+		"data":  dataContainerImage(t),
+		"image": im,
+	} {
+		ref, err := name.ParseReference(u.Host + "/" + repo + ":latest")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := remote.Write(ref, img); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gets.Store(0)
+	heads.Store(0)
+	return u.Host, gets, heads
+}
+
+// checkDataContainer checks that path is an OCI-SIF holding the data container
+// want, unmodified.
+func checkDataContainer(t *testing.T, path string, want ggcrv1.Image) {
+	t.Helper()
+
+	fi, err := sif.LoadContainerFromPath(path, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fi.UnloadContainer()
+
+	img, err := ocisif.GetSingleImage(fi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := ocisif.IsDataContainer(img); err != nil || !ok {
+		t.Errorf("image is not a data container (err = %v)", err)
+	}
+	got, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDigest, err := want.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != wantDigest {
+		t.Errorf("got digest %v, want %v", got, wantDigest)
+	}
+}
+
+func TestIsDataContainer(t *testing.T) {
+	dataManifest, err := dataContainerImage(t).RawManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	im, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imageManifest, err := im.RawManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		pullFrom string
+		manifest []byte
+		want     bool
+		wantErr  bool
+	}{
+		{
+			name:     "DataContainer",
+			pullFrom: "docker://example.com/data:latest",
+			manifest: dataManifest,
+			want:     true,
+		},
+		{
+			name:     "Image",
+			pullFrom: "docker://example.com/image:latest",
+			manifest: imageManifest,
+			want:     false,
+		},
+		{
+			name:     "Index",
+			pullFrom: "docker://example.com/index:latest",
+			manifest: nil,
+			want:     false,
+		},
+		{
+			name:     "NotRegistry",
+			pullFrom: "oci:/path/to/layout",
+			manifest: dataManifest,
+			want:     false,
+		},
+		{
+			name:     "InvalidManifest",
+			pullFrom: "docker://example.com/data:latest",
+			manifest: []byte("{"),
+			wantErr:  true,
+		},
+		{
+			name:     "InvalidURI",
+			pullFrom: "invalid://example.com/data:latest",
+			manifest: dataManifest,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsDataContainer(tt.pullFrom, tt.manifest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IsDataContainer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("IsDataContainer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPullDataContainer(t *testing.T) {
+	host, _, _ := testRegistry(t)
+	want := dataContainerImage(t)
+
+	tests := []struct {
+		name     string
+		pullFrom string
+		wantErr  bool
+	}{
+		{
+			name:     "DataContainer",
+			pullFrom: "docker://" + host + "/data:latest",
+		},
+		{
+			name:     "Image",
+			pullFrom: "docker://" + host + "/image:latest",
+			wantErr:  true,
+		},
+		{
+			name:     "NotRegistry",
+			pullFrom: "oci:" + t.TempDir(),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := PullOptions{TmpDir: t.TempDir(), NoHTTPS: true}
+			dest := filepath.Join(t.TempDir(), "data.oci.sif")
+
+			path, err := PullDataContainer(context.Background(), nil, dest, tt.pullFrom, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PullDataContainer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if path != dest {
+				t.Errorf("got path %q, want %q", path, dest)
+			}
+			checkDataContainer(t, path, want)
+		})
+	}
+}
+
+func TestPullDataContainerCache(t *testing.T) {
+	host, gets, _ := testRegistry(t)
+	want := dataContainerImage(t)
+	pullFrom := "docker://" + host + "/data:latest"
+
+	imgCache, err := cache.New(cache.Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := PullOptions{TmpDir: t.TempDir(), NoHTTPS: true}
+
+	path, err := PullDataContainer(context.Background(), imgCache, "", pullFrom, opts)
+	if err != nil {
+		t.Fatalf("PullDataContainer() error = %v", err)
+	}
+	checkDataContainer(t, path, want)
+
+	// The data container, and its manifest, are now cached. A second pull
+	// must not GET the manifest from the registry.
+	gets.Store(0)
+	cachedPath, err := PullDataContainer(context.Background(), imgCache, "", pullFrom, opts)
+	if err != nil {
+		t.Fatalf("PullDataContainer() error = %v", err)
+	}
+	if cachedPath != path {
+		t.Errorf("got path %q, want cached path %q", cachedPath, path)
+	}
+	if n := gets.Load(); n != 0 {
+		t.Errorf("got %d manifest GET requests, want 0", n)
+	}
+}
+
+func TestPullOCISIFDataContainer(t *testing.T) {
+	host, _, heads := testRegistry(t)
+	want := dataContainerImage(t)
+
+	imgCache, err := cache.New(cache.Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := PullOptions{TmpDir: t.TempDir(), NoHTTPS: true}
+	dest := filepath.Join(t.TempDir(), "data.oci.sif")
+
+	// A data container is detected from the manifest retrieved to obtain the
+	// image digest, and pulled unmodified.
+	path, err := PullOCISIF(context.Background(), imgCache, dest, "docker://"+host+"/data:latest", opts)
+	if err != nil {
+		t.Fatalf("PullOCISIF() error = %v", err)
+	}
+	checkDataContainer(t, path, want)
+	if n := heads.Load(); n != 1 {
+		t.Errorf("got %d manifest HEAD requests, want 1", n)
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2023-2025 Sylabs Inc. All rights reserved.
+// Copyright (c) 2023-2026 Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -11,12 +11,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/match"
+	ggcrmutate "github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	cosignremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
@@ -31,7 +34,9 @@ import (
 	"github.com/sylabs/singularity/v4/internal/pkg/ocisif"
 	"github.com/sylabs/singularity/v4/internal/pkg/remote/credential/ociauth"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/fs"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs/squashfs"
 	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/cryptkey"
 	useragent "github.com/sylabs/singularity/v4/pkg/util/user-agent"
 	"golang.org/x/term"
 )
@@ -50,15 +55,43 @@ type PullOptions struct {
 	ReqAuthFile string
 	KeepLayers  bool
 	WithCosign  bool
+	// EncryptionKeyInfo, if set, is the key material used to encrypt the
+	// layers of the OCI-SIF.
+	EncryptionKeyInfo *cryptkey.KeyInfo
+	// MultiPlatform, if set, pulls a multi-platform image index into the
+	// OCI-SIF, holding the images for Platforms, or for all platforms if
+	// Platforms is empty. Platform is ignored.
+	MultiPlatform bool
+	Platforms     []ggcrv1.Platform
+	// SquashfsCompression, if set, is the compression of the squashfs layers
+	// of the OCI-SIF.
+	SquashfsCompression squashfs.Compression
+	// Excludes are mksquashfs wildcard patterns of paths to omit from the
+	// squashfs layers of the OCI-SIF.
+	Excludes []string
 }
 
-// PullOCISIF will create an OCI-SIF image in the cache if directTo="", or a specific file if directTo is set.
-func PullOCISIF(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string, opts PullOptions) (imagePath string, err error) {
-	if opts.WithCosign && directTo == "" {
-		return "", fmt.Errorf("cosign signatures cannot be pulled through the OCI-SIF cache")
+// imageWriterOpts maps PullOptions to OCI-SIF image writer options.
+func imageWriterOpts(opts PullOptions) []ocisif.ImageWriterOpt {
+	iwOpts := []ocisif.ImageWriterOpt{ocisif.WithSquashFSLayers(true)}
+	if !opts.KeepLayers {
+		iwOpts = append(iwOpts, ocisif.WithSquash(true))
+	}
+	if opts.EncryptionKeyInfo != nil {
+		iwOpts = append(iwOpts, ocisif.WithEncryption(opts.EncryptionKeyInfo))
 	}
+	if !opts.SquashfsCompression.IsDefault() {
+		iwOpts = append(iwOpts, ocisif.WithSquashfsCompression(opts.SquashfsCompression))
+	}
+	if len(opts.Excludes) > 0 {
+		iwOpts = append(iwOpts, ocisif.WithExcludes(opts.Excludes))
+	}
+	return iwOpts
+}
 
-	tOpts := &ociimage.TransportOptions{
+// transportOptions maps PullOptions to OCI image transport options.
+func transportOptions(opts PullOptions) *ociimage.TransportOptions {
+	return &ociimage.TransportOptions{
 		AuthConfig:       opts.OciAuth,
 		AuthFilePath:     ociauth.ChooseAuthFile(opts.ReqAuthFile),
 		Insecure:         opts.NoHTTPS,
@@ -67,11 +100,44 @@ func PullOCISIF(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom
 		DockerDaemonHost: opts.DockerHost,
 		Platform:         opts.Platform,
 	}
+}
+
+// PullOCISIF will create an OCI-SIF image in the cache if directTo="", or a specific file if directTo is set.
+func PullOCISIF(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string, opts PullOptions) (imagePath string, err error) {
+	if opts.WithCosign && directTo == "" {
+		return "", fmt.Errorf("cosign signatures cannot be pulled through the OCI-SIF cache")
+	}
+	if opts.EncryptionKeyInfo != nil && directTo == "" {
+		return "", fmt.Errorf("encrypted OCI-SIF images cannot be created in the OCI-SIF cache")
+	}
+	if (!opts.SquashfsCompression.IsDefault() || len(opts.Excludes) > 0) && directTo == "" {
+		return "", fmt.Errorf("OCI-SIF images with non-default squashfs compression or excludes cannot be created in the OCI-SIF cache")
+	}
+
+	tOpts := transportOptions(opts)
+
+	if opts.MultiPlatform {
+		if directTo == "" {
+			return "", fmt.Errorf("multi-platform OCI-SIF images cannot be created in the OCI-SIF cache")
+		}
+		if err := createMultiPlatformOciSif(ctx, tOpts, pullFrom, directTo, opts); err != nil {
+			return "", fmt.Errorf("while creating multi-platform OCI-SIF: %w", err)
+		}
+		return directTo, nil
+	}
 
-	hash, err := ociimage.ImageDigest(ctx, tOpts, imgCache, pullFrom)
+	hash, mf, err := ociimage.ImageManifest(ctx, tOpts, imgCache, pullFrom)
 	if err != nil {
 		return "", fmt.Errorf("failed to get digest for %s: %s", pullFrom, err)
 	}
+	isData, err := IsDataContainer(pullFrom, mf)
+	if err != nil {
+		return "", err
+	}
+	if isData {
+		sylog.Infof("Pulling data container")
+		return PullDataContainer(ctx, imgCache, directTo, pullFrom, opts)
+	}
 
 	if directTo != "" {
 		if err := createOciSif(ctx, tOpts, imgCache, pullFrom, directTo, opts); err != nil {
@@ -144,10 +210,7 @@ func createOciSif(ctx context.Context, tOpts *ociimage.TransportOptions, imgCach
 		return fmt.Errorf("while fetching OCI image: %w", err)
 	}
 
-	iwOpts := []ocisif.ImageWriterOpt{ocisif.WithSquashFSLayers(true)}
-	if !opts.KeepLayers {
-		iwOpts = append(iwOpts, ocisif.WithSquash(true))
-	}
+	iwOpts := imageWriterOpts(opts)
 	w, err := ocisif.NewImageWriter(img, imageDest, tmpDir, iwOpts...)
 	if err != nil {
 		return err
@@ -157,6 +220,10 @@ func createOciSif(ctx context.Context, tOpts *ociimage.TransportOptions, imgCach
 	}
 
 	if opts.WithCosign {
+		if opts.EncryptionKeyInfo != nil {
+			sylog.Warningf("Not fetching cosign signatures: encrypting layers invalidates signatures")
+			return nil
+		}
 		if err := canPullSignatures(img, opts.KeepLayers); err != nil {
 			sylog.Warningf("Not fetching cosign signatures: %v", err)
 			return nil
@@ -167,6 +234,69 @@ func createOciSif(ctx context.Context, tOpts *ociimage.TransportOptions, imgCach
 	return nil
 }
 
+// createMultiPlatformOciSif will convert the images in a multi-platform image
+// index, from an OCI registry, into an OCI-SIF image with squashfs layers,
+// holding the index.
+func createMultiPlatformOciSif(ctx context.Context, tOpts *ociimage.TransportOptions, imageSrc, imageDest string, opts PullOptions) error {
+	srcType, srcRef, err := ociimage.URItoSourceSinkRef(imageSrc)
+	if err != nil {
+		return err
+	}
+	if srcType != ociimage.RegistrySourceSink {
+		return fmt.Errorf("multi-platform images can only be pulled from OCI registries")
+	}
+	ref, ok := srcType.Reference(srcRef, tOpts)
+	if !ok {
+		return fmt.Errorf("invalid reference %q", srcRef)
+	}
+	if opts.WithCosign {
+		sylog.Warningf("Not fetching cosign signatures: not supported for multi-platform images")
+	}
+
+	ii, err := remote.Index(ref,
+		remote.WithContext(ctx),
+		remote.WithUserAgent(tOpts.UserAgent),
+		ociauth.AuthOptn(tOpts.AuthConfig, tOpts.AuthFilePath),
+	)
+	if err != nil {
+		return fmt.Errorf("while fetching image index: %w", err)
+	}
+
+	// Keep only the images for the requested platforms.
+	m := ociplatform.Matcher(opts.Platforms...)
+	ii = ggcrmutate.RemoveManifests(ii, func(d ggcrv1.Descriptor) bool { return !m(d) })
+	im, err := ii.IndexManifest()
+	if err != nil {
+		return err
+	}
+	if len(im.Manifests) == 0 {
+		return fmt.Errorf("image index has no images for the requested platforms")
+	}
+	for _, p := range opts.Platforms {
+		if !slices.ContainsFunc(im.Manifests, func(d ggcrv1.Descriptor) bool { return d.Platform.Satisfies(p) }) {
+			return fmt.Errorf("image index has no image for platform %s", p)
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp(opts.TmpDir, "oci-sif-tmp-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		sylog.Infof("Cleaning up.")
+		if err := fs.ForceRemoveAll(tmpDir); err != nil {
+			sylog.Warningf("Couldn't remove oci-sif temporary directory %q: %v", tmpDir, err)
+		}
+	}()
+
+	iwOpts := imageWriterOpts(opts)
+	w, err := ocisif.NewIndexWriter(ii, imageDest, tmpDir, iwOpts...)
+	if err != nil {
+		return err
+	}
+	return w.Write()
+}
+
 func canPullSignatures(img ggcrv1.Image, keepLayers bool) error {
 	layers, err := img.Layers()
 	if err != nil {
@@ -254,9 +384,16 @@ type PushOptions struct {
 	// WithCosign controls whether cosign signatures present in the SIF are also
 	// pushed to the destination repository in the registry.
 	WithCosign bool
+	// Subject is an optional reference to an image in the destination
+	// repository. If set, the pushed image is attached to it as an OCI
+	// referrer, e.g. to associate a data container with the application image
+	// that consumes it.
+	Subject string
 }
 
 // PushOCISIF pushes a single image from sourceFile to the OCI registry destRef.
+// If sourceFile holds a multi-platform image index, the index is pushed as a
+// manifest list.
 func PushOCISIF(ctx context.Context, sourceFile, destRef string, opts PushOptions) error {
 	destRef = strings.TrimPrefix(destRef, "docker://")
 	destRef = strings.TrimPrefix(destRef, "//")
@@ -265,6 +402,19 @@ func PushOCISIF(ctx context.Context, sourceFile, destRef string, opts PushOption
 		return fmt.Errorf("invalid reference %q: %w", destRef, err)
 	}
 
+	fi, err := sif.LoadContainerFromPath(sourceFile, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		return fmt.Errorf("failed to open OCI-SIF: %w", err)
+	}
+	defer fi.UnloadContainer()
+	ii, err := ocisif.GetMultiPlatformIndex(fi)
+	if err != nil {
+		return fmt.Errorf("while reading OCI-SIF: %w", err)
+	}
+	if ii != nil {
+		return pushIndex(ctx, ir, ii, opts)
+	}
+
 	if err := handleOverlay(sourceFile, opts); err != nil {
 		return err
 	}
@@ -282,6 +432,14 @@ func PushOCISIF(ctx context.Context, sourceFile, destRef string, opts PushOption
 		return fmt.Errorf("failed to retrieve image: %w", err)
 	}
 
+	isData, err := ocisif.IsDataContainer(image)
+	if err != nil {
+		return err
+	}
+	if isData && opts.LayerFormat == TarLayerFormat {
+		return fmt.Errorf("data containers can only be pushed with squashfs layers")
+	}
+
 	image, err = transformLayers(image, opts)
 	if err != nil {
 		return err
@@ -292,6 +450,13 @@ func PushOCISIF(ctx context.Context, sourceFile, destRef string, opts PushOption
 		remote.WithUserAgent(useragent.Value()),
 		remote.WithContext(ctx),
 	}
+
+	if opts.Subject != "" {
+		image, err = setSubject(image, ir, opts, remoteOpts)
+		if err != nil {
+			return err
+		}
+	}
 	if term.IsTerminal(2) {
 		pb := &progress.DownloadBar{}
 		progChan := make(chan ggcrv1.Update, 1)
@@ -332,6 +497,88 @@ func PushOCISIF(ctx context.Context, sourceFile, destRef string, opts PushOption
 	return nil
 }
 
+// pushIndex pushes the multi-platform image index ii, from an OCI-SIF, to the
+// OCI registry as a manifest list at ir. Layers of each image are transformed
+// according to opts.LayerFormat.
+func pushIndex(ctx context.Context, ir name.Reference, ii ggcrv1.ImageIndex, opts PushOptions) error {
+	if opts.WithCosign {
+		return errors.New("cosign signatures cannot be pushed for multi-platform images")
+	}
+	if opts.Subject != "" {
+		return errors.New("a subject cannot be set for multi-platform images")
+	}
+
+	im, err := ii.IndexManifest()
+	if err != nil {
+		return fmt.Errorf("while getting index manifest: %w", err)
+	}
+	adds := []ggcrmutate.IndexAddendum{}
+	for _, d := range im.Manifests {
+		if !d.MediaType.IsImage() {
+			continue
+		}
+		img, err := ii.Image(d.Digest)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve image %s: %w", d.Digest, err)
+		}
+		img, err = transformLayers(img, opts)
+		if err != nil {
+			return err
+		}
+		if d.Platform != nil {
+			sylog.Infof("Pushing image for platform %s", d.Platform)
+		}
+		adds = append(adds, ggcrmutate.IndexAddendum{
+			Add: img,
+			Descriptor: ggcrv1.Descriptor{
+				Platform:    d.Platform,
+				Annotations: d.Annotations,
+			},
+		})
+	}
+
+	remoteOpts := []remote.Option{
+		ociauth.AuthOptn(opts.Auth, opts.AuthFile),
+		remote.WithUserAgent(useragent.Value()),
+		remote.WithContext(ctx),
+	}
+	return remote.WriteIndex(ir, ggcrmutate.AppendManifests(empty.Index, adds...), remoteOpts...)
+}
+
+// setSubject sets the subject of img, which will be pushed to ir, to the image
+// opts.Subject, so that img is an OCI referrer of the subject image.
+func setSubject(img ggcrv1.Image, ir name.Reference, opts PushOptions, remoteOpts []remote.Option) (ggcrv1.Image, error) {
+	if opts.WithCosign {
+		return nil, errors.New("cannot push signature - invalidated by setting subject")
+	}
+
+	subject := strings.TrimPrefix(opts.Subject, "docker://")
+	subject = strings.TrimPrefix(subject, "//")
+	sr, err := name.ParseReference(subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject reference %q: %w", subject, err)
+	}
+	if sr.Context().Name() != ir.Context().Name() {
+		return nil, fmt.Errorf("subject %s must be in the destination repository %s", sr.Name(), ir.Context().Name())
+	}
+
+	desc, err := remote.Head(sr, remoteOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("while fetching subject %s: %w", sr.Name(), err)
+	}
+	sylog.Infof("Attaching to subject %s@%s", sr.Context().Name(), desc.Digest)
+
+	si, ok := ggcrmutate.Subject(img, ggcrv1.Descriptor{
+		MediaType: desc.MediaType,
+		Size:      desc.Size,
+		Digest:    desc.Digest,
+	}).(ggcrv1.Image)
+	if !ok {
+		return nil, errors.New("internal error: could not set subject of image")
+	}
+	return si, nil
+}
+
 func transformLayers(base ggcrv1.Image, opts PushOptions) (ggcrv1.Image, error) {
 	ls, err := base.Layers()
 	if err != nil {
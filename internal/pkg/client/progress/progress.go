@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2023, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -130,6 +130,23 @@ func (dpb *DownloadBar) IncrBy(n int) {
 	dpb.bar.IncrBy(n)
 }
 
+// SetCurrent sets the progress of the bar to n, e.g. when a download restarts.
+func (dpb *DownloadBar) SetCurrent(n int64) {
+	if dpb.bar == nil {
+		return
+	}
+	dpb.bar.SetCurrent(n)
+}
+
+// SetTotal sets the total size of the bar. If complete is true, the bar is
+// marked as complete, which is required for a bar of unknown size.
+func (dpb *DownloadBar) SetTotal(total int64, complete bool) {
+	if dpb.bar == nil {
+		return
+	}
+	dpb.bar.SetTotal(total, complete)
+}
+
 func (dpb *DownloadBar) Abort(drop bool) {
 	if dpb.bar == nil {
 		return
@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -16,6 +16,7 @@ import (
 
 	"github.com/sylabs/singularity/v4/internal/pkg/util/user"
 	"github.com/sylabs/singularity/v4/pkg/syfs"
+	"github.com/sylabs/singularity/v4/pkg/util/fs/lock"
 )
 
 const (
@@ -31,6 +32,7 @@ const (
 	instancePath    = "instances"
 	authorizedChars = `^[a-zA-Z0-9._-]+$`
 	prognameFormat  = "%s: %s [%s]"
+	conmonProgName  = "conmon"
 )
 
 // File represents an instance file storing instance information
@@ -47,6 +49,15 @@ type File struct {
 	IP         string `json:"ip"`
 	LogErrPath string `json:"logErrPath"`
 	LogOutPath string `json:"logOutPath"`
+	// ContainerID is the runc/crun container ID of an instance started in
+	// OCI-mode. It is empty for native runtime instances.
+	ContainerID string `json:"containerID,omitempty"`
+	// RestartPolicy is the policy applied when the instance process exits,
+	// in the form accepted by ParseRestartPolicy.
+	RestartPolicy string `json:"restartPolicy,omitempty"`
+	// Health holds the result of health checks, if a health check command
+	// was specified when the instance was started.
+	Health *Health `json:"health,omitempty"`
 }
 
 // ProcName returns process name based on instance name
@@ -185,6 +196,12 @@ func List(username string, name string, subDir string) ([]*File, error) {
 	return list, nil
 }
 
+// IsOCI returns true if the instance was started in OCI-mode, i.e. it is
+// managed by conmon and an OCI runtime rather than by starter.
+func (i *File) IsOCI() bool {
+	return i.ContainerID != ""
+}
+
 // Delete deletes instance file
 func (i *File) Delete() error {
 	dir := filepath.Dir(i.Path)
@@ -219,6 +236,11 @@ func (i *File) isExited() bool {
 			// for process presence
 			return syscall.Kill(i.PPid, 0) == syscall.ESRCH
 		}
+		// OCI-mode instances are parented by the conmon monitor process
+		if i.IsOCI() {
+			argv0, _, _ := strings.Cut(string(d), "\x00")
+			return filepath.Base(argv0) != conmonProgName
+		}
 		// not an instance master process
 		return !strings.HasPrefix(string(d), ProgPrefix)
 	}
@@ -226,32 +248,94 @@ func (i *File) isExited() bool {
 	return false
 }
 
-// Update stores instance information in associated instance file
+// Update stores instance information in associated instance file. An
+// exclusive lock is held on the instance directory while the file is written.
 func (i *File) Update() error {
-	b, err := json.Marshal(i)
+	unlock, err := i.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return i.write()
+}
+
+// Modify reads the current content of the instance file, applies fn to it,
+// and stores the result. The instance directory is locked throughout, so that
+// changes made concurrently by other processes are not lost.
+func (i *File) Modify(fn func(*File)) error {
+	unlock, err := i.lock()
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
+	b, err := os.ReadFile(i.Path)
+	if err != nil {
+		return err
+	}
+	f := &File{}
+	if err := json.Unmarshal(b, f); err != nil {
+		return fmt.Errorf("failed to read instance file %s: %s", i.Path, err)
+	}
+	f.Path = i.Path
+
+	fn(f)
+	if err := f.write(); err != nil {
+		return err
+	}
+	*i = *f
+	return nil
+}
+
+// lock takes an exclusive lock on the instance directory, creating it if
+// necessary. The returned function releases the lock.
+func (i *File) lock() (func(), error) {
 	path := filepath.Dir(i.Path)
 
 	oldumask := syscall.Umask(0)
 	defer syscall.Umask(oldumask)
 
 	if err := os.MkdirAll(path, 0o700); err != nil {
+		return nil, err
+	}
+	fd, err := lock.Exclusive(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock instance directory %s: %s", path, err)
+	}
+	return func() { lock.Release(fd) }, nil
+}
+
+// write stores the instance file. The content is written to a temporary file
+// that is then renamed over the instance file, so that readers never see a
+// partially written file.
+func (i *File) write() error {
+	b, err := json.Marshal(i)
+	if err != nil {
 		return err
 	}
-	file, err := os.OpenFile(i.Path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|syscall.O_NOFOLLOW, 0o644)
+
+	file, err := os.CreateTemp(filepath.Dir(i.Path), "."+filepath.Base(i.Path)+".*")
 	if err != nil {
 		return err
 	}
+	defer os.Remove(file.Name())
 	defer file.Close()
 
+	if err := file.Chmod(0o644); err != nil {
+		return err
+	}
 	if _, err := file.Write(b); err != nil {
 		return fmt.Errorf("failed to write instance file %s: %s", i.Path, err)
 	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
 
-	return file.Sync()
+	return os.Rename(file.Name(), i.Path)
 }
 
 // GetLogFilePaths returns the paths of log files containing
@@ -1,4 +1,4 @@
-// Copyright (c) 2019, Sylabs Inc. All rights reserved.
+// Copyright (c) 2019-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -10,6 +10,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/sylabs/singularity/v4/internal/pkg/test"
 )
@@ -251,6 +252,108 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestIsExitedOCI(t *testing.T) {
+	// spawn a fake conmon process
+	cmd := exec.Command("cat")
+	cmd.StdinPipe()
+	cmd.Args = []string{"/usr/bin/conmon"}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("while starting fake conmon process: %s", err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	tests := []struct {
+		name       string
+		ppid       int
+		wantExited bool
+	}{
+		{
+			name:       "conmon parent",
+			ppid:       cmd.Process.Pid,
+			wantExited: false,
+		},
+		{
+			name:       "starter parent",
+			ppid:       fakeInstancePid,
+			wantExited: true,
+		},
+		{
+			name:       "no parent",
+			ppid:       0,
+			wantExited: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &File{
+				Name:        "oci",
+				PPid:        tt.ppid,
+				ContainerID: "0b2c3d4e-oci",
+			}
+			if !file.IsOCI() {
+				t.Errorf("expected OCI instance")
+			}
+			if got := file.isExited(); got != tt.wantExited {
+				t.Errorf("isExited() = %v, want %v", got, tt.wantExited)
+			}
+		})
+	}
+}
+
+func TestModify(t *testing.T) {
+	file := &File{
+		Name: "modify",
+		Path: filepath.Join(t.TempDir(), "modify", "modify.json"),
+	}
+	if err := file.Update(); err != nil {
+		t.Fatalf("unexpected error while writing instance file: %s", err)
+	}
+
+	// Concurrent modifications must not be lost.
+	const n = 20
+	errs := make(chan error, n)
+	for range n {
+		go func() {
+			f := &File{Path: file.Path}
+			errs <- f.Modify(func(f *File) {
+				if f.Health == nil {
+					f.Health = &Health{Status: HealthStarting}
+				}
+				f.Health.Record(1, "", time.Now())
+			})
+		}()
+	}
+	for range n {
+		if err := <-errs; err != nil {
+			t.Errorf("unexpected error while modifying instance file: %s", err)
+		}
+	}
+
+	f := &File{Path: file.Path}
+	if err := f.Modify(func(*File) {}); err != nil {
+		t.Fatalf("unexpected error while reading instance file: %s", err)
+	}
+	if f.Name != file.Name {
+		t.Errorf("got name %q, want %q", f.Name, file.Name)
+	}
+	if f.Health == nil || f.Health.FailingStreak != n {
+		t.Errorf("got health %+v, want failing streak %d", f.Health, n)
+	}
+
+	// No temporary files are left behind.
+	entries, err := os.ReadDir(filepath.Dir(file.Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d entries in instance directory, want 1", len(entries))
+	}
+}
+
 func TestMain(m *testing.M) {
 	// spawn a fake instance process
 	cmd := exec.Command("cat")
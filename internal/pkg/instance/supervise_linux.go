@@ -0,0 +1,183 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package instance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// RestartNo never restarts the instance process.
+	RestartNo = "no"
+	// RestartOnFailure restarts the instance process when it exits with a
+	// non-zero status, or is killed by a signal.
+	RestartOnFailure = "on-failure"
+	// RestartAlways restarts the instance process whenever it exits.
+	RestartAlways = "always"
+)
+
+// RestartPolicy determines whether the process of an instance is restarted
+// when it exits.
+type RestartPolicy struct {
+	// Mode is one of RestartNo, RestartOnFailure, or RestartAlways.
+	Mode string
+	// MaxRetries limits the number of restarts for RestartOnFailure. Zero
+	// places no limit on restarts.
+	MaxRetries int
+}
+
+// ParseRestartPolicy parses a restart policy in the form `no`, `always`,
+// `on-failure` or `on-failure:<max retries>`. An empty string is equivalent
+// to `no`.
+func ParseRestartPolicy(s string) (RestartPolicy, error) {
+	mode, retries, hasRetries := strings.Cut(s, ":")
+	switch mode {
+	case "", RestartNo, RestartAlways:
+		if hasRetries {
+			return RestartPolicy{}, fmt.Errorf("maximum retries can only be set for the %s restart policy", RestartOnFailure)
+		}
+		if mode == "" {
+			mode = RestartNo
+		}
+		return RestartPolicy{Mode: mode}, nil
+	case RestartOnFailure:
+		p := RestartPolicy{Mode: mode}
+		if hasRetries {
+			n, err := strconv.Atoi(retries)
+			if err != nil || n < 1 {
+				return RestartPolicy{}, fmt.Errorf("invalid maximum retries %q, must be a positive integer", retries)
+			}
+			p.MaxRetries = n
+		}
+		return p, nil
+	default:
+		return RestartPolicy{}, fmt.Errorf("invalid restart policy %q, must be one of %s, %s, %s[:N]", s, RestartNo, RestartAlways, RestartOnFailure)
+	}
+}
+
+// String returns the restart policy in the form accepted by
+// ParseRestartPolicy.
+func (p RestartPolicy) String() string {
+	if p.Mode == RestartOnFailure && p.MaxRetries > 0 {
+		return fmt.Sprintf("%s:%d", p.Mode, p.MaxRetries)
+	}
+	if p.Mode == "" {
+		return RestartNo
+	}
+	return p.Mode
+}
+
+// ShouldRestart returns true if a process that exited with status should be
+// restarted, given that it has already been restarted restarts times.
+func (p RestartPolicy) ShouldRestart(status syscall.WaitStatus, restarts int) bool {
+	switch p.Mode {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		if status.Exited() && status.ExitStatus() == 0 {
+			return false
+		}
+		return p.MaxRetries == 0 || restarts < p.MaxRetries
+	default:
+		return false
+	}
+}
+
+const (
+	// MinRestartDelay is the delay before an instance process is first
+	// restarted.
+	MinRestartDelay = time.Second
+	// MaxRestartDelay is the maximum delay between instance process restarts.
+	MaxRestartDelay = 30 * time.Second
+)
+
+// Restarter applies a RestartPolicy to the successive exits of an instance
+// process. Restarts back off exponentially, from MinRestartDelay to
+// MaxRestartDelay, unless the process ran for long enough to be considered
+// to have started successfully.
+type Restarter struct {
+	policy   RestartPolicy
+	restarts int
+	delay    time.Duration
+}
+
+// NewRestarter returns a Restarter for policy p.
+func NewRestarter(p RestartPolicy) *Restarter {
+	return &Restarter{policy: p, delay: MinRestartDelay}
+}
+
+// Exited is called when the process exits with status, after running for
+// ranFor. It returns whether the process should be restarted, and the delay
+// before restarting it. Each restart counts against the maximum retries of
+// the policy.
+func (r *Restarter) Exited(status syscall.WaitStatus, ranFor time.Duration) (time.Duration, bool) {
+	if !r.policy.ShouldRestart(status, r.restarts) {
+		return 0, false
+	}
+	if ranFor > MaxRestartDelay {
+		r.delay = MinRestartDelay
+	}
+	delay := r.delay
+	r.delay = min(r.delay*2, MaxRestartDelay)
+	r.restarts++
+	return delay, true
+}
+
+const (
+	// HealthStarting indicates that no health check has completed yet.
+	HealthStarting = "starting"
+	// HealthHealthy indicates that the last health check succeeded.
+	HealthHealthy = "healthy"
+	// HealthUnhealthy indicates that the health check has failed
+	// HealthRetries times in succession.
+	HealthUnhealthy = "unhealthy"
+
+	// HealthRetries is the number of consecutive failed health checks after
+	// which an instance is considered unhealthy.
+	HealthRetries = 3
+	// healthOutputMax is the maximum length of health check output that is
+	// stored in the instance file.
+	healthOutputMax = 1024
+)
+
+// Health holds the result of instance health checks.
+type Health struct {
+	// Status is one of HealthStarting, HealthHealthy, or HealthUnhealthy.
+	Status string `json:"status"`
+	// FailingStreak is the number of consecutive failed checks.
+	FailingStreak int `json:"failingStreak"`
+	// LastCheck is the time at which the last check completed.
+	LastCheck time.Time `json:"lastCheck,omitzero"`
+	// LastExitCode is the exit code of the last check.
+	LastExitCode int `json:"lastExitCode"`
+	// LastOutput holds the (truncated) output of the last check.
+	LastOutput string `json:"lastOutput,omitempty"`
+}
+
+// Record updates the health status with the result of a check, which exited
+// with exitCode and produced output.
+func (h *Health) Record(exitCode int, output string, at time.Time) {
+	if len(output) > healthOutputMax {
+		output = output[:healthOutputMax]
+	}
+	h.LastCheck = at
+	h.LastExitCode = exitCode
+	h.LastOutput = output
+
+	if exitCode == 0 {
+		h.FailingStreak = 0
+		h.Status = HealthHealthy
+		return
+	}
+	h.FailingStreak++
+	if h.FailingStreak >= HealthRetries {
+		h.Status = HealthUnhealthy
+	}
+}
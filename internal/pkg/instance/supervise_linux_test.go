@@ -0,0 +1,189 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package instance
+
+import (
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestParseRestartPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		want    RestartPolicy
+		wantErr bool
+	}{
+		{name: "Empty", policy: "", want: RestartPolicy{Mode: RestartNo}},
+		{name: "No", policy: "no", want: RestartPolicy{Mode: RestartNo}},
+		{name: "Always", policy: "always", want: RestartPolicy{Mode: RestartAlways}},
+		{name: "OnFailure", policy: "on-failure", want: RestartPolicy{Mode: RestartOnFailure}},
+		{name: "OnFailureRetries", policy: "on-failure:3", want: RestartPolicy{Mode: RestartOnFailure, MaxRetries: 3}},
+		{name: "OnFailureZeroRetries", policy: "on-failure:0", wantErr: true},
+		{name: "OnFailureBadRetries", policy: "on-failure:x", wantErr: true},
+		{name: "AlwaysRetries", policy: "always:3", wantErr: true},
+		{name: "Unknown", policy: "sometimes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRestartPolicy(tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if tt.policy != "" && got.String() != tt.policy {
+				t.Errorf("String() returned %q, want %q", got.String(), tt.policy)
+			}
+		})
+	}
+}
+
+func TestShouldRestart(t *testing.T) {
+	// WaitStatus values, as encoded by the kernel.
+	exited := func(code int) syscall.WaitStatus { return syscall.WaitStatus(code << 8) }
+	killed := syscall.WaitStatus(syscall.SIGKILL)
+
+	tests := []struct {
+		name     string
+		policy   RestartPolicy
+		status   syscall.WaitStatus
+		restarts int
+		want     bool
+	}{
+		{name: "NoFailure", policy: RestartPolicy{Mode: RestartNo}, status: exited(1), want: false},
+		{name: "AlwaysSuccess", policy: RestartPolicy{Mode: RestartAlways}, status: exited(0), want: true},
+		{name: "AlwaysFailure", policy: RestartPolicy{Mode: RestartAlways}, status: exited(1), restarts: 100, want: true},
+		{name: "OnFailureSuccess", policy: RestartPolicy{Mode: RestartOnFailure}, status: exited(0), want: false},
+		{name: "OnFailureFailure", policy: RestartPolicy{Mode: RestartOnFailure}, status: exited(1), restarts: 100, want: true},
+		{name: "OnFailureSignal", policy: RestartPolicy{Mode: RestartOnFailure}, status: killed, want: true},
+		{name: "OnFailureBelowMax", policy: RestartPolicy{Mode: RestartOnFailure, MaxRetries: 2}, status: exited(1), restarts: 1, want: true},
+		{name: "OnFailureAtMax", policy: RestartPolicy{Mode: RestartOnFailure, MaxRetries: 2}, status: exited(1), restarts: 2, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.ShouldRestart(tt.status, tt.restarts); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestarter(t *testing.T) {
+	exited := func(code int) syscall.WaitStatus { return syscall.WaitStatus(code << 8) }
+
+	type exit struct {
+		status    syscall.WaitStatus
+		ranFor    time.Duration
+		wantDelay time.Duration
+		wantOK    bool
+	}
+
+	tests := []struct {
+		name   string
+		policy RestartPolicy
+		exits  []exit
+	}{
+		{
+			name:   "No",
+			policy: RestartPolicy{Mode: RestartNo},
+			exits: []exit{
+				{status: exited(1)},
+			},
+		},
+		{
+			name:   "Backoff",
+			policy: RestartPolicy{Mode: RestartAlways},
+			exits: []exit{
+				{status: exited(0), wantDelay: time.Second, wantOK: true},
+				{status: exited(0), wantDelay: 2 * time.Second, wantOK: true},
+				{status: exited(0), wantDelay: 4 * time.Second, wantOK: true},
+				{status: exited(0), wantDelay: 8 * time.Second, wantOK: true},
+				{status: exited(0), wantDelay: 16 * time.Second, wantOK: true},
+				{status: exited(0), wantDelay: MaxRestartDelay, wantOK: true},
+				{status: exited(0), wantDelay: MaxRestartDelay, wantOK: true},
+			},
+		},
+		{
+			name:   "BackoffReset",
+			policy: RestartPolicy{Mode: RestartAlways},
+			exits: []exit{
+				{status: exited(1), wantDelay: time.Second, wantOK: true},
+				{status: exited(1), wantDelay: 2 * time.Second, wantOK: true},
+				{status: exited(1), ranFor: time.Hour, wantDelay: time.Second, wantOK: true},
+				{status: exited(1), wantDelay: 2 * time.Second, wantOK: true},
+			},
+		},
+		{
+			name:   "OnFailureMaxRetries",
+			policy: RestartPolicy{Mode: RestartOnFailure, MaxRetries: 2},
+			exits: []exit{
+				{status: exited(1), wantDelay: time.Second, wantOK: true},
+				{status: exited(1), wantDelay: 2 * time.Second, wantOK: true},
+				{status: exited(1)},
+			},
+		},
+		{
+			name:   "OnFailureSuccess",
+			policy: RestartPolicy{Mode: RestartOnFailure},
+			exits: []exit{
+				{status: exited(1), wantDelay: time.Second, wantOK: true},
+				{status: exited(0)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRestarter(tt.policy)
+			for i, e := range tt.exits {
+				delay, ok := r.Exited(e.status, e.ranFor)
+				if ok != e.wantOK || delay != e.wantDelay {
+					t.Errorf("exit %d: got %v, %v, want %v, %v", i, delay, ok, e.wantDelay, e.wantOK)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthRecord(t *testing.T) {
+	h := Health{Status: HealthStarting}
+	now := time.Now()
+
+	h.Record(1, "fail", now)
+	if h.Status != HealthStarting || h.FailingStreak != 1 {
+		t.Errorf("after first failure got status %q, streak %d", h.Status, h.FailingStreak)
+	}
+
+	h.Record(0, "ok", now)
+	if h.Status != HealthHealthy || h.FailingStreak != 0 || h.LastOutput != "ok" {
+		t.Errorf("after success got status %q, streak %d, output %q", h.Status, h.FailingStreak, h.LastOutput)
+	}
+
+	for i := 0; i < HealthRetries; i++ {
+		if h.Status == HealthUnhealthy {
+			t.Fatalf("unhealthy after %d failures, expected %d", i, HealthRetries)
+		}
+		h.Record(2, strings.Repeat("x", 2*healthOutputMax), now)
+	}
+	if h.Status != HealthUnhealthy || h.LastExitCode != 2 {
+		t.Errorf("after %d failures got status %q, exit code %d", HealthRetries, h.Status, h.LastExitCode)
+	}
+	if len(h.LastOutput) != healthOutputMax {
+		t.Errorf("output not truncated, length %d", len(h.LastOutput))
+	}
+	if !h.LastCheck.Equal(now) {
+		t.Errorf("got last check %v, want %v", h.LastCheck, now)
+	}
+}
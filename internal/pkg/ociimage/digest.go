@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2024, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -8,14 +8,17 @@ package ociimage
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"strings"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"github.com/sylabs/singularity/v4/internal/pkg/cache"
 	"github.com/sylabs/singularity/v4/internal/pkg/remote/credential/ociauth"
 	"github.com/sylabs/singularity/v4/pkg/sylog"
@@ -26,11 +29,19 @@ import (
 // index (manifest list), it will traverse this to retrieve the digest of the
 // image manifest for the requested architecture specified in tOpts.
 func ImageDigest(ctx context.Context, tOpts *TransportOptions, imgCache *cache.Handle, uri string) (ggcrv1.Hash, error) {
+	digest, _, err := ImageManifest(ctx, tOpts, imgCache, uri)
+	return digest, err
+}
+
+// ImageManifest obtains the digest of the image manifest for a uri-like image
+// reference, as ImageDigest, and the raw image manifest if it was retrieved in
+// doing so. Where only an image index was retrieved, the manifest is nil.
+func ImageManifest(ctx context.Context, tOpts *TransportOptions, imgCache *cache.Handle, uri string) (ggcrv1.Hash, []byte, error) {
 	// oci-archive - Perform a tar extraction first, and handle as an oci layout.
 	if strings.HasPrefix(uri, "oci-archive:") {
 		layoutURI, cleanup, err := extractOCIArchive(uri, tOpts.TmpDir)
 		if err != nil {
-			return ggcrv1.Hash{}, err
+			return ggcrv1.Hash{}, nil, err
 		}
 		defer cleanup()
 		uri = layoutURI
@@ -38,7 +49,7 @@ func ImageDigest(ctx context.Context, tOpts *TransportOptions, imgCache *cache.H
 
 	srcType, srcRef, err := URItoSourceSinkRef(uri)
 	if err != nil {
-		return ggcrv1.Hash{}, err
+		return ggcrv1.Hash{}, nil, err
 	}
 
 	// For OCI registries (docker://) attempt to use HEAD operation and cached
@@ -49,31 +60,40 @@ func ImageDigest(ctx context.Context, tOpts *TransportOptions, imgCache *cache.H
 	return directDigest(ctx, tOpts, srcType, srcRef)
 }
 
-// directDigest obtains the image manifest digest for srcRef, by retrieving the
-// manifest from the OCI source. If the srcRef points at a multi-arch repository
-// with an image index (manifest list), it will traverse this to retrieve the
-// digest of the image manifest for the requested architecture specified in
-// tOpts.
-func directDigest(ctx context.Context, tOpts *TransportOptions, srcType SourceSink, srcRef string) (ggcrv1.Hash, error) {
+// directDigest obtains the image manifest digest, and image manifest, for
+// srcRef, by retrieving the manifest from the OCI source. If the srcRef points
+// at a multi-arch repository with an image index (manifest list), it will
+// traverse this to retrieve the image manifest for the requested architecture
+// specified in tOpts.
+func directDigest(ctx context.Context, tOpts *TransportOptions, srcType SourceSink, srcRef string) (ggcrv1.Hash, []byte, error) {
 	img, err := srcType.Image(ctx, srcRef, tOpts, nil)
 	if err != nil {
-		return ggcrv1.Hash{}, err
+		return ggcrv1.Hash{}, nil, err
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return ggcrv1.Hash{}, nil, err
 	}
-	return img.Digest()
+	mf, err := img.RawManifest()
+	if err != nil {
+		return ggcrv1.Hash{}, nil, err
+	}
+	return digest, mf, nil
 }
 
 // cachedRegistryDigest obtains the image manifest digest for a registry
 // (docker://) image source, attempting to use a HEAD against the registry and a
 // locally cached image index / manifest, to avoid unnecessary GET operations
-// that count against Docker Hub API limits.
-func cachedRegistryDigest(ctx context.Context, tOpts *TransportOptions, imgCache cache.Handle, srcRef string) (ggcrv1.Hash, error) {
+// that count against Docker Hub API limits. The image manifest is also returned,
+// unless only an image index was retrieved.
+func cachedRegistryDigest(ctx context.Context, tOpts *TransportOptions, imgCache cache.Handle, srcRef string) (ggcrv1.Hash, []byte, error) {
 	var nameOpts []name.Option
 	if tOpts != nil && tOpts.Insecure {
 		nameOpts = append(nameOpts, name.Insecure)
 	}
 	remoteRef, err := name.ParseReference(srcRef, nameOpts...)
 	if err != nil {
-		return ggcrv1.Hash{}, err
+		return ggcrv1.Hash{}, nil, err
 	}
 	remoteOpts := []remote.Option{
 		remote.WithContext(ctx),
@@ -86,6 +106,16 @@ func cachedRegistryDigest(ctx context.Context, tOpts *TransportOptions, imgCache
 	// remote.HEAD will return a descriptor with the digest indicated by the Docker-Content-Digest header.
 	headDesc, err := remote.Head(remoteRef, remoteOpts...)
 	if err != nil {
+		// The registry may be unreachable, e.g. on an air-gapped system where
+		// the cache has been imported. Use the digest that the reference
+		// resolved to when it was cached. An error response from the registry,
+		// e.g. 401 or 404, must not be masked by the cache.
+		if isNetworkError(err) {
+			if digest, mf, cacheErr := offlineRegistryDigest(tOpts, imgCache, remoteRef); cacheErr == nil {
+				sylog.Warningf("Couldn't reach registry (%v), using cached digest for %s", err, remoteRef.Name())
+				return digest, mf, nil
+			}
+		}
 		return registryDigestFallback(ctx, tOpts, srcRef, err)
 	}
 	sylog.Debugf("HEAD returned digest %v, mediaType %v", headDesc.Digest, headDesc.MediaType)
@@ -104,7 +134,8 @@ func cachedRegistryDigest(ctx context.Context, tOpts *TransportOptions, imgCache
 		if err != nil {
 			return registryDigestFallback(ctx, tOpts, srcRef, err)
 		}
-		return digestFromManifestOrIndex(tOpts, mf)
+		putCachedReference(imgCache, remoteRef, *headDesc)
+		return digestAndManifest(tOpts, mf)
 	}
 	// Not in cache - GET the index or manifest from the remote, and cache it.
 	sylog.Debugf("No cached image index or manifest")
@@ -120,15 +151,73 @@ func cachedRegistryDigest(ctx context.Context, tOpts *TransportOptions, imgCache
 	if err := imgCache.PutOciCacheBlob(cache.OciBlobCacheType, getDesc.Digest, io.NopCloser(bytes.NewBuffer(getDesc.Manifest))); err != nil {
 		return registryDigestFallback(ctx, tOpts, srcRef, err)
 	}
-	return digestFromManifestOrIndex(tOpts, getDesc.Manifest)
+	putCachedReference(imgCache, remoteRef, *headDesc)
+	return digestAndManifest(tOpts, getDesc.Manifest)
 }
 
-func registryDigestFallback(ctx context.Context, tOpts *TransportOptions, srcRef string, cause error) (ggcrv1.Hash, error) {
+// putCachedReference records, in the OCI blob cache, that remoteRef resolved to
+// the image manifest or image index desc. Failure to do so is not fatal.
+func putCachedReference(imgCache cache.Handle, remoteRef name.Reference, desc ggcrv1.Descriptor) {
+	if err := imgCache.PutOciCacheReference(cache.OciBlobCacheType, remoteRef.Name(), desc); err != nil {
+		sylog.Debugf("Couldn't record cached reference %s: %v", remoteRef.Name(), err)
+	}
+}
+
+// offlineRegistryDigest obtains the image manifest digest for remoteRef, without
+// contacting the registry, from the image manifest or image index that it
+// resolved to when it was cached. The image manifest is also returned, unless
+// the reference resolved to an image index.
+func offlineRegistryDigest(tOpts *TransportOptions, imgCache cache.Handle, remoteRef name.Reference) (ggcrv1.Hash, []byte, error) {
+	desc, err := imgCache.GetOciCacheReference(cache.OciBlobCacheType, remoteRef.Name())
+	if err != nil {
+		return ggcrv1.Hash{}, nil, err
+	}
+	r, err := imgCache.GetOciCacheBlob(cache.OciBlobCacheType, desc.Digest)
+	if err != nil {
+		return ggcrv1.Hash{}, nil, err
+	}
+	defer r.Close()
+	mf, err := io.ReadAll(r)
+	if err != nil {
+		return ggcrv1.Hash{}, nil, err
+	}
+	return digestAndManifest(tOpts, mf)
+}
+
+// isNetworkError returns true if err indicates that a registry could not be
+// reached, rather than an error response from the registry, or cancellation.
+func isNetworkError(err error) bool {
+	var tErr *transport.Error
+	if errors.As(err, &tErr) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func registryDigestFallback(ctx context.Context, tOpts *TransportOptions, srcRef string, cause error) (ggcrv1.Hash, []byte, error) {
 	sylog.Warningf("Couldn't use cached digest for registry: %v", cause)
 	sylog.Warningf("Falling back to direct digest.")
 	return directDigest(ctx, tOpts, RegistrySourceSink, srcRef)
 }
 
+// digestAndManifest returns the digest of the image manifest for the provided
+// manifest or image index, as digestFromManifestOrIndex. If manifestOrIndex is
+// that image manifest, it is also returned.
+func digestAndManifest(tOpts *TransportOptions, manifestOrIndex []byte) (ggcrv1.Hash, []byte, error) {
+	digest, err := digestFromManifestOrIndex(tOpts, manifestOrIndex)
+	if err != nil {
+		return ggcrv1.Hash{}, nil, err
+	}
+	if h, _, err := ggcrv1.SHA256(bytes.NewReader(manifestOrIndex)); err == nil && h == digest {
+		return digest, manifestOrIndex, nil
+	}
+	return digest, nil, nil
+}
+
 // digestFromManifestOrIndex returns the digest of the provided manifest, or the
 // digest of the manifest of an image satisfying sysCtx platform requirements if
 // an image index is supplied.
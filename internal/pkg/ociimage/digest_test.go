@@ -5,14 +5,22 @@
 package ociimage
 
 import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"runtime"
+	"strings"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/name"
 	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
 	ggcrempty "github.com/google/go-containerregistry/pkg/v1/empty"
 	ggcrmutate "github.com/google/go-containerregistry/pkg/v1/mutate"
 	ggcrrandom "github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/sylabs/singularity/v4/internal/pkg/cache"
 	"github.com/sylabs/singularity/v4/internal/pkg/ociplatform"
 )
 
@@ -147,3 +155,76 @@ func Test_digestFromManifestOrIndex(t *testing.T) {
 		})
 	}
 }
+
+func Test_cachedRegistryDigestOffline(t *testing.T) {
+	manifest, manifestImageDigest := imageWithManifest(t)
+
+	// statusHost returns the host of a registry that responds to all requests
+	// with status.
+	statusHost := func(status int) string {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(srv.Close)
+		return strings.TrimPrefix(srv.URL, "http://")
+	}
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedHost := strings.TrimPrefix(closed.URL, "http://")
+	closed.Close()
+
+	tests := []struct {
+		name    string
+		host    string
+		want    ggcrv1.Hash
+		wantErr bool
+	}{
+		{
+			// The cached digest is used when the registry cannot be reached.
+			name: "Unreachable",
+			host: closedHost,
+			want: manifestImageDigest,
+		},
+		{
+			name:    "Unauthorized",
+			host:    statusHost(http.StatusUnauthorized),
+			wantErr: true,
+		},
+		{
+			name:    "NotFound",
+			host:    statusHost(http.StatusNotFound),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imgCache, err := cache.New(cache.Config{ParentDir: t.TempDir()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			srcRef := tt.host + "/repo:latest"
+			remoteRef, err := name.ParseReference(srcRef, name.Insecure)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d, size, err := ggcrv1.SHA256(bytes.NewReader(manifest))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := imgCache.PutOciCacheBlob(cache.OciBlobCacheType, d, io.NopCloser(bytes.NewReader(manifest))); err != nil {
+				t.Fatal(err)
+			}
+			desc := ggcrv1.Descriptor{MediaType: types.OCIManifestSchema1, Size: size, Digest: d}
+			if err := imgCache.PutOciCacheReference(cache.OciBlobCacheType, remoteRef.Name(), desc); err != nil {
+				t.Fatal(err)
+			}
+
+			got, _, err := cachedRegistryDigest(t.Context(), &TransportOptions{Insecure: true}, *imgCache, srcRef)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("cachedRegistryDigest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("cachedRegistryDigest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -116,6 +116,14 @@ func LocalImageLayout(ctx context.Context, tOpts *TransportOptions, imgCache *ca
 	srcImg, err := srcType.Image(ctx, srcRef, tOpts, rt)
 	if err != nil {
 		rt.ProgressShutdown()
+		// The registry may be unreachable, e.g. on an air-gapped system where
+		// the cache has been imported.
+		if srcType == RegistrySourceSink && imgCache != nil && !imgCache.IsDisabled() {
+			if cachedImg, cacheErr := offlineRegistryImage(ctx, tOpts, imgCache, srcRef); cacheErr == nil {
+				sylog.Warningf("Couldn't fetch image from registry (%v), using cached image", err)
+				return cachedImg, nil
+			}
+		}
 		return nil, err
 	}
 
@@ -161,6 +169,32 @@ func LocalImageLayout(ctx context.Context, tOpts *TransportOptions, imgCache *ca
 	return OCISourceSink.Image(ctx, tmpLayout, tOpts, nil)
 }
 
+// offlineRegistryImage returns the cached image that the registry (docker://)
+// image reference srcRef resolved to when it was cached, without contacting the
+// registry. The platform of the image is checked against tOpts.Platform.
+func offlineRegistryImage(ctx context.Context, tOpts *TransportOptions, imgCache *cache.Handle, srcRef string) (ggcrv1.Image, error) {
+	remoteRef, ok := RegistrySourceSink.Reference(srcRef, tOpts)
+	if !ok {
+		return nil, fmt.Errorf("invalid reference %q", srcRef)
+	}
+	digest, _, err := offlineRegistryDigest(tOpts, *imgCache, remoteRef)
+	if err != nil {
+		return nil, err
+	}
+	layoutDir, err := imgCache.GetOciCacheDir(cache.OciBlobCacheType)
+	if err != nil {
+		return nil, err
+	}
+	img, err := OCISourceSink.Image(ctx, layoutDir+"@"+digest.String(), tOpts, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := ociplatform.CheckImagePlatform(tOpts.Platform, img); err != nil {
+		return nil, fmt.Errorf("while checking OCI image: %w", err)
+	}
+	return img, nil
+}
+
 // extractOCIArchive will extract a tar `oci-archive:` image into a temporary
 // layout that will be a subdirectory of tmpDir. The caller is responsible for
 // calling cleanup to remove the layout, or otherwise cleaning up tmpDir.
@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2023, Sylabs Inc. All rights reserved.
+// Copyright (c) 2019-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -8,8 +8,10 @@ package ociplatform
 import (
 	"fmt"
 	"runtime"
+	"strings"
 
 	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/match"
 	"github.com/sylabs/singularity/v4/pkg/sylog"
 )
 
@@ -77,3 +79,57 @@ func PlatformFromArch(a string) (*ggcrv1.Platform, error) {
 		Variant:      variant,
 	}, nil
 }
+
+// AllArch is the --arch value that selects images for all platforms in a
+// multi-platform image index.
+const AllArch = "all"
+
+// PlatformsFromArchList returns the platforms for a comma separated list of
+// architectures. If archList is AllArch, an empty slice is returned, which
+// Matcher treats as selecting all platforms.
+func PlatformsFromArchList(archList string) ([]ggcrv1.Platform, error) {
+	if archList == AllArch {
+		return []ggcrv1.Platform{}, nil
+	}
+
+	platforms := []ggcrv1.Platform{}
+	for _, a := range strings.Split(archList, ",") {
+		a = strings.TrimSpace(a)
+		if a == "" {
+			continue
+		}
+		if a == AllArch {
+			return nil, fmt.Errorf("%q cannot be combined with other architectures", AllArch)
+		}
+		p, err := PlatformFromArch(a)
+		if err != nil {
+			return nil, err
+		}
+		platforms = append(platforms, *p)
+	}
+	if len(platforms) == 0 {
+		return nil, fmt.Errorf("no architectures in %q", archList)
+	}
+	return platforms, nil
+}
+
+// Matcher returns a matcher that selects the descriptors of images in an image
+// index, whose platform satisfies one of platforms. If platforms is empty, the
+// descriptors of images for any linux platform are selected. Descriptors
+// without a platform, e.g. attestations, are never selected.
+func Matcher(platforms ...ggcrv1.Platform) match.Matcher {
+	return func(d ggcrv1.Descriptor) bool {
+		if !d.MediaType.IsImage() || d.Platform == nil || d.Platform.OS != "linux" {
+			return false
+		}
+		if len(platforms) == 0 {
+			return true
+		}
+		for _, p := range platforms {
+			if d.Platform.Satisfies(p) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2023, Sylabs Inc. All rights reserved.
+// Copyright (c) 2023-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 )
 
 func TestPlatformFromString(t *testing.T) {
@@ -69,3 +70,102 @@ func TestPlatformFromString(t *testing.T) {
 		})
 	}
 }
+
+func TestPlatformsFromArchList(t *testing.T) {
+	tests := []struct {
+		name     string
+		archList string
+		want     []ggcrv1.Platform
+		wantErr  bool
+	}{
+		{
+			name:     "All",
+			archList: "all",
+			want:     []ggcrv1.Platform{},
+		},
+		{
+			name:     "Single",
+			archList: "x86_64",
+			want: []ggcrv1.Platform{
+				{OS: "linux", Architecture: "amd64"},
+			},
+		},
+		{
+			name:     "Multiple",
+			archList: "amd64, arm64",
+			want: []ggcrv1.Platform{
+				{OS: "linux", Architecture: "amd64"},
+				{OS: "linux", Architecture: "arm64"},
+			},
+		},
+		{
+			name:     "AllCombined",
+			archList: "amd64,all",
+			wantErr:  true,
+		},
+		{
+			name:     "Empty",
+			archList: ",",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PlatformsFromArchList(tt.archList)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PlatformsFromArchList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlatformsFromArchList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	amd64 := ggcrv1.Descriptor{
+		MediaType: types.OCIManifestSchema1,
+		Platform:  &ggcrv1.Platform{OS: "linux", Architecture: "amd64"},
+	}
+	arm64 := ggcrv1.Descriptor{
+		MediaType: types.OCIManifestSchema1,
+		Platform:  &ggcrv1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+	}
+	attestation := ggcrv1.Descriptor{
+		MediaType: types.OCIManifestSchema1,
+		Platform:  &ggcrv1.Platform{OS: "unknown", Architecture: "unknown"},
+	}
+	noPlatform := ggcrv1.Descriptor{
+		MediaType: types.OCIManifestSchema1,
+	}
+
+	tests := []struct {
+		name      string
+		platforms []ggcrv1.Platform
+		desc      ggcrv1.Descriptor
+		want      bool
+	}{
+		{name: "AllAMD64", desc: amd64, want: true},
+		{name: "AllAttestation", desc: attestation, want: false},
+		{name: "AllNoPlatform", desc: noPlatform, want: false},
+		{
+			name:      "ARM64Variant",
+			platforms: []ggcrv1.Platform{{OS: "linux", Architecture: "arm64"}},
+			desc:      arm64,
+			want:      true,
+		},
+		{
+			name:      "ARM64NotAMD64",
+			platforms: []ggcrv1.Platform{{OS: "linux", Architecture: "arm64"}},
+			desc:      amd64,
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Matcher(tt.platforms...)(tt.desc); got != tt.want {
+				t.Errorf("Matcher() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2025-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -6,6 +6,7 @@
 package ocisif
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -19,9 +20,11 @@ import (
 	ocimutate "github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sylabs/oci-tools/pkg/mutate"
 	ocitsif "github.com/sylabs/oci-tools/pkg/sif"
 	"github.com/sylabs/sif/v2/pkg/sif"
+	"github.com/sylabs/squashfs"
 )
 
 // ConfigMediaType custom media type.
@@ -30,6 +33,10 @@ const (
 	EmptyConfigMediaType      types.MediaType = "application/vnd.oci.empty.v1+json"
 )
 
+// ErrNotDataContainer is returned when an OCI-SIF image does not have the data
+// container artifact type.
+var ErrNotDataContainer = errors.New("image is not a data container")
+
 // WriteDataContainerFromPath takes a path to a directory or regular file, and writes
 // a data container image populated with the directory/file to dest, as an OCI-SIF.
 func WriteDataContainerFromPath(path string, dst string, workDir string) error {
@@ -111,40 +118,218 @@ func createDataContainerFromLayer(layer ggcrv1.Layer) (ggcrv1.Image, error) {
 	)
 }
 
+// AppendDataContainerFromPath takes a path to a directory or regular file, and
+// appends it to the existing data container at dst as a new squashfs layer,
+// which becomes the latest version of the data container.
+func AppendDataContainerFromPath(path string, dst string, workDir string) error {
+	fi, err := sif.LoadContainerFromPath(dst)
+	if err != nil {
+		return err
+	}
+	defer fi.UnloadContainer()
+
+	img, err := GetSingleImage(fi)
+	if err != nil {
+		return fmt.Errorf("while initializing image: %w", err)
+	}
+	if err := checkDataContainer(img); err != nil {
+		return err
+	}
+
+	src, err := newDataContainerFromFSPath(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	layers, err := src.Layers()
+	if err != nil {
+		return err
+	}
+	l, err := mutate.SquashfsLayer(layers[0], workDir, mutate.OptSquashfsSkipWhiteoutConversion(true))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFailedSquashfsConversion, err)
+	}
+
+	img, err = ocimutate.AppendLayers(img, l)
+	if err != nil {
+		return err
+	}
+
+	ofi, err := ocitsif.FromFileImage(fi)
+	if err != nil {
+		return err
+	}
+	return ofi.ReplaceImage(img, nil)
+}
+
+// IsDataContainer returns true if img has the data container artifact type.
+func IsDataContainer(img ggcrv1.Image) (bool, error) {
+	b, err := img.RawManifest()
+	if err != nil {
+		return false, fmt.Errorf("while getting manifest: %w", err)
+	}
+	return IsDataContainerManifest(b)
+}
+
+// IsDataContainerManifest returns true if the raw image manifest b has the
+// data container artifact type.
+func IsDataContainerManifest(b []byte) (bool, error) {
+	var m imagespec.Manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return false, fmt.Errorf("while decoding manifest: %w", err)
+	}
+	return m.ArtifactType == DataContainerArtifactType, nil
+}
+
+// checkDataContainer returns an error if img does not have the data container
+// artifact type.
+func checkDataContainer(img ggcrv1.Image) error {
+	ok, err := IsDataContainer(img)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotDataContainer
+	}
+	return nil
+}
+
+// dataContainerLayer returns the latest squashfs layer of the data container
+// img. Each layer of a data container holds a version of its content, with
+// the latest version in the final layer.
+func dataContainerLayer(img ggcrv1.Image) (*ocitsif.Layer, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("while getting image layers: %w", err)
+	}
+	if len(layers) < 1 {
+		return nil, errors.New("data container has no layers")
+	}
+	l := layers[len(layers)-1]
+	mt, err := l.MediaType()
+	if err != nil {
+		return nil, fmt.Errorf("while getting layer mediatype: %w", err)
+	}
+	if mt != SquashfsLayerMediaType {
+		return nil, fmt.Errorf("unsupported layer mediaType: %v", mt)
+	}
+	ol, ok := l.(*ocitsif.Layer)
+	if !ok {
+		return nil, fmt.Errorf("couldn't get layer %d as an OCI-SIF layer", len(layers)-1)
+	}
+	return ol, nil
+}
+
+// DataContainerLayerOffset returns the offset, within the OCI-SIF file f, of
+// the latest squashfs layer of a data container.
 func DataContainerLayerOffset(f *os.File) (int64, error) {
+	offset, _, err := DataContainerLayer(f)
+	return offset, err
+}
+
+// DataContainerLayer returns the offset and size, within the OCI-SIF file f,
+// of the latest squashfs layer of a data container. If f is not a data
+// container, ErrNotDataContainer is returned.
+func DataContainerLayer(f *os.File) (offset, size int64, err error) {
 	fimg, err := sif.LoadContainer(f,
 		sif.OptLoadWithFlag(os.O_RDONLY),
 		sif.OptLoadWithCloseOnUnload(false),
 	)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	defer fimg.UnloadContainer()
 
 	img, err := GetSingleImage(fimg)
 	if err != nil {
-		return 0, fmt.Errorf("while initializing image: %w", err)
+		return 0, 0, fmt.Errorf("while initializing image: %w", err)
+	}
+	if err := checkDataContainer(img); err != nil {
+		return 0, 0, err
 	}
 
-	// One SquashFS layer only.
-	layers, err := img.Layers()
+	ol, err := dataContainerLayer(img)
 	if err != nil {
-		return 0, fmt.Errorf("while getting image layers: %w", err)
+		return 0, 0, err
+	}
+	if offset, err = ol.Offset(); err != nil {
+		return 0, 0, fmt.Errorf("while getting layer offset: %w", err)
 	}
-	if len(layers) != 1 {
-		return 0, fmt.Errorf("only single layer data containers are supported, found %d layers", len(layers))
+	if size, err = ol.Size(); err != nil {
+		return 0, 0, fmt.Errorf("while getting layer size: %w", err)
 	}
-	mt, err := layers[0].MediaType()
+	return offset, size, nil
+}
+
+// DataContainer is an OCI-SIF data container, opened for reading.
+type DataContainer struct {
+	f   *os.File
+	fi  *sif.FileImage
+	img ggcrv1.Image
+}
+
+// OpenDataContainer opens the OCI-SIF data container at path for reading. The
+// caller must call Close when the data container is no longer needed.
+func OpenDataContainer(path string) (*DataContainer, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		return 0, fmt.Errorf("while getting layer mediatype: %w", err)
+		return nil, err
 	}
-	if mt != SquashfsLayerMediaType {
-		return 0, fmt.Errorf("unsupported layer mediaType: %v", mt)
+
+	fi, err := sif.LoadContainer(f,
+		sif.OptLoadWithFlag(os.O_RDONLY),
+		sif.OptLoadWithCloseOnUnload(false),
+	)
+	if err != nil {
+		f.Close()
+		return nil, err
 	}
-	ol, ok := layers[0].(*ocitsif.Layer)
-	if !ok {
-		return 0, errors.New("couldn't get layer 0 as an OCI-SIF layer")
+
+	dc := &DataContainer{f: f, fi: fi}
+
+	if dc.img, err = GetSingleImage(fi); err != nil {
+		dc.Close()
+		return nil, fmt.Errorf("while initializing image: %w", err)
+	}
+	if err := checkDataContainer(dc.img); err != nil {
+		dc.Close()
+		return nil, err
+	}
+
+	return dc, nil
+}
+
+// Close closes the data container.
+func (dc *DataContainer) Close() error {
+	if err := dc.fi.UnloadContainer(); err != nil {
+		dc.f.Close()
+		return err
+	}
+	return dc.f.Close()
+}
+
+// Image returns the OCI image of the data container.
+func (dc *DataContainer) Image() ggcrv1.Image {
+	return dc.img
+}
+
+// FS returns the content of the latest version of the data container, read
+// directly from its squashfs layer.
+func (dc *DataContainer) FS() (fs.FS, error) {
+	ol, err := dataContainerLayer(dc.img)
+	if err != nil {
+		return nil, err
 	}
 	offset, err := ol.Offset()
-	return offset, err
+	if err != nil {
+		return nil, fmt.Errorf("while getting layer offset: %w", err)
+	}
+	size, err := ol.Size()
+	if err != nil {
+		return nil, fmt.Errorf("while getting layer size: %w", err)
+	}
+	r, err := squashfs.NewReader(io.NewSectionReader(dc.f, offset, size))
+	if err != nil {
+		return nil, fmt.Errorf("while reading squashfs layer: %w", err)
+	}
+	return r, nil
 }
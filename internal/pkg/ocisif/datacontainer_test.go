@@ -1,4 +1,4 @@
-// Copyright (c) 2024, Sylabs Inc. All rights reserved.
+// Copyright (c) 2024-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -6,10 +6,13 @@
 package ocisif
 
 import (
+	"errors"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/sebdah/goldie/v2"
 	"github.com/sylabs/squashfs"
 )
@@ -63,6 +66,82 @@ func Test_newDataContainerFromFSPath(t *testing.T) {
 	}
 }
 
+func TestAppendDataContainerFromPath(t *testing.T) {
+	srcDir := t.TempDir()
+	srcFile := filepath.Join(srcDir, "file.txt")
+	dcFile := filepath.Join(t.TempDir(), "data.oci.sif")
+
+	if err := os.WriteFile(srcFile, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteDataContainerFromPath(srcDir, dcFile, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(srcFile, []byte("v2"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendDataContainerFromPath(srcDir, dcFile, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	dc, err := OpenDataContainer(dcFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dc.Close()
+
+	layers, err := dc.Image().Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(layers), 2; got != want {
+		t.Errorf("got %d layers, want %d", got, want)
+	}
+
+	fsys, err := dc.FS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := fs.ReadFile(fsys, "file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "v2"; got != want {
+		t.Errorf("got content %q, want %q", got, want)
+	}
+}
+
+func TestOpenDataContainer(t *testing.T) {
+	im, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imgFile := filepath.Join(t.TempDir(), "image.oci.sif")
+	iw, err := NewImageWriter(im, imgFile, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := iw.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenDataContainer(imgFile); err == nil {
+		t.Errorf("unexpected success opening image as data container")
+	}
+	f, err := os.Open(imgFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, _, err := DataContainerLayer(f); !errors.Is(err, ErrNotDataContainer) {
+		t.Errorf("got error %v from DataContainerLayer, want %v", err, ErrNotDataContainer)
+	}
+	if err := AppendDataContainerFromPath(t.TempDir(), imgFile, t.TempDir()); err == nil {
+		t.Errorf("unexpected success appending to image")
+	}
+}
+
 func getSourceFS(t *testing.T, src string) fs.FS { //nolint:unparam
 	t.Helper()
 	r, err := os.Open(src)
@@ -0,0 +1,251 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package ocisif
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	ocitmutate "github.com/sylabs/oci-tools/pkg/mutate"
+	"github.com/sylabs/sif/v2/pkg/sif"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/crypt"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/cryptkey"
+)
+
+const (
+	// EncryptedSquashfsLayerMediaType is the mediaType of a squashfs layer
+	// that has been encrypted into a LUKS2 container.
+	EncryptedSquashfsLayerMediaType types.MediaType = "application/vnd.sylabs.image.layer.v1.squashfs.luks2"
+
+	// EncryptionKeyAnnotation holds the RSA-OAEP encrypted LUKS key for a
+	// layer that was encrypted with a PEM key, as a PEM message.
+	EncryptionKeyAnnotation = "org.sylabs.image.layer.encryption.key"
+)
+
+// IsEncrypted returns whether the single image in the OCI-SIF at imagePath has
+// any encrypted layers.
+func IsEncrypted(imagePath string) (bool, error) {
+	fi, err := sif.LoadContainerFromPath(imagePath, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		return false, err
+	}
+	defer fi.UnloadContainer()
+
+	img, err := GetRuntimeImage(fi)
+	if err != nil {
+		return false, fmt.Errorf("while getting image: %w", err)
+	}
+	mf, err := img.Manifest()
+	if err != nil {
+		return false, fmt.Errorf("while getting image manifest: %w", err)
+	}
+	for _, l := range mf.Layers {
+		if l.MediaType == EncryptedSquashfsLayerMediaType {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// LayerKey returns the plaintext key for an encrypted layer, described by d,
+// using the key material in k.
+func LayerKey(k cryptkey.KeyInfo, d ggcrv1.Descriptor) ([]byte, error) {
+	if d.MediaType != EncryptedSquashfsLayerMediaType {
+		return nil, fmt.Errorf("layer %s is not encrypted", d.Digest)
+	}
+	return cryptkey.PlaintextKeyFromMessage(k, []byte(d.Annotations[EncryptionKeyAnnotation]))
+}
+
+// encryptLayers returns a copy of img in which all squashfs layers are
+// encrypted with LUKS2, using the key material in k. A final ext3 overlay
+// layer is not encrypted. The encrypted layers are held in files created in
+// workDir, which must not be removed until the image has been written.
+func encryptLayers(img ggcrv1.Image, k cryptkey.KeyInfo, workDir string) (ggcrv1.Image, error) {
+	plaintext, err := cryptkey.NewPlaintextKey(k)
+	if err != nil {
+		return nil, fmt.Errorf("while generating key: %w", err)
+	}
+	message, err := cryptkey.EncryptKey(k, plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("while encrypting key: %w", err)
+	}
+	var annotations map[string]string
+	if len(message) > 0 {
+		annotations = map[string]string{EncryptionKeyAnnotation: string(message)}
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("while retrieving layers: %w", err)
+	}
+
+	sylog.Infof("Encrypting layers")
+	ms := []ocitmutate.Mutation{}
+	for i, l := range layers {
+		mt, err := l.MediaType()
+		if err != nil {
+			return nil, err
+		}
+		if i == len(layers)-1 && mt == Ext3LayerMediaType {
+			sylog.Warningf("Image contains a writable overlay, which will not be encrypted.")
+			continue
+		}
+		if mt != SquashfsLayerMediaType {
+			return nil, fmt.Errorf("cannot encrypt layer %d with mediaType %q", i, mt)
+		}
+
+		sqfsPath := filepath.Join(workDir, fmt.Sprintf("layer-%d.sqfs", i))
+		if err := writeLayerFile(l, sqfsPath); err != nil {
+			return nil, err
+		}
+
+		cryptDev := &crypt.Device{}
+		cryptPath, err := cryptDev.EncryptFilesystem(sqfsPath, plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("while encrypting layer %d: %w", i, err)
+		}
+		// EncryptFilesystem creates its output in the default temporary
+		// directory, so move it alongside our other intermediate files.
+		encPath := filepath.Join(workDir, fmt.Sprintf("layer-%d.luks", i))
+		if err := moveFile(cryptPath, encPath); err != nil {
+			return nil, err
+		}
+		if err := os.Remove(sqfsPath); err != nil {
+			return nil, err
+		}
+
+		encLayer, err := encryptedLayerFromFile(encPath, annotations)
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, ocitmutate.SetLayer(i, encLayer))
+	}
+
+	return ocitmutate.Apply(img, ms...)
+}
+
+// writeLayerFile writes the uncompressed content of layer l to path.
+func writeLayerFile(l ggcrv1.Layer, path string) error {
+	rc, err := l.Uncompressed()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, rc); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// moveFile moves src to dst, falling back to a copy where they are on
+// different filesystems.
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// encryptedLayer is an encrypted squashfs layer, held in a file.
+type encryptedLayer struct {
+	path        string
+	digest      ggcrv1.Hash
+	size        int64
+	annotations map[string]string
+}
+
+var _ ggcrv1.Layer = (*encryptedLayer)(nil)
+
+func encryptedLayerFromFile(path string, annotations map[string]string) (ggcrv1.Layer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	digest, size, err := ggcrv1.SHA256(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptedLayer{
+		path:        path,
+		digest:      digest,
+		size:        size,
+		annotations: annotations,
+	}, nil
+}
+
+// Descriptor returns the descriptor for the layer, including the encrypted key
+// annotation, if any. See partial.Descriptor.
+func (l *encryptedLayer) Descriptor() (*ggcrv1.Descriptor, error) {
+	return &ggcrv1.Descriptor{
+		Size:        l.size,
+		Digest:      l.digest,
+		MediaType:   EncryptedSquashfsLayerMediaType,
+		Annotations: l.annotations,
+	}, nil
+}
+
+// Digest implements v1.Layer
+func (l *encryptedLayer) Digest() (ggcrv1.Hash, error) {
+	return l.digest, nil
+}
+
+// DiffID returns the Hash of the uncompressed layer. An encrypted layer has no
+// distinct uncompressed form, so this is the same as the Digest.
+func (l *encryptedLayer) DiffID() (ggcrv1.Hash, error) {
+	return l.digest, nil
+}
+
+// Compressed returns an io.ReadCloser for the compressed layer contents.
+func (l *encryptedLayer) Compressed() (io.ReadCloser, error) {
+	return os.Open(l.path)
+}
+
+// Uncompressed returns an io.ReadCloser for the uncompressed layer contents.
+func (l *encryptedLayer) Uncompressed() (io.ReadCloser, error) {
+	return os.Open(l.path)
+}
+
+// Size returns the compressed size of the Layer.
+func (l *encryptedLayer) Size() (int64, error) {
+	return l.size, nil
+}
+
+// MediaType returns the media type of the Layer.
+func (l *encryptedLayer) MediaType() (types.MediaType, error) {
+	return EncryptedSquashfsLayerMediaType, nil
+}
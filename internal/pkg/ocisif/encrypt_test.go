@@ -0,0 +1,56 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package ocisif
+
+import (
+	"bytes"
+	"testing"
+
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/sylabs/singularity/v4/pkg/util/cryptkey"
+)
+
+func TestLayerKey(t *testing.T) {
+	passphrase := cryptkey.KeyInfo{Format: cryptkey.Passphrase, Material: "secret"}
+
+	tests := []struct {
+		name    string
+		k       cryptkey.KeyInfo
+		d       ggcrv1.Descriptor
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name:    "NotEncrypted",
+			k:       passphrase,
+			d:       ggcrv1.Descriptor{MediaType: SquashfsLayerMediaType},
+			wantErr: true,
+		},
+		{
+			name: "Passphrase",
+			k:    passphrase,
+			d:    ggcrv1.Descriptor{MediaType: EncryptedSquashfsLayerMediaType},
+			want: []byte("secret"),
+		},
+		{
+			name:    "PEMNoKey",
+			k:       cryptkey.KeyInfo{Format: cryptkey.PEM, Path: "/does/not/exist.pem"},
+			d:       ggcrv1.Descriptor{MediaType: EncryptedSquashfsLayerMediaType},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LayerKey(tt.k, tt.d)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LayerKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("LayerKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
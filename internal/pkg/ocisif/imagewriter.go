@@ -8,7 +8,9 @@ package ocisif
 import (
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
@@ -18,7 +20,9 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	ocitmutate "github.com/sylabs/oci-tools/pkg/mutate"
 	ocitsif "github.com/sylabs/oci-tools/pkg/sif"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs/squashfs"
 	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/cryptkey"
 	useragent "github.com/sylabs/singularity/v4/pkg/util/user-agent"
 )
 
@@ -43,6 +47,9 @@ type ImageWriter struct {
 	squashLayers   bool
 	squashFSLayers bool
 	artifactType   string
+	keyInfo        *cryptkey.KeyInfo
+	sqfsComp       squashfs.Compression
+	excludes       []string
 	workDir        string
 }
 
@@ -73,6 +80,33 @@ func WithArtifactType(v string) ImageWriterOpt {
 	}
 }
 
+// WithEncryption sets the key material with which squashfs layers will be
+// encrypted. Layers are converted to squashfs before encryption, if required.
+func WithEncryption(k *cryptkey.KeyInfo) ImageWriterOpt {
+	return func(w *ImageWriter) error {
+		w.keyInfo = k
+		return nil
+	}
+}
+
+// WithSquashfsCompression sets the compression of layers that are converted to
+// squashfs.
+func WithSquashfsCompression(c squashfs.Compression) ImageWriterOpt {
+	return func(w *ImageWriter) error {
+		w.sqfsComp = c
+		return nil
+	}
+}
+
+// WithExcludes sets mksquashfs wildcard patterns of paths to omit from layers
+// that are converted to squashfs.
+func WithExcludes(patterns []string) ImageWriterOpt {
+	return func(w *ImageWriter) error {
+		w.excludes = patterns
+		return nil
+	}
+}
+
 var (
 	errNoDestProvided    = errors.New("no destination file provided")
 	errNoWorkDirProvided = errors.New("no workDir for intermediate files provided")
@@ -120,12 +154,27 @@ func NewImageWriter(src ggcrv1.Image, dest, workDir string, opts ...ImageWriterO
 // Write will write an image to an OCI-SIF file, applying relevant mutations set
 // via options on the ImageWriter.
 func (w *ImageWriter) Write() error {
+	img, err := w.image()
+	if err != nil {
+		return err
+	}
+
+	ii := ggcrmutate.AppendManifests(empty.Index, ggcrmutate.IndexAddendum{
+		Add: img,
+	})
+
+	return ocitsif.Write(w.dest, ii, ocitsif.OptWriteWithSpareDescriptorCapacity(spareDescriptorCapacity))
+}
+
+// image returns the source image, with the relevant mutations set via options
+// on the ImageWriter applied.
+func (w *ImageWriter) image() (ggcrv1.Image, error) {
 	var err error
 	img := w.src
 
 	numLayers := len(w.srcManifest.Layers)
 	if numLayers < 1 {
-		return fmt.Errorf("image has no layers")
+		return nil, fmt.Errorf("image has no layers")
 	}
 	hasOverlay := w.srcManifest.Layers[numLayers-1].MediaType == Ext3LayerMediaType
 	canSquash := (hasOverlay && numLayers > 2) || (!hasOverlay && numLayers > 1)
@@ -134,32 +183,117 @@ func (w *ImageWriter) Write() error {
 		if hasOverlay {
 			img, err = squashWithOverlay(img, w.workDir)
 			if err != nil {
-				return fmt.Errorf("while squashing image with overlay: %w", err)
+				return nil, fmt.Errorf("while squashing image with overlay: %w", err)
 			}
 		} else {
 			img, err = ocitmutate.Squash(img)
 			if err != nil {
-				return fmt.Errorf("while squashing image: %w", err)
+				return nil, fmt.Errorf("while squashing image: %w", err)
 			}
 		}
 	}
 
-	if w.squashFSLayers {
-		img, err = imgLayersToSquashfs(img, w.srcDigest, w.workDir)
+	if w.squashFSLayers || w.keyInfo != nil {
+		img, err = imgLayersToSquashfs(img, w.srcDigest, w.workDir, w.sqfsComp, w.excludes)
+		if err != nil {
+			return nil, fmt.Errorf("while converting layers: %w", err)
+		}
+	}
+
+	if w.keyInfo != nil {
+		img, err = encryptLayers(img, *w.keyInfo, w.workDir)
 		if err != nil {
-			return fmt.Errorf("while converting layers: %w", err)
+			return nil, fmt.Errorf("while encrypting layers: %w", err)
 		}
 	}
 
 	if w.artifactType != "" {
 		img, err = ocitmutate.Apply(img, ocitmutate.SetArtifactType(w.artifactType))
 		if err != nil {
-			return fmt.Errorf("while setting artifact type: %w", err)
+			return nil, fmt.Errorf("while setting artifact type: %w", err)
 		}
 	}
 
+	return img, nil
+}
+
+// IndexWriter writes a multi-platform OCI image index into an OCI-SIF file.
+type IndexWriter struct {
+	dest    string
+	src     ggcrv1.ImageIndex
+	workDir string
+	opts    []ImageWriterOpt
+}
+
+// NewIndexWriter returns a writer, which will write the images in a
+// multi-platform OCI image index into an OCI-SIF file. The options are applied
+// to each image in the index.
+func NewIndexWriter(src ggcrv1.ImageIndex, dest, workDir string, opts ...ImageWriterOpt) (*IndexWriter, error) {
+	if dest == "" {
+		return nil, errNoDestProvided
+	}
+	if workDir == "" {
+		return nil, errNoWorkDirProvided
+	}
+
+	return &IndexWriter{
+		dest:    filepath.Clean(dest),
+		src:     src,
+		workDir: workDir,
+		opts:    opts,
+	}, nil
+}
+
+// Write will write the images in the index to an OCI-SIF file, nested in an
+// image index that records the platform of each image. Descriptors in the
+// source index that are not images, e.g. nested indexes, are skipped.
+func (w *IndexWriter) Write() error {
+	im, err := w.src.IndexManifest()
+	if err != nil {
+		return fmt.Errorf("while getting index manifest: %w", err)
+	}
+
+	adds := []ggcrmutate.IndexAddendum{}
+	for i, d := range im.Manifests {
+		if !d.MediaType.IsImage() {
+			sylog.Debugf("Skipping %s, not an image", d.Digest)
+			continue
+		}
+		if d.Platform != nil {
+			sylog.Infof("Adding image for platform %s", d.Platform)
+		}
+
+		src, err := w.src.Image(d.Digest)
+		if err != nil {
+			return fmt.Errorf("while getting image %s: %w", d.Digest, err)
+		}
+		workDir := filepath.Join(w.workDir, strconv.Itoa(i))
+		if err := os.Mkdir(workDir, 0o755); err != nil {
+			return err
+		}
+		iw, err := NewImageWriter(src, w.dest, workDir, w.opts...)
+		if err != nil {
+			return err
+		}
+		img, err := iw.image()
+		if err != nil {
+			return fmt.Errorf("while preparing image %s: %w", d.Digest, err)
+		}
+
+		adds = append(adds, ggcrmutate.IndexAddendum{
+			Add: img,
+			Descriptor: ggcrv1.Descriptor{
+				Platform:    d.Platform,
+				Annotations: d.Annotations,
+			},
+		})
+	}
+	if len(adds) == 0 {
+		return fmt.Errorf("index has no images")
+	}
+
 	ii := ggcrmutate.AppendManifests(empty.Index, ggcrmutate.IndexAddendum{
-		Add: img,
+		Add: ggcrmutate.AppendManifests(empty.Index, adds...),
 	})
 
 	return ocitsif.Write(w.dest, ii, ocitsif.OptWriteWithSpareDescriptorCapacity(spareDescriptorCapacity))
@@ -199,7 +333,7 @@ func squashWithOverlay(base ggcrv1.Image, workDir string) (ggcrv1.Image, error)
 	return ocitmutate.SquashSubset(img, 0, len(ls)-1)
 }
 
-func imgLayersToSquashfs(img ggcrv1.Image, digest ggcrv1.Hash, workDir string) (sqfsImage ggcrv1.Image, err error) {
+func imgLayersToSquashfs(img ggcrv1.Image, digest ggcrv1.Hash, workDir string, comp squashfs.Compression, excludes []string) (sqfsImage ggcrv1.Image, err error) {
 	ms := []ocitmutate.Mutation{}
 
 	layers, err := img.Layers()
@@ -242,7 +376,17 @@ func imgLayersToSquashfs(img ggcrv1.Image, digest ggcrv1.Hash, workDir string) (
 			continue
 		}
 
-		squashfsLayer, err := ocitmutate.SquashfsLayer(l, workDir, sqOpts...)
+		// oci-tools only creates gzip compressed squashfs, without excludes.
+		var squashfsLayer ggcrv1.Layer
+		if comp.IsDefault() && len(excludes) == 0 {
+			squashfsLayer, err = ocitmutate.SquashfsLayer(l, workDir, sqOpts...)
+		} else {
+			squashfsLayer, err = squashfsLayerFromTAR(l, workDir, squashfsLayerOpts{
+				comp:             comp,
+				excludes:         excludes,
+				convertWhiteouts: len(layers) > 1,
+			})
+		}
 		if err != nil {
 			return nil, fmt.Errorf("%w: %v", ErrFailedSquashfsConversion, err)
 		}
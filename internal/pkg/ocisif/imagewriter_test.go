@@ -7,7 +7,9 @@ package ocisif
 import (
 	"archive/tar"
 	"bytes"
+	"errors"
 	"io"
+	"os"
 	"path/filepath"
 	"strconv"
 	"testing"
@@ -16,6 +18,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/sylabs/sif/v2/pkg/sif"
 	useragent "github.com/sylabs/singularity/v4/pkg/util/user-agent"
 )
 
@@ -178,3 +181,72 @@ func TestWrite(t *testing.T) {
 		})
 	}
 }
+
+func TestIndexWriter(t *testing.T) {
+	useragent.InitValue("TestIndexWriter", "0.0.0")
+	tmpDir := t.TempDir()
+
+	amd64 := ggcrv1.Platform{OS: "linux", Architecture: "amd64"}
+	arm64 := ggcrv1.Platform{OS: "linux", Architecture: "arm64"}
+	var src ggcrv1.ImageIndex = empty.Index
+	for _, p := range []ggcrv1.Platform{amd64, arm64} {
+		img := testImage(t)
+		cf, err := img.ConfigFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cf.OS = p.OS
+		cf.Architecture = p.Architecture
+		img, err = mutate.ConfigFile(img, cf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src = mutate.AppendManifests(src, mutate.IndexAddendum{
+			Add:        img,
+			Descriptor: ggcrv1.Descriptor{Platform: &p},
+		})
+	}
+
+	dest := filepath.Join(tmpDir, "multi.oci.sif")
+	w, err := NewIndexWriter(src, dest, tmpDir, WithSquash(true), WithSquashFSLayers(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := sif.LoadContainerFromPath(dest, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fi.UnloadContainer()
+
+	platforms, err := Platforms(fi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(platforms) != 2 || !platforms[0].Equals(amd64) || !platforms[1].Equals(arm64) {
+		t.Errorf("unexpected platforms %v", platforms)
+	}
+
+	if _, err := GetSingleImage(fi); !errors.Is(err, ErrMultiPlatform) {
+		t.Errorf("GetSingleImage() error = %v, want %v", err, ErrMultiPlatform)
+	}
+
+	img, err := GetPlatformImage(fi, arm64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layers) != 1 {
+		t.Errorf("got %d layers, want 1", len(layers))
+	}
+
+	if _, err := GetPlatformImage(fi, ggcrv1.Platform{OS: "linux", Architecture: "ppc64le"}); err == nil {
+		t.Errorf("GetPlatformImage() expected error for missing platform")
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2024-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2024-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -39,7 +39,7 @@ func HasOverlay(imagePath string) (bool, int64, error) {
 	}
 	defer fi.UnloadContainer()
 
-	img, err := GetSingleImage(fi)
+	img, err := GetRuntimeImage(fi)
 	if err != nil {
 		return false, 0, fmt.Errorf("while getting image: %w", err)
 	}
@@ -0,0 +1,148 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package ocisif
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	ocimutate "github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	cosignremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sylabs/oci-tools/pkg/mutate"
+	ocitsif "github.com/sylabs/oci-tools/pkg/sif"
+	"github.com/sylabs/oci-tools/pkg/sourcesink"
+	"github.com/sylabs/sif/v2/pkg/sif"
+)
+
+// ErrNoSBOM is returned when an OCI-SIF holds no SBOM for its image.
+var ErrNoSBOM = errors.New("no SBOM found")
+
+// AddSBOM adds the SBOM document data, of media type mediaType, to the OCI-SIF
+// at imagePath. The SBOM is held as an OCI artifact, whose subject is the image
+// in the OCI-SIF, replacing any existing SBOM for the image. As with cosign
+// signatures, the artifact is referenced by the cosign tag convention.
+func AddSBOM(imagePath string, data []byte, mediaType types.MediaType) error {
+	fi, err := sif.LoadContainerFromPath(imagePath)
+	if err != nil {
+		return fmt.Errorf("while loading SIF: %w", err)
+	}
+	defer fi.UnloadContainer()
+
+	img, err := GetSingleImage(fi)
+	if err != nil {
+		return fmt.Errorf("while getting image: %w", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return err
+	}
+	size, err := img.Size()
+	if err != nil {
+		return err
+	}
+	mt, err := img.MediaType()
+	if err != nil {
+		return err
+	}
+
+	art := ocimutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	art, err = ocimutate.AppendLayers(art, static.NewLayer(data, mediaType))
+	if err != nil {
+		return err
+	}
+	art, err = mutate.Apply(art,
+		mutate.SetConfig(struct{}{}, EmptyConfigMediaType),
+		mutate.SetArtifactType(string(mediaType)),
+	)
+	if err != nil {
+		return err
+	}
+	subject := ggcrv1.Descriptor{MediaType: mt, Size: size, Digest: digest}
+	art, ok := ocimutate.Subject(art, subject).(ggcrv1.Image)
+	if !ok {
+		return errors.New("while setting SBOM subject: unexpected type")
+	}
+
+	ref, err := sourcesink.CosignRef(digest, nil, cosignremote.SBOMTagSuffix)
+	if err != nil {
+		return err
+	}
+	ofi, err := ocitsif.FromFileImage(fi)
+	if err != nil {
+		return err
+	}
+	return ofi.ReplaceImage(art, match.Name(ref.Name()), ocitsif.OptAppendReference(ref))
+}
+
+// SBOM returns the SBOM document, and its media type, held in the OCI-SIF fi
+// for the image that will be run on this host. ErrNoSBOM is returned if there
+// is no SBOM for the image.
+func SBOM(fi *sif.FileImage) ([]byte, types.MediaType, error) {
+	img, err := GetRuntimeImage(fi)
+	if err != nil {
+		return nil, "", err
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, "", err
+	}
+	ref, err := sourcesink.CosignRef(digest, nil, cosignremote.SBOMTagSuffix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ofi, err := ocitsif.FromFileImage(fi)
+	if err != nil {
+		return nil, "", err
+	}
+	ri, err := ofi.RootIndex()
+	if err != nil {
+		return nil, "", err
+	}
+	im, err := ri.IndexManifest()
+	if err != nil {
+		return nil, "", err
+	}
+	var art ggcrv1.Image
+	for _, d := range im.Manifests {
+		if match.Name(ref.Name())(d) {
+			if art, err = ri.Image(d.Digest); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if art == nil {
+		return nil, "", ErrNoSBOM
+	}
+
+	layers, err := art.Layers()
+	if err != nil {
+		return nil, "", err
+	}
+	if len(layers) != 1 {
+		return nil, "", fmt.Errorf("SBOM artifact has %d layers, expected 1", len(layers))
+	}
+	mt, err := layers[0].MediaType()
+	if err != nil {
+		return nil, "", err
+	}
+	rc, err := layers[0].Compressed()
+	if err != nil {
+		return nil, "", err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, mt, nil
+}
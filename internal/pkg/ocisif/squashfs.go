@@ -0,0 +1,215 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package ocisif
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/bin"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs/squashfs"
+	"github.com/sylabs/singularity/v4/pkg/image"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+)
+
+const (
+	aufsWhiteoutPrefix = ".wh."
+	aufsMetaPrefix     = ".wh..wh."
+	aufsOpaqueMarker   = ".wh..wh..opq"
+
+	// overlayOpaqueXattr is the PAX record that sets the overlayfs opaque
+	// xattr on a directory, in a TAR read by sqfstar.
+	overlayOpaqueXattr = "SCHILY.xattr.trusted.overlay.opaque"
+)
+
+// squashfsLayerOpts holds options for the conversion of a TAR layer to
+// squashfs, performed with sqfstar rather than by oci-tools.
+type squashfsLayerOpts struct {
+	// comp is the compression of the squashfs.
+	comp squashfs.Compression
+	// excludes are wildcard patterns of paths to omit from the squashfs.
+	excludes []string
+	// convertWhiteouts indicates AUFS whiteouts in the layer should be
+	// converted to the overlayfs form.
+	convertWhiteouts bool
+}
+
+// squashfsLayerFromTAR converts the TAR layer l to a squashfs layer, stored in
+// workDir.
+func squashfsLayerFromTAR(l ggcrv1.Layer, workDir string, opts squashfsLayerOpts) (ggcrv1.Layer, error) {
+	sqfstar, err := bin.FindBin("sqfstar")
+	if err != nil {
+		return nil, fmt.Errorf("sqfstar is required to set squashfs compression or exclude paths: %w", err)
+	}
+
+	var opaque map[string]bool
+	if opts.convertWhiteouts {
+		rc, err := l.Uncompressed()
+		if err != nil {
+			return nil, err
+		}
+		opaque, err = opaqueDirs(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("while scanning layer for opaque directories: %w", err)
+		}
+	}
+
+	dir, err := os.MkdirTemp(workDir, "layer-")
+	if err != nil {
+		return nil, err
+	}
+	dest := filepath.Join(dir, "layer.sqfs")
+
+	// sqfstar takes args of the form: [options] destination [excludes]
+	args := opts.comp.Args()
+	if len(opts.excludes) > 0 {
+		args = append(args, "-wildcards")
+	}
+	args = append(args, dest)
+	args = append(args, opts.excludes...)
+
+	rc, err := l.Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	pr, pw := io.Pipe()
+	go func() {
+		if !opts.convertWhiteouts {
+			_, err := io.Copy(pw, rc)
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(convertWhiteouts(rc, pw, opaque))
+	}()
+
+	var stderr bytes.Buffer
+	sylog.Debugf("Executing %q with args: %v", sqfstar, args)
+	cmd := exec.Command(sqfstar, args...)
+	cmd.Stdin = pr
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	// Unblock the writer if sqfstar exited before consuming all input.
+	pr.Close()
+	if err != nil {
+		return nil, fmt.Errorf("while running sqfstar: %v: %s", err, stderr.String())
+	}
+
+	return imageLayerFromFile(dest, image.SQUASHFS)
+}
+
+// opaqueDirs returns the set of directories, as cleaned absolute paths, that
+// contain an AUFS opaque marker in the TAR stream from r.
+func opaqueDirs(r io.Reader) (map[string]bool, error) {
+	opaque := map[string]bool{}
+
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return opaque, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if name := path.Clean("/" + h.Name); path.Base(name) == aufsOpaqueMarker {
+			opaque[path.Dir(name)] = true
+		}
+	}
+}
+
+// convertWhiteouts copies the TAR stream from r to w, converting AUFS whiteout
+// files to the overlayfs form. A '.wh.<name>' file becomes a 0:0 character
+// device '<name>', and the directories in opaque are marked with the overlayfs
+// opaque xattr, being added to the stream if not present.
+func convertWhiteouts(r io.Reader, w io.Writer, opaque map[string]bool) error {
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	written := map[string]bool{}
+
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean("/" + h.Name)
+		base := path.Base(name)
+
+		switch {
+		case strings.HasPrefix(base, aufsMetaPrefix):
+			// The opaque marker, or other AUFS metadata, is not carried over.
+			continue
+
+		case strings.HasPrefix(base, aufsWhiteoutPrefix):
+			target := path.Join(path.Dir(name), strings.TrimPrefix(base, aufsWhiteoutPrefix))
+			if err := tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeChar,
+				Name:     strings.TrimPrefix(target, "/"),
+				Uid:      h.Uid,
+				Gid:      h.Gid,
+				ModTime:  h.ModTime,
+			}); err != nil {
+				return err
+			}
+			continue
+
+		case h.Typeflag == tar.TypeDir && opaque[name]:
+			setOpaque(h)
+			written[name] = true
+		}
+
+		if err := tw.WriteHeader(h); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+
+	// Add any opaque directories that have no entry of their own in the layer.
+	for _, name := range slices.Sorted(maps.Keys(opaque)) {
+		if written[name] || name == "/" {
+			continue
+		}
+		h := &tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     strings.TrimPrefix(name, "/") + "/",
+			Mode:     0o755,
+		}
+		setOpaque(h)
+		if err := tw.WriteHeader(h); err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
+
+// setOpaque adds the overlayfs opaque xattr to the TAR header h.
+func setOpaque(h *tar.Header) {
+	if h.PAXRecords == nil {
+		h.PAXRecords = map[string]string{}
+	}
+	h.PAXRecords[overlayOpaqueXattr] = "y"
+	h.Format = tar.FormatPAX
+}
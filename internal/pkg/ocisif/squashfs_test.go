@@ -0,0 +1,94 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package ocisif
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func testTAR(t *testing.T, hdrs []*tar.Header) []byte {
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	for _, h := range hdrs {
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if h.Size > 0 {
+			if _, err := tw.Write(bytes.Repeat([]byte("x"), int(h.Size))); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// tarEntry summarizes the fields of a TAR header relevant to whiteouts.
+type tarEntry struct {
+	name     string
+	typeflag byte
+	opaque   bool
+}
+
+func readTAR(t *testing.T, b []byte) []tarEntry {
+	var entries []tarEntry
+	tr := tar.NewReader(bytes.NewReader(b))
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, tarEntry{
+			name:     h.Name,
+			typeflag: h.Typeflag,
+			opaque:   h.PAXRecords[overlayOpaqueXattr] == "y",
+		})
+	}
+}
+
+func TestConvertWhiteouts(t *testing.T) {
+	layer := testTAR(t, []*tar.Header{
+		{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "dir/.wh..wh..opq", Typeflag: tar.TypeReg},
+		{Name: "dir/file", Typeflag: tar.TypeReg, Size: 4},
+		{Name: "./other/.wh.removed", Typeflag: tar.TypeReg},
+		{Name: "nodir/.wh..wh..opq", Typeflag: tar.TypeReg},
+		{Name: ".wh..wh.plnk", Typeflag: tar.TypeDir},
+	})
+
+	opaque, err := opaqueDirs(bytes.NewReader(layer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"/dir": true, "/nodir": true}; !reflect.DeepEqual(opaque, want) {
+		t.Errorf("opaqueDirs() = %v, want %v", opaque, want)
+	}
+
+	var b bytes.Buffer
+	if err := convertWhiteouts(bytes.NewReader(layer), &b, opaque); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []tarEntry{
+		{name: "dir/", typeflag: tar.TypeDir, opaque: true},
+		{name: "dir/file", typeflag: tar.TypeReg},
+		{name: "other/removed", typeflag: tar.TypeChar},
+		{name: "nodir/", typeflag: tar.TypeDir, opaque: true},
+	}
+	if got := readTAR(t, b.Bytes()); !reflect.DeepEqual(got, want) {
+		t.Errorf("convertWhiteouts() entries = %+v, want %+v", got, want)
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2024, Sylabs Inc. All rights reserved.
+// Copyright (c) 2024-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -6,18 +6,34 @@
 package ocisif
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
 	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
 	ocitsif "github.com/sylabs/oci-tools/pkg/sif"
 	"github.com/sylabs/oci-tools/pkg/sourcesink"
 	"github.com/sylabs/sif/v2/pkg/sif"
+	"github.com/sylabs/singularity/v4/internal/pkg/ociplatform"
 )
 
+// ErrMultiPlatform is returned when an operation that requires an OCI-SIF
+// holding a single image is attempted on a multi-platform OCI-SIF.
+var ErrMultiPlatform = errors.New("OCI-SIF holds a multi-platform image index")
+
 // GetSingleImage returns a v1.Image from an OCI-SIF, that must contain a single
 // image. It ignores any cosign images that may be present in the OCI-SIF.
 func GetSingleImage(fi *sif.FileImage) (v1.Image, error) {
+	ii, err := GetMultiPlatformIndex(fi)
+	if err != nil {
+		return nil, err
+	}
+	if ii != nil {
+		return nil, ErrMultiPlatform
+	}
+
 	ofi, err := ocitsif.FromFileImage(fi)
 	if err != nil {
 		return nil, err
@@ -26,6 +42,98 @@ func GetSingleImage(fi *sif.FileImage) (v1.Image, error) {
 	return ofi.Image(SkipCosignMatcher)
 }
 
+// GetMultiPlatformIndex returns the multi-platform image index held in an
+// OCI-SIF, or nil if the OCI-SIF holds a single image.
+func GetMultiPlatformIndex(fi *sif.FileImage) (v1.ImageIndex, error) {
+	ofi, err := ocitsif.FromFileImage(fi)
+	if err != nil {
+		return nil, err
+	}
+	ri, err := ofi.RootIndex()
+	if err != nil {
+		return nil, err
+	}
+	im, err := ri.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range im.Manifests {
+		if d.MediaType.IsIndex() && SkipCosignMatcher(d) {
+			return ri.ImageIndex(d.Digest)
+		}
+	}
+	return nil, nil
+}
+
+// GetPlatformImage returns a v1.Image from an OCI-SIF. If the OCI-SIF holds a
+// multi-platform image index, the image satisfying platform is returned.
+// Otherwise, the single image in the OCI-SIF is returned.
+func GetPlatformImage(fi *sif.FileImage, platform v1.Platform) (v1.Image, error) {
+	ii, err := GetMultiPlatformIndex(fi)
+	if err != nil {
+		return nil, err
+	}
+	if ii == nil {
+		return GetSingleImage(fi)
+	}
+
+	imgs, err := partial.FindImages(ii, ociplatform.Matcher(platform))
+	if err != nil {
+		return nil, err
+	}
+	if len(imgs) == 0 {
+		return nil, fmt.Errorf("OCI-SIF holds no image for platform %s", platform)
+	}
+	return imgs[0], nil
+}
+
+// GetRuntimeImage returns the v1.Image from an OCI-SIF that will be run on this
+// host. If the OCI-SIF holds a multi-platform image index, the image matching
+// the host platform is selected.
+func GetRuntimeImage(fi *sif.FileImage) (v1.Image, error) {
+	p, err := ociplatform.DefaultPlatform()
+	if err != nil {
+		return nil, err
+	}
+	return GetPlatformImage(fi, *p)
+}
+
+// Platforms returns the platforms of the images in an OCI-SIF. For an OCI-SIF
+// holding a single image, the platform is read from its config.
+func Platforms(fi *sif.FileImage) ([]v1.Platform, error) {
+	ii, err := GetMultiPlatformIndex(fi)
+	if err != nil {
+		return nil, err
+	}
+
+	if ii == nil {
+		img, err := GetSingleImage(fi)
+		if err != nil {
+			return nil, err
+		}
+		cf, err := img.ConfigFile()
+		if err != nil {
+			return nil, err
+		}
+		if p := cf.Platform(); p != nil {
+			return []v1.Platform{*p}, nil
+		}
+		return []v1.Platform{}, nil
+	}
+
+	im, err := ii.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	platforms := []v1.Platform{}
+	for _, d := range im.Manifests {
+		if d.Platform != nil {
+			platforms = append(platforms, *d.Platform)
+		}
+	}
+	return platforms, nil
+}
+
 // SkipCosignMatcher matches all images / indices, except those that are related
 // to cosign images, as annotated using the sylabs/oci-tools ref.name convention.
 func SkipCosignMatcher(d v1.Descriptor) bool {
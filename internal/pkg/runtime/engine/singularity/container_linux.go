@@ -135,6 +135,9 @@ func create(ctx context.Context, engine *EngineOperations, rpcOps *client.RPC, p
 	if engine.EngineConfig.File.AllowKernelExtfs {
 		mount.AuthorizeImageFS("ext3")
 	}
+	if engine.EngineConfig.File.AllowKernelErofs {
+		mount.AuthorizeImageFS("erofs")
+	}
 
 	c := &container{
 		engine:        engine,
@@ -707,7 +710,7 @@ func (c *container) mountImage(mnt *mount.Point) error {
 	err = c.rpcOps.Mount(c.session.Path(), path, mnt.Destination, mountType, flags, optsString)
 	switch {
 	case errors.Is(err, syscall.EINVAL):
-		if mountType == "squashfs" {
+		if mountType == "squashfs" || mountType == "erofs" {
 			return fmt.Errorf(
 				"kernel reported a bad superblock for %s image partition, "+
 					"possible causes are that your kernel doesn't support "+
@@ -753,6 +756,8 @@ func (c *container) addRootfsMount(system *mount.System) error {
 		mountType = "squashfs"
 	case image.EXT3:
 		mountType = "ext3"
+	case image.EROFS:
+		mountType = "erofs"
 	case image.ENCRYPTSQUASHFS:
 		mountType = "encrypted_squashfs"
 		key = c.engine.EngineConfig.GetEncryptionKey()
@@ -909,6 +914,13 @@ func (c *container) addOverlayMount(system *mount.System) error {
 					return err
 				}
 				ov.AddLowerDir(dst)
+			case image.EROFS:
+				flags := uintptr(c.suidFlag | syscall.MS_NODEV | syscall.MS_RDONLY)
+				err = system.Points.AddImage(mount.PreLayerTag, src, dst, "erofs", flags, offset, size, nil)
+				if err != nil {
+					return err
+				}
+				ov.AddLowerDir(dst)
 			case image.SANDBOX:
 				// In setuid mode, only root user may mount a directory overlay, as the mount
 				// will take place privileged, and could be abused.
@@ -1063,6 +1075,9 @@ func (c *container) addImageBindMount(system *mount.System) error {
 			case image.SQUASHFS:
 				flags |= syscall.MS_RDONLY
 				fstype = "squashfs"
+			case image.EROFS:
+				flags |= syscall.MS_RDONLY
+				fstype = "erofs"
 			default:
 				return fmt.Errorf("could not use %s for image binding: not supported image format", img.Path)
 			}
@@ -0,0 +1,128 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package singularity
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/sylabs/singularity/v4/internal/pkg/buildcfg"
+	"github.com/sylabs/singularity/v4/internal/pkg/instance"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"golang.org/x/sys/unix"
+)
+
+// runHealthChecks periodically runs the health check command of an instance,
+// recording the result in the instance file, until done is closed.
+func (e *EngineOperations) runHealthChecks(done <-chan struct{}) {
+	name := e.CommonConfig.ContainerID
+	cmd := e.EngineConfig.GetHealthCmd()
+	interval := e.EngineConfig.GetHealthInterval()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		// A check may not take longer than the interval between checks.
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		exitCode, output := runHealthCheck(ctx, name, cmd)
+		cancel()
+
+		file, err := instance.Get(name, instance.SingSubDir)
+		if err != nil {
+			sylog.Debugf("Could not read instance file to record health check: %s", err)
+			continue
+		}
+		err = file.Modify(func(f *instance.File) {
+			if f.Health == nil {
+				f.Health = &instance.Health{Status: instance.HealthStarting}
+			}
+			f.Health.Record(exitCode, output, time.Now())
+		})
+		if err != nil {
+			sylog.Warningf("Could not record health check result: %s", err)
+		}
+	}
+}
+
+// runHealthCheck runs cmd with /bin/sh in the named instance, returning its
+// exit code and combined output. A check that cannot be run, or that times
+// out, is reported with a non-zero exit code.
+//
+// The master process may hold a privileged saved uid in the setuid flow. The
+// check is started from a thread that has dropped to the real uid and gid of
+// the user, so that the command never runs with those privileges.
+func runHealthCheck(ctx context.Context, name, cmd string) (int, string) {
+	type result struct {
+		exitCode int
+		output   string
+	}
+	resultCh := make(chan result, 1)
+
+	go func() {
+		// The thread is left locked, so that it is terminated rather than
+		// returned to the pool, once its privileges have been dropped.
+		runtime.LockOSThread()
+		if err := dropThreadPrivileges(); err != nil {
+			resultCh <- result{-1, fmt.Sprintf("could not drop privileges: %s", err)}
+			return
+		}
+		exitCode, output := execHealthCheck(ctx, name, cmd)
+		resultCh <- result{exitCode, output}
+	}()
+
+	r := <-resultCh
+	return r.exitCode, r.output
+}
+
+// dropThreadPrivileges sets the real, effective and saved uid / gid of the
+// calling thread to the real uid / gid of the process. Only the calling thread
+// is affected, which must be locked, and must not be returned to the pool.
+func dropThreadPrivileges() error {
+	gid := os.Getgid()
+	if err := unix.Setresgid(gid, gid, gid); err != nil {
+		return err
+	}
+	uid := os.Getuid()
+	return unix.Setresuid(uid, uid, uid)
+}
+
+// execHealthCheck runs cmd with /bin/sh in the named instance, through
+// singularity exec.
+func execHealthCheck(ctx context.Context, name, cmd string) (int, string) {
+	singularityBin := filepath.Join(buildcfg.BINDIR, "singularity")
+	c := exec.CommandContext(ctx, singularityBin, "exec", "instance://"+name, defaultShell, "-c", cmd)
+
+	var out bytes.Buffer
+	c.Stdout = &out
+	c.Stderr = &out
+
+	err := c.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return -1, "health check timed out"
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), out.String()
+	}
+	if err != nil {
+		return -1, err.Error()
+	}
+	return 0, out.String()
+}
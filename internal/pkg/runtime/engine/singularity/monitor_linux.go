@@ -39,6 +39,12 @@ func (e *EngineOperations) MonitorContainer(pid int, signals chan os.Signal) (sy
 		return callbacks[0].(singularitycallback.MonitorContainer)(e.CommonConfig, pid, signals)
 	}
 
+	if e.EngineConfig.GetInstance() && e.EngineConfig.GetHealthCmd() != "" {
+		done := make(chan struct{})
+		defer close(done)
+		go e.runHealthChecks(done)
+	}
+
 	for {
 		s := <-signals
 		switch s {
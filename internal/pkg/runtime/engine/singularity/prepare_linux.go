@@ -28,6 +28,7 @@ import (
 	"github.com/sylabs/singularity/v4/internal/pkg/cgroups"
 	"github.com/sylabs/singularity/v4/internal/pkg/fakeroot"
 	"github.com/sylabs/singularity/v4/internal/pkg/instance"
+	"github.com/sylabs/singularity/v4/internal/pkg/ocisif"
 	"github.com/sylabs/singularity/v4/internal/pkg/runtime/engine/config/starter"
 	"github.com/sylabs/singularity/v4/internal/pkg/security"
 	"github.com/sylabs/singularity/v4/internal/pkg/security/seccomp"
@@ -720,6 +721,10 @@ func (e *EngineOperations) prepareContainerConfig(starterConfig *starter.Config)
 		e.EngineConfig.OciConfig.SetProcessApparmorProfile(param)
 	}
 	param = security.GetParam(e.EngineConfig.GetSecurity(), "seccomp")
+	// fall back to the default seccomp profile from singularity.conf, if any
+	if param == "" {
+		param = e.EngineConfig.File.SeccompProfile
+	}
 	if param != "" {
 		sylog.Debugf("Applying seccomp rule from %s", param)
 		generator := &e.EngineConfig.OciConfig.Generator
@@ -1548,6 +1553,11 @@ func (e *EngineOperations) loadImage(path string, writable bool) (*image.Image,
 		if !e.EngineConfig.File.AllowContainerExtfs {
 			return nil, fmt.Errorf("configuration disallows users from running extFS containers")
 		}
+	// Bare EROFS
+	case image.EROFS:
+		if !e.EngineConfig.File.AllowContainerErofs {
+			return nil, fmt.Errorf("configuration disallows users from running EROFS containers")
+		}
 	// Bare sandbox directory
 	case image.SANDBOX:
 		if !e.EngineConfig.File.AllowContainerDir {
@@ -1569,6 +1579,16 @@ func (e *EngineOperations) loadImage(path string, writable bool) (*image.Image,
 		if !encrypted && !e.EngineConfig.File.AllowContainerSIF {
 			return nil, fmt.Errorf("configuration disallows users from running unencrypted SIF containers")
 		}
+	// OCI-SIF, which can only be used as a data container.
+	case image.OCISIF:
+		if !e.EngineConfig.File.AllowContainerSIF {
+			return nil, fmt.Errorf("configuration disallows users from binding OCI-SIF data containers")
+		}
+		if err := setDataContainerPartition(imgObject); errors.Is(err, ocisif.ErrNotDataContainer) {
+			return nil, fmt.Errorf("%s is an OCI-SIF image, not a data container: OCI-SIF images can only be run in OCI-mode", path)
+		} else if err != nil {
+			return nil, fmt.Errorf("while locating data container layer: %w", err)
+		}
 	// We shouldn't be able to run anything else, but make sure we don't!
 	default:
 		return nil, fmt.Errorf("unknown image format %d", imgObject.Type)
@@ -1577,6 +1597,32 @@ func (e *EngineOperations) loadImage(path string, writable bool) (*image.Image,
 	return imgObject, imgErr
 }
 
+// setDataContainerPartition sets the single squashfs layer of the OCI-SIF
+// data container img as its only partition, so that it can be bound into the
+// container as a data image.
+func setDataContainerPartition(img *image.Image) error {
+	offset, size, err := ocisif.DataContainerLayer(img.File)
+	if err != nil {
+		return err
+	}
+	uOffset, err := safecast.Convert[uint64](offset)
+	if err != nil {
+		return err
+	}
+	uSize, err := safecast.Convert[uint64](size)
+	if err != nil {
+		return err
+	}
+	img.Partitions = []image.Section{{
+		Name:         "data",
+		Offset:       uOffset,
+		Size:         uSize,
+		Type:         image.SQUASHFS,
+		AllowedUsage: image.DataUsage,
+	}}
+	return nil
+}
+
 func (e *EngineOperations) setUserInfo(useTargetIDs bool) error {
 	var gids []int
 
@@ -185,35 +185,57 @@ func (e *EngineOperations) StartProcess(masterConn net.Conn) error {
 	statusChan := make(chan syscall.WaitStatus, 1)
 	cmdPid := -2
 
+	restartPolicy, err := instance.ParseRestartPolicy(e.EngineConfig.GetRestartPolicy())
+	if err != nil {
+		return err
+	}
+	restarter := instance.NewRestarter(restartPolicy)
+	// stopping is set once a termination signal has been forwarded to the
+	// instance process, so that it isn't restarted when it exits.
+	stopping := false
+	var restartTimer <-chan time.Time
+	var cmdStarted time.Time
+
 	args, env, err := runActionScript(e.EngineConfig)
 	if err != nil {
 		return err
-	} else if len(args) > 0 {
-	cmdexec:
-		// Spawn and wait container process, signal handler
-		cmd := exec.Command(args[0], args[1:]...)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		cmd.Stdin = os.Stdin
-		cmd.Env = env
-		cmd.SysProcAttr = &syscall.SysProcAttr{
-			Setpgid: isInstance,
-		}
-		if err := cmd.Start(); err != nil {
-			if e, ok := err.(*os.PathError); ok {
-				//nolint:forcetypeassert
-				if e.Err.(syscall.Errno) == syscall.ENOEXEC && args[0] != defaultShell {
-					args = append([]string{defaultShell}, args...)
-					goto cmdexec
+	}
+
+	startCmd := func() error {
+		for {
+			// Spawn and wait container process, signal handler
+			cmd := exec.Command(args[0], args[1:]...)
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			cmd.Stdin = os.Stdin
+			cmd.Env = env
+			cmd.SysProcAttr = &syscall.SysProcAttr{
+				Setpgid: isInstance,
+			}
+			if err := cmd.Start(); err != nil {
+				if e, ok := err.(*os.PathError); ok {
+					//nolint:forcetypeassert
+					if e.Err.(syscall.Errno) == syscall.ENOEXEC && args[0] != defaultShell {
+						args = append([]string{defaultShell}, args...)
+						continue
+					}
 				}
+				return fmt.Errorf("exec %s failed: %s", args[0], err)
 			}
-			return fmt.Errorf("exec %s failed: %s", args[0], err)
+			cmdPid = cmd.Process.Pid
+			cmdStarted = time.Now()
+
+			go func() {
+				errChan <- cmd.Wait()
+			}()
+			return nil
 		}
-		cmdPid = cmd.Process.Pid
+	}
 
-		go func() {
-			errChan <- cmd.Wait()
-		}()
+	if len(args) > 0 {
+		if err := startCmd(); err != nil {
+			return err
+		}
 	}
 
 	// Modify argv argument and program name shown in /proc/self/comm
@@ -255,7 +277,9 @@ func (e *EngineOperations) StartProcess(masterConn net.Conn) error {
 					}
 
 					if wpid == cmdPid {
-						e.stopFuseDrivers()
+						if !isInstance || restartPolicy.Mode == instance.RestartNo {
+							e.stopFuseDrivers()
+						}
 						statusChan <- status
 					}
 				}
@@ -273,6 +297,10 @@ func (e *EngineOperations) StartProcess(masterConn net.Conn) error {
 				// mean to update the Go runtime or the kernel to something more
 				// stable :)
 				if isInstance && cmdPid > 0 {
+					switch signal {
+					case syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGHUP:
+						stopping = true
+					}
 					if err := syscall.Kill(-cmdPid, signal); err == syscall.ESRCH {
 						sylog.Debugf("No child process, exiting ...")
 						os.Exit(128 + int(signal))
@@ -285,36 +313,73 @@ func (e *EngineOperations) StartProcess(masterConn net.Conn) error {
 				}
 			}
 		case err := <-errChan:
-			if e, ok := err.(*exec.ExitError); ok {
-				status, ok := e.Sys().(syscall.WaitStatus)
-				if !ok {
-					return fmt.Errorf("command exit with error: %s", err)
-				}
-				statusChan <- status
-			} else if e, ok := err.(*os.SyscallError); ok {
-				// handle possible race with Wait4 call above by ignoring ECHILD
-				// error because child process was already caught
-				//nolint:forcetypeassert
-				if e.Err.(syscall.Errno) != syscall.ECHILD {
-					sylog.Fatalf("error while waiting container process: %s", e.Error())
-				}
+			status, err := waitStatus(err, statusChan)
+			if err != nil {
+				sylog.Fatalf("error while waiting container process: %s", err)
 			}
 			if !isInstance {
-				if len(statusChan) > 0 {
-					status := <-statusChan
-					if status.Signaled() {
-						os.Exit(128 + int(status.Signal()))
-					}
-					os.Exit(status.ExitStatus())
-				} else if err == nil {
-					os.Exit(0)
-				}
-				sylog.Fatalf("command exited with unknown error: %s", err)
+				os.Exit(exitCode(status))
+			}
+
+			if stopping || restartPolicy.Mode == instance.RestartNo {
+				continue
+			}
+			delay, restart := restarter.Exited(status, time.Since(cmdStarted))
+			if !restart {
+				sylog.Infof("Instance process exited with status %d, not restarting", exitCode(status))
+				e.stopFuseDrivers()
+				continue
+			}
+			sylog.Infof("Instance process exited with status %d, restarting in %s", exitCode(status), delay)
+			restartTimer = time.After(delay)
+		case <-restartTimer:
+			restartTimer = nil
+			if stopping {
+				continue
+			}
+			if err := startCmd(); err != nil {
+				sylog.Errorf("Could not restart instance process: %s", err)
+				e.stopFuseDrivers()
 			}
 		}
 	}
 }
 
+// waitStatus returns the wait status of the container process, given the
+// error returned by its cmd.Wait. When the process has already been reaped by
+// the SIGCHLD handler, cmd.Wait fails with ECHILD, and the status is received
+// from statusChan instead. The handler sends the status before the ECHILD
+// error can be received, so this does not block indefinitely.
+func waitStatus(err error, statusChan <-chan syscall.WaitStatus) (syscall.WaitStatus, error) {
+	var exitErr *exec.ExitError
+	var sysErr *os.SyscallError
+
+	switch {
+	case err == nil:
+		// The process exited successfully.
+		return 0, nil
+	case errors.As(err, &exitErr):
+		status, ok := exitErr.Sys().(syscall.WaitStatus)
+		if !ok {
+			return 0, fmt.Errorf("command exit with error: %s", err)
+		}
+		return status, nil
+	case errors.As(err, &sysErr) && errors.Is(sysErr.Err, syscall.ECHILD):
+		return <-statusChan, nil
+	default:
+		return 0, err
+	}
+}
+
+// exitCode returns the exit code of a process, following the shell
+// convention of 128+N for a process that was killed by signal N.
+func exitCode(status syscall.WaitStatus) int {
+	if status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return status.ExitStatus()
+}
+
 // PostStartProcess is called from master after successful
 // execution of the container process. It will write instance
 // state/config files (if any).
@@ -368,6 +433,10 @@ func (e *EngineOperations) PostStartProcess(_ context.Context, pid int) error {
 		file.Image = e.EngineConfig.GetImage()
 		file.LogErrPath = logErrPath
 		file.LogOutPath = logOutPath
+		file.RestartPolicy = e.EngineConfig.GetRestartPolicy()
+		if e.EngineConfig.GetHealthCmd() != "" {
+			file.Health = &instance.Health{Status: instance.HealthStarting}
+		}
 
 		ip, err := e.getIP()
 		if err != nil {
@@ -0,0 +1,73 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package singularity
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+)
+
+func TestWaitStatus(t *testing.T) {
+	exitErr := exec.Command("/bin/sh", "-c", "exit 3").Run()
+	if exitErr == nil {
+		t.Fatal("expected command to fail")
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		reaped     []syscall.WaitStatus
+		wantStatus int
+		wantErr    bool
+	}{
+		{
+			name:       "Success",
+			err:        nil,
+			wantStatus: 0,
+		},
+		{
+			name:       "ExitError",
+			err:        exitErr,
+			wantStatus: 3,
+		},
+		{
+			// The process was reaped by the SIGCHLD handler, which sent the
+			// status it received.
+			name:       "Reaped",
+			err:        os.NewSyscallError("wait", syscall.ECHILD),
+			reaped:     []syscall.WaitStatus{syscall.WaitStatus(syscall.SIGKILL)},
+			wantStatus: 128 + int(syscall.SIGKILL),
+		},
+		{
+			name:    "Error",
+			err:     errors.New("unexpected"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statusChan := make(chan syscall.WaitStatus, 1)
+			for _, s := range tt.reaped {
+				statusChan <- s
+			}
+
+			status, err := waitStatus(tt.err, statusChan)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("waitStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := exitCode(status); got != tt.wantStatus {
+				t.Errorf("exit code = %d, want %d", got, tt.wantStatus)
+			}
+		})
+	}
+}
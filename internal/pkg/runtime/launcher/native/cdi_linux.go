@@ -0,0 +1,139 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package native
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/bind"
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+	"tags.cncf.io/container-device-interface/pkg/parser"
+	cdispecs "tags.cncf.io/container-device-interface/specs-go"
+)
+
+// getCDIEdits resolves the CDI devices requested with --device, returning the
+// container edits that apply to them. Edits that apply to all devices in a
+// CDI spec are returned once per spec, before those of its devices. Devices
+// that require hooks are rejected, as hooks can only be run by an OCI runtime.
+func (l *Launcher) getCDIEdits() ([]cdispecs.ContainerEdits, error) {
+	// CDI spec files are read once, below, and are not scanned asynchronously.
+	opts := []cdi.Option{cdi.WithAutoRefresh(false)}
+	if len(l.cfg.CdiDirs) > 0 {
+		opts = append(opts, cdi.WithSpecDirs(l.cfg.CdiDirs...))
+	}
+	cache, err := cdi.NewCache(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error configuring CDI cache: %w", err)
+	}
+	if err := cache.Refresh(); err != nil {
+		return nil, fmt.Errorf("error refreshing CDI cache: %w", err)
+	}
+
+	edits := []cdispecs.ContainerEdits{}
+	specs := map[*cdi.Spec]bool{}
+	for _, name := range l.cfg.Devices {
+		if !parser.IsQualifiedName(name) {
+			return nil, fmt.Errorf("string %#v does not represent a valid CDI device", name)
+		}
+		dev := cache.GetDevice(name)
+		if dev == nil {
+			return nil, fmt.Errorf("while setting up CDI devices: unresolvable CDI device %s", name)
+		}
+		spec := dev.GetSpec()
+		if len(spec.ContainerEdits.Hooks) > 0 || len(dev.ContainerEdits.Hooks) > 0 {
+			return nil, fmt.Errorf("CDI device %s requires hooks, which are not supported in native mode, use --oci", name)
+		}
+		if !specs[spec] {
+			specs[spec] = true
+			edits = append(edits, spec.ContainerEdits)
+		}
+		edits = append(edits, dev.ContainerEdits)
+	}
+	return edits, nil
+}
+
+// setCDIDevices applies the CDI devices requested with --device to the engine
+// configuration. Device nodes and bind mounts are added to the bind list.
+// Environment variables are held, to be applied by setEnv. Other mounts can
+// only be applied by an OCI runtime, and are skipped with a warning.
+func (l *Launcher) setCDIDevices() error {
+	if len(l.cfg.Devices) == 0 {
+		return nil
+	}
+
+	edits, err := l.getCDIEdits()
+	if err != nil {
+		return err
+	}
+
+	binds := l.engineConfig.GetBindPath()
+	for _, e := range edits {
+		for _, d := range e.DeviceNodes {
+			src := d.HostPath
+			if src == "" {
+				src = d.Path
+			}
+			sylog.Debugf("Binding CDI device node %s to %s", src, d.Path)
+			binds = append(binds, bind.Path{Source: src, Destination: d.Path})
+		}
+
+		for _, m := range e.Mounts {
+			if !isCDIBindMount(m) {
+				sylog.Warningf("Skipping CDI %s mount of %s to %s, only bind mounts are supported in native mode", m.Type, m.HostPath, m.ContainerPath)
+				continue
+			}
+			bp := bind.Path{Source: m.HostPath, Destination: m.ContainerPath}
+			if slices.Contains(m.Options, "ro") {
+				bp.Options = map[string]*bind.Option{"ro": {}}
+			}
+			sylog.Debugf("Binding CDI mount %s to %s", m.HostPath, m.ContainerPath)
+			binds = append(binds, bp)
+		}
+
+		for _, kv := range e.Env {
+			k, v, _ := strings.Cut(kv, "=")
+			if l.cdiEnv == nil {
+				l.cdiEnv = map[string]string{}
+			}
+			l.cdiEnv[k] = v
+		}
+		if len(e.NetDevices) > 0 || e.IntelRdt != nil || len(e.AdditionalGIDs) > 0 {
+			sylog.Warningf("Skipping CDI network device, RDT and additional GID edits, not supported in native mode")
+		}
+	}
+
+	l.engineConfig.SetBindPath(binds)
+	return nil
+}
+
+// isCDIBindMount returns true if a CDI mount is a bind mount.
+func isCDIBindMount(m *cdispecs.Mount) bool {
+	if m.Type == "bind" || m.Type == "rbind" {
+		return true
+	}
+	return m.Type == "" && (slices.Contains(m.Options, "bind") || slices.Contains(m.Options, "rbind"))
+}
+
+// setCDIEnv sets the environment variables from CDI devices for injection into
+// the container. They have the lowest precedence, so a variable that is also
+// set with --env, --env-file, or SINGULARITYENV_ on the host, is ignored.
+func (l *Launcher) setCDIEnv() {
+	for k, v := range l.cdiEnv {
+		if _, ok := l.cfg.Env[k]; ok {
+			sylog.Debugf("Ignored CDI environment variable %s: override from --env or --env-file", k)
+			continue
+		}
+		if _, ok := os.LookupEnv("SINGULARITYENV_" + k); ok {
+			sylog.Debugf("Ignored CDI environment variable %s: override from SINGULARITYENV_%s", k, k)
+			continue
+		}
+		os.Setenv("SINGULARITYENV_"+k, v)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package native
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sylabs/singularity/v4/internal/pkg/runtime/launcher"
+	singularityConfig "github.com/sylabs/singularity/v4/pkg/runtime/engine/singularity/config"
+	"github.com/sylabs/singularity/v4/pkg/util/bind"
+)
+
+const testCDISpec = `{
+	"cdiVersion": "0.5.0",
+	"kind": "singularityCEtesting.sylabs.io/device",
+	"devices": [
+		{
+			"name": "null",
+			"containerEdits": {
+				"deviceNodes": [
+					{"path": "/dev/null"}
+				],
+				"env": ["DEVICE=null"]
+			}
+		},
+		{
+			"name": "mounts",
+			"containerEdits": {
+				"mounts": [
+					{"hostPath": "/tmp/a", "containerPath": "/mnt/a", "options": ["rbind", "ro"]},
+					{"hostPath": "/tmp/b", "containerPath": "/mnt/b", "type": "bind"},
+					{"hostPath": "tmpfs", "containerPath": "/mnt/c", "type": "tmpfs"}
+				],
+				"env": ["DEVICE=mounts"]
+			}
+		}
+	],
+	"containerEdits": {
+		"env": ["SPEC=all", "EMPTY="]
+	}
+}`
+
+const testCDIHookSpec = `{
+	"cdiVersion": "0.5.0",
+	"kind": "singularityCEtesting.sylabs.io/hooks",
+	"devices": [
+		{
+			"name": "device",
+			"containerEdits": {
+				"hooks": [
+					{"hookName": "createContainer", "path": "/bin/true"}
+				]
+			}
+		},
+		{
+			"name": "nohook",
+			"containerEdits": {
+				"env": ["HOOK=none"]
+			}
+		}
+	]
+}`
+
+func writeCDISpecs(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "test.json"), []byte(testCDISpec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "hooks.json"), []byte(testCDIHookSpec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestSetCDIDevices(t *testing.T) {
+	specDir := writeCDISpecs(t)
+
+	tests := []struct {
+		name      string
+		devices   []string
+		wantBinds []bind.Path
+		wantEnv   map[string]string
+		wantErr   bool
+	}{
+		{
+			name: "NoDevices",
+		},
+		{
+			name:    "DeviceNode",
+			devices: []string{"singularityCEtesting.sylabs.io/device=null"},
+			wantBinds: []bind.Path{
+				{Source: "/dev/null", Destination: "/dev/null"},
+			},
+			wantEnv: map[string]string{"SPEC": "all", "EMPTY": "", "DEVICE": "null"},
+		},
+		{
+			name:    "Mounts",
+			devices: []string{"singularityCEtesting.sylabs.io/device=mounts"},
+			wantBinds: []bind.Path{
+				{Source: "/tmp/a", Destination: "/mnt/a", Options: map[string]*bind.Option{"ro": {}}},
+				{Source: "/tmp/b", Destination: "/mnt/b"},
+			},
+			wantEnv: map[string]string{"SPEC": "all", "EMPTY": "", "DEVICE": "mounts"},
+		},
+		{
+			// Spec edits are applied once, and later devices take precedence.
+			name: "MultipleDevices",
+			devices: []string{
+				"singularityCEtesting.sylabs.io/device=null",
+				"singularityCEtesting.sylabs.io/device=mounts",
+			},
+			wantBinds: []bind.Path{
+				{Source: "/dev/null", Destination: "/dev/null"},
+				{Source: "/tmp/a", Destination: "/mnt/a", Options: map[string]*bind.Option{"ro": {}}},
+				{Source: "/tmp/b", Destination: "/mnt/b"},
+			},
+			wantEnv: map[string]string{"SPEC": "all", "EMPTY": "", "DEVICE": "mounts"},
+		},
+		{
+			name:    "NoHook",
+			devices: []string{"singularityCEtesting.sylabs.io/hooks=nohook"},
+			wantEnv: map[string]string{"HOOK": "none"},
+		},
+		{
+			name:    "Hook",
+			devices: []string{"singularityCEtesting.sylabs.io/hooks=device"},
+			wantErr: true,
+		},
+		{
+			name:    "InvalidName",
+			devices: []string{"null"},
+			wantErr: true,
+		},
+		{
+			name:    "Unresolvable",
+			devices: []string{"singularityCEtesting.sylabs.io/device=missing"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Launcher{
+				cfg: launcher.Options{
+					Devices: tt.devices,
+					CdiDirs: []string{specDir},
+				},
+				engineConfig: singularityConfig.NewConfig(),
+			}
+
+			err := l.setCDIDevices()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setCDIDevices() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := l.engineConfig.GetBindPath(); !reflect.DeepEqual(got, tt.wantBinds) {
+				t.Errorf("got binds %v, want %v", got, tt.wantBinds)
+			}
+			if !reflect.DeepEqual(l.cdiEnv, tt.wantEnv) {
+				t.Errorf("got CDI env %v, want %v", l.cdiEnv, tt.wantEnv)
+			}
+			if l.cfg.Env != nil {
+				t.Errorf("CDI env was merged into --env: %v", l.cfg.Env)
+			}
+		})
+	}
+}
+
+func TestSetCDIEnv(t *testing.T) {
+	// t.Setenv restores the original values when the test completes.
+	for _, k := range []string{"SINGULARITYENV_ENV", "SINGULARITYENV_CDI"} {
+		t.Setenv(k, "")
+		os.Unsetenv(k)
+	}
+	t.Setenv("SINGULARITYENV_HOST", "host")
+
+	l := &Launcher{
+		cfg: launcher.Options{
+			Env: map[string]string{"ENV": "env"},
+		},
+		cdiEnv: map[string]string{
+			"ENV":  "cdi",
+			"HOST": "cdi",
+			"CDI":  "cdi",
+		},
+	}
+
+	l.setCDIEnv()
+
+	tests := []struct {
+		key    string
+		want   string
+		wantOK bool
+	}{
+		// --env and --env-file variables are set later, by setEnv.
+		{key: "SINGULARITYENV_ENV", wantOK: false},
+		{key: "SINGULARITYENV_HOST", want: "host", wantOK: true},
+		{key: "SINGULARITYENV_CDI", want: "cdi", wantOK: true},
+	}
+	for _, tt := range tests {
+		got, ok := os.LookupEnv(tt.key)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("%s = %q (set %v), want %q (set %v)", tt.key, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
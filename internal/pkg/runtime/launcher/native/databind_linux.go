@@ -0,0 +1,97 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package native
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/sylabs/singularity/v4/internal/pkg/ocisif"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/bin"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/bind"
+	"github.com/sylabs/singularity/v4/pkg/util/namespaces"
+)
+
+// setDataBinds sets engine configuration for data containers requested with
+// --data. The squashfs layer of a data container is always mounted read-only.
+//
+// Where kernel squashfs mounts are permitted, the layer is bound as a data
+// image, which the engine mounts with a loop device. Otherwise, it is mounted
+// with squashfuse, as a host FUSE mount.
+func (l *Launcher) setDataBinds() error {
+	if len(l.cfg.DataBinds) == 0 {
+		return nil
+	}
+
+	insideUserNs, _ := namespaces.IsInsideUserNamespace(os.Getpid())
+	useFuse := !l.engineConfig.File.AllowKernelSquashfs || insideUserNs || l.cfg.Namespaces.User || l.cfg.SIFFUSE
+
+	binds := l.engineConfig.GetBindPath()
+	for _, b := range l.cfg.DataBinds {
+		bp, err := bind.ParseDataBindPath(b)
+		if err != nil {
+			return fmt.Errorf("while parsing data bind: %w", err)
+		}
+
+		if useFuse {
+			fm, err := dataFuseMount(bp)
+			if err != nil {
+				return fmt.Errorf("while preparing data container %s: %w", bp.Source, err)
+			}
+			sylog.Debugf("Mounting data container %s at %s with squashfuse", bp.Source, bp.Destination)
+			l.cfg.FuseMount = append(l.cfg.FuseMount, fm)
+			continue
+		}
+
+		sylog.Debugf("Binding data container %s to %s", bp.Source, bp.Destination)
+		binds = append(binds, bind.Path{
+			Source:      bp.Source,
+			Destination: bp.Destination,
+			Options: map[string]*bind.Option{
+				"image-src": {Value: "/"},
+				"ro":        {},
+			},
+		})
+	}
+
+	l.engineConfig.SetBindPath(binds)
+	return nil
+}
+
+// dataFuseMount returns a --fusemount spec that mounts the squashfs layer of
+// the data container bp.Source, read-only, at bp.Destination with squashfuse.
+func dataFuseMount(bp bind.Path) (string, error) {
+	src, err := filepath.Abs(bp.Source)
+	if err != nil {
+		return "", err
+	}
+	// FUSE mount specs are split on whitespace.
+	if strings.ContainsFunc(src+bp.Destination, unicode.IsSpace) {
+		return "", fmt.Errorf("data container paths containing whitespace cannot be mounted with squashfuse")
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	offset, err := ocisif.DataContainerLayerOffset(f)
+	if err != nil {
+		return "", err
+	}
+
+	squashfuse, err := bin.FindBin("squashfuse")
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("host:%s -o ro,offset=%d %s %s", squashfuse, offset, src, bp.Destination), nil
+}
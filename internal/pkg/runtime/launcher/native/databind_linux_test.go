@@ -0,0 +1,216 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package native
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/sylabs/singularity/v4/internal/pkg/ocisif"
+	"github.com/sylabs/singularity/v4/internal/pkg/runtime/launcher"
+	"github.com/sylabs/singularity/v4/internal/pkg/test/tool/require"
+	singularityConfig "github.com/sylabs/singularity/v4/pkg/runtime/engine/singularity/config"
+	"github.com/sylabs/singularity/v4/pkg/util/bind"
+	"github.com/sylabs/singularity/v4/pkg/util/namespaces"
+)
+
+// writeDataContainer writes a data container holding a single file to dir.
+func writeDataContainer(t *testing.T, dir string) string {
+	t.Helper()
+
+	srcDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcDir, "file.txt"), []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dcFile := filepath.Join(dir, "data.oci.sif")
+	if err := ocisif.WriteDataContainerFromPath(srcDir, dcFile, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	return dcFile
+}
+
+// writeOCISIFImage writes an OCI-SIF image, which is not a data container, to
+// dir.
+func writeOCISIFImage(t *testing.T, dir string) string {
+	t.Helper()
+
+	im, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imgFile := filepath.Join(dir, "image.oci.sif")
+	iw, err := ocisif.NewImageWriter(im, imgFile, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := iw.Write(); err != nil {
+		t.Fatal(err)
+	}
+	return imgFile
+}
+
+func TestSetDataBindsKernel(t *testing.T) {
+	if insideUserNs, _ := namespaces.IsInsideUserNamespace(os.Getpid()); insideUserNs {
+		t.Skip("data containers are mounted with squashfuse inside a user namespace")
+	}
+
+	tests := []struct {
+		name      string
+		dataBinds []string
+		wantBinds []bind.Path
+		wantErr   bool
+	}{
+		{
+			name: "NoBinds",
+		},
+		{
+			name:      "Binds",
+			dataBinds: []string{"data1.oci.sif:/data1", "data2.oci.sif:/data2"},
+			wantBinds: []bind.Path{
+				{
+					Source:      "data1.oci.sif",
+					Destination: "/data1",
+					Options:     map[string]*bind.Option{"image-src": {Value: "/"}, "ro": {}},
+				},
+				{
+					Source:      "data2.oci.sif",
+					Destination: "/data2",
+					Options:     map[string]*bind.Option{"image-src": {Value: "/"}, "ro": {}},
+				},
+			},
+		},
+		{
+			name:      "NoDestination",
+			dataBinds: []string{"data.oci.sif"},
+			wantErr:   true,
+		},
+		{
+			name:      "EmptySource",
+			dataBinds: []string{":/data"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Launcher{
+				cfg: launcher.Options{
+					DataBinds: tt.dataBinds,
+				},
+				engineConfig: singularityConfig.NewConfig(),
+			}
+			l.engineConfig.File.AllowKernelSquashfs = true
+
+			err := l.setDataBinds()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setDataBinds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := l.engineConfig.GetBindPath(); !reflect.DeepEqual(got, tt.wantBinds) {
+				t.Errorf("got binds %v, want %v", got, tt.wantBinds)
+			}
+			if len(l.cfg.FuseMount) > 0 {
+				t.Errorf("unexpected FUSE mounts %v", l.cfg.FuseMount)
+			}
+		})
+	}
+}
+
+func TestSetDataBindsFuse(t *testing.T) {
+	dir := t.TempDir()
+	imgFile := writeOCISIFImage(t, dir)
+
+	tests := []struct {
+		name     string
+		dataBind string
+		wantErr  error
+	}{
+		{
+			name:     "NotDataContainer",
+			dataBind: imgFile + ":/data",
+			wantErr:  ocisif.ErrNotDataContainer,
+		},
+		{
+			name:     "Missing",
+			dataBind: filepath.Join(dir, "missing.oci.sif") + ":/data",
+			wantErr:  os.ErrNotExist,
+		},
+		{
+			name:     "WhitespaceDestination",
+			dataBind: imgFile + ":/data dir",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Launcher{
+				cfg: launcher.Options{
+					DataBinds: []string{tt.dataBind},
+					// Kernel squashfs mounts are not permitted.
+				},
+				engineConfig: singularityConfig.NewConfig(),
+			}
+
+			err := l.setDataBinds()
+			if err == nil {
+				t.Fatalf("unexpected success")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("setDataBinds() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("DataContainer", func(t *testing.T) {
+		require.Command(t, "squashfuse")
+
+		dcFile := writeDataContainer(t, t.TempDir())
+		f, err := os.Open(dcFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		offset, err := ocisif.DataContainerLayerOffset(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		l := &Launcher{
+			cfg: launcher.Options{
+				DataBinds: []string{dcFile + ":/data"},
+				SIFFUSE:   true,
+			},
+			engineConfig: singularityConfig.NewConfig(),
+		}
+		l.engineConfig.File.AllowKernelSquashfs = true
+
+		if err := l.setDataBinds(); err != nil {
+			t.Fatalf("setDataBinds() error = %v", err)
+		}
+		if got := l.engineConfig.GetBindPath(); len(got) > 0 {
+			t.Errorf("unexpected binds %v", got)
+		}
+		if len(l.cfg.FuseMount) != 1 {
+			t.Fatalf("got FUSE mounts %v, want 1", l.cfg.FuseMount)
+		}
+		fm := l.cfg.FuseMount[0]
+		if !strings.HasPrefix(fm, "host:") {
+			t.Errorf("FUSE mount %q is not a host mount", fm)
+		}
+		if want := fmt.Sprintf(" -o ro,offset=%d %s /data", offset, dcFile); !strings.HasSuffix(fm, want) {
+			t.Errorf("got FUSE mount %q, want suffix %q", fm, want)
+		}
+	})
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/sylabs/singularity/v4/internal/pkg/security"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/bin"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/env"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs/erofs"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/fs/fuse"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/fs/squashfs"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/gpu"
@@ -59,6 +60,8 @@ type Launcher struct {
 	cfg          launcher.Options
 	engineConfig *singularityConfig.EngineConfig
 	generator    *generate.Generator
+	// cdiEnv holds environment variables set by CDI devices.
+	cdiEnv map[string]string
 }
 
 // NewLauncher returns a native.Launcher with an initial configuration set by opts.
@@ -69,18 +72,6 @@ func NewLauncher(opts ...launcher.Option) (*Launcher, error) {
 			return nil, fmt.Errorf("%w", err)
 		}
 	}
-	if len(lo.Devices) > 0 {
-		return nil, fmt.Errorf("CDI device mappings unsupported in native launcher")
-	}
-
-	if len(lo.CdiDirs) > 0 {
-		return nil, fmt.Errorf("CDI device mappings unsupported in native launcher")
-	}
-
-	if len(lo.DataBinds) > 0 {
-		return nil, fmt.Errorf("data container binds unsupported in native launcher")
-	}
-
 	if lo.NoCompat {
 		sylog.Warningf("--no-compat applies to --oci mode only, ignoring")
 	}
@@ -180,13 +171,22 @@ func (l *Launcher) Exec(ctx context.Context, ep launcher.ExecParams) error {
 		l.generator.AddProcessRlimits("RLIMIT_STACK", hard, soft)
 	}
 
-	// Handle requested binds, fuse mounts.
+	// Handle requested binds, data container binds, fuse mounts.
 	if err := l.setBinds(); err != nil {
 		sylog.Fatalf("While setting bind mount configuration: %s", err)
 	}
+	// Data containers mounted with squashfuse are added to the FUSE mounts.
+	if err := l.setDataBinds(); err != nil {
+		sylog.Fatalf("While setting data container bind configuration: %s", err)
+	}
 	if err := l.setFuseMounts(); err != nil {
 		sylog.Fatalf("While setting FUSE mount configuration: %s", err)
 	}
+	// CDI devices add device node and mount binds. Their environment variables
+	// are applied by setEnv.
+	if err := l.setCDIDevices(); err != nil {
+		sylog.Fatalf("While setting CDI device configuration: %s", err)
+	}
 
 	// Set the home directory that should be effective in the container.
 	if err := l.setHome(); err != nil {
@@ -333,6 +333,18 @@ func (l *Launcher) Exec(ctx context.Context, ep launcher.ExecParams) error {
 			return fmt.Errorf("instance %s already exists", ep.Instance)
 		}
 
+		if _, err := instance.ParseRestartPolicy(l.cfg.RestartPolicy); err != nil {
+			return err
+		}
+		l.engineConfig.SetRestartPolicy(l.cfg.RestartPolicy)
+		if l.cfg.HealthCmd != "" {
+			if l.cfg.HealthInterval <= 0 {
+				return fmt.Errorf("health check interval must be greater than zero")
+			}
+			l.engineConfig.SetHealthCmd(l.cfg.HealthCmd)
+			l.engineConfig.SetHealthInterval(l.cfg.HealthInterval)
+		}
+
 		if l.cfg.Boot {
 			l.cfg.Namespaces.UTS = true
 			l.cfg.Namespaces.Net = true
@@ -346,6 +358,10 @@ func (l *Launcher) Exec(ctx context.Context, ep launcher.ExecParams) error {
 		}
 	}
 
+	if ep.Instance == "" && (l.cfg.RestartPolicy != "" || l.cfg.HealthCmd != "") {
+		return fmt.Errorf("restart policies and health checks can only be used with instances")
+	}
+
 	// Set the required namespaces in the engine config.
 	l.setNamespaces()
 	// Set the container environment.
@@ -559,11 +575,11 @@ func (l *Launcher) checkEncryptionKey(img *imgutil.Image) error {
 	if part.Type == imgutil.ENCRYPTSQUASHFS {
 		sylog.Debugf("Encrypted container filesystem detected")
 
-		if l.cfg.KeyInfo == nil {
+		if len(l.cfg.KeyInfos) == 0 {
 			return fmt.Errorf("no key was provided, cannot access encrypted container")
 		}
 
-		plaintextKey, err := cryptkey.PlaintextKey(*l.cfg.KeyInfo, l.engineConfig.GetImage())
+		plaintextKey, err := cryptkey.PlaintextKeyFromKeys(l.cfg.KeyInfos, l.engineConfig.GetImage())
 		if err != nil {
 			sylog.Errorf("Please check you are providing the correct key for decryption")
 			return fmt.Errorf("cannot decrypt %s: %w", l.engineConfig.GetImage(), err)
@@ -947,6 +963,9 @@ func (l *Launcher) setEnv(ctx context.Context, args []string) error {
 			}
 		}
 	}
+	// CDI device variables are set first, as they must not override
+	// SINGULARITYENV_ variables set on the host.
+	l.setCDIEnv()
 	// process --env and --env-file variables for injection
 	// into the environment by prefixing them with SINGULARITYENV_
 	for envName, envValue := range l.cfg.Env {
@@ -1082,6 +1101,12 @@ func (l *Launcher) prepareImage(c context.Context, image string) error {
 		}
 		// setuid, kernel extfs permitted, fuse not requested - no action needed
 		return nil
+	case imgutil.EROFS:
+		if !l.engineConfig.File.AllowKernelErofs || noKernelMount {
+			return l.prepareErofs(c, img, part, tryFuse)
+		}
+		// setuid, kernel erofs permitted, fuse not requested - no action needed
+		return nil
 	}
 
 	return fmt.Errorf("unsupported image rootfs type: %d", part.Type)
@@ -1183,6 +1208,35 @@ func (l *Launcher) prepareExtfs(ctx context.Context, img *imgutil.Image, tryFuse
 	return nil
 }
 
+func (l *Launcher) prepareErofs(ctx context.Context, img *imgutil.Image, part *imgutil.Section, tryFuse bool) error {
+	if !tryFuse {
+		return fmt.Errorf("erofs images must be kernel or FUSE mounted, extraction to a temporary sandbox is not supported")
+	}
+
+	// In fakeroot mode, the users is able to assume a subuid/subgid, so allow
+	// others to access the FUSE mount.
+	allowOther := l.cfg.Fakeroot
+
+	tempDir, imageDir, err := mkContainerDirs()
+	if err != nil {
+		return err
+	}
+
+	sylog.Infof("Mounting image with FUSE.")
+	if _, err := erofs.FUSEMount(ctx, part.Offset, img.Path, imageDir, allowOther); err != nil {
+		if err2 := os.RemoveAll(tempDir); err2 != nil {
+			sylog.Errorf("Couldn't remove temporary directory %s: %s", tempDir, err2)
+		}
+		return err
+	}
+
+	l.engineConfig.SetImage(imageDir)
+	l.engineConfig.SetImageFuse(true)
+	l.engineConfig.SetDeleteTempDir(tempDir)
+	l.generator.AddProcessEnv("SINGULARITY_CONTAINER", imageDir)
+	return nil
+}
+
 // mkContainerDirs creates a tempDir, with a nested 'root' imageDir that an image can be placed into.
 // The directory nesting is required so that extraction of an image doesn't apply permissions that
 // cause the tempDir to be accessible to others.
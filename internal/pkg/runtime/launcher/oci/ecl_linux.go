@@ -0,0 +1,51 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/sylabs/singularity/v4/internal/pkg/buildcfg"
+	"github.com/sylabs/singularity/v4/internal/pkg/syecl"
+	"github.com/sylabs/singularity/v4/internal/pkg/sypgp"
+)
+
+// checkECL verifies that the (OCI-)SIF image at imagePath may be run, with
+// respect to the Execution Control List configuration.
+//
+// OCI-mode is unprivileged, so that the ECL cannot be enforced against a user
+// who runs the image by other means. It is applied here so that OCI-SIF
+// images are subject to the same policy as native SIF images, where
+// singularity is used.
+func checkECL(ctx context.Context, imagePath string) error {
+	ecl, err := syecl.LoadConfig(buildcfg.ECL_FILE)
+	if err != nil {
+		return fmt.Errorf("while loading ECL configuration: %s", err)
+	}
+	if err = ecl.ValidateConfig(); err != nil {
+		return fmt.Errorf("while validating ECL configuration: %s", err)
+	}
+
+	// Only try to load the global keyring here if the ECL is active.
+	var kr openpgp.KeyRing = openpgp.EntityList{}
+	if ecl.Activated {
+		keyring := sypgp.NewHandle(buildcfg.SINGULARITY_CONFDIR, sypgp.GlobalHandleOpt())
+		kr, err = keyring.LoadPubKeyring()
+		if err != nil {
+			return fmt.Errorf("while obtaining keyring for ECL: %s", err)
+		}
+	}
+
+	if ok, err := ecl.ShouldRun(ctx, imagePath, kr); err != nil {
+		return fmt.Errorf("while checking container image with ECL: %s", err)
+	} else if !ok {
+		return errors.New("image prohibited by ECL")
+	}
+	return nil
+}
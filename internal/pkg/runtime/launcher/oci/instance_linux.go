@@ -0,0 +1,216 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/sylabs/singularity/v4/internal/pkg/instance"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/crypt"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/fs/fuse"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/rootless"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/fs/proc"
+	"golang.org/x/sys/unix"
+)
+
+// StartInstance creates and starts a container from the bundle at bundlePath,
+// as a named instance. The container is monitored by conmon, and continues to
+// run in the background after StartInstance returns. The image and overlay
+// mounts made into the bundle are left in place, to be removed by Cleanup when
+// the container exits.
+func (l *Launcher) StartInstance(ctx context.Context, name, containerID, bundlePath string, spec *specs.Spec) error {
+	file, err := instance.Add(name, instance.SingSubDir)
+	if err != nil {
+		return err
+	}
+
+	pw, err := rootless.GetUser()
+	if err != nil {
+		return err
+	}
+
+	// conmon writes both the stdout and stderr streams of the container to a
+	// single log file.
+	_, logPath, err := instance.GetLogFilePaths(name, instance.LogSubDir)
+	if err != nil {
+		return fmt.Errorf("could not find log paths: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0o700); err != nil {
+		return fmt.Errorf("failed to create instance log directory: %w", err)
+	}
+
+	absBundle, err := filepath.Abs(bundlePath)
+	if err != nil {
+		return fmt.Errorf("failed to determine bundle absolute path: %w", err)
+	}
+
+	if err := l.mountImages(ctx); err != nil {
+		return err
+	}
+	cleanupOverlays, err := l.mountOverlays(ctx, absBundle)
+	if err != nil {
+		l.unmountImages(ctx)
+		return err
+	}
+
+	systemdCgroups := l.systemdCgroups()
+	pid, conmonPid, err := CreateDetached(containerID, absBundle, logPath, systemdCgroups)
+	if err != nil {
+		cleanupOverlays()
+		l.unmountImages(ctx)
+		return fmt.Errorf("failed to start instance: %w", err)
+	}
+
+	// From here on, conmon will call Cleanup when the container exits, so
+	// failures are handled by killing the container.
+	sd, err := stateDir(containerID)
+	if err != nil {
+		return fmt.Errorf("while computing state directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(sd, instanceMarker), []byte(name), 0o600); err != nil {
+		killInstanceContainer(containerID)
+		return fmt.Errorf("while marking instance state directory: %w", err)
+	}
+
+	if err := Start(containerID, systemdCgroups); err != nil {
+		killInstanceContainer(containerID)
+		return fmt.Errorf("failed to start instance: %w", err)
+	}
+
+	file.User = pw.Username
+	file.Pid = pid
+	file.PPid = conmonPid
+	file.Image = instanceImage(l.image)
+	file.LogErrPath = logPath
+	file.LogOutPath = logPath
+	file.ContainerID = containerID
+	file.IP = l.instanceIP()
+	// As for native mode instances, we don't store the cgroup path. Stats are
+	// retrieved from the cgroup manager for the container pid.
+	file.Cgroup = spec.Linux != nil && spec.Linux.CgroupsPath != ""
+
+	// Store the runtime spec as the instance configuration.
+	file.Config, err = json.Marshal(spec)
+	if err != nil {
+		killInstanceContainer(containerID)
+		return err
+	}
+
+	if err := file.Update(); err != nil {
+		killInstanceContainer(containerID)
+		return err
+	}
+
+	sylog.Verbosef("you will find instance output here: %s", logPath)
+	sylog.Infof("instance started successfully")
+	return nil
+}
+
+// killInstanceContainer forcibly stops an instance container that could not be
+// started successfully.
+func killInstanceContainer(containerID string) {
+	if err := Kill(containerID, "SIGKILL"); err != nil {
+		sylog.Errorf("Couldn't kill container %s: %v", containerID, err)
+	}
+}
+
+// instanceImage returns the image reference recorded in the instance file, for
+// the normalized image reference used by the launcher.
+func instanceImage(image string) string {
+	for _, prefix := range []string{"oci-sif:", "sif:"} {
+		if path, ok := strings.CutPrefix(image, prefix); ok {
+			if abs, err := filepath.Abs(path); err == nil {
+				return abs
+			}
+			return path
+		}
+	}
+	return image
+}
+
+// Cleanup deletes the container from the runtime once it has exited. It is
+// called by conmon as the exit command for the container. If the container was
+// started as an instance, any CNI networks are removed, all mounts beneath the
+// instance bundle are removed, and the bundle itself is deleted.
+func Cleanup(ctx context.Context, containerID string, systemdCgroups bool) error {
+	sd, err := stateDir(containerID)
+	if err != nil {
+		return fmt.Errorf("while computing state directory: %w", err)
+	}
+
+	bundle, err := filepath.EvalSymlinks(filepath.Join(sd, bundleLink))
+	if err != nil {
+		return fmt.Errorf("while finding bundle directory: %w", err)
+	}
+
+	if err := Delete(ctx, containerID, systemdCgroups); err != nil {
+		return err
+	}
+
+	// Bundles that were not created for an instance are owned by the caller of
+	// `oci create`, so must be left alone.
+	if _, err := os.Stat(filepath.Join(sd, instanceMarker)); os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := cleanupBundleNetwork(ctx, bundle); err != nil {
+		sylog.Errorf("Couldn't clean up network: %v", err)
+	}
+
+	if err := unmountBundle(ctx, bundle); err != nil {
+		return err
+	}
+
+	sylog.Debugf("Removing OCI bundle at: %s", bundle)
+	if err := fs.ForceRemoveAll(bundle); err != nil {
+		return fmt.Errorf("while removing bundle: %w", err)
+	}
+
+	sylog.Debugf("Removing state directory at: %s", sd)
+	return os.RemoveAll(sd)
+}
+
+// unmountBundle unmounts everything mounted at or beneath bundle, in reverse
+// order of mounting. FUSE mounts are unmounted in a manner that allows the
+// FUSE process to exit. The crypt devices backing any encrypted layers are
+// closed once they have been unmounted.
+func unmountBundle(ctx context.Context, bundle string) error {
+	entries, err := proc.GetMountInfoEntry("/proc/self/mountinfo")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range slices.Backward(entries) {
+		if e.Point != bundle && !strings.HasPrefix(e.Point, bundle+"/") {
+			continue
+		}
+		sylog.Debugf("Unmounting %s (%s)", e.Point, e.FSType)
+		if strings.HasPrefix(e.FSType, "fuse") {
+			err = fuse.UnmountWithFuse(ctx, e.Point)
+		} else {
+			err = unix.Unmount(e.Point, unix.MNT_DETACH)
+		}
+		if err != nil {
+			return fmt.Errorf("while unmounting %s: %w", e.Point, err)
+		}
+		if deviceName, ok := strings.CutPrefix(e.Source, "/dev/mapper/"); ok {
+			sylog.Debugf("Closing crypt device %s", deviceName)
+			if err := (&crypt.Device{}).CloseCryptDevice(deviceName); err != nil {
+				sylog.Errorf("Couldn't close crypt device %s: %v", deviceName, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstanceImage(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		image string
+		want  string
+	}{
+		{
+			name:  "OCI-SIF absolute",
+			image: "oci-sif:/images/alpine.sif",
+			want:  "/images/alpine.sif",
+		},
+		{
+			name:  "OCI-SIF relative",
+			image: "oci-sif:alpine.sif",
+			want:  filepath.Join(cwd, "alpine.sif"),
+		},
+		{
+			name:  "native SIF",
+			image: "sif:/images/native.sif",
+			want:  "/images/native.sif",
+		},
+		{
+			name:  "docker",
+			image: "docker://alpine:latest",
+			want:  "docker://alpine:latest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := instanceImage(tt.image); got != tt.want {
+				t.Errorf("instanceImage(%q) = %q, want %q", tt.image, got, tt.want)
+			}
+		})
+	}
+}
@@ -30,7 +30,9 @@ import (
 	"github.com/sylabs/singularity/v4/internal/pkg/buildcfg"
 	"github.com/sylabs/singularity/v4/internal/pkg/cache"
 	"github.com/sylabs/singularity/v4/internal/pkg/cgroups"
+	"github.com/sylabs/singularity/v4/internal/pkg/instance"
 	"github.com/sylabs/singularity/v4/internal/pkg/ociimage"
+	"github.com/sylabs/singularity/v4/internal/pkg/ocisif"
 	"github.com/sylabs/singularity/v4/internal/pkg/runtime/launcher"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/env"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/fs"
@@ -39,12 +41,14 @@ import (
 	"github.com/sylabs/singularity/v4/internal/pkg/util/rootless"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/shell"
 	imgutil "github.com/sylabs/singularity/v4/pkg/image"
+	"github.com/sylabs/singularity/v4/pkg/network"
 	"github.com/sylabs/singularity/v4/pkg/ocibundle"
 	"github.com/sylabs/singularity/v4/pkg/ocibundle/native"
 	ocisifbundle "github.com/sylabs/singularity/v4/pkg/ocibundle/ocisif"
 	sifbundle "github.com/sylabs/singularity/v4/pkg/ocibundle/sif"
 	"github.com/sylabs/singularity/v4/pkg/ocibundle/tools"
 	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/cryptkey"
 	"github.com/sylabs/singularity/v4/pkg/util/singularityconf"
 	"golang.org/x/sys/unix"
 	"tags.cncf.io/container-device-interface/pkg/cdi"
@@ -92,6 +96,8 @@ type Launcher struct {
 	cgroupsSupport bool
 	// cgroupsV2 indicates if the system is running cgroups v2
 	cgroupsV2 bool
+	// networkSetup is the CNI network setup applied to the container, if any.
+	networkSetup *network.Setup
 }
 
 // NewLauncher returns a oci.Launcher with an initial configuration set by opts.
@@ -107,6 +113,14 @@ func NewLauncher(opts ...launcher.Option) (*Launcher, error) {
 		return nil, err
 	}
 
+	if err := applyContainOpts(&lo); err != nil {
+		return nil, err
+	}
+
+	if err := checkNetwork(lo); err != nil {
+		return nil, err
+	}
+
 	c := singularityconf.GetCurrentConfig()
 	if c == nil {
 		return nil, fmt.Errorf("singularity configuration is not initialized")
@@ -166,57 +180,68 @@ func checkOpts(lo launcher.Options) error {
 		badOpt = append(badOpt, "Proot")
 	}
 
-	// Network always set in CLI layer even if network namespace not requested.
-	// We only support isolation at present
-	if lo.Namespaces.Net && lo.Network != "none" {
-		badOpt = append(badOpt, "Network (except none)")
+	// ConfigFile always set by CLI. We should support only the default from build time.
+	if lo.ConfigFile != "" && lo.ConfigFile != buildcfg.SINGULARITY_CONF_FILE {
+		badOpt = append(badOpt, "ConfigFile")
 	}
 
-	if len(lo.NetworkArgs) > 0 {
-		badOpt = append(badOpt, "NetworkArgs")
+	if lo.ShellPath != "" {
+		badOpt = append(badOpt, "ShellPath")
 	}
 
-	// No join of an existing network namespace
-	if lo.NetnsPath != "" {
-		badOpt = append(badOpt, "NetnsPath")
+	if lo.SIFFUSE {
+		badOpt = append(badOpt, "SIFFUSE")
 	}
 
-	if len(lo.SecurityOpts) > 0 {
-		badOpt = append(badOpt, "SecurityOpts")
+	if lo.RestartPolicy != "" {
+		badOpt = append(badOpt, "RestartPolicy")
 	}
 
-	// ConfigFile always set by CLI. We should support only the default from build time.
-	if lo.ConfigFile != "" && lo.ConfigFile != buildcfg.SINGULARITY_CONF_FILE {
-		badOpt = append(badOpt, "ConfigFile")
+	if lo.HealthCmd != "" {
+		badOpt = append(badOpt, "HealthCmd")
 	}
 
-	if lo.ShellPath != "" {
-		badOpt = append(badOpt, "ShellPath")
+	if len(badOpt) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnsupportedOption, strings.Join(badOpt, ","))
 	}
 
+	return nil
+}
+
+// applyContainOpts applies the options inferred by --containall, --boot and
+// --no-init, in the same manner as the native launcher.
+func applyContainOpts(lo *launcher.Options) error {
 	if lo.Boot {
-		badOpt = append(badOpt, "Boot")
-	}
-	if lo.NoInit {
-		badOpt = append(badOpt, "NoInit")
-	}
-	if lo.Contain {
-		badOpt = append(badOpt, "Contain")
-	}
-	if lo.ContainAll {
-		badOpt = append(badOpt, "ContainAll")
+		// We are always root, or fake root, when the launcher runs, so must
+		// check against the host uid.
+		uid, err := rootless.Getuid()
+		if err != nil {
+			return err
+		}
+		if uid != 0 {
+			return fmt.Errorf("--boot requires root privileges")
+		}
+		// The init process is given its own hostname and network namespace.
+		lo.Namespaces.UTS = true
+		lo.Namespaces.Net = true
 	}
 
-	if lo.KeyInfo != nil {
-		badOpt = append(badOpt, "KeyInfo")
+	// --containall or --boot infer --contain.
+	if lo.ContainAll || lo.Boot {
+		lo.Contain = true
 	}
 
-	if lo.SIFFUSE {
-		badOpt = append(badOpt, "SIFFUSE")
+	// --containall infers PID/IPC isolation and a clean environment. The OCI
+	// runtime always uses an IPC namespace, and a PID namespace unless
+	// --no-pid is specified, so only the environment must be handled here.
+	if lo.ContainAll {
+		lo.CleanEnv = true
 	}
 
-	if len(badOpt) > 0 {
-		return fmt.Errorf("%w: %s", ErrUnsupportedOption, strings.Join(badOpt, ","))
+	// The container process is always PID 1 in OCI-mode, as there is no
+	// shim process to disable.
+	if lo.NoInit {
+		sylog.Debugf("OCI-mode does not run a shim process, --no-init is redundant.")
 	}
 
 	return nil
@@ -371,6 +396,20 @@ func (l *Launcher) finalizeSpec(ctx context.Context, b ocibundle.Bundle, spec *s
 		targetGID = 0
 	}
 
+	// As root, a specific uid / gids may be requested with --security options.
+	securityUID, securityGIDs, err := l.getSecurityIDs()
+	if err != nil {
+		return err
+	}
+	if securityUID != nil {
+		targetUID = *securityUID
+		containerUser = true
+	}
+	if len(securityGIDs) > 0 {
+		targetGID = securityGIDs[0]
+		containerUser = true
+	}
+
 	if targetUID != 0 && currentUID != 0 {
 		uidMap, gidMap, err := getReverseUserMaps(currentUID, uint32(rootlessUID), uint32(rootlessGID))
 		if err != nil {
@@ -389,6 +428,9 @@ func (l *Launcher) finalizeSpec(ctx context.Context, b ocibundle.Bundle, spec *s
 		UID: targetUID,
 		GID: targetGID,
 	}
+	if len(securityGIDs) > 1 {
+		u.AdditionalGids = securityGIDs[1:]
+	}
 	// In native mode emulation (--no-compat) propagate umask unless --no-umask set
 	if l.cfg.NoCompat && !l.cfg.NoUmask {
 		currentMask := unix.Umask(0)
@@ -406,6 +448,16 @@ func (l *Launcher) finalizeSpec(ctx context.Context, b ocibundle.Bundle, spec *s
 	}
 	spec.Process = specProcess
 
+	if l.cfg.Boot {
+		if err := l.addBoot(spec, ep.Instance); err != nil {
+			return err
+		}
+	}
+
+	if err := l.addSecurity(spec); err != nil {
+		return err
+	}
+
 	if l.nativeSIF {
 		envMount, err := l.prepareNativeEnv(b.Path(), userEnv)
 		if err != nil {
@@ -690,7 +742,9 @@ fi
 // image is a reference to an OCI image, e.g. docker://ubuntu or oci:/tmp/mycontainer
 func (l *Launcher) Exec(ctx context.Context, ep launcher.ExecParams) error {
 	if ep.Instance != "" {
-		return fmt.Errorf("%w: instanceName", ErrNotImplemented)
+		if err := instance.CheckName(ep.Instance); err != nil {
+			return err
+		}
 	}
 
 	if l.cfg.TransportOptions == nil {
@@ -712,7 +766,13 @@ func (l *Launcher) Exec(ctx context.Context, ep launcher.ExecParams) error {
 	if err != nil {
 		return err
 	}
+	// The bundle of a successfully started instance must outlive us. It is
+	// removed by Cleanup, when conmon reports that the instance has exited.
+	keepBundle := false
 	defer func() {
+		if keepBundle {
+			return
+		}
 		sylog.Debugf("Removing OCI bundle at: %s", bundleDir)
 		if cleanupErr := fs.ForceRemoveAll(bundleDir); cleanupErr != nil {
 			sylog.Errorf("Couldn't remove OCI bundle %s: %v", bundleDir, cleanupErr)
@@ -741,12 +801,22 @@ func (l *Launcher) Exec(ctx context.Context, ep launcher.ExecParams) error {
 	var b ocibundle.Bundle
 	switch {
 	case strings.HasPrefix(image, "oci-sif:"):
+		if err := l.checkEncryption(strings.TrimPrefix(image, "oci-sif:")); err != nil {
+			return err
+		}
+		if err := checkECL(ctx, strings.TrimPrefix(image, "oci-sif:")); err != nil {
+			return err
+		}
 		b, err = ocisifbundle.New(
 			ocisifbundle.OptBundlePath(bundleDir),
 			ocisifbundle.OptImageRef(image),
+			ocisifbundle.OptKeyInfo(l.keyInfo()),
 		)
 	case strings.HasPrefix(image, "sif:"):
 		sylog.Infof("Running a non-OCI SIF in OCI mode. See user guide for compatibility information.")
+		if err := checkECL(ctx, strings.TrimPrefix(image, "sif:")); err != nil {
+			return err
+		}
 		b, err = sifbundle.FromSif(
 			strings.TrimPrefix(image, "sif:"),
 			bundleDir,
@@ -775,19 +845,52 @@ func (l *Launcher) Exec(ctx context.Context, ep launcher.ExecParams) error {
 		return err
 	}
 
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return fmt.Errorf("while generating container id: %w", err)
+	}
+
+	// CNI networks are added to a network namespace created ahead of the
+	// container, so they are torn down once the container has exited. For an
+	// instance this happens in Cleanup.
+	cleanupNetwork, err := l.setupNetwork(ctx, id.String(), b.Path(), spec)
+	if err != nil {
+		return err
+	}
+	teardownNetwork := func() {
+		if err := cleanupNetwork(context.Background()); err != nil { //nolint:contextcheck
+			sylog.Errorf("Couldn't clean up network: %v", err)
+		}
+	}
+
 	// With reference to the bundle's image spec, now set the process configuration.
 	if err := l.finalizeSpec(ctx, b, spec, ep); err != nil {
+		teardownNetwork()
 		return err
 	}
 
-	id, err := uuid.NewRandom()
-	if err != nil {
-		return fmt.Errorf("while generating container id: %w", err)
+	if ep.Instance != "" {
+		// Create and start the container under conmon, in the background.
+		err = l.StartInstance(ctx, ep.Instance, id.String(), b.Path(), spec)
+		if err == nil {
+			keepBundle = true
+		} else {
+			teardownNetwork()
+			if cleanupErr := b.Delete(context.Background()); cleanupErr != nil { //nolint:contextcheck
+				sylog.Errorf("Couldn't cleanup bundle: %v", cleanupErr)
+			}
+		}
+		// Any image pulled to a temporary dir is already held open by the
+		// instance mounts.
+		l.removePullTempDir()
+		return err
 	}
 
 	// Execution of runc/crun run, wrapped with overlay prep / cleanup.
 	err = l.RunWrapped(ctx, id.String(), b.Path(), "")
 
+	teardownNetwork()
+
 	// Unmounts pristine rootfs from bundle, and removes the bundle. We want to
 	// make a best effort here even if the main context has been canceled, hence
 	// the use of context.Background().
@@ -799,14 +902,7 @@ func (l *Launcher) Exec(ctx context.Context, ep launcher.ExecParams) error {
 		sylog.Errorf("Couldn't unmount session directory: %v", err)
 	}
 
-	// If the original image was implicitly pulled to a temporary dir due to
-	// disabled cache, remove it.
-	if l.cfg.PullTempDir != "" {
-		sylog.Debugf("Removing image pull temp dir: %s", l.cfg.PullTempDir)
-		if cleanupErr := fs.ForceRemoveAll(l.cfg.PullTempDir); cleanupErr != nil {
-			sylog.Errorf("Couldn't remove image pull temp dir %s: %v", l.cfg.PullTempDir, cleanupErr)
-		}
-	}
+	l.removePullTempDir()
 
 	if e, ok := err.(*exec.ExitError); ok {
 		status, ok := e.Sys().(syscall.WaitStatus)
@@ -821,6 +917,18 @@ func (l *Launcher) Exec(ctx context.Context, ep launcher.ExecParams) error {
 	return err
 }
 
+// removePullTempDir removes the temporary directory that the original image
+// was implicitly pulled to, due to a disabled cache, if applicable.
+func (l *Launcher) removePullTempDir() {
+	if l.cfg.PullTempDir == "" {
+		return
+	}
+	sylog.Debugf("Removing image pull temp dir: %s", l.cfg.PullTempDir)
+	if cleanupErr := fs.ForceRemoveAll(l.cfg.PullTempDir); cleanupErr != nil {
+		sylog.Errorf("Couldn't remove image pull temp dir %s: %v", l.cfg.PullTempDir, cleanupErr)
+	}
+}
+
 // RunWrapped runs a container via the OCI runtime, wrapped with prep / cleanup steps.
 func (l *Launcher) RunWrapped(ctx context.Context, containerID, bundlePath, pidFile string) error {
 	absBundle, err := filepath.Abs(bundlePath)
@@ -833,28 +941,13 @@ func (l *Launcher) RunWrapped(ctx context.Context, containerID, bundlePath, pidF
 			return fmt.Errorf("failed to change directory to %s: %s", absBundle, err)
 		}
 
-		for _, im := range l.imageMountsByMountpoint {
-			if err := os.MkdirAll(im.GetMountPoint(), 0o755); err != nil {
-				return err
-			}
-			if err := im.Mount(ctx); err != nil {
-				return err
-			}
-		}
-
-		systemdCgroups := l.singularityConf.SystemdCgroups
-		// If singularity.conf is set to use systemd for cgroup management, but
-		// we cannot due faulty configuration / environment (e.g. no Dbus),
-		// don't ask runc/crun to use systemd.
-		if systemdCgroups && !l.cgroupsSupport {
-			systemdCgroups = false
+		if err := l.mountImages(ctx); err != nil {
+			return err
 		}
 
-		err = Run(ctx, containerID, absBundle, pidFile, systemdCgroups)
+		err = Run(ctx, containerID, absBundle, pidFile, l.systemdCgroups())
 
-		for _, im := range l.imageMountsByMountpoint {
-			im.Unmount(ctx)
-		}
+		l.unmountImages(ctx)
 
 		return err
 	}
@@ -862,6 +955,36 @@ func (l *Launcher) RunWrapped(ctx context.Context, containerID, bundlePath, pidF
 	return l.WrapWithOverlays(ctx, runFunc, absBundle)
 }
 
+// mountImages carries out the FUSE image mounts that are required before the
+// container is run.
+func (l *Launcher) mountImages(ctx context.Context) error {
+	for _, im := range l.imageMountsByMountpoint {
+		if err := os.MkdirAll(im.GetMountPoint(), 0o755); err != nil {
+			return err
+		}
+		if err := im.Mount(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmountImages unmounts the FUSE image mounts carried out by mountImages.
+func (l *Launcher) unmountImages(ctx context.Context) {
+	for _, im := range l.imageMountsByMountpoint {
+		im.Unmount(ctx)
+	}
+}
+
+// systemdCgroups returns true if runc/crun should be asked to manage cgroups
+// via systemd.
+func (l *Launcher) systemdCgroups() bool {
+	// If singularity.conf is set to use systemd for cgroup management, but
+	// we cannot due faulty configuration / environment (e.g. no Dbus),
+	// don't ask runc/crun to use systemd.
+	return l.singularityConf.SystemdCgroups && l.cgroupsSupport
+}
+
 // getCgroup will return a cgroup path and resources for the runtime to create.
 func (l *Launcher) getCgroup() (path string, resources *specs.LinuxResources, err error) {
 	// We can't create a cgroup, but we don't have any resource limits to apply.
@@ -954,6 +1077,39 @@ func CrunNestCgroup() error {
 	return nil
 }
 
+// checkEncryption verifies that the OCI-SIF image at imagePath may be run, with
+// respect to the encryption of its layers and the key material provided.
+func (l *Launcher) checkEncryption(imagePath string) error {
+	encrypted, err := ocisif.IsEncrypted(imagePath)
+	if err != nil {
+		return fmt.Errorf("while checking for encrypted layers: %w", err)
+	}
+	if encrypted && !l.singularityConf.AllowContainerEncrypted {
+		return fmt.Errorf("configuration disallows users from running encrypted SIF containers")
+	}
+	// Decrypted layers are mounted with the kernel squashfs driver.
+	if encrypted && !l.singularityConf.AllowKernelSquashfs {
+		return fmt.Errorf("configuration disallows kernel squashfs mounts, which are required to run encrypted OCI-SIF images")
+	}
+	if !encrypted && len(l.cfg.KeyInfos) > 0 {
+		sylog.Warningf("Image is not encrypted, ignoring key material provided with --pem-path / --pgp-key / --passphrase")
+	}
+	return nil
+}
+
+// keyInfo returns the key material used to decrypt the layers of an OCI-SIF
+// image. An OCI-SIF image has a single recipient, so only the first key
+// provided is used.
+func (l *Launcher) keyInfo() *cryptkey.KeyInfo {
+	if len(l.cfg.KeyInfos) == 0 {
+		return nil
+	}
+	if len(l.cfg.KeyInfos) > 1 {
+		sylog.Warningf("Only the first key provided is used to decrypt an OCI-SIF image")
+	}
+	return &l.cfg.KeyInfos[0]
+}
+
 // normalizeImageRef transforms a bare image path to an oci-sif: or sif: prefixed path,
 // after checking the image is an oci-sif or native (non-oci) sif.
 func normalizeImageRef(imageRef string) (string, error) {
@@ -980,6 +1136,8 @@ func normalizeImageRef(imageRef string) (string, error) {
 		return "", fmt.Errorf("OCI mode cannot run bare squashfs images")
 	case imgutil.EXT3:
 		return "", fmt.Errorf("OCI mode cannot run bare ext3 images")
+	case imgutil.EROFS:
+		return "", fmt.Errorf("OCI mode cannot run bare erofs images")
 	case imgutil.SANDBOX:
 		return "", fmt.Errorf("OCI mode cannot run bare directory/sandbox images")
 	case imgutil.SIF:
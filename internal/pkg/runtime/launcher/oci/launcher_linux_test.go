@@ -1,4 +1,4 @@
-// Copyright (c) 2022, Sylabs Inc. All rights reserved.
+// Copyright (c) 2022-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -6,12 +6,23 @@
 package oci
 
 import (
+	"os"
 	"os/user"
+	"path/filepath"
 	"reflect"
+	"slices"
 	"testing"
 
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	ggcrmutate "github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/static"
 	lccgroups "github.com/opencontainers/cgroups"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/sylabs/singularity/v4/internal/pkg/cgroups"
+	"github.com/sylabs/singularity/v4/internal/pkg/ocisif"
 	"github.com/sylabs/singularity/v4/internal/pkg/runtime/launcher"
 	"github.com/sylabs/singularity/v4/internal/pkg/test"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/fs/fuse"
@@ -129,10 +140,72 @@ func TestNewLauncher(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "contain",
+			opts: []launcher.Option{
+				launcher.OptContain(true),
+			},
+			want: &Launcher{
+				cfg:                     launcher.Options{Contain: true, WritableTmpfs: true},
+				singularityConf:         sc,
+				homeHost:                u.HomeDir,
+				homeSrc:                 "",
+				homeDest:                u.HomeDir,
+				imageMountsByImagePath:  make(map[string]*fuse.ImageMount),
+				imageMountsByMountpoint: make(map[string]*fuse.ImageMount),
+				cgroupsV2:               cgroupsV2,
+				cgroupsSupport:          cgroupsSupport,
+			},
+			wantErr: false,
+		},
+		{
+			name: "containall",
+			opts: []launcher.Option{
+				launcher.OptContainAll(true),
+			},
+			want: &Launcher{
+				cfg:                     launcher.Options{ContainAll: true, Contain: true, CleanEnv: true, WritableTmpfs: true},
+				singularityConf:         sc,
+				homeHost:                u.HomeDir,
+				homeSrc:                 "",
+				homeDest:                u.HomeDir,
+				imageMountsByImagePath:  make(map[string]*fuse.ImageMount),
+				imageMountsByMountpoint: make(map[string]*fuse.ImageMount),
+				cgroupsV2:               cgroupsV2,
+				cgroupsSupport:          cgroupsSupport,
+			},
+			wantErr: false,
+		},
+		{
+			name: "no-init",
+			opts: []launcher.Option{
+				launcher.OptNoInit(true),
+			},
+			want: &Launcher{
+				cfg:                     launcher.Options{NoInit: true, WritableTmpfs: true},
+				singularityConf:         sc,
+				homeHost:                u.HomeDir,
+				homeSrc:                 "",
+				homeDest:                u.HomeDir,
+				imageMountsByImagePath:  make(map[string]*fuse.ImageMount),
+				imageMountsByMountpoint: make(map[string]*fuse.ImageMount),
+				cgroupsV2:               cgroupsV2,
+				cgroupsSupport:          cgroupsSupport,
+			},
+			wantErr: false,
+		},
+		{
+			name: "bootUnprivileged",
+			opts: []launcher.Option{
+				launcher.OptBoot(true),
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "unsupportedOption",
 			opts: []launcher.Option{
-				launcher.OptSecurity([]string{"seccomp:example.json"}),
+				launcher.OptShellPath("/bin/bash"),
 			},
 			want:    nil,
 			wantErr: true,
@@ -209,3 +282,327 @@ func Test_normalizeImageRef(t *testing.T) {
 		})
 	}
 }
+
+func Test_applyContainOpts(t *testing.T) {
+	test.EnsurePrivilege(t)
+
+	tests := []struct {
+		name string
+		lo   launcher.Options
+		want launcher.Options
+	}{
+		{
+			name: "none",
+			lo:   launcher.Options{},
+			want: launcher.Options{},
+		},
+		{
+			name: "contain",
+			lo:   launcher.Options{Contain: true},
+			want: launcher.Options{Contain: true},
+		},
+		{
+			name: "containall",
+			lo:   launcher.Options{ContainAll: true},
+			want: launcher.Options{ContainAll: true, Contain: true, CleanEnv: true},
+		},
+		{
+			name: "containallNoPID",
+			lo:   launcher.Options{ContainAll: true, Namespaces: launcher.Namespaces{NoPID: true}},
+			want: launcher.Options{ContainAll: true, Contain: true, CleanEnv: true, Namespaces: launcher.Namespaces{NoPID: true}},
+		},
+		{
+			name: "boot",
+			lo:   launcher.Options{Boot: true},
+			want: launcher.Options{Boot: true, Contain: true, Namespaces: launcher.Namespaces{UTS: true, Net: true}},
+		},
+		{
+			name: "no-init",
+			lo:   launcher.Options{NoInit: true},
+			want: launcher.Options{NoInit: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lo := tt.lo
+			if err := applyContainOpts(&lo); err != nil {
+				t.Fatalf("applyContainOpts() error = %v", err)
+			}
+			if !reflect.DeepEqual(lo, tt.want) {
+				t.Errorf("applyContainOpts() = %+v, want %+v", lo, tt.want)
+			}
+		})
+	}
+}
+
+func TestLauncher_containMounts(t *testing.T) {
+	test.DropPrivilege(t)
+	defer test.ResetPrivilege(t)
+
+	sc, err := singularityconf.GetConfig(nil)
+	if err != nil {
+		t.Fatalf("while initializing singularityconf: %s", err)
+	}
+	const homeDest = "/home/test"
+	homeHost := t.TempDir()
+	workDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		cfg         launcher.Options
+		wantHomeSrc string
+		wantTmpSrc  string
+		wantCwd     bool
+	}{
+		{
+			name:        "no-compat",
+			cfg:         launcher.Options{NoCompat: true},
+			wantHomeSrc: homeHost,
+			wantTmpSrc:  "/tmp",
+			wantCwd:     true,
+		},
+		{
+			name:        "no-compat contain",
+			cfg:         launcher.Options{NoCompat: true, Contain: true},
+			wantHomeSrc: "tmpfs",
+			wantTmpSrc:  "tmpfs",
+		},
+		{
+			name:        "no-compat containall",
+			cfg:         launcher.Options{NoCompat: true, ContainAll: true},
+			wantHomeSrc: "tmpfs",
+			wantTmpSrc:  "tmpfs",
+		},
+		{
+			name:        "contain workdir",
+			cfg:         launcher.Options{Contain: true, WorkDir: workDir},
+			wantHomeSrc: filepath.Join(workDir, "home"),
+			wantTmpSrc:  filepath.Join(workDir, "tmp"),
+		},
+		{
+			name:        "compat workdir",
+			cfg:         launcher.Options{WorkDir: workDir},
+			wantHomeSrc: "tmpfs",
+			wantTmpSrc:  filepath.Join(workDir, "tmp"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Launcher{
+				cfg:             tt.cfg,
+				singularityConf: sc,
+				homeHost:        homeHost,
+				homeDest:        homeDest,
+			}
+
+			mounts := &[]specs.Mount{}
+			if err := l.addTmpMounts(mounts); err != nil {
+				t.Fatalf("addTmpMounts() error = %v", err)
+			}
+			if err := l.addHomeMount(mounts); err != nil {
+				t.Fatalf("addHomeMount() error = %v", err)
+			}
+
+			for _, m := range *mounts {
+				switch m.Destination {
+				case "/tmp":
+					if m.Source != tt.wantTmpSrc {
+						t.Errorf("/tmp source = %q, want %q", m.Source, tt.wantTmpSrc)
+					}
+				case homeDest:
+					if m.Source != tt.wantHomeSrc {
+						t.Errorf("home source = %q, want %q", m.Source, tt.wantHomeSrc)
+					}
+				}
+			}
+
+			cwd, err := l.getProcessCwd(imgspecv1.Image{})
+			if err != nil {
+				t.Fatalf("getProcessCwd() error = %v", err)
+			}
+			wd, err := os.Getwd()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (cwd == wd) != tt.wantCwd {
+				t.Errorf("getProcessCwd() = %q, host cwd %q, wantCwd %v", cwd, wd, tt.wantCwd)
+			}
+		})
+	}
+}
+
+func TestLauncher_addBoot(t *testing.T) {
+	allCaps := []string{"CAP_CHOWN", "CAP_SYS_BOOT", "CAP_SYS_RAWIO"}
+
+	tests := []struct {
+		name         string
+		cfg          launcher.Options
+		cgroupsV2    bool
+		instanceName string
+		hostname     string
+		wantHostname string
+		wantCaps     []string
+		wantErr      bool
+	}{
+		{
+			name:         "NoInstance",
+			cgroupsV2:    true,
+			instanceName: "",
+			wantErr:      true,
+		},
+		{
+			name:         "CgroupsV1",
+			cgroupsV2:    false,
+			instanceName: "test",
+			wantErr:      true,
+		},
+		{
+			name:         "Default",
+			cgroupsV2:    true,
+			instanceName: "test",
+			wantHostname: "test",
+			wantCaps:     []string{"CAP_CHOWN"},
+		},
+		{
+			name:         "Hostname",
+			cgroupsV2:    true,
+			instanceName: "test",
+			hostname:     "myhost",
+			wantHostname: "myhost",
+			wantCaps:     []string{"CAP_CHOWN"},
+		},
+		{
+			name:         "KeepPrivs",
+			cfg:          launcher.Options{KeepPrivs: true},
+			cgroupsV2:    true,
+			instanceName: "test",
+			wantHostname: "test",
+			wantCaps:     allCaps,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Launcher{cfg: tt.cfg, cgroupsV2: tt.cgroupsV2}
+			spec := minimalSpec()
+			spec.Hostname = tt.hostname
+			spec.Process.Capabilities = &specs.LinuxCapabilities{
+				Bounding:  slices.Clone(allCaps),
+				Effective: slices.Clone(allCaps),
+				Permitted: slices.Clone(allCaps),
+			}
+
+			err := l.addBoot(&spec, tt.instanceName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("addBoot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if !reflect.DeepEqual(spec.Process.Args, []string{"/sbin/init"}) {
+				t.Errorf("addBoot() args = %v, want [/sbin/init]", spec.Process.Args)
+			}
+			if spec.Hostname != tt.wantHostname {
+				t.Errorf("addBoot() hostname = %q, want %q", spec.Hostname, tt.wantHostname)
+			}
+			if !reflect.DeepEqual(spec.Process.Capabilities.Bounding, tt.wantCaps) {
+				t.Errorf("addBoot() bounding caps = %v, want %v", spec.Process.Capabilities.Bounding, tt.wantCaps)
+			}
+			if !reflect.DeepEqual(spec.Process.Capabilities.Effective, tt.wantCaps) {
+				t.Errorf("addBoot() effective caps = %v, want %v", spec.Process.Capabilities.Effective, tt.wantCaps)
+			}
+			if !slices.ContainsFunc(spec.Linux.Namespaces, func(ns specs.LinuxNamespace) bool {
+				return ns.Type == specs.CgroupNamespace
+			}) {
+				t.Errorf("addBoot() did not add cgroup namespace")
+			}
+		})
+	}
+}
+
+// writeTestOCISIF writes an OCI-SIF holding img to dir, without converting or
+// encrypting its layers.
+func writeTestOCISIF(t *testing.T, dir string, img ggcrv1.Image) string {
+	t.Helper()
+
+	imgFile := filepath.Join(dir, "image.oci.sif")
+	iw, err := ocisif.NewImageWriter(img, imgFile, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := iw.Write(); err != nil {
+		t.Fatal(err)
+	}
+	return imgFile
+}
+
+func TestLauncher_checkEncryption(t *testing.T) {
+	plainImg, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plainFile := writeTestOCISIF(t, t.TempDir(), plainImg)
+
+	// IsEncrypted inspects layer media types only, so the layer content need
+	// not be a LUKS2 container.
+	encryptedImg, err := ggcrmutate.AppendLayers(empty.Image,
+		static.NewLayer([]byte("encrypted"), ocisif.EncryptedSquashfsLayerMediaType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedFile := writeTestOCISIF(t, t.TempDir(), encryptedImg)
+
+	tests := []struct {
+		name            string
+		imagePath       string
+		allowEncrypted  bool
+		allowKernelSqfs bool
+		wantErr         bool
+	}{
+		{
+			name:            "Plain",
+			imagePath:       plainFile,
+			allowEncrypted:  true,
+			allowKernelSqfs: true,
+		},
+		{
+			name:            "PlainNoKernelSquashfs",
+			imagePath:       plainFile,
+			allowEncrypted:  true,
+			allowKernelSqfs: false,
+		},
+		{
+			name:            "Encrypted",
+			imagePath:       encryptedFile,
+			allowEncrypted:  true,
+			allowKernelSqfs: true,
+		},
+		{
+			name:            "EncryptedNotAllowed",
+			imagePath:       encryptedFile,
+			allowEncrypted:  false,
+			allowKernelSqfs: true,
+			wantErr:         true,
+		},
+		{
+			name:            "EncryptedNoKernelSquashfs",
+			imagePath:       encryptedFile,
+			allowEncrypted:  true,
+			allowKernelSqfs: false,
+			wantErr:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Launcher{
+				singularityConf: &singularityconf.File{
+					AllowContainerEncrypted: tt.allowEncrypted,
+					AllowKernelSquashfs:     tt.allowKernelSqfs,
+				},
+			}
+			if err := l.checkEncryption(tt.imagePath); (err != nil) != tt.wantErr {
+				t.Errorf("checkEncryption() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -66,8 +66,10 @@ func (l *Launcher) getMounts() ([]specs.Mount, error) {
 	if err := l.addScratchMounts(mounts); err != nil {
 		return nil, fmt.Errorf("while configuring scratch mount(s): %w", err)
 	}
-	// System bind path mounts (singularity.conf) are only added with --no-compat (native emulation)
-	if l.cfg.NoCompat {
+	// System bind path mounts (singularity.conf) and the cwd are only added
+	// with --no-compat (native emulation), without --contain / --containall.
+	contained := l.cfg.Contain || l.cfg.ContainAll
+	if l.cfg.NoCompat && !contained {
 		if err := l.addSystemBindMounts(mounts); err != nil {
 			return nil, fmt.Errorf("while configuring system bind mount(s): %w", err)
 		}
@@ -75,7 +77,7 @@ func (l *Launcher) getMounts() ([]specs.Mount, error) {
 	if err := l.addUserBindMounts(mounts); err != nil {
 		return nil, fmt.Errorf("while configuring user bind mount(s): %w", err)
 	}
-	if l.cfg.NoCompat {
+	if l.cfg.NoCompat && !contained {
 		if err := l.addCwdMount(mounts); err != nil {
 			return nil, fmt.Errorf("while configuring cwd mount: %w", err)
 		}
@@ -394,7 +396,9 @@ func (l *Launcher) addSysMount(mounts *[]specs.Mount) error {
 
 	if l.cgroupsV2 {
 		cgroupRORW := "ro"
-		if l.cfg.KeepPrivs {
+		// An init process started with --boot must be able to manage the
+		// cgroup hierarchy of its cgroup namespace.
+		if l.cfg.KeepPrivs || l.cfg.Boot {
 			cgroupRORW = "rw"
 		}
 		*mounts = append(*mounts,
@@ -429,11 +433,29 @@ func (l *Launcher) addHomeMount(mounts *[]specs.Mount) error {
 		return fmt.Errorf("cannot add home mount with empty destination")
 	}
 
-	// In --no-compat we bind $HOME from host like native mode default.
-	if l.cfg.NoCompat && l.homeSrc == "" {
+	// In --no-compat we bind $HOME from host like native mode default, unless
+	// --contain / --containall was requested.
+	contained := l.cfg.Contain || l.cfg.ContainAll
+	if l.cfg.NoCompat && !contained && l.homeSrc == "" {
 		l.homeSrc = l.homeHost
 	}
 
+	// With --contain / --containall and a --workdir, $HOME is held in the
+	// workdir, as in native mode.
+	if contained && l.homeSrc == "" && len(l.cfg.WorkDir) > 0 {
+		homeSrc, err := workdirHome(l.cfg.WorkDir)
+		if err != nil {
+			return err
+		}
+		sylog.Debugf("Using work directory %s for home directory", homeSrc)
+		return l.addBindMount(mounts,
+			bind.Path{
+				Source:      homeSrc,
+				Destination: l.homeDest,
+			},
+			l.cfg.AllowSUID)
+	}
+
 	// If l.homeSrc is set, then we are simply bind mounting from the host.
 	if l.homeSrc != "" {
 		return l.addBindMount(mounts,
@@ -482,6 +504,22 @@ func (l *Launcher) addHomeMount(mounts *[]specs.Mount) error {
 	return nil
 }
 
+// workdirHome creates, if necessary, and returns the directory in workdir that
+// is used to hold $HOME for a contained container.
+func workdirHome(workdir string) (string, error) {
+	const homeSrcSubdir = "home"
+
+	workdir, err := filepath.Abs(filepath.Clean(workdir))
+	if err != nil {
+		return "", fmt.Errorf("can't determine absolute path of workdir %s: %s", workdir, err)
+	}
+	homeSrc := filepath.Join(workdir, homeSrcSubdir)
+	if err := fs.MkdirAt(workdir, homeSrcSubdir, 0o700); err != nil && !os.IsExist(err) {
+		return "", fmt.Errorf("failed to create %s: %s", homeSrc, err)
+	}
+	return homeSrc, nil
+}
+
 // addScratchMounts adds tmpfs mounts for scratch directories in the container.
 func (l *Launcher) addScratchMounts(mounts *[]specs.Mount) error {
 	const scratchContainerDirName = "/scratch"
@@ -740,7 +778,7 @@ func (l *Launcher) prepareImageBindMount(bindPath bind.Path) (*fuse.ImageMount,
 
 	switch img.Type {
 	case image.EXT3:
-	case image.SQUASHFS:
+	case image.SQUASHFS, image.EROFS:
 		readonly = true
 	case image.OCISIF:
 		readonly = true
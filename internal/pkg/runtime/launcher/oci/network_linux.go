@@ -0,0 +1,297 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/sylabs/singularity/v4/internal/pkg/buildcfg"
+	"github.com/sylabs/singularity/v4/internal/pkg/runtime/launcher"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/rootless"
+	"github.com/sylabs/singularity/v4/pkg/network"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	noneNetwork = "none"
+	// Files in the OCI bundle root
+	netnsFile        = "netns"
+	networkStateFile = "network.json"
+)
+
+var (
+	// defaultCNIConfPath is the default directory to CNI network configuration files.
+	defaultCNIConfPath = filepath.Join(buildcfg.SYSCONFDIR, "singularity", "network")
+	// defaultCNIPluginPath is the default directory to CNI plugins executables.
+	defaultCNIPluginPath = filepath.Join(buildcfg.LIBEXECDIR, "singularity", "cni")
+)
+
+// networkState records the CNI configuration that was applied to a container,
+// so that it can be torn down by Cleanup after an instance has exited.
+type networkState struct {
+	ContainerID string   `json:"containerID"`
+	Networks    []string `json:"networks"`
+	Args        []string `json:"args"`
+	NetnsPath   string   `json:"netnsPath"`
+	// CNIPath is recorded so that teardown uses the same configuration as
+	// setup, without reference to singularity.conf.
+	CNIPath network.CNIPath `json:"cniPath"`
+}
+
+// checkNetwork verifies that the network configuration requested can be
+// applied in OCI-mode.
+func checkNetwork(lo launcher.Options) error {
+	cniRequested := lo.Namespaces.Net && lo.Network != "" && lo.Network != noneNetwork
+	if lo.NetnsPath == "" && !cniRequested {
+		return nil
+	}
+
+	if lo.NetnsPath != "" && lo.Namespaces.Net {
+		return fmt.Errorf("cannot join existing --netns-path and create a new network namespace with --net/-n")
+	}
+
+	// The OCI runtime is always run as root, but for an unprivileged user this
+	// is only inside a user namespace. That namespace does not hold the
+	// privileges required to configure the host network, or to join a network
+	// namespace created outside of it. The 'allow net users / groups /
+	// networks' and 'allow netns paths' directives in singularity.conf are
+	// applied by the setuid starter in native mode, and so do not apply here.
+	uid, err := rootless.Getuid()
+	if err != nil {
+		return err
+	}
+	if uid != 0 {
+		if lo.NetnsPath != "" {
+			return fmt.Errorf("--netns-path requires root in OCI-mode, 'allow netns paths' in singularity.conf only applies to native mode")
+		}
+		return fmt.Errorf("--network %s requires root in OCI-mode, non-root users can only use --network %s ('allow net networks' in singularity.conf only applies to native mode)", lo.Network, noneNetwork)
+	}
+
+	if lo.NetnsPath != "" {
+		if _, err := os.Stat(lo.NetnsPath); err != nil {
+			return fmt.Errorf("while checking --netns-path: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// setupNetwork applies the requested network configuration to the spec. When
+// joining an existing network namespace, the spec references it directly.
+// Otherwise, when CNI networks are requested, a network namespace is created,
+// held open by a bind mount in the bundle, and the CNI networks are added
+// to it. The returned function removes the networks and the namespace, and
+// must be called after the container has exited.
+func (l *Launcher) setupNetwork(ctx context.Context, containerID, bundleDir string, spec *specs.Spec) (cleanup func(context.Context) error, err error) {
+	noop := func(context.Context) error { return nil }
+
+	if l.cfg.NetnsPath != "" {
+		sylog.Debugf("Joining network namespace %s", l.cfg.NetnsPath)
+		setNetworkNamespacePath(spec, l.cfg.NetnsPath)
+		return noop, nil
+	}
+
+	if !l.cfg.Namespaces.Net || l.cfg.Network == "" || l.cfg.Network == noneNetwork {
+		return noop, nil
+	}
+
+	nsPath := filepath.Join(bundleDir, netnsFile)
+	if err := createNetNS(nsPath); err != nil {
+		return nil, fmt.Errorf("while creating network namespace: %w", err)
+	}
+
+	ns := networkState{
+		ContainerID: containerID,
+		Networks:    strings.Split(l.cfg.Network, ","),
+		Args:        l.cfg.NetworkArgs,
+		NetnsPath:   nsPath,
+		CNIPath:     l.cniPath(),
+	}
+
+	setup, err := ns.setup()
+	if err == nil {
+		sylog.Debugf("Adding CNI networks %v", ns.Networks)
+		if err = setup.AddNetworks(ctx); err != nil {
+			err = fmt.Errorf("network setup failed: %w", err)
+		}
+	}
+	if err != nil {
+		if nsErr := removeNetNS(nsPath); nsErr != nil {
+			sylog.Errorf("Couldn't remove network namespace: %v", nsErr)
+		}
+		return nil, err
+	}
+	l.networkSetup = setup
+
+	if err := ns.write(bundleDir); err != nil {
+		if teardownErr := ns.teardown(ctx, setup); teardownErr != nil {
+			sylog.Errorf("Couldn't clean up network: %v", teardownErr)
+		}
+		return nil, err
+	}
+
+	setNetworkNamespacePath(spec, nsPath)
+
+	return func(ctx context.Context) error {
+		return ns.teardown(ctx, setup)
+	}, nil
+}
+
+// cniPath returns the CNI configuration and plugin locations to use, as set in
+// singularity.conf, or the defaults.
+func (l *Launcher) cniPath() network.CNIPath {
+	cniPath := network.CNIPath{
+		Conf:   l.singularityConf.CniConfPath,
+		Plugin: l.singularityConf.CniPluginPath,
+	}
+	if cniPath.Conf == "" {
+		cniPath.Conf = defaultCNIConfPath
+	}
+	if cniPath.Plugin == "" {
+		cniPath.Plugin = defaultCNIPluginPath
+	}
+	return cniPath
+}
+
+// instanceIP returns the IP address of an instance on its first network, if
+// it has been attached to a CNI network.
+func (l *Launcher) instanceIP() string {
+	if l.networkSetup == nil {
+		return ""
+	}
+	ip, err := l.networkSetup.GetNetworkIP("", "4")
+	if err != nil {
+		sylog.Warningf("Could not get instance IP: %v", err)
+		return ""
+	}
+	return ip.String()
+}
+
+// setup returns a CNI network setup for the networkState.
+func (ns networkState) setup() (*network.Setup, error) {
+	setup, err := network.NewSetup(ns.Networks, ns.ContainerID, ns.NetnsPath, &ns.CNIPath)
+	if err != nil {
+		return nil, fmt.Errorf("network setup failed: %w", err)
+	}
+	if err := setup.SetArgs(ns.Args); err != nil {
+		return nil, fmt.Errorf("error while setting network arguments: %w", err)
+	}
+	setup.SetEnvPath("/bin:/sbin:/usr/bin:/usr/sbin")
+	return setup, nil
+}
+
+// write stores the networkState in bundleDir, for use by cleanupBundleNetwork.
+func (ns networkState) write(bundleDir string) error {
+	b, err := json.Marshal(ns)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(bundleDir, networkStateFile), b, 0o600); err != nil {
+		return fmt.Errorf("while writing network state: %w", err)
+	}
+	return nil
+}
+
+// teardown removes the CNI networks from the network namespace, and then the
+// network namespace itself.
+func (ns networkState) teardown(ctx context.Context, setup *network.Setup) error {
+	sylog.Debugf("Cleaning up CNI networks %v", ns.Networks)
+	err := setup.DelNetworks(ctx)
+	if nsErr := removeNetNS(ns.NetnsPath); nsErr != nil {
+		err = errors.Join(err, nsErr)
+	}
+	return err
+}
+
+// cleanupBundleNetwork tears down the CNI networks recorded in the bundle at
+// bundleDir, if any.
+func cleanupBundleNetwork(ctx context.Context, bundleDir string) error {
+	b, err := os.ReadFile(filepath.Join(bundleDir, networkStateFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("while reading network state: %w", err)
+	}
+	var ns networkState
+	if err := json.Unmarshal(b, &ns); err != nil {
+		return fmt.Errorf("while reading network state: %w", err)
+	}
+	setup, err := ns.setup()
+	if err != nil {
+		return err
+	}
+	return ns.teardown(ctx, setup)
+}
+
+// setNetworkNamespacePath sets the path of the network namespace in the spec,
+// adding the network namespace if it is not already present.
+func setNetworkNamespacePath(spec *specs.Spec, path string) {
+	for i, ns := range spec.Linux.Namespaces {
+		if ns.Type == specs.NetworkNamespace {
+			spec.Linux.Namespaces[i].Path = path
+			return
+		}
+	}
+	spec.Linux.Namespaces = append(
+		spec.Linux.Namespaces,
+		specs.LinuxNamespace{Type: specs.NetworkNamespace, Path: path},
+	)
+}
+
+// createNetNS creates a new network namespace, which is held open by a bind
+// mount onto path.
+func createNetNS(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDONLY|unix.O_NOFOLLOW, 0o600)
+	if err != nil {
+		return err
+	}
+	f.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		// The thread is left locked, so that it is terminated rather than
+		// returned to the pool, once it has moved into the new namespace.
+		runtime.LockOSThread()
+		if err := unix.Unshare(unix.CLONE_NEWNET); err != nil {
+			errCh <- fmt.Errorf("while unsharing network namespace: %w", err)
+			return
+		}
+		nsPath := fmt.Sprintf("/proc/self/task/%d/ns/net", unix.Gettid())
+		if err := unix.Mount(nsPath, path, "", unix.MS_BIND, ""); err != nil {
+			errCh <- fmt.Errorf("while binding network namespace: %w", err)
+			return
+		}
+		errCh <- nil
+	}()
+
+	if err := <-errCh; err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// removeNetNS unmounts and removes a network namespace bind mount created by
+// createNetNS.
+func removeNetNS(path string) error {
+	if err := unix.Unmount(path, unix.MNT_DETACH); err != nil && !errors.Is(err, unix.EINVAL) && !errors.Is(err, unix.ENOENT) {
+		return fmt.Errorf("while unmounting network namespace: %w", err)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("while removing network namespace: %w", err)
+	}
+	return nil
+}
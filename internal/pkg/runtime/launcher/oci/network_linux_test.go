@@ -0,0 +1,105 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/sylabs/singularity/v4/internal/pkg/runtime/launcher"
+	"github.com/sylabs/singularity/v4/internal/pkg/test"
+)
+
+func Test_checkNetwork(t *testing.T) {
+	test.DropPrivilege(t)
+	defer test.ResetPrivilege(t)
+
+	tests := []struct {
+		name    string
+		lo      launcher.Options
+		wantErr bool
+	}{
+		{
+			name: "NoNet",
+			lo:   launcher.Options{Network: "bridge"},
+		},
+		{
+			name: "NetNone",
+			lo: launcher.Options{
+				Namespaces: launcher.Namespaces{Net: true},
+				Network:    "none",
+			},
+		},
+		{
+			name: "NetBridgeUnprivileged",
+			lo: launcher.Options{
+				Namespaces: launcher.Namespaces{Net: true},
+				Network:    "bridge",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "NetnsPathUnprivileged",
+			lo:      launcher.Options{NetnsPath: "/proc/self/ns/net"},
+			wantErr: true,
+		},
+		{
+			name: "NetAndNetnsPath",
+			lo: launcher.Options{
+				Namespaces: launcher.Namespaces{Net: true},
+				Network:    "none",
+				NetnsPath:  "/proc/self/ns/net",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkNetwork(tt.lo)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkNetwork() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_setNetworkNamespacePath(t *testing.T) {
+	tests := []struct {
+		name       string
+		namespaces []specs.LinuxNamespace
+		want       []specs.LinuxNamespace
+	}{
+		{
+			name:       "Add",
+			namespaces: []specs.LinuxNamespace{{Type: specs.PIDNamespace}},
+			want: []specs.LinuxNamespace{
+				{Type: specs.PIDNamespace},
+				{Type: specs.NetworkNamespace, Path: "/netns"},
+			},
+		},
+		{
+			name: "Replace",
+			namespaces: []specs.LinuxNamespace{
+				{Type: specs.NetworkNamespace},
+				{Type: specs.PIDNamespace},
+			},
+			want: []specs.LinuxNamespace{
+				{Type: specs.NetworkNamespace, Path: "/netns"},
+				{Type: specs.PIDNamespace},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &specs.Spec{Linux: &specs.Linux{Namespaces: tt.namespaces}}
+			setNetworkNamespacePath(spec, "/netns")
+			if !reflect.DeepEqual(spec.Linux.Namespaces, tt.want) {
+				t.Errorf("got %v, want %v", spec.Linux.Namespaces, tt.want)
+			}
+		})
+	}
+}
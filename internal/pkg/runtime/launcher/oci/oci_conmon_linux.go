@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2022, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -17,12 +17,15 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sylabs/singularity/v4/internal/pkg/buildcfg"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/bin"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/rootless"
 	"github.com/sylabs/singularity/v4/pkg/sylog"
 	"golang.org/x/sys/unix"
 )
@@ -35,44 +38,64 @@ type ociError struct {
 
 // Create creates a container from an OCI bundle
 func Create(containerID, bundlePath string, systemdCgroups bool) error {
+	_, _, err := create(containerID, bundlePath, "", systemdCgroups)
+	return err
+}
+
+// CreateDetached creates a container from an OCI bundle, without a terminal
+// or attached standard streams. The container output is written by conmon to
+// logPath. The PIDs of the container process and of its conmon monitor
+// process are returned on success.
+func CreateDetached(containerID, bundlePath, logPath string, systemdCgroups bool) (pid, conmonPid int, err error) {
+	if logPath == "" {
+		return -1, -1, fmt.Errorf("a log path is required for a detached container")
+	}
+	return create(containerID, bundlePath, logPath, systemdCgroups)
+}
+
+// create creates a container from an OCI bundle using conmon. If logPath is
+// empty the container is attached to the current terminal, and its output is
+// logged into the state directory. Otherwise the container is detached and
+// its output is logged to logPath.
+func create(containerID, bundlePath, logPath string, systemdCgroups bool) (pid, conmonPid int, err error) {
 	conmon, err := bin.FindBin("conmon")
 	if err != nil {
-		return err
+		return -1, -1, err
 	}
 	runtimeBin, err := Runtime()
 	if err != nil {
-		return err
+		return -1, -1, err
 	}
 	// chdir to bundle and lock it, so another oci create cannot use the same bundle
 	absBundle, err := filepath.Abs(bundlePath)
 	if err != nil {
-		return fmt.Errorf("failed to determine bundle absolute path: %w", err)
+		return -1, -1, fmt.Errorf("failed to determine bundle absolute path: %w", err)
 	}
 	if err := os.Chdir(absBundle); err != nil {
-		return fmt.Errorf("failed to change directory to %s: %w", absBundle, err)
+		return -1, -1, fmt.Errorf("failed to change directory to %s: %w", absBundle, err)
 	}
 	if err := lockBundle(absBundle); err != nil {
-		return fmt.Errorf("while locking bundle: %w", err)
+		return -1, -1, fmt.Errorf("while locking bundle: %w", err)
 	}
 
 	// Create our own state location for conmon and singularity related files
 	sd, err := stateDir(containerID)
 	if err != nil {
-		return fmt.Errorf("while computing state directory: %w", err)
+		return -1, -1, fmt.Errorf("while computing state directory: %w", err)
 	}
 	err = os.MkdirAll(sd, 0o700)
 	if err != nil {
-		return fmt.Errorf("while creating state directory: %w", err)
+		return -1, -1, fmt.Errorf("while creating state directory: %w", err)
 	}
 	containerUUID, err := uuid.NewRandom()
 	if err != nil {
-		return err
+		return -1, -1, err
 	}
 
 	// Pipes for sync and start communication with conmon
 	syncFds, err := unix.Socketpair(unix.AF_LOCAL, unix.SOCK_SEQPACKET|unix.SOCK_CLOEXEC, 0)
 	if err != nil {
-		return fmt.Errorf("could not create sync socket pair: %w", err)
+		return -1, -1, fmt.Errorf("could not create sync socket pair: %w", err)
 	}
 	syncChild := os.NewFile(uintptr(syncFds[0]), "sync_child")
 	syncParent := os.NewFile(uintptr(syncFds[1]), "sync_parent")
@@ -80,7 +103,7 @@ func Create(containerID, bundlePath string, systemdCgroups bool) error {
 
 	startFds, err := unix.Socketpair(unix.AF_LOCAL, unix.SOCK_SEQPACKET|unix.SOCK_CLOEXEC, 0)
 	if err != nil {
-		return fmt.Errorf("could not create sync socket pair: %w", err)
+		return -1, -1, fmt.Errorf("could not create sync socket pair: %w", err)
 	}
 	startChild := os.NewFile(uintptr(startFds[0]), "start_child")
 	startParent := os.NewFile(uintptr(startFds[1]), "start_parent")
@@ -90,7 +113,7 @@ func Create(containerID, bundlePath string, systemdCgroups bool) error {
 
 	rsd, err := runtimeStateDir()
 	if err != nil {
-		return err
+		return -1, -1, err
 	}
 
 	cmdArgs := []string{
@@ -101,13 +124,11 @@ func Create(containerID, bundlePath string, systemdCgroups bool) error {
 		"--runtime", runtimeBin,
 		"--conmon-pidfile", path.Join(sd, conmonPidFile),
 		"--container-pidfile", path.Join(sd, containerPidFile),
-		"--log-path", path.Join(sd, containerLogFile),
 		"--runtime-arg", "--root",
 		"--runtime-arg", rsd,
 		"--runtime-arg", "--log",
 		"--runtime-arg", path.Join(sd, runcLogFile),
 		"--full-attach",
-		"--terminal",
 		"--bundle", absBundle,
 		"--exit-command", singularityBin,
 		"--exit-command-arg", "--debug",
@@ -116,6 +137,13 @@ func Create(containerID, bundlePath string, systemdCgroups bool) error {
 		"--exit-command-arg", containerID,
 	}
 
+	detached := logPath != ""
+	if detached {
+		cmdArgs = append(cmdArgs, "--log-path", logPath)
+	} else {
+		cmdArgs = append(cmdArgs, "--log-path", path.Join(sd, containerLogFile), "--terminal")
+	}
+
 	if systemdCgroups {
 		cmdArgs = append(cmdArgs, "--systemd-cgroup")
 	}
@@ -123,9 +151,15 @@ func Create(containerID, bundlePath string, systemdCgroups bool) error {
 	cmd := exec.Command(conmon, cmdArgs...)
 	cmd.Dir = absBundle
 	cmd.Env = append(cmd.Env, fmt.Sprintf("_OCI_SYNCPIPE=%d", 3), fmt.Sprintf("_OCI_STARTPIPE=%d", 4))
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	if detached {
+		// The exit command runs long after we have gone, so must be able to
+		// identify the host user and namespace from the environment.
+		cmd.Env = append(cmd.Env, rootlessEnv()...)
+	} else {
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
 	cmd.SysProcAttr = &syscall.SysProcAttr{
 		Setpgid: true,
 	}
@@ -137,7 +171,7 @@ func Create(containerID, bundlePath string, systemdCgroups bool) error {
 		if err2 := releaseBundle(absBundle); err2 != nil {
 			sylog.Errorf("while releasing bundle: %v", err)
 		}
-		return fmt.Errorf("while starting conmon: %w", err)
+		return -1, -1, fmt.Errorf("while starting conmon: %w", err)
 	}
 	syncChild.Close()
 	startChild.Close()
@@ -151,26 +185,33 @@ func Create(containerID, bundlePath string, systemdCgroups bool) error {
 		if err2 := releaseBundle(absBundle); err2 != nil {
 			sylog.Errorf("while releasing bundle: %v", err)
 		}
-		return fmt.Errorf("while starting conmon: %w", err)
+		return -1, -1, fmt.Errorf("while starting conmon: %w", err)
 	}
 
 	// We check for errors from runc (which conmon invokes) via the sync pipe
-	pid, err := readConmonPipeData(syncParent, path.Join(sd, runcLogFile))
+	pid, err = readConmonPipeData(syncParent, path.Join(sd, runcLogFile))
 	if err != nil {
 		if err2 := Delete(context.TODO(), containerID, systemdCgroups); err2 != nil {
 			sylog.Errorf("Removing container %s from runtime after creation failed", containerID)
 		}
-		return err
+		return -1, -1, err
 	}
 
 	// Create a symlink from the state dir to the bundle, so it's easy to find later on.
 	bundleLink := path.Join(sd, "bundle")
 	if err := os.Symlink(absBundle, bundleLink); err != nil {
-		return fmt.Errorf("could not link attach socket: %w", err)
+		return -1, -1, fmt.Errorf("could not link attach socket: %w", err)
+	}
+
+	// conmon has daemonized by the time the create step is complete, and has
+	// written its own pid file.
+	conmonPid, err = readPidFile(path.Join(sd, conmonPidFile))
+	if err != nil {
+		return -1, -1, fmt.Errorf("while reading conmon pid file: %w", err)
 	}
 
 	sylog.Infof("Container %s created with PID %d", containerID, pid)
-	return nil
+	return pid, conmonPid, nil
 }
 
 // The following utility functions are taken from https://github.com/containers/podman
@@ -242,6 +283,28 @@ func readConmonPipeData(pipe *os.File, ociLog string) (int, error) {
 	return data, nil
 }
 
+// readPidFile returns the pid stored in the file at path.
+func readPidFile(path string) (int, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return -1, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(b)))
+}
+
+// rootlessEnv returns the subset of our environment that identifies the host
+// user, and the namespace we are running in, for singularity commands spawned
+// by conmon.
+func rootlessEnv() []string {
+	env := []string{}
+	for _, k := range []string{rootless.NSEnv, rootless.UIDEnv, rootless.GIDEnv} {
+		if v, ok := os.LookupEnv(k); ok {
+			env = append(env, k+"="+v)
+		}
+	}
+	return env
+}
+
 // writeConmonPipeData writes nonce data to a pipe
 func writeConmonPipeData(pipe *os.File) error {
 	someData := []byte{0}
@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -36,6 +36,7 @@ const (
 	runcLogFile      = "runc.log"
 	conmonPidFile    = "conmon.pid"
 	bundleLink       = "bundle"
+	instanceMarker   = "instance"
 	// Files in the OCI bundle root
 	bundleLock   = ".singularity-oci.lock"
 	attachSocket = "attach"
@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -108,20 +108,46 @@ func (l *Launcher) imageOverlaySet(bundleDir string) (*overlay.Set, error) {
 // Whether an ephemeral overlay is writable from inside the container is
 // controlled by the runtime config.
 func (l *Launcher) WrapWithOverlays(ctx context.Context, f func() error, bundleDir string) error {
-	s, err := l.imageOverlaySet(bundleDir)
+	cleanup, err := l.mountOverlays(ctx, bundleDir)
 	if err != nil {
 		return err
 	}
 
+	err = f()
+
+	// Cleanup actions log errors, but don't return - so we get as much cleanup done as possible.
+	cleanup()
+
+	// Return any error from the actual container payload - preserve exit code.
+	return err
+}
+
+// mountOverlays carries out the prep steps of WrapWithOverlays, returning a
+// function that must be called to perform the matching cleanup steps.
+func (l *Launcher) mountOverlays(ctx context.Context, bundleDir string) (cleanup func(), err error) {
+	s, err := l.imageOverlaySet(bundleDir)
+	if err != nil {
+		return nil, err
+	}
+
 	hasSifOverlay := s != nil
 	hasUserOverlay := len(l.cfg.OverlayPaths) > 0
 	if l.cfg.Writable && !hasSifOverlay {
-		return fmt.Errorf("image %s does not contain a writable overlay", l.image)
+		return nil, fmt.Errorf("image %s does not contain a writable overlay", l.image)
 	}
 
-	// No image embedded overlay, or user requested --overlay - just wrap with a writable tmpfs.
+	// No image embedded overlay, or user requested --overlay - just use a writable tmpfs.
 	if !hasSifOverlay && !hasUserOverlay {
-		return WrapWithWritableTmpFs(ctx, f, bundleDir, l.cfg.AllowSUID)
+		overlayDir, err := prepareWritableTmpfs(ctx, bundleDir, l.cfg.AllowSUID)
+		sylog.Debugf("Done with prepareWritableTmpfs; overlayDir is: %q", overlayDir)
+		if err != nil {
+			return nil, err
+		}
+		return func() {
+			if cleanupErr := cleanupWritableTmpfs(ctx, bundleDir, overlayDir); cleanupErr != nil {
+				sylog.Errorf("While cleaning up writable tmpfs: %v", cleanupErr)
+			}
+		}, nil
 	}
 
 	if s == nil {
@@ -131,7 +157,7 @@ func (l *Launcher) WrapWithOverlays(ctx context.Context, f func() error, bundleD
 	for _, p := range l.cfg.OverlayPaths {
 		item, err := overlay.NewItemFromString(p)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		item.SetParentDir(bundleDir)
@@ -141,7 +167,7 @@ func (l *Launcher) WrapWithOverlays(ctx context.Context, f func() error, bundleD
 		}
 
 		if s.WritableOverlay != nil && !item.Readonly {
-			return fmt.Errorf("you can't specify more than one writable overlay; %#v has already been specified as a writable overlay; use '--overlay %s:ro' instead", s.WritableOverlay, item.SourcePath)
+			return nil, fmt.Errorf("you can't specify more than one writable overlay; %#v has already been specified as a writable overlay; use '--overlay %s:ro' instead", s.WritableOverlay, item.SourcePath)
 		}
 		if !item.Readonly {
 			s.WritableOverlay = item
@@ -154,32 +180,27 @@ func (l *Launcher) WrapWithOverlays(ctx context.Context, f func() error, bundleD
 	if s.WritableOverlay == nil {
 		i, err := prepareSystemOverlay(bundleDir, l.cfg.AllowSUID)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		systemOverlay = i.SourcePath
 		s.WritableOverlay = i
 	}
 
 	rootFsDir := tools.RootFs(bundleDir).Path()
-	err = s.Mount(ctx, rootFsDir)
-	if err != nil {
-		return err
+	if err := s.Mount(ctx, rootFsDir); err != nil {
+		return nil, err
 	}
 
-	err = f()
-
-	// Cleanup actions log errors, but don't return - so we get as much cleanup done as possible.
-	if cleanupErr := s.Unmount(ctx, rootFsDir); cleanupErr != nil {
-		sylog.Errorf("While unmounting rootfs overlay: %v", cleanupErr)
-	}
-	if systemOverlay != "" {
-		if cleanupErr := cleanupSystemOverlay(systemOverlay); cleanupErr != nil {
-			sylog.Errorf("While cleaning up ephemeral writable tmpfs: %v", cleanupErr)
+	return func() {
+		if cleanupErr := s.Unmount(ctx, rootFsDir); cleanupErr != nil {
+			sylog.Errorf("While unmounting rootfs overlay: %v", cleanupErr)
 		}
-	}
-
-	// Return any error from the actual container payload - preserve exit code.
-	return err
+		if systemOverlay != "" {
+			if cleanupErr := cleanupSystemOverlay(systemOverlay); cleanupErr != nil {
+				sylog.Errorf("While cleaning up ephemeral writable tmpfs: %v", cleanupErr)
+			}
+		}
+	}, nil
 }
 
 func prepareSystemOverlay(bundleDir string, allowSetuid bool) (*overlay.Item, error) {
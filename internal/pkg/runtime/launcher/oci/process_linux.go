@@ -1,4 +1,4 @@
-// Copyright (c) 2022-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2022-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -123,7 +123,8 @@ func (l *Launcher) getProcess(ctx context.Context, imgSpec imgspecv1.Image, bund
 		Env:             l.getProcessEnv(imgSpec, os.Environ(), rtEnv),
 		NoNewPrivileges: noNewPrivs,
 		User:            u,
-		Terminal:        getProcessTerminal(),
+		// Instances run in the background, and are never attached to a terminal.
+		Terminal: ep.Instance == "" && getProcessTerminal(),
 	}
 
 	return &p, rtEnv, nil
@@ -181,7 +182,8 @@ func getSpecArgs(imageSpec imgspecv1.Image) []string {
 
 // getProcessCwd computes the Cwd that the container process should start in.
 // Default in OCI mode is the value in the image config, or $HOME.
-// In native emulation (--no-compat), we use the CWD.
+// In native emulation (--no-compat), we use the CWD, unless --contain /
+// --containall was requested, as the CWD is not then mounted.
 // Can be overridden with a custom value via --cwd/pwd.
 
 // Because this is called after mounts have already been computed, we can count on homeDest containing the right value, incorporating any custom home dir overrides (i.e., --home).
@@ -190,7 +192,7 @@ func (l *Launcher) getProcessCwd(imgSpec imgspecv1.Image) (dir string, err error
 		return l.cfg.CwdPath, nil
 	}
 
-	if l.cfg.NoCompat {
+	if l.cfg.NoCompat && !l.cfg.Contain && !l.cfg.ContainAll {
 		return os.Getwd()
 	}
 
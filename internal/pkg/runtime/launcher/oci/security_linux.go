@@ -0,0 +1,104 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/sylabs/singularity/v4/internal/pkg/runtime/engine/config/oci/generate"
+	"github.com/sylabs/singularity/v4/internal/pkg/security"
+	"github.com/sylabs/singularity/v4/internal/pkg/security/seccomp"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/rootless"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+)
+
+// getSecurityIDs returns the target uid and gids requested via `--security
+// uid:<uid>` and `--security gid:<gid>[:<gid>...]` options. A nil uid, or
+// empty gids, indicates that no target was requested. Setting a target uid or
+// gid requires root on the host.
+func (l *Launcher) getSecurityIDs() (uid *uint32, gids []uint32, err error) {
+	uidParam := security.GetParam(l.cfg.SecurityOpts, "uid")
+	gidParam := security.GetParam(l.cfg.SecurityOpts, "gid")
+	if uidParam == "" && gidParam == "" {
+		return nil, nil, nil
+	}
+
+	// We are always root, or fake root, when the launcher runs, so must check
+	// against the host uid.
+	hostUID, err := rootless.Getuid()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if uidParam != "" {
+		if hostUID != 0 {
+			return nil, nil, fmt.Errorf("uid security feature requires root privileges")
+		}
+		u, err := strconv.ParseUint(uidParam, 10, 32)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse provided UID: %w", err)
+		}
+		targetUID := uint32(u)
+		uid = &targetUID
+	}
+
+	if gidParam != "" {
+		if hostUID != 0 {
+			return nil, nil, fmt.Errorf("gid security feature requires root privileges")
+		}
+		for _, id := range strings.Split(gidParam, ":") {
+			g, err := strconv.ParseUint(id, 10, 32)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to parse provided GID: %w", err)
+			}
+			gids = append(gids, uint32(g))
+		}
+	}
+
+	return uid, gids, nil
+}
+
+// addSecurity applies the SELinux label, AppArmor profile, and seccomp filter
+// requested via `--security` options to the spec. Where no seccomp profile is
+// requested, the default profile from singularity.conf is applied, if set. The
+// spec Process must have been populated before calling addSecurity, as the
+// seccomp filter depends on the process capabilities.
+func (l *Launcher) addSecurity(spec *specs.Spec) error {
+	if spec.Process == nil {
+		return fmt.Errorf("spec has no process configuration")
+	}
+
+	selinuxLabel := security.GetParam(l.cfg.SecurityOpts, "selinux")
+	apparmorProfile := security.GetParam(l.cfg.SecurityOpts, "apparmor")
+	if selinuxLabel != "" && apparmorProfile != "" {
+		return fmt.Errorf("you can't specify both an apparmor profile and a selinux label")
+	}
+	if selinuxLabel != "" {
+		sylog.Debugf("Applying SELinux context %s", selinuxLabel)
+		spec.Process.SelinuxLabel = selinuxLabel
+	}
+	if apparmorProfile != "" {
+		sylog.Debugf("Applying Apparmor profile %s", apparmorProfile)
+		spec.Process.ApparmorProfile = apparmorProfile
+	}
+
+	seccompProfile := security.GetParam(l.cfg.SecurityOpts, "seccomp")
+	if seccompProfile == "" {
+		seccompProfile = l.singularityConf.SeccompProfile
+	}
+	if seccompProfile != "" {
+		sylog.Debugf("Applying seccomp rule from %s", seccompProfile)
+		g := generate.New(spec)
+		if err := seccomp.LoadProfileFromFile(seccompProfile, g); err != nil {
+			return fmt.Errorf("while loading seccomp profile: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/sylabs/singularity/v4/internal/pkg/runtime/launcher"
+	"github.com/sylabs/singularity/v4/internal/pkg/security/seccomp"
+	"github.com/sylabs/singularity/v4/internal/pkg/test"
+	"github.com/sylabs/singularity/v4/pkg/util/singularityconf"
+)
+
+func TestGetSecurityIDs(t *testing.T) {
+	uid := uint32(1001)
+
+	tests := []struct {
+		name         string
+		securityOpts []string
+		privileged   bool
+		wantUID      *uint32
+		wantGIDs     []uint32
+		wantErr      bool
+	}{
+		{
+			name:       "None",
+			privileged: true,
+		},
+		{
+			name:         "UnrelatedOpts",
+			securityOpts: []string{"apparmor:unconfined"},
+		},
+		{
+			name:         "UIDPrivileged",
+			securityOpts: []string{"uid:1001"},
+			privileged:   true,
+			wantUID:      &uid,
+		},
+		{
+			name:         "GIDsPrivileged",
+			securityOpts: []string{"gid:1001:1002"},
+			privileged:   true,
+			wantGIDs:     []uint32{1001, 1002},
+		},
+		{
+			name:         "BadUID",
+			securityOpts: []string{"uid:abc"},
+			privileged:   true,
+			wantErr:      true,
+		},
+		{
+			name:         "BadGID",
+			securityOpts: []string{"gid:1001:abc"},
+			privileged:   true,
+			wantErr:      true,
+		},
+		{
+			name:         "UIDUnprivileged",
+			securityOpts: []string{"uid:1001"},
+			wantErr:      true,
+		},
+		{
+			name:         "GIDUnprivileged",
+			securityOpts: []string{"gid:1001"},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.privileged {
+				test.EnsurePrivilege(t)
+			} else {
+				test.DropPrivilege(t)
+				defer test.ResetPrivilege(t)
+			}
+
+			l := &Launcher{cfg: launcher.Options{SecurityOpts: tt.securityOpts}}
+			uid, gids, err := l.getSecurityIDs()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getSecurityIDs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(uid, tt.wantUID) {
+				t.Errorf("getSecurityIDs() uid = %v, want %v", uid, tt.wantUID)
+			}
+			if !reflect.DeepEqual(gids, tt.wantGIDs) {
+				t.Errorf("getSecurityIDs() gids = %v, want %v", gids, tt.wantGIDs)
+			}
+		})
+	}
+}
+
+// writeSeccompProfile writes a seccomp profile, with default action action, to
+// dir.
+func writeSeccompProfile(t *testing.T, dir string, action specs.LinuxSeccompAction) string {
+	t.Helper()
+	profile := `{"defaultAction": "` + string(action) + `", "syscalls": [{"names": ["fchmod"], "action": "SCMP_ACT_ERRNO"}]}`
+	path := filepath.Join(dir, string(action)+".json")
+	if err := os.WriteFile(path, []byte(profile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAddSecurity(t *testing.T) {
+	allowProfile := writeSeccompProfile(t, t.TempDir(), specs.ActAllow)
+	logProfile := writeSeccompProfile(t, t.TempDir(), specs.ActLog)
+
+	tests := []struct {
+		name         string
+		securityOpts []string
+		confProfile  string
+		wantProcess  specs.Process
+		// wantSeccomp is the default action of the seccomp filter that must
+		// be applied, if any.
+		wantSeccomp specs.LinuxSeccompAction
+		wantErr     bool
+		// requireSeccomp indicates the case can only be checked where
+		// seccomp support is built in.
+		requireSeccomp bool
+	}{
+		{
+			name: "None",
+		},
+		{
+			name:         "SELinux",
+			securityOpts: []string{"selinux:system_u:system_r:container_t:s0"},
+			wantProcess:  specs.Process{SelinuxLabel: "system_u:system_r:container_t:s0"},
+		},
+		{
+			name:         "AppArmor",
+			securityOpts: []string{"apparmor:unconfined"},
+			wantProcess:  specs.Process{ApparmorProfile: "unconfined"},
+		},
+		{
+			name:         "SELinuxAndAppArmor",
+			securityOpts: []string{"selinux:system_u:system_r:container_t:s0", "apparmor:unconfined"},
+			wantErr:      true,
+		},
+		{
+			name:           "Seccomp",
+			securityOpts:   []string{"seccomp:" + allowProfile},
+			wantSeccomp:    specs.ActAllow,
+			requireSeccomp: true,
+		},
+		{
+			name:           "SeccompConfDefault",
+			confProfile:    logProfile,
+			wantSeccomp:    specs.ActLog,
+			requireSeccomp: true,
+		},
+		// A profile given with --security replaces the default profile.
+		{
+			name:           "SeccompOverridesConfDefault",
+			securityOpts:   []string{"seccomp:" + allowProfile},
+			confProfile:    logProfile,
+			wantSeccomp:    specs.ActAllow,
+			requireSeccomp: true,
+		},
+		{
+			name:           "SeccompInvalid",
+			securityOpts:   []string{"seccomp:" + filepath.Join(t.TempDir(), "missing.json")},
+			wantErr:        true,
+			requireSeccomp: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.requireSeccomp && !seccomp.Enabled() {
+				t.Skip("seccomp support not built in")
+			}
+
+			l := &Launcher{
+				cfg:             launcher.Options{SecurityOpts: tt.securityOpts},
+				singularityConf: &singularityconf.File{SeccompProfile: tt.confProfile},
+			}
+			spec := &specs.Spec{Process: &specs.Process{}, Linux: &specs.Linux{}}
+			err := l.addSecurity(spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("addSecurity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if tt.wantSeccomp == "" {
+				if !reflect.DeepEqual(*spec.Process, tt.wantProcess) {
+					t.Errorf("addSecurity() process = %v, want %v", *spec.Process, tt.wantProcess)
+				}
+				if spec.Linux.Seccomp != nil {
+					t.Errorf("addSecurity() unexpected seccomp configuration")
+				}
+				return
+			}
+			if spec.Linux.Seccomp == nil {
+				t.Fatalf("addSecurity() no seccomp configuration")
+			}
+			if spec.Linux.Seccomp.DefaultAction != tt.wantSeccomp {
+				t.Errorf("addSecurity() seccomp default action = %v, want %v", spec.Linux.Seccomp.DefaultAction, tt.wantSeccomp)
+			}
+		})
+	}
+}
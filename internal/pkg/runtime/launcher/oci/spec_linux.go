@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -74,8 +75,8 @@ func (l *Launcher) addNamespaces(spec *runtimespec.Spec, ns launcher.Namespaces)
 		sylog.Infof("--oci runtime always uses an IPC namespace, ipc flag is redundant.")
 	}
 
-	// Currently supports only `--network none`, i.e. isolated loopback only.
-	// Launcher.checkopts enforces this.
+	// A new network namespace is isolated, with loopback only. Where CNI
+	// networks are requested, the namespace path is set by setupNetwork.
 	if ns.Net {
 		spec.Linux.Namespaces = append(
 			spec.Linux.Namespaces,
@@ -127,6 +128,56 @@ func (l *Launcher) addNamespaces(spec *runtimespec.Spec, ns launcher.Namespaces)
 	return nil
 }
 
+// addBoot configures the spec to run /sbin/init as PID 1 of an instance
+// started with --boot. The spec Process must have been populated before
+// calling addBoot. As in native mode, CAP_SYS_BOOT and CAP_SYS_RAWIO are dropped
+// unless --keep-privs is set.
+func (l *Launcher) addBoot(spec *runtimespec.Spec, instanceName string) error {
+	if instanceName == "" {
+		return fmt.Errorf("--boot is only supported when starting an instance")
+	}
+	if spec.Process == nil {
+		return fmt.Errorf("spec has no process configuration")
+	}
+
+	// systemd, and other init systems, require a cgroup namespace with a
+	// writable cgroups v2 hierarchy. See addSysMount.
+	if !l.cgroupsV2 {
+		return fmt.Errorf("--boot requires cgroups v2 in OCI-mode")
+	}
+	if !slices.ContainsFunc(spec.Linux.Namespaces, func(ns runtimespec.LinuxNamespace) bool {
+		return ns.Type == runtimespec.CgroupNamespace
+	}) {
+		spec.Linux.Namespaces = append(
+			spec.Linux.Namespaces,
+			runtimespec.LinuxNamespace{Type: runtimespec.CgroupNamespace},
+		)
+	}
+
+	if spec.Hostname == "" {
+		spec.Hostname = instanceName
+	}
+
+	sylog.Debugf("Running /sbin/init as instance process")
+	spec.Process.Args = []string{"/sbin/init"}
+
+	if !l.cfg.KeepPrivs && spec.Process.Capabilities != nil {
+		drop := func(caps []string) []string {
+			return slices.DeleteFunc(caps, func(c string) bool {
+				return c == "CAP_SYS_BOOT" || c == "CAP_SYS_RAWIO"
+			})
+		}
+		c := spec.Process.Capabilities
+		c.Bounding = drop(c.Bounding)
+		c.Effective = drop(c.Effective)
+		c.Inheritable = drop(c.Inheritable)
+		c.Permitted = drop(c.Permitted)
+		c.Ambient = drop(c.Ambient)
+	}
+
+	return nil
+}
+
 // addAnnotations adds the required annotations to the runtime-spec, based on an image-spec.
 // See https://github.com/opencontainers/image-spec/blob/main/conversion.md
 func addAnnotations(rSpec *runtimespec.Spec, iSpec *imgspecv1.Image) error {
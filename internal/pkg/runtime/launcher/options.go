@@ -7,6 +7,7 @@ package launcher
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/sylabs/singularity/v4/internal/pkg/ociimage"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/fs/overlay"
@@ -138,11 +139,21 @@ type Options struct {
 	// ContainAll infers Contain, and adds PID, IPC namespaces, and CleanEnv.
 	ContainAll bool
 
+	// RestartPolicy determines whether the process of an instance is restarted
+	// when it exits, e.g. on-failure:3.
+	RestartPolicy string
+	// HealthCmd is a command run periodically in an instance to check its
+	// health.
+	HealthCmd string
+	// HealthInterval is the interval between instance health checks.
+	HealthInterval time.Duration
+
 	// AppName sets a SCIF application name to run.
 	AppName string
 
-	// KeyInfo holds encryption key information for accessing encrypted containers.
-	KeyInfo *cryptkey.KeyInfo
+	// KeyInfos holds encryption key information for accessing encrypted
+	// containers. Each key is tried in turn.
+	KeyInfos []cryptkey.KeyInfo
 
 	// SIFFUSE enables mounting SIF container images using FUSE.
 	SIFFUSE bool
@@ -500,6 +511,25 @@ func OptBoot(b bool) Option {
 	}
 }
 
+// OptRestartPolicy sets the policy applied when the process of an instance
+// exits.
+func OptRestartPolicy(policy string) Option {
+	return func(lo *Options) error {
+		lo.RestartPolicy = policy
+		return nil
+	}
+}
+
+// OptHealthCheck sets a command that is run in an instance every interval, to
+// check its health.
+func OptHealthCheck(cmd string, interval time.Duration) Option {
+	return func(lo *Options) error {
+		lo.HealthCmd = cmd
+		lo.HealthInterval = interval
+		return nil
+	}
+}
+
 // OptNoInit disables shim process when PID namespace is used.
 func OptNoInit(b bool) Option {
 	return func(lo *Options) error {
@@ -532,10 +562,11 @@ func OptAppName(a string) Option {
 	}
 }
 
-// OptKeyInfo sets encryption key material to use when accessing an encrypted container image.
-func OptKeyInfo(ki *cryptkey.KeyInfo) Option {
+// OptKeyInfos sets encryption key material to use when accessing an encrypted container image.
+// Each key is tried in turn.
+func OptKeyInfos(kis []cryptkey.KeyInfo) Option {
 	return func(lo *Options) error {
-		lo.KeyInfo = ki
+		lo.KeyInfos = kis
 		return nil
 	}
 }
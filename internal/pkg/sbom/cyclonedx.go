@@ -0,0 +1,104 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package sbom
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sylabs/singularity/v4/internal/pkg/buildcfg"
+)
+
+type cdxBOM struct {
+	BOMFormat    string         `json:"bomFormat"`
+	SpecVersion  string         `json:"specVersion"`
+	SerialNumber string         `json:"serialNumber"`
+	Version      int            `json:"version"`
+	Metadata     cdxMetadata    `json:"metadata"`
+	Components   []cdxComponent `json:"components"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type       string        `json:"type"`
+	BOMRef     string        `json:"bom-ref,omitempty"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Licenses   []cdxLicense  `json:"licenses,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxLicense struct {
+	License cdxLicenseName `json:"license"`
+}
+
+type cdxLicenseName struct {
+	Name string `json:"name"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// generateCycloneDX returns a CycloneDX 1.5 JSON document describing the
+// container image, which contains pkgs.
+func generateCycloneDX(pkgs []Package, opts Options) ([]byte, error) {
+	pkgs = sortPackages(pkgs)
+
+	bom := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + documentID(pkgs, opts).String(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: opts.Created.Format(time.RFC3339),
+			Tools: cdxTools{
+				Components: []cdxComponent{
+					{
+						Type:    "application",
+						Name:    "singularity",
+						Version: buildcfg.PACKAGE_VERSION,
+					},
+				},
+			},
+			Component: cdxComponent{
+				Type: "container",
+				Name: opts.Name,
+			},
+		},
+		Components: make([]cdxComponent, 0, len(pkgs)),
+	}
+
+	for _, p := range pkgs {
+		c := cdxComponent{
+			Type:    "library",
+			BOMRef:  p.PURL(),
+			Name:    p.Name,
+			Version: p.Version,
+			PURL:    p.PURL(),
+			Properties: []cdxProperty{
+				{Name: "sylabs:singularity:location", Value: p.Location},
+			},
+		}
+		if p.License != "" {
+			c.Licenses = []cdxLicense{{License: cdxLicenseName{Name: p.License}}}
+		}
+		bom.Components = append(bom.Components, c)
+	}
+
+	return json.MarshalIndent(bom, "", "  ")
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package sbom
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+// ScanImage returns the packages installed in the root filesystem of the OCI
+// image img, as Scan. The files read by Scan are extracted from the flattened
+// layers of img into a temporary directory in tmpDir.
+func ScanImage(img ggcrv1.Image, tmpDir string) ([]Package, error) {
+	dir, err := os.MkdirTemp(tmpDir, "sbom-rootfs-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := extractWanted(img, dir); err != nil {
+		return nil, fmt.Errorf("while extracting image: %w", err)
+	}
+	return Scan(dir)
+}
+
+// extractWanted extracts the regular files from the flattened layers of img
+// that are read by Scan into dir.
+func extractWanted(img ggcrv1.Image, dir string) error {
+	rc := mutate.Extract(img)
+	defer rc.Close()
+
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		if !wanted(name) {
+			continue
+		}
+
+		dest := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+// Package sbom generates software bills of materials, listing the packages
+// installed in a container root filesystem, in SPDX or CycloneDX format.
+package sbom
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Format is an SBOM document format.
+type Format string
+
+const (
+	// FormatSPDX is an SPDX 2.3 JSON document.
+	FormatSPDX Format = "spdx"
+	// FormatCycloneDX is a CycloneDX 1.5 JSON document.
+	FormatCycloneDX Format = "cyclonedx"
+)
+
+// ParseFormat returns the Format named by s.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatSPDX, FormatCycloneDX:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported SBOM format %q, must be one of: %s, %s", s, FormatSPDX, FormatCycloneDX)
+	}
+}
+
+// MediaType returns the media type of documents in format f.
+func (f Format) MediaType() string {
+	switch f {
+	case FormatSPDX:
+		return "application/spdx+json"
+	case FormatCycloneDX:
+		return "application/vnd.cyclonedx+json"
+	default:
+		return ""
+	}
+}
+
+// Package is a software package found in a root filesystem.
+type Package struct {
+	// Type is the package URL type, e.g. deb, rpm, apk, npm, pypi, gem.
+	Type string
+	// Namespace is the package URL namespace, e.g. the distribution ID for
+	// operating system packages, or the scope of an npm package.
+	Namespace string
+	Name      string
+	Version   string
+	Arch      string
+	// License is the license declared by the package, as recorded by its
+	// package manager.
+	License string
+	// Location is the path, in the root filesystem, of the package manager
+	// database or manifest that the package was found in.
+	Location string
+}
+
+// PURL returns the package URL identifying p.
+func (p Package) PURL() string {
+	var sb strings.Builder
+	sb.WriteString("pkg:")
+	sb.WriteString(p.Type)
+	sb.WriteString("/")
+	if p.Namespace != "" {
+		sb.WriteString(purlEscape(p.Namespace))
+		sb.WriteString("/")
+	}
+	sb.WriteString(purlEscape(p.Name))
+	if p.Version != "" {
+		sb.WriteString("@")
+		sb.WriteString(purlEscape(p.Version))
+	}
+	if p.Arch != "" {
+		sb.WriteString("?arch=")
+		sb.WriteString(url.QueryEscape(p.Arch))
+	}
+	return sb.String()
+}
+
+// purlEscape percent-encodes s for use as a package URL component.
+func purlEscape(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "@", "%40")
+}
+
+// Options configures SBOM generation.
+type Options struct {
+	// Name is the name of the container image that the SBOM describes.
+	Name string
+	// Created is the creation time recorded in the SBOM. The current time is
+	// used if it is zero.
+	Created time.Time
+}
+
+// Generate returns an SBOM document in format f, listing pkgs.
+func Generate(f Format, pkgs []Package, opts Options) ([]byte, error) {
+	if opts.Created.IsZero() {
+		opts.Created = time.Now()
+	}
+	opts.Created = opts.Created.UTC().Truncate(time.Second)
+
+	switch f {
+	case FormatSPDX:
+		return generateSPDX(pkgs, opts)
+	case FormatCycloneDX:
+		return generateCycloneDX(pkgs, opts)
+	default:
+		return nil, fmt.Errorf("unsupported SBOM format %q", f)
+	}
+}
+
+// sortPackages sorts pkgs by package URL, removing duplicates, so that the same
+// packages are always listed in the same order.
+func sortPackages(pkgs []Package) []Package {
+	sorted := make([]Package, 0, len(pkgs))
+	seen := make(map[string]bool, len(pkgs))
+	for _, p := range pkgs {
+		purl := p.PURL()
+		if seen[purl] {
+			continue
+		}
+		seen[purl] = true
+		sorted = append(sorted, p)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].PURL() < sorted[j].PURL()
+	})
+	return sorted
+}
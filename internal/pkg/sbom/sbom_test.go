@@ -0,0 +1,194 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+var testRootfs = map[string]string{
+	"etc/os-release": "NAME=\"Debian GNU/Linux\"\nID=debian\n",
+	"var/lib/dpkg/status": `Package: base-files
+Status: install ok installed
+Architecture: amd64
+Version: 12.4+deb12u5
+Description: Debian base system miscellaneous files
+ This package contains the basic filesystem hierarchy.
+
+Package: removed
+Status: deinstall ok config-files
+Architecture: amd64
+Version: 1.0
+
+`,
+	"var/lib/dpkg/status.d/tzdata":                                        "Package: tzdata\nVersion: 2024a-0+deb12u1\nArchitecture: all\n",
+	"lib/apk/db/installed":                                                "C:Q1abc=\nP:musl\nV:1.2.5-r0\nA:x86_64\nL:MIT\n\nP:busybox\nV:1.36.1-r29\nA:x86_64\nL:GPL-2.0-only\n",
+	"usr/lib/node_modules/npm/package.json":                               `{"name":"npm","version":"10.5.0","license":"Artistic-2.0"}`,
+	"usr/lib/node_modules/npm/node_modules/@npmcli/arborist/package.json": `{"name":"@npmcli/arborist","version":"7.4.0","license":{"type":"ISC"}}`,
+	"usr/lib/node_modules/npm/lib/package.json":                           `{"name":"not-a-module","version":"1.0.0"}`,
+	"usr/lib/python3/dist-packages/requests-2.31.0.dist-info/METADATA":    "Metadata-Version: 2.1\nName: Requests\nVersion: 2.31.0\nLicense: Apache 2.0\n\nName: description\n",
+	"var/lib/gems/3.1.0/specifications/rake-13.0.6.gemspec":               "# gemspec",
+	"tmp/package.json":                                                    `{"name":"ignored","version":"1.0.0"}`,
+}
+
+func writeRootfs(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestScan(t *testing.T) {
+	rootfs := writeRootfs(t, testRootfs)
+
+	pkgs, err := Scan(rootfs)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	var got []string
+	for _, p := range pkgs {
+		got = append(got, p.PURL())
+	}
+	sort.Strings(got)
+	want := []string{
+		"pkg:apk/debian/busybox@1.36.1-r29?arch=x86_64",
+		"pkg:apk/debian/musl@1.2.5-r0?arch=x86_64",
+		"pkg:deb/debian/base-files@12.4+deb12u5?arch=amd64",
+		"pkg:deb/debian/tzdata@2024a-0+deb12u1?arch=all",
+		"pkg:gem/rake@13.0.6",
+		"pkg:npm/%40npmcli/arborist@7.4.0",
+		"pkg:npm/npm@10.5.0",
+		"pkg:pypi/requests@2.31.0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan() found %v, want %v", got, want)
+	}
+
+	for _, p := range pkgs {
+		if p.Name == "arborist" && p.License != "ISC" {
+			t.Errorf("arborist license = %q, want ISC", p.License)
+		}
+		if p.Name == "musl" && p.Location != "/lib/apk/db/installed" {
+			t.Errorf("musl location = %q, want /lib/apk/db/installed", p.Location)
+		}
+	}
+}
+
+func TestParseRPMQuery(t *testing.T) {
+	out := "bash\t(none)\t5.2.26-3.fc40\tx86_64\tGPL-3.0-or-later\n" +
+		"gpg-pubkey\t(none)\ta15b79cc-63d04c2c\t(none)\tpubkey\n" +
+		"shadow-utils\t2\t4.15.1-3.fc40\tx86_64\tBSD-3-Clause\n"
+	pkgs, err := parseRPMQuery(bytes.NewBufferString(out), "/var/lib/rpm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Package{
+		{Type: "rpm", Name: "bash", Version: "5.2.26-3.fc40", Arch: "x86_64", License: "GPL-3.0-or-later", Location: "/var/lib/rpm"},
+		{Type: "rpm", Name: "shadow-utils", Version: "2:4.15.1-3.fc40", Arch: "x86_64", License: "BSD-3-Clause", Location: "/var/lib/rpm"},
+	}
+	if !reflect.DeepEqual(pkgs, want) {
+		t.Errorf("parseRPMQuery() = %v, want %v", pkgs, want)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	pkgs := []Package{
+		{Type: "deb", Namespace: "debian", Name: "tzdata", Version: "2024a", Arch: "all", Location: "/var/lib/dpkg/status"},
+		{Type: "npm", Name: "npm", Version: "10.5.0", License: "Artistic-2.0", Location: "/usr/lib/node_modules/npm/package.json"},
+		{Type: "deb", Namespace: "debian", Name: "tzdata", Version: "2024a", Arch: "all", Location: "/var/lib/dpkg/status"},
+	}
+	opts := Options{
+		Name:    "test.sif",
+		Created: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	tests := []struct {
+		format Format
+		check  func(t *testing.T, doc map[string]any)
+	}{
+		{
+			format: FormatSPDX,
+			check: func(t *testing.T, doc map[string]any) {
+				if doc["spdxVersion"] != "SPDX-2.3" {
+					t.Errorf("spdxVersion = %v", doc["spdxVersion"])
+				}
+				// The image, and the two distinct packages.
+				if n := len(doc["packages"].([]any)); n != 3 {
+					t.Errorf("got %d packages, want 3", n)
+				}
+				ci := doc["creationInfo"].(map[string]any)
+				if ci["created"] != "2026-01-02T03:04:05Z" {
+					t.Errorf("created = %v", ci["created"])
+				}
+			},
+		},
+		{
+			format: FormatCycloneDX,
+			check: func(t *testing.T, doc map[string]any) {
+				if doc["bomFormat"] != "CycloneDX" {
+					t.Errorf("bomFormat = %v", doc["bomFormat"])
+				}
+				components := doc["components"].([]any)
+				if n := len(components); n != 2 {
+					t.Fatalf("got %d components, want 2", n)
+				}
+				if purl := components[0].(map[string]any)["purl"]; purl != "pkg:deb/debian/tzdata@2024a?arch=all" {
+					t.Errorf("first component purl = %v", purl)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			b, err := Generate(tt.format, pkgs, opts)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			var doc map[string]any
+			if err := json.Unmarshal(b, &doc); err != nil {
+				t.Fatalf("Generate() returned invalid JSON: %v", err)
+			}
+			tt.check(t, doc)
+
+			// Identical input must produce an identical document.
+			b2, err := Generate(tt.format, pkgs, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, b2) {
+				t.Errorf("Generate() is not deterministic")
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, s := range []string{"spdx", "SPDX", "cyclonedx"} {
+		if _, err := ParseFormat(s); err != nil {
+			t.Errorf("ParseFormat(%q) error = %v", s, err)
+		}
+	}
+	if _, err := ParseFormat("syft"); err == nil {
+		t.Errorf("ParseFormat(\"syft\") succeeded")
+	}
+}
@@ -0,0 +1,407 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sylabs/singularity/v4/internal/pkg/util/bin"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+)
+
+// rpmDBDirs are the locations of the rpm database in a root filesystem.
+var rpmDBDirs = []string{
+	"usr/lib/sysimage/rpm",
+	"var/lib/rpm",
+}
+
+// osReleaseFiles are the locations of the os-release file in a root filesystem.
+var osReleaseFiles = []string{
+	"etc/os-release",
+	"usr/lib/os-release",
+}
+
+// skipDirs are not scanned, as they hold no installed packages.
+var skipDirs = map[string]bool{
+	"proc": true,
+	"sys":  true,
+	"dev":  true,
+	"tmp":  true,
+}
+
+// parser reads the packages from a package database or manifest r, found at
+// name in a root filesystem.
+type parser func(r io.Reader, name string) ([]Package, error)
+
+// parserFor returns the parser for the file at name, a slash-separated path
+// relative to the root of a root filesystem, or nil if the file does not list
+// packages.
+func parserFor(name string) parser {
+	dir, base := path.Split(name)
+	switch {
+	case name == "var/lib/dpkg/status", dir == "var/lib/dpkg/status.d/" && !strings.Contains(base, "."):
+		return parseDpkgStatus
+	case name == "lib/apk/db/installed":
+		return parseApkInstalled
+	case base == "package.json" && isNodeModule(dir):
+		return parsePackageJSON
+	case base == "METADATA" && strings.HasSuffix(dir, ".dist-info/"),
+		base == "PKG-INFO" && strings.HasSuffix(dir, ".egg-info/"):
+		return parsePythonMetadata
+	case strings.HasSuffix(base, ".gemspec") && strings.HasSuffix(dir, "/specifications/"):
+		return parseGemspecName
+	default:
+		return nil
+	}
+}
+
+// isNodeModule returns true if dir is the directory of a package installed in
+// a node_modules directory.
+func isNodeModule(dir string) bool {
+	parent := path.Dir(path.Dir(dir))
+	if strings.HasPrefix(path.Base(parent), "@") {
+		parent = path.Dir(parent)
+	}
+	return path.Base(parent) == "node_modules"
+}
+
+// isRPMDB returns true if name is a file of the rpm database.
+func isRPMDB(name string) bool {
+	for _, d := range rpmDBDirs {
+		if strings.HasPrefix(name, d+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isOSRelease returns true if name is an os-release file.
+func isOSRelease(name string) bool {
+	for _, f := range osReleaseFiles {
+		if name == f {
+			return true
+		}
+	}
+	return false
+}
+
+// wanted returns true if the file at name, a slash-separated path relative to
+// the root of a root filesystem, is read by Scan.
+func wanted(name string) bool {
+	return parserFor(name) != nil || isRPMDB(name) || isOSRelease(name)
+}
+
+// Scan returns the packages installed in the root filesystem at rootfs, as
+// recorded in the dpkg, rpm and apk databases, and the manifests of npm, Python
+// and Ruby packages. The rpm database is read with the host rpm command, and is
+// skipped, with a warning, if it is not available.
+func Scan(rootfs string) ([]Package, error) {
+	distro := distroID(rootfs)
+
+	var pkgs []Package
+	err := filepath.WalkDir(rootfs, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrPermission) {
+				sylog.Debugf("Skipping %s: %v", p, err)
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(rootfs, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if skipDirs[rel] {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		parse := parserFor(rel)
+		if parse == nil {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		found, err := parse(f, "/"+rel)
+		if err != nil {
+			sylog.Warningf("Could not read packages from /%s: %v", rel, err)
+			return nil
+		}
+		pkgs = append(pkgs, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("while scanning root filesystem: %w", err)
+	}
+
+	rpms, err := scanRPMDB(rootfs)
+	if err != nil {
+		return nil, err
+	}
+	pkgs = append(pkgs, rpms...)
+
+	for i, p := range pkgs {
+		if p.Namespace == "" && (p.Type == "deb" || p.Type == "rpm" || p.Type == "apk") {
+			pkgs[i].Namespace = distro
+		}
+	}
+	sylog.Debugf("Found %d packages in %s", len(pkgs), rootfs)
+	return pkgs, nil
+}
+
+// distroID returns the ID of the distribution installed in the root filesystem
+// at rootfs, from its os-release file.
+func distroID(rootfs string) string {
+	for _, f := range osReleaseFiles {
+		b, err := os.ReadFile(filepath.Join(rootfs, f))
+		if err != nil {
+			continue
+		}
+		for _, l := range strings.Split(string(b), "\n") {
+			if v, ok := strings.CutPrefix(l, "ID="); ok {
+				return strings.Trim(strings.TrimSpace(v), `"'`)
+			}
+		}
+	}
+	return ""
+}
+
+// scanRPMDB returns the packages recorded in the rpm database of the root
+// filesystem at rootfs.
+func scanRPMDB(rootfs string) ([]Package, error) {
+	dbDir := ""
+	for _, d := range rpmDBDirs {
+		if fi, err := os.Stat(filepath.Join(rootfs, d)); err == nil && fi.IsDir() {
+			dbDir = d
+			break
+		}
+	}
+	if dbDir == "" {
+		return nil, nil
+	}
+
+	rpm, err := bin.FindBin("rpm")
+	if err != nil {
+		sylog.Warningf("rpm database found at /%s, but rpm is not available on the host: rpm packages will not be listed", dbDir)
+		return nil, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(rpm, "--root", rootfs, "--dbpath", "/"+dbDir, "-qa",
+		"--qf", `%{NAME}\t%{EPOCH}\t%{VERSION}-%{RELEASE}\t%{ARCH}\t%{LICENSE}\n`)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("while reading rpm database: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseRPMQuery(&stdout, "/"+dbDir)
+}
+
+// parseRPMQuery parses the output of the rpm query run by scanRPMDB.
+func parseRPMQuery(r io.Reader, name string) ([]Package, error) {
+	var pkgs []Package
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		fields := strings.Split(s.Text(), "\t")
+		if len(fields) != 5 || fields[0] == "gpg-pubkey" {
+			continue
+		}
+		version := fields[2]
+		if fields[1] != "(none)" && fields[1] != "0" {
+			version = fields[1] + ":" + version
+		}
+		arch := fields[3]
+		if arch == "(none)" {
+			arch = ""
+		}
+		pkgs = append(pkgs, Package{
+			Type:     "rpm",
+			Name:     fields[0],
+			Version:  version,
+			Arch:     arch,
+			License:  fields[4],
+			Location: name,
+		})
+	}
+	return pkgs, s.Err()
+}
+
+// parseStanzas calls fn with the fields of each blank-line separated stanza of
+// "Key: value" lines in r. Continuation lines, starting with whitespace, are
+// ignored.
+func parseStanzas(r io.Reader, fn func(fields map[string]string)) error {
+	fields := map[string]string{}
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		l := s.Text()
+		if strings.TrimSpace(l) == "" {
+			if len(fields) > 0 {
+				fn(fields)
+				fields = map[string]string{}
+			}
+			continue
+		}
+		if l[0] == ' ' || l[0] == '\t' {
+			continue
+		}
+		if k, v, ok := strings.Cut(l, ":"); ok {
+			if _, exists := fields[k]; !exists {
+				fields[k] = strings.TrimSpace(v)
+			}
+		}
+	}
+	if len(fields) > 0 {
+		fn(fields)
+	}
+	return s.Err()
+}
+
+// parseDpkgStatus reads the installed packages from a dpkg status file.
+func parseDpkgStatus(r io.Reader, name string) ([]Package, error) {
+	var pkgs []Package
+	err := parseStanzas(r, func(f map[string]string) {
+		if f["Package"] == "" {
+			return
+		}
+		// status.d files, as used in distroless images, have no Status.
+		if st, ok := f["Status"]; ok && !strings.HasSuffix(st, " installed") {
+			return
+		}
+		pkgs = append(pkgs, Package{
+			Type:     "deb",
+			Name:     f["Package"],
+			Version:  f["Version"],
+			Arch:     f["Architecture"],
+			Location: name,
+		})
+	})
+	return pkgs, err
+}
+
+// parseApkInstalled reads the installed packages from an apk installed
+// database.
+func parseApkInstalled(r io.Reader, name string) ([]Package, error) {
+	var pkgs []Package
+	err := parseStanzas(r, func(f map[string]string) {
+		if f["P"] == "" {
+			return
+		}
+		pkgs = append(pkgs, Package{
+			Type:     "apk",
+			Name:     f["P"],
+			Version:  f["V"],
+			Arch:     f["A"],
+			License:  f["L"],
+			Location: name,
+		})
+	})
+	return pkgs, err
+}
+
+// parsePackageJSON reads an npm package from its package.json manifest.
+func parsePackageJSON(r io.Reader, name string) ([]Package, error) {
+	var m struct {
+		Name    string          `json:"name"`
+		Version string          `json:"version"`
+		License json.RawMessage `json:"license"`
+	}
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	if m.Name == "" || m.Version == "" {
+		return nil, nil
+	}
+
+	// The license is usually an SPDX expression, but older packages hold an
+	// object with a type.
+	var license string
+	if err := json.Unmarshal(m.License, &license); err != nil {
+		var l struct {
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(m.License, &l) == nil {
+			license = l.Type
+		}
+	}
+
+	p := Package{
+		Type:     "npm",
+		Name:     m.Name,
+		Version:  m.Version,
+		License:  license,
+		Location: name,
+	}
+	if scope, n, ok := strings.Cut(m.Name, "/"); ok && strings.HasPrefix(scope, "@") {
+		p.Namespace = scope
+		p.Name = n
+	}
+	return []Package{p}, nil
+}
+
+// parsePythonMetadata reads a Python package from its core metadata file.
+func parsePythonMetadata(r io.Reader, name string) ([]Package, error) {
+	var pkgs []Package
+	first := true
+	err := parseStanzas(r, func(f map[string]string) {
+		// Only the header stanza holds metadata; the description may follow.
+		if !first {
+			return
+		}
+		first = false
+		if f["Name"] == "" {
+			return
+		}
+		license := f["License-Expression"]
+		if license == "" {
+			license = f["License"]
+		}
+		pkgs = append(pkgs, Package{
+			Type:     "pypi",
+			Name:     strings.ToLower(f["Name"]),
+			Version:  f["Version"],
+			License:  license,
+			Location: name,
+		})
+	})
+	return pkgs, err
+}
+
+// parseGemspecName reads a Ruby gem from the name of its installed
+// specification, which is <name>-<version>.gemspec.
+func parseGemspecName(_ io.Reader, name string) ([]Package, error) {
+	nv := strings.TrimSuffix(path.Base(name), ".gemspec")
+	i := strings.LastIndex(nv, "-")
+	if i < 1 {
+		return nil, nil
+	}
+	return []Package{{
+		Type:     "gem",
+		Name:     nv[:i],
+		Version:  nv[i+1:],
+		Location: name,
+	}}, nil
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sylabs/singularity/v4/internal/pkg/buildcfg"
+)
+
+const (
+	spdxNoAssertion = "NOASSERTION"
+	spdxImageID     = "SPDXRef-Image"
+)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	LicenseComments  string            `json:"licenseComments,omitempty"`
+	CopyrightText    string            `json:"copyrightText"`
+	SourceInfo       string            `json:"sourceInfo,omitempty"`
+	PrimaryPurpose   string            `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// generateSPDX returns an SPDX 2.3 JSON document describing the container
+// image, which contains pkgs.
+func generateSPDX(pkgs []Package, opts Options) ([]byte, error) {
+	pkgs = sortPackages(pkgs)
+
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              opts.Name,
+		DocumentNamespace: "https://sylabs.io/spdxdocs/" + opts.Name + "-" + documentID(pkgs, opts).String(),
+		CreationInfo: spdxCreationInfo{
+			Created:  opts.Created.Format(time.RFC3339),
+			Creators: []string{"Tool: singularity-" + buildcfg.PACKAGE_VERSION},
+		},
+		Packages: []spdxPackage{
+			{
+				Name:             opts.Name,
+				SPDXID:           spdxImageID,
+				DownloadLocation: spdxNoAssertion,
+				LicenseConcluded: spdxNoAssertion,
+				LicenseDeclared:  spdxNoAssertion,
+				CopyrightText:    spdxNoAssertion,
+				PrimaryPurpose:   "CONTAINER",
+			},
+		},
+		Relationships: []spdxRelationship{
+			{
+				SPDXElementID:      "SPDXRef-DOCUMENT",
+				RelationshipType:   "DESCRIBES",
+				RelatedSPDXElement: spdxImageID,
+			},
+		},
+	}
+
+	for i, p := range pkgs {
+		id := fmt.Sprintf("SPDXRef-Package-%s-%d", p.Type, i+1)
+		sp := spdxPackage{
+			Name:             p.Name,
+			SPDXID:           id,
+			VersionInfo:      p.Version,
+			DownloadLocation: spdxNoAssertion,
+			LicenseConcluded: spdxNoAssertion,
+			// The licenses recorded by package managers are not reliably valid
+			// SPDX license expressions, so are held as a comment.
+			LicenseDeclared: spdxNoAssertion,
+			LicenseComments: p.License,
+			CopyrightText:   spdxNoAssertion,
+			SourceInfo:      "found in " + p.Location,
+			ExternalRefs: []spdxExternalRef{
+				{
+					ReferenceCategory: "PACKAGE-MANAGER",
+					ReferenceType:     "purl",
+					ReferenceLocator:  p.PURL(),
+				},
+			},
+		}
+		doc.Packages = append(doc.Packages, sp)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      spdxImageID,
+			RelationshipType:   "CONTAINS",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// documentID returns an identifier for an SBOM document listing pkgs. It is
+// derived from the content of the document, so that identical builds produce
+// identical documents.
+func documentID(pkgs []Package, opts Options) uuid.UUID {
+	data := opts.Name + "\n" + opts.Created.Format(time.RFC3339) + "\n"
+	for _, p := range pkgs {
+		data += p.PURL() + "\n"
+	}
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(data))
+}
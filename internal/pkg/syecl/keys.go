@@ -0,0 +1,257 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package syecl
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sylabs/sif/v2/pkg/integrity"
+	"github.com/sylabs/sif/v2/pkg/sif"
+	"github.com/sylabs/singularity/v4/internal/pkg/cosign"
+	sifsignature "github.com/sylabs/singularity/v4/internal/pkg/signature"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"golang.org/x/sys/unix"
+)
+
+// hasKeyMaterial returns true if egroup lists x509 certificates or cosign
+// keys, in addition to any PGP key fingerprints.
+func (egroup *Execgroup) hasKeyMaterial() bool {
+	return len(egroup.Certificates) > 0 || len(egroup.CosignKeys) > 0
+}
+
+// validateKeyMaterial checks that the x509 and cosign settings of egroup are
+// logically correct.
+func (egroup *Execgroup) validateKeyMaterial() error {
+	files := [][]string{egroup.Certificates, egroup.Roots, egroup.Intermediates, egroup.CosignKeys}
+	for _, paths := range files {
+		for _, p := range paths {
+			if !filepath.IsAbs(p) {
+				return fmt.Errorf("certificate and key paths must be absolute: %s", p)
+			}
+		}
+	}
+
+	if len(egroup.Certificates) == 0 {
+		if len(egroup.Roots) > 0 || len(egroup.Intermediates) > 0 || len(egroup.Subjects) > 0 || len(egroup.SANs) > 0 || egroup.OCSP {
+			return fmt.Errorf("roots, intermediates, subjects, sans and ocsp require certificates to be set in execgroup %q", egroup.TagName)
+		}
+	}
+
+	for _, pattern := range append(egroup.Subjects, egroup.SANs...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid subject or SAN pattern %q: %v", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// readPEMFile reads a PEM encoded file at path, without following symlinks.
+func readPEMFile(path string) ([]byte, error) {
+	f, err := os.OpenFile(path, os.O_RDONLY|unix.O_NOFOLLOW, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+// loadCertificates returns all of the certificates in the PEM files at paths.
+func loadCertificates(paths []string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+
+	for _, p := range paths {
+		b, err := readPEMFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("while reading certificates: %w", err)
+		}
+
+		for {
+			var block *pem.Block
+			block, b = pem.Decode(b)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			c, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("while parsing certificate from %s: %w", p, err)
+			}
+			certs = append(certs, c)
+		}
+	}
+
+	return certs, nil
+}
+
+// loadCertPool returns a pool holding the certificates in the PEM files at
+// paths, or nil if no paths are specified, so that the system pool is used.
+func loadCertPool(paths []string) (*x509.CertPool, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	certs, err := loadCertificates(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	for _, c := range certs {
+		pool.AddCert(c)
+	}
+	return pool, nil
+}
+
+// matchAny returns true if any of values matches any of patterns.
+func matchAny(patterns, values []string) bool {
+	for _, p := range patterns {
+		for _, v := range values {
+			if ok, _ := path.Match(p, v); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// certificateSANs returns the subject alternative names of c.
+func certificateSANs(c *x509.Certificate) []string {
+	sans := []string{}
+	sans = append(sans, c.DNSNames...)
+	sans = append(sans, c.EmailAddresses...)
+	for _, ip := range c.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, u := range c.URIs {
+		sans = append(sans, u.String())
+	}
+	return sans
+}
+
+// trustCertificate checks that c chains to a trusted root, matches the
+// subject and SAN patterns of egroup, and optionally has not been revoked.
+func (egroup *Execgroup) trustCertificate(c *x509.Certificate, intermediates, roots *x509.CertPool) error {
+	if len(egroup.Subjects) > 0 && !matchAny(egroup.Subjects, []string{c.Subject.String(), c.Subject.CommonName}) {
+		return fmt.Errorf("subject %q does not match", c.Subject)
+	}
+	if len(egroup.SANs) > 0 && !matchAny(egroup.SANs, certificateSANs(c)) {
+		return fmt.Errorf("subject alternative names do not match")
+	}
+
+	chains, err := c.Verify(x509.VerifyOptions{
+		Intermediates: intermediates,
+		Roots:         roots,
+		KeyUsages: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageCodeSigning,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if egroup.OCSP {
+		if len(chains) != 1 {
+			return fmt.Errorf("unhandled OCSP condition, chain length %d != 1", len(chains))
+		}
+		if err := sifsignature.OCSPVerify(chains[0]...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifyKeyMaterial returns, for each x509 certificate and cosign key listed
+// in egroup, whether it verifies the image f, which was opened from path.
+//
+// In whitelist and whitestrict modes, a certificate that is not trusted,
+// according to the roots and patterns of egroup, does not verify any image.
+// In blacklist mode a certificate is forbidden regardless of its trust.
+func verifyKeyMaterial(ctx context.Context, egroup *Execgroup, f *sif.FileImage, path string) ([]bool, error) {
+	verified := []bool{}
+
+	certs, err := loadCertificates(egroup.Certificates)
+	if err != nil {
+		return nil, err
+	}
+	if len(egroup.Certificates) > 0 && len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found for execgroup %q", egroup.TagName)
+	}
+	if len(certs) > 0 {
+		intermediates, err := loadCertPool(egroup.Intermediates)
+		if err != nil {
+			return nil, err
+		}
+		roots, err := loadCertPool(egroup.Roots)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range certs {
+			if egroup.ListMode != "blacklist" {
+				if err := egroup.trustCertificate(c, intermediates, roots); err != nil {
+					sylog.Debugf("ECL: certificate %q not trusted: %v", c.Subject, err)
+					verified = append(verified, false)
+					continue
+				}
+			}
+
+			sv, err := signature.LoadVerifier(c.PublicKey, crypto.SHA256)
+			if err != nil {
+				return nil, fmt.Errorf("while loading certificate %q: %w", c.Subject, err)
+			}
+			v, err := integrity.NewVerifier(f,
+				integrity.OptVerifyWithContext(ctx),
+				integrity.OptVerifyWithVerifier(sv),
+			)
+			if err == nil {
+				err = v.Verify()
+			}
+			if err != nil {
+				sylog.Debugf("ECL: image not verified by certificate %q: %v", c.Subject, err)
+			}
+			verified = append(verified, err == nil)
+		}
+	}
+
+	for _, k := range egroup.CosignKeys {
+		b, err := readPEMFile(k)
+		if err != nil {
+			return nil, fmt.Errorf("while reading cosign key: %w", err)
+		}
+		pub, err := cryptoutils.UnmarshalPEMToPublicKey(b)
+		if err != nil {
+			return nil, fmt.Errorf("while loading cosign key %s: %w", k, err)
+		}
+		sv, err := signature.LoadVerifier(pub, crypto.SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("while loading cosign key %s: %w", k, err)
+		}
+		// Cosign signatures are only held by OCI-SIF images, so that a native
+		// SIF image is never verified by a cosign key.
+		_, err = cosign.VerifyOCISIF(ctx, path, sv)
+		if err != nil {
+			sylog.Debugf("ECL: image not verified by cosign key %s: %v", k, err)
+		}
+		verified = append(verified, err == nil)
+	}
+
+	return verified, nil
+}
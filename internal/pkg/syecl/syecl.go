@@ -18,6 +18,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
@@ -43,16 +44,30 @@ type EclConfig struct {
 //
 //	TagName: a descriptive identifier
 //	ListMode: whether the execgroup follows a whitelist, whitestrict or blacklist model
-//		whitelist: one or more KeyFP's present and verified,
-//		whitestrict: all KeyFP's present and verified,
-//		blacklist: none of the KeyFP should be present
+//		whitelist: one or more signing entities present and verified,
+//		whitestrict: all signing entities present and verified,
+//		blacklist: none of the signing entities should be present
 //	DirPath: containers must be stored in this directory path
 //	KeyFPs: list of Key Fingerprints of entities to verify
+//	Certificates: list of PEM files holding x509 signing certificates
+//	Roots: list of PEM files holding trusted root CA certificates
+//	Intermediates: list of PEM files holding intermediate CA certificates
+//	Subjects: patterns, one of which a certificate subject must match
+//	SANs: patterns, one of which a certificate subject alternative name must match
+//	OCSP: check x509 certificates for revocation via OCSP
+//	CosignKeys: list of PEM files holding cosign public keys, for OCI-SIF images
 type Execgroup struct {
-	TagName  string   `toml:"tagname"`
-	ListMode string   `toml:"mode"`
-	DirPath  string   `toml:"dirpath"`
-	KeyFPs   []string `toml:"keyfp"`
+	TagName       string   `toml:"tagname"`
+	ListMode      string   `toml:"mode"`
+	DirPath       string   `toml:"dirpath"`
+	KeyFPs        []string `toml:"keyfp"`
+	Certificates  []string `toml:"certificates,omitempty"`
+	Roots         []string `toml:"roots,omitempty"`
+	Intermediates []string `toml:"intermediates,omitempty"`
+	Subjects      []string `toml:"subjects,omitempty"`
+	SANs          []string `toml:"sans,omitempty"`
+	OCSP          bool     `toml:"ocsp,omitempty"`
+	CosignKeys    []string `toml:"cosignkeys,omitempty"`
 }
 
 // LoadConfig opens an ECL config file and unmarshals it into structures
@@ -129,6 +144,9 @@ func (ecl *EclConfig) ValidateConfig() error {
 				return fmt.Errorf("expecting a 40 chars hex fingerprint string")
 			}
 		}
+		if err := v.validateKeyMaterial(); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -221,6 +239,56 @@ func shouldRun(ctx context.Context, ecl *EclConfig, fp *os.File, kr openpgp.KeyR
 	}
 	defer f.UnloadContainer()
 
+	// Execgroups that list only PGP key fingerprints are evaluated against the
+	// PGP signatures in the image alone.
+	if !egroup.hasKeyMaterial() {
+		return checkPGP(ctx, ecl, egroup, f, kr)
+	}
+
+	var pgpOK bool
+	var pgpErr error
+	if len(egroup.KeyFPs) > 0 {
+		pgpOK, pgpErr = checkPGP(ctx, ecl, egroup, f, kr)
+	}
+
+	verified, err := verifyKeyMaterial(ctx, egroup, f, fp.Name())
+	if err != nil {
+		return false, err
+	}
+
+	switch egroup.ListMode {
+	case "whitelist":
+		if pgpOK || slices.Contains(verified, true) {
+			return true, nil
+		}
+		if pgpErr != nil {
+			return false, pgpErr
+		}
+		return false, errNotSignedByRequired
+	case "whitestrict":
+		if pgpErr != nil {
+			return false, pgpErr
+		}
+		if slices.Contains(verified, false) {
+			return false, errNotSignedByRequired
+		}
+		return true, nil
+	case "blacklist":
+		if pgpErr != nil {
+			return false, pgpErr
+		}
+		if slices.Contains(verified, true) {
+			return false, errSignedByForbidden
+		}
+		return true, nil
+	}
+
+	return false, fmt.Errorf("ecl config file invalid")
+}
+
+// checkPGP evaluates the PGP signatures in f against the key fingerprints of
+// egroup, verifying them with key material from kr.
+func checkPGP(ctx context.Context, ecl *EclConfig, egroup *Execgroup, f *sif.FileImage, kr openpgp.KeyRing) (ok bool, err error) {
 	opts := []integrity.VerifierOpt{
 		integrity.OptVerifyWithContext(ctx),
 		integrity.OptVerifyWithKeyRing(kr),
@@ -264,6 +264,72 @@ func TestValidateConfig(t *testing.T) {
 			}},
 			wantErr: true,
 		},
+		{
+			name: "RelativeCertificate",
+			c: EclConfig{ExecGroups: []Execgroup{
+				{ListMode: "whitelist", Certificates: []string{"leaf.pem"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "RootsWithoutCertificates",
+			c: EclConfig{ExecGroups: []Execgroup{
+				{ListMode: "whitelist", Roots: []string{"/etc/ssl/root.pem"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "BadSubjectPattern",
+			c: EclConfig{ExecGroups: []Execgroup{
+				{ListMode: "whitelist", Certificates: []string{"/etc/ssl/leaf.pem"}, Subjects: []string{"["}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "Certificates",
+			c: EclConfig{ExecGroups: []Execgroup{
+				{
+					ListMode:     "whitelist",
+					Certificates: []string{"/etc/ssl/leaf.pem"},
+					Roots:        []string{"/etc/ssl/root.pem"},
+					Subjects:     []string{"CN=leaf,*"},
+					CosignKeys:   []string{"/etc/ssl/cosign.pub"},
+				},
+			}},
+		},
+		{
+			name: "RelativeCertificate",
+			c: EclConfig{ExecGroups: []Execgroup{
+				{ListMode: "whitelist", Certificates: []string{"leaf.pem"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "RootsWithoutCertificates",
+			c: EclConfig{ExecGroups: []Execgroup{
+				{ListMode: "whitelist", Roots: []string{"/etc/ssl/root.pem"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "BadSubjectPattern",
+			c: EclConfig{ExecGroups: []Execgroup{
+				{ListMode: "whitelist", Certificates: []string{"/etc/ssl/leaf.pem"}, Subjects: []string{"["}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "Certificates",
+			c: EclConfig{ExecGroups: []Execgroup{
+				{
+					ListMode:     "whitelist",
+					Certificates: []string{"/etc/ssl/leaf.pem"},
+					Roots:        []string{"/etc/ssl/root.pem"},
+					Subjects:     []string{"CN=leaf,*"},
+					CosignKeys:   []string{"/etc/ssl/cosign.pub"},
+				},
+			}},
+		},
 		{
 			name: "Deactivated",
 			c:    EclConfig{Activated: false},
@@ -455,3 +521,117 @@ func TestShouldRun(t *testing.T) {
 		})
 	}
 }
+
+func TestShouldRunKeyMaterial(t *testing.T) {
+	dirPath, err := filepath.Abs(filepath.Join("..", "..", "..", "test", "images"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath := func(name string) string {
+		p, err := filepath.Abs(filepath.Join("..", "..", "..", "test", "certs", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	leaf := []string{certPath("leaf.pem")}
+	intermediates := []string{certPath("intermediate.pem")}
+	roots := []string{certPath("root.pem")}
+
+	unsigned := filepath.Join(dirPath, "one-group.sif")
+	signed := filepath.Join(dirPath, "one-group-signed-dsse.sif")
+
+	tests := []struct {
+		name    string
+		eg      Execgroup
+		path    string
+		wantErr bool
+	}{
+		{
+			name: "WhitelistOK",
+			eg:   Execgroup{ListMode: "whitelist", Certificates: leaf, Intermediates: intermediates, Roots: roots},
+			path: signed,
+		},
+		{
+			name:    "WhitelistUnsigned",
+			eg:      Execgroup{ListMode: "whitelist", Certificates: leaf, Intermediates: intermediates, Roots: roots},
+			path:    unsigned,
+			wantErr: true,
+		},
+		{
+			name:    "WhitelistUntrusted",
+			eg:      Execgroup{ListMode: "whitelist", Certificates: leaf, Roots: roots},
+			path:    signed,
+			wantErr: true,
+		},
+		{
+			name: "WhitelistSubjectOK",
+			eg:   Execgroup{ListMode: "whitelist", Certificates: leaf, Intermediates: intermediates, Roots: roots, Subjects: []string{"leaf"}},
+			path: signed,
+		},
+		{
+			name:    "WhitelistSubjectMismatch",
+			eg:      Execgroup{ListMode: "whitelist", Certificates: leaf, Intermediates: intermediates, Roots: roots, Subjects: []string{"other"}},
+			path:    signed,
+			wantErr: true,
+		},
+		{
+			name:    "WhitelistSANMismatch",
+			eg:      Execgroup{ListMode: "whitelist", Certificates: leaf, Intermediates: intermediates, Roots: roots, SANs: []string{"*.example.com"}},
+			path:    signed,
+			wantErr: true,
+		},
+		{
+			name: "WhitelistPGPOrCertificate",
+			eg:   Execgroup{ListMode: "whitelist", KeyFPs: []string{KeyFP1}, Certificates: leaf, Intermediates: intermediates, Roots: roots},
+			path: signed,
+		},
+		{
+			name:    "WhitestrictPGPAndCertificate",
+			eg:      Execgroup{ListMode: "whitestrict", KeyFPs: []string{KeyFP1}, Certificates: leaf, Intermediates: intermediates, Roots: roots},
+			path:    signed,
+			wantErr: true,
+		},
+		{
+			name: "WhitestrictOK",
+			eg:   Execgroup{ListMode: "whitestrict", Certificates: leaf, Intermediates: intermediates, Roots: roots},
+			path: signed,
+		},
+		{
+			name:    "BlacklistSigned",
+			eg:      Execgroup{ListMode: "blacklist", Certificates: leaf},
+			path:    signed,
+			wantErr: true,
+		},
+		{
+			name: "BlacklistUnsigned",
+			eg:   Execgroup{ListMode: "blacklist", Certificates: leaf},
+			path: unsigned,
+		},
+		{
+			name:    "CosignNativeSIF",
+			eg:      Execgroup{ListMode: "whitelist", CosignKeys: []string{filepath.Join(dirPath, "..", "keys", "cosign.pub")}},
+			path:    signed,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := EclConfig{
+				Activated:  true,
+				ExecGroups: []Execgroup{tt.eg},
+			}
+
+			got, err := c.ShouldRun(t.Context(), tt.path, openpgp.EntityList{getTestEntity(t)})
+
+			if want := !tt.wantErr; got != want {
+				t.Errorf("got run %v, want %v", got, want)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got err %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -427,7 +427,9 @@ func compareKeyEntity(e *openpgp.Entity, oldToken string) bool {
 	return fmt.Sprintf("%X", e.PrimaryKey.Fingerprint) == oldToken
 }
 
-func findKeyByFingerprint(entities openpgp.EntityList, fingerprint string) *openpgp.Entity {
+// FindKeyByFingerprint returns the entity in entities with the provided
+// fingerprint, which may have a 0x prefix, or nil if there is none.
+func FindKeyByFingerprint(entities openpgp.EntityList, fingerprint string) *openpgp.Entity {
 	// Strip any `0x` prefix off the front of the fingerprint we are looking for
 	// to match with or without `0x` passed in
 	fingerprint = strings.TrimPrefix(fingerprint, "0x")
@@ -1,5 +1,5 @@
 // Copyright (c) 2020, Control Command Inc. All rights reserved.
-// Copyright (c) 2018-2021, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -612,7 +612,7 @@ func TestFindKeyByPringerprint(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			found := findKeyByFingerprint(tc.list, tc.fingerprint)
+			found := FindKeyByFingerprint(tc.list, tc.fingerprint)
 
 			switch {
 			case found == nil && tc.exists:
@@ -751,7 +751,7 @@ func TestRemoveKey(t *testing.T) {
 						len(tc.list)-1)
 				}
 
-				if found := findKeyByFingerprint(newList, tc.fingerprint); found != nil {
+				if found := FindKeyByFingerprint(newList, tc.fingerprint); found != nil {
 					t.Errorf("After removing key %q it should not be present in the new list, but it was found there",
 						tc.fingerprint)
 				}
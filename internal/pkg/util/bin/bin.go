@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2019-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -17,7 +17,7 @@ func FindBin(name string) (path string, err error) {
 	case "true", "mkfs.ext3", "cp", "rm", "dd", "truncate":
 		return findOnPath(name)
 	// Bootstrap related executables that we assume are on PATH
-	case "mount", "mknod", "debootstrap", "pacstrap", "dnf", "yum", "rpm", "curl", "uname", "zypper", "SUSEConnect", "rpmkeys", "proot":
+	case "mount", "mknod", "debootstrap", "pacstrap", "dnf", "yum", "rpm", "curl", "uname", "zypper", "SUSEConnect", "rpmkeys", "proot", "apk.static", "apk":
 		return findOnPath(name)
 	// Configurable executables that are found at build time, can be overridden
 	// in singularity.conf. If config value is "" will look on PATH.
@@ -48,6 +48,9 @@ func FindBin(name string) (path string, err error) {
 	// fuse2fs for OCI-mode bare-image overlay
 	case "fuse2fs":
 		return findOnPath(name)
+	// erofs-utils for creating, and FUSE mounting, EROFS images
+	case "mkfs.erofs", "erofsfuse":
+		return findOnPath(name)
 	// fuse-overlayfs for mounting overlays without kernel support for
 	// unprivileged overlays
 	case "fuse-overlayfs":
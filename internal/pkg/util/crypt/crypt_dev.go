@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -229,6 +230,97 @@ func (crypt *Device) EncryptFilesystem(path string, key []byte) (string, error)
 	return cryptF.Name(), err
 }
 
+// DecryptFilesystem opens the encrypted volume of size bytes, at offset in the
+// file at path, using the provided key. The decrypted contents of the volume
+// are copied to a temporary file, whose path is returned.
+// NOTE: it is the callers responsibility to remove the returned file.
+func (crypt *Device) DecryptFilesystem(path string, offset, size uint64, key []byte) (string, error) {
+	loop, err := createLoop(path, offset, size)
+	if err != nil {
+		return "", err
+	}
+
+	cryptName, err := crypt.Open(key, loop)
+	if err != nil {
+		return "", err
+	}
+	defer crypt.CloseCryptDevice(cryptName)
+
+	src, err := os.Open("/dev/mapper/" + cryptName)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp("", "decrypt-")
+	if err != nil {
+		sylog.Debugf("Error creating temporary decrypt file")
+		return "", err
+	}
+
+	sylog.Debugf("Copying %s to %s", src.Name(), dst.Name())
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return "", fmt.Errorf("unable to copy decrypted contents of %s: %v", path, err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(dst.Name())
+		return "", err
+	}
+
+	return dst.Name(), nil
+}
+
+// ChangeKey replaces oldKey with newKey in the crypt header of the encrypted
+// volume of size bytes, at offset in the file at path. The encrypted data
+// is not modified.
+func (crypt *Device) ChangeKey(path string, offset, size uint64, oldKey, newKey []byte) error {
+	loop, err := createLoop(path, offset, size)
+	if err != nil {
+		return err
+	}
+
+	cryptsetupFd, cryptsetupPath, err := trustedCryptsetup()
+	if err != nil {
+		return err
+	}
+	defer unix.Close(cryptsetupFd)
+
+	// The old key is passed on stdin, and the new key through a pipe that
+	// cryptsetup reads as a key file.
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer pr.Close()
+
+	cmd := exec.Command(cryptsetupPath, "luksChangeKey", "--batch-mode", "--key-file", "-", loop, "/dev/fd/3")
+	cmd.Stdin = bytes.NewReader(oldKey)
+	cmd.ExtraFiles = []*os.File{pr}
+
+	go func() {
+		pw.Write(newKey)
+		pw.Close()
+	}()
+
+	sylog.Debugf("Running %s %s", cmd.Path, strings.Join(cmd.Args, " "))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		err = checkCryptsetupVersion(cryptsetupPath)
+		if err == ErrUnsupportedCryptsetupVersion {
+			return err
+		}
+		if strings.Contains(string(out), "No key available") {
+			sylog.Debugf("Invalid password")
+			return ErrInvalidPassphrase
+		}
+		return fmt.Errorf("unable to change key of crypt device: %s: %s", path, string(out))
+	}
+
+	return nil
+}
+
 // copyDeviceContents copies the contents of source to destination.
 // source and dest can either be a file or a block device
 func copyDeviceContents(source, dest string, size int64) error {
@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2019-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -6,6 +6,7 @@
 package crypt
 
 import (
+	"bytes"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -124,3 +125,60 @@ func TestEncrypt(t *testing.T) {
 		})
 	}
 }
+
+func TestDecryptChangeKey(t *testing.T) {
+	test.EnsurePrivilege(t)
+	defer test.ResetPrivilege(t)
+
+	dev := &Device{}
+
+	// The content of the volume is opaque to cryptsetup, so any file will do.
+	content := bytes.Repeat([]byte("singularity"), 4096)
+	plainFile := filepath.Join(t.TempDir(), "plain")
+	if err := os.WriteFile(plainFile, content, 0o644); err != nil {
+		t.Fatalf("failed to create %s: %s", plainFile, err)
+	}
+
+	oldKey := []byte("oldKey")
+	newKey := []byte("newKey")
+
+	cryptPath, err := dev.EncryptFilesystem(plainFile, oldKey)
+	if err == ErrUnsupportedCryptsetupVersion {
+		t.Skip("installed version of cryptsetup is not supported, >=2.0.0 required")
+	}
+	if err != nil {
+		t.Fatalf("failed to encrypt %s: %s", plainFile, err)
+	}
+	defer os.Remove(cryptPath)
+
+	fi, err := os.Stat(cryptPath)
+	if err != nil {
+		t.Fatalf("failed to stat %s: %s", cryptPath, err)
+	}
+	size := uint64(fi.Size())
+
+	if err := dev.ChangeKey(cryptPath, 0, size, newKey, newKey); err != ErrInvalidPassphrase {
+		t.Fatalf("ChangeKey() with wrong key, error = %v, want %v", err, ErrInvalidPassphrase)
+	}
+	if err := dev.ChangeKey(cryptPath, 0, size, oldKey, newKey); err != nil {
+		t.Fatalf("failed to change key: %s", err)
+	}
+
+	if _, err := dev.DecryptFilesystem(cryptPath, 0, size, oldKey); err != ErrInvalidPassphrase {
+		t.Fatalf("DecryptFilesystem() with old key, error = %v, want %v", err, ErrInvalidPassphrase)
+	}
+
+	decPath, err := dev.DecryptFilesystem(cryptPath, 0, size, newKey)
+	if err != nil {
+		t.Fatalf("failed to decrypt: %s", err)
+	}
+	defer os.Remove(decPath)
+
+	dec, err := os.ReadFile(decPath)
+	if err != nil {
+		t.Fatalf("failed to read %s: %s", decPath, err)
+	}
+	if !bytes.HasPrefix(dec, content) {
+		t.Errorf("decrypted content does not match original")
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+// Package erofs creates, and FUSE mounts, EROFS images.
+package erofs
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sylabs/singularity/v4/internal/pkg/util/bin"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+)
+
+// mkfsOpts accumulates mkfs.erofs options.
+type mkfsOpts struct {
+	path     string
+	comp     string
+	allRoot  bool
+	mkfsTime *time.Time
+}
+
+// MkfsOpt are used to specify options to apply when creating an EROFS image.
+type MkfsOpt func(*mkfsOpts) error
+
+// OptPath sets the path to the mkfs.erofs binary.
+func OptPath(p string) MkfsOpt {
+	return func(o *mkfsOpts) error {
+		o.path = p
+		return nil
+	}
+}
+
+// OptComp sets the compression algorithm to use when creating an EROFS image,
+// e.g. lz4, lz4hc, lzma, deflate or zstd. An empty value creates an
+// uncompressed image.
+func OptComp(c string) MkfsOpt {
+	return func(o *mkfsOpts) error {
+		o.comp = c
+		return nil
+	}
+}
+
+// OptAllRoot forces ownership of all files in the EROFS image to root.
+func OptAllRoot(a bool) MkfsOpt {
+	return func(o *mkfsOpts) error {
+		o.allRoot = a
+		return nil
+	}
+}
+
+// OptReproducible configures mkfs.erofs to create a reproducible EROFS image,
+// for the same input files. The filesystem creation time, and the timestamp of
+// all files, is set to t, and the filesystem UUID is cleared.
+func OptReproducible(t time.Time) MkfsOpt {
+	return func(o *mkfsOpts) error {
+		o.mkfsTime = &t
+		return nil
+	}
+}
+
+// args returns the mkfs.erofs arguments to create dest from the directory src.
+func (o *mkfsOpts) args(src, dest string) []string {
+	args := []string{}
+	if o.comp != "" {
+		args = append(args, "-z", o.comp)
+	}
+	if o.allRoot {
+		args = append(args, "--all-root")
+	}
+	if o.mkfsTime != nil {
+		args = append(args, "-T", strconv.FormatInt(o.mkfsTime.Unix(), 10), "-U", "clear")
+	}
+	// mkfs.erofs takes args of the form: [options] destination source
+	return append(args, dest, src)
+}
+
+// Mkfs calls the mkfs.erofs binary to create an EROFS image at dest,
+// containing the content of the directory src. By default, lz4hc compression
+// is used. To override the defaults, use the OptX functions. If running as
+// non-root, consider using OptAllRoot to squash ownership of files to root, to
+// avoid uid/gid mismatch when moving images between systems.
+func Mkfs(src, dest string, opts ...MkfsOpt) error {
+	mo := &mkfsOpts{comp: "lz4hc"}
+	for _, opt := range opts {
+		if err := opt(mo); err != nil {
+			return err
+		}
+	}
+
+	if mo.path == "" {
+		path, err := bin.FindBin("mkfs.erofs")
+		if err != nil {
+			return err
+		}
+		mo.path = path
+	}
+
+	var stderr bytes.Buffer
+
+	args := mo.args(src, dest)
+	sylog.Debugf("Executing %q with args: %v", mo.path, args)
+	cmd := exec.Command(mo.path, args...)
+	cmd.Stderr = &stderr
+	if mo.mkfsTime != nil {
+		// The timestamp is given explicitly, so must not be overridden.
+		cmd.Env = withoutEnv(os.Environ(), "SOURCE_DATE_EPOCH")
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("create command failed: %v: %s", err, stderr.String())
+	}
+	return nil
+}
+
+// withoutEnv returns env without the variable named name.
+func withoutEnv(env []string, name string) []string {
+	out := make([]string, 0, len(env))
+	for _, e := range env {
+		if !strings.HasPrefix(e, name+"=") {
+			out = append(out, e)
+		}
+	}
+	return out
+}
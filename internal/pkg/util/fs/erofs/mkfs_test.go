@@ -0,0 +1,78 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package erofs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sylabs/singularity/v4/pkg/image"
+)
+
+func TestMkfsArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []MkfsOpt
+		want []string
+	}{
+		{
+			name: "Default",
+			want: []string{"-z", "lz4hc", "dest", "src"},
+		},
+		{
+			name: "Uncompressed",
+			opts: []MkfsOpt{OptComp("")},
+			want: []string{"dest", "src"},
+		},
+		{
+			name: "AllRoot",
+			opts: []MkfsOpt{OptComp("zstd"), OptAllRoot(true)},
+			want: []string{"-z", "zstd", "--all-root", "dest", "src"},
+		},
+		{
+			name: "Reproducible",
+			opts: []MkfsOpt{OptReproducible(time.Unix(1700000000, 0))},
+			want: []string{"-z", "lz4hc", "-T", "1700000000", "-U", "clear", "dest", "src"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mo := &mkfsOpts{comp: "lz4hc"}
+			for _, opt := range tt.opts {
+				if err := opt(mo); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := mo.args("src", "dest"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("args() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMkfs(t *testing.T) {
+	if _, err := exec.LookPath("mkfs.erofs"); err != nil {
+		t.Skip("mkfs.erofs not available")
+	}
+
+	dest := filepath.Join(t.TempDir(), "test.erofs")
+	if err := Mkfs(".", dest, OptAllRoot(true)); err != nil {
+		t.Fatalf("Mkfs() error = %v", err)
+	}
+
+	b, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := image.CheckErofsHeader(b); err != nil {
+		t.Errorf("Mkfs() created an invalid image: %v", err)
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2023, Sylabs Inc. All rights reserved.
+// Copyright (c) 2023-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -95,6 +95,8 @@ func (i *ImageMount) determineMountCmd() (string, error) {
 		fuseMountTool = "squashfuse"
 	case image.EXT3:
 		fuseMountTool = "fuse2fs"
+	case image.EROFS:
+		fuseMountTool = "erofsfuse"
 	default:
 		return "", fmt.Errorf("image %q is not of a type that can be mounted with FUSE (type: %v)", i.SourcePath, i.Type)
 	}
@@ -110,9 +112,19 @@ func (i *ImageMount) determineMountCmd() (string, error) {
 func (i *ImageMount) generateCmdArgs() ([]string, error) {
 	args := make([]string, 0, 4)
 
+	extraOpts := i.ExtraOpts
 	switch i.Type {
 	case image.SQUASHFS:
 		i.Readonly = true
+	case image.EROFS:
+		i.Readonly = true
+		// erofsfuse takes the image offset as an argument, rather than a
+		// mount option.
+		var offset string
+		extraOpts, offset = splitOffsetOpt(i.ExtraOpts)
+		if offset != "" {
+			args = append(args, "--offset="+offset)
+		}
 	}
 
 	// Even though fusermount is not needed for this step, we shouldn't perform
@@ -137,7 +149,7 @@ func (i *ImageMount) generateCmdArgs() ([]string, error) {
 		}
 	}()
 
-	opts, err := i.generateMountOpts()
+	opts, err := i.generateMountOpts(extraOpts)
 	if err != nil {
 		return args, err
 	}
@@ -152,10 +164,10 @@ func (i *ImageMount) generateCmdArgs() ([]string, error) {
 	return args, nil
 }
 
-func (i ImageMount) generateMountOpts() ([]string, error) {
+func (i ImageMount) generateMountOpts(extraOpts []string) ([]string, error) {
 	// Create a map of the extra mount options that have been requested, so we
 	// can catch attempts to overwrite builtin struct fields.
-	extraOptsMap := lo.SliceToMap(i.ExtraOpts, func(s string) (string, *string) {
+	extraOptsMap := lo.SliceToMap(extraOpts, func(s string) (string, *string) {
 		parts := strings.SplitN(s, "=", 2)
 		if len(parts) < 2 {
 			return strings.ToLower(s), nil
@@ -221,6 +233,21 @@ func (i ImageMount) generateMountOpts() ([]string, error) {
 	return opts, nil
 }
 
+// splitOffsetOpt removes any offset option from opts, returning the remaining
+// options and the offset value.
+func splitOffsetOpt(opts []string) ([]string, string) {
+	var offset string
+	rest := make([]string, 0, len(opts))
+	for _, o := range opts {
+		if v, ok := strings.CutPrefix(strings.ToLower(o), "offset="); ok {
+			offset = v
+			continue
+		}
+		rest = append(rest, o)
+	}
+	return rest, offset
+}
+
 func checkProhibitedOpt(extraOptsMap map[string]*string, opt string) error {
 	if maps.HasKey(extraOptsMap, opt) {
 		return fmt.Errorf("cannot pass %q as extra FUSE-mount option, as it is handled by an internal field", opt)
@@ -1,4 +1,4 @@
-// Copyright (c) 2023, Sylabs Inc. All rights reserved.
+// Copyright (c) 2023-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -7,6 +7,7 @@ package fuse
 
 import (
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/sylabs/singularity/v4/pkg/image"
@@ -85,3 +86,21 @@ func TestAllOverridesAtOnce(t *testing.T) {
 		}
 	}
 }
+
+func TestSplitOffsetOpt(t *testing.T) {
+	opts, offset := splitOffsetOpt([]string{"offset=1024", "uid=0"})
+	if offset != "1024" {
+		t.Errorf("got offset %q, want %q", offset, "1024")
+	}
+	if want := []string{"uid=0"}; !reflect.DeepEqual(opts, want) {
+		t.Errorf("got opts %v, want %v", opts, want)
+	}
+
+	opts, offset = splitOffsetOpt([]string{"ro"})
+	if offset != "" {
+		t.Errorf("got offset %q, want none", offset)
+	}
+	if want := []string{"ro"}; !reflect.DeepEqual(opts, want) {
+		t.Errorf("got opts %v, want %v", opts, want)
+	}
+}
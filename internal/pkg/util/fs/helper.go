@@ -13,6 +13,7 @@ import (
 	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/ccoveille/go-safecast/v2"
 	"github.com/sylabs/singularity/v4/pkg/sylog"
@@ -443,6 +444,30 @@ func Touch(path string) error {
 	return nil
 }
 
+// ClampMtimes sets the modification and access times of every file, directory
+// and symlink under root, including root itself, that was modified after t to
+// t. Symlinks are not followed. Used for reproducible builds, so that files
+// written during a build do not record the time of the build.
+func ClampMtimes(root string, t time.Time) error {
+	ts := []unix.Timespec{unix.NsecToTimespec(t.UnixNano()), unix.NsecToTimespec(t.UnixNano())}
+	return filepath.WalkDir(root, func(path string, _ os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		fi, err := os.Lstat(path)
+		if err != nil {
+			return err
+		}
+		if !fi.ModTime().After(t) {
+			return nil
+		}
+		if err := unix.UtimesNanoAt(unix.AT_FDCWD, path, ts, unix.AT_SYMLINK_NOFOLLOW); err != nil {
+			return fmt.Errorf("while setting times of %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
 // MakeTmpDir creates a temporary directory with provided mode
 // in os.TempDir if basedir is "". This function assumes that
 // basedir exists, so it's the caller's responsibility to create
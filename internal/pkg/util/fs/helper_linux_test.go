@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ccoveille/go-safecast/v2"
 	"github.com/sylabs/singularity/v4/internal/pkg/test"
@@ -608,6 +609,47 @@ func TestTouch(t *testing.T) {
 	}
 }
 
+func TestClampMtimes(t *testing.T) {
+	tmpdir := t.TempDir()
+
+	clamp := time.Unix(1700000000, 0)
+	old := time.Unix(1600000000, 0)
+
+	newFile := filepath.Join(tmpdir, "new")
+	oldFile := filepath.Join(tmpdir, "old")
+	link := filepath.Join(tmpdir, "link")
+	for _, f := range []string{newFile, oldFile} {
+		if err := os.WriteFile(f, []byte(f), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chtimes(oldFile, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("old", link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ClampMtimes(tmpdir, clamp); err != nil {
+		t.Fatalf("ClampMtimes() error = %v", err)
+	}
+
+	for path, want := range map[string]time.Time{
+		tmpdir:  clamp,
+		newFile: clamp,
+		oldFile: old,
+		link:    clamp,
+	} {
+		fi, err := os.Lstat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fi.ModTime(); !got.Equal(want) {
+			t.Errorf("%s mtime = %v, want %v", path, got, want)
+		}
+	}
+}
+
 func TestMakeTempDir(t *testing.T) {
 	test.DropPrivilege(t)
 	defer test.ResetPrivilege(t)
@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2023, Sylabs Inc. All rights reserved.
+// Copyright (c) 2019-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -105,6 +105,10 @@ func (i *Item) analyzeImageFile() error {
 		i.Type = image.SQUASHFS
 		// squashfs image must be readonly
 		i.Readonly = true
+	case image.EROFS:
+		i.Type = image.EROFS
+		// erofs image must be readonly
+		i.Readonly = true
 	case image.EXT3:
 		i.Type = image.EXT3
 	default:
@@ -165,7 +169,7 @@ func (i *Item) Mount(ctx context.Context) error {
 	case image.SANDBOX:
 		err = i.mountDir()
 
-	case image.SQUASHFS, image.EXT3:
+	case image.SQUASHFS, image.EROFS, image.EXT3:
 		err = i.mountWithFuse(ctx)
 
 	default:
@@ -184,12 +188,12 @@ func (i *Item) Mount(ctx context.Context) error {
 }
 
 // GetMountDir returns the path to the directory that will actually be mounted
-// for this overlay. For squashfs overlays, this is equivalent to the
+// for this overlay. For squashfs and erofs overlays, this is equivalent to the
 // Item.StagingDir field. But for all other overlays, it is the "upper"
 // subdirectory of Item.StagingDir.
 func (i Item) GetMountDir() string {
 	switch i.Type {
-	case image.SQUASHFS:
+	case image.SQUASHFS, image.EROFS:
 		return i.StagingDir
 
 	case image.SANDBOX:
@@ -293,7 +297,7 @@ func (i Item) Unmount(ctx context.Context) error {
 	case image.SANDBOX:
 		return i.unmountDir(ctx)
 
-	case image.SQUASHFS, image.EXT3:
+	case image.SQUASHFS, image.EROFS, image.EXT3:
 		return i.unmountFuse(ctx)
 
 	default:
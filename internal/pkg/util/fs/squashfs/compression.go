@@ -0,0 +1,84 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package squashfs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultCompression is the compression algorithm used by mksquashfs if none
+// is specified.
+const DefaultCompression = "gzip"
+
+// compressionLevels holds the valid range of compression levels for each
+// supported algorithm. A nil entry indicates the algorithm does not accept a
+// level.
+var compressionLevels = map[string][]int{
+	"gzip": {1, 9},
+	"zstd": {1, 22},
+	"xz":   nil,
+	"lz4":  nil,
+}
+
+// Compression is a squashfs compression algorithm, with an optional level.
+type Compression struct {
+	// Algorithm is one of gzip, zstd, xz, or lz4.
+	Algorithm string
+	// Level is the compression level. The algorithm default is used if zero.
+	Level int
+}
+
+// ParseCompression parses a compression specification of the form
+// algorithm[:level], e.g. "zstd" or "zstd:19".
+func ParseCompression(s string) (Compression, error) {
+	alg, level, hasLevel := strings.Cut(s, ":")
+
+	levels, ok := compressionLevels[alg]
+	if !ok {
+		return Compression{}, fmt.Errorf("unsupported compression %q, must be one of: gzip, zstd, xz, lz4", alg)
+	}
+	c := Compression{Algorithm: alg}
+	if !hasLevel {
+		return c, nil
+	}
+
+	if levels == nil {
+		return Compression{}, fmt.Errorf("compression %q does not accept a level", alg)
+	}
+	l, err := strconv.Atoi(level)
+	if err != nil || l < levels[0] || l > levels[1] {
+		return Compression{}, fmt.Errorf("invalid %s compression level %q, must be %d-%d", alg, level, levels[0], levels[1])
+	}
+	c.Level = l
+	return c, nil
+}
+
+// String returns the compression in the form accepted by ParseCompression.
+func (c Compression) String() string {
+	if c.Level == 0 {
+		return c.Algorithm
+	}
+	return c.Algorithm + ":" + strconv.Itoa(c.Level)
+}
+
+// IsDefault returns true if c specifies the default mksquashfs compression.
+func (c Compression) IsDefault() bool {
+	return (c.Algorithm == "" || c.Algorithm == DefaultCompression) && c.Level == 0
+}
+
+// Args returns the mksquashfs / sqfstar arguments that select c.
+func (c Compression) Args() []string {
+	if c.Algorithm == "" {
+		return nil
+	}
+	args := []string{"-comp", c.Algorithm}
+	if c.Level != 0 {
+		args = append(args, "-Xcompression-level", strconv.Itoa(c.Level))
+	}
+	return args
+}
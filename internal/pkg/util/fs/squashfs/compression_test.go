@@ -0,0 +1,121 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package squashfs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCompression(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		want     Compression
+		wantArgs []string
+		wantErr  bool
+	}{
+		{
+			name:     "Gzip",
+			s:        "gzip",
+			want:     Compression{Algorithm: "gzip"},
+			wantArgs: []string{"-comp", "gzip"},
+		},
+		{
+			name:     "GzipLevel",
+			s:        "gzip:9",
+			want:     Compression{Algorithm: "gzip", Level: 9},
+			wantArgs: []string{"-comp", "gzip", "-Xcompression-level", "9"},
+		},
+		{
+			name:     "ZstdLevel",
+			s:        "zstd:22",
+			want:     Compression{Algorithm: "zstd", Level: 22},
+			wantArgs: []string{"-comp", "zstd", "-Xcompression-level", "22"},
+		},
+		{
+			name:     "Xz",
+			s:        "xz",
+			want:     Compression{Algorithm: "xz"},
+			wantArgs: []string{"-comp", "xz"},
+		},
+		{
+			name:     "Lz4",
+			s:        "lz4",
+			want:     Compression{Algorithm: "lz4"},
+			wantArgs: []string{"-comp", "lz4"},
+		},
+		{
+			name:    "Unsupported",
+			s:       "lzo",
+			wantErr: true,
+		},
+		{
+			name:    "Empty",
+			s:       "",
+			wantErr: true,
+		},
+		{
+			name:    "LevelNotAccepted",
+			s:       "xz:6",
+			wantErr: true,
+		},
+		{
+			name:    "LevelTooHigh",
+			s:       "gzip:10",
+			wantErr: true,
+		},
+		{
+			name:    "LevelZero",
+			s:       "zstd:0",
+			wantErr: true,
+		},
+		{
+			name:    "LevelNotNumber",
+			s:       "zstd:max",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCompression(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCompression() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseCompression() = %v, want %v", got, tt.want)
+			}
+			if got.String() != tt.s {
+				t.Errorf("String() = %q, want %q", got.String(), tt.s)
+			}
+			if args := got.Args(); !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args() = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCompressionIsDefault(t *testing.T) {
+	tests := []struct {
+		c    Compression
+		want bool
+	}{
+		{Compression{}, true},
+		{Compression{Algorithm: "gzip"}, true},
+		{Compression{Algorithm: "gzip", Level: 9}, false},
+		{Compression{Algorithm: "zstd"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.c.IsDefault(); got != tt.want {
+			t.Errorf("%v IsDefault() = %v, want %v", tt.c, got, tt.want)
+		}
+	}
+}
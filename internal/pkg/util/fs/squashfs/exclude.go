@@ -0,0 +1,80 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package squashfs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// IgnoreFile is the name of the file, alongside a definition file or in the
+// build context of a Dockerfile, that lists patterns of paths to exclude from
+// the image.
+const IgnoreFile = ".singularityignore"
+
+// ParseExcludes reads exclude patterns from r, one per line, and returns them
+// in the wildcard form expected by mksquashfs / sqfstar. Blank lines, and lines
+// starting with #, are ignored.
+//
+// Patterns are matched relative to the root of the image. A pattern that
+// contains no slash, e.g. '*.pyc', matches at any depth. Otherwise the pattern
+// is anchored at the root, e.g. '/var/cache/*' or 'root/.cache'.
+func ParseExcludes(r io.Reader) ([]string, error) {
+	var patterns []string
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		p := strings.TrimSpace(s.Text())
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+		if strings.HasPrefix(p, "!") {
+			return nil, fmt.Errorf("exclude pattern %q: negated patterns are not supported", p)
+		}
+
+		// mksquashfs matches a trailing slash against nothing, and would
+		// treat a leading slash as an absolute host path.
+		p = strings.TrimRight(p, "/")
+		if p == "" {
+			return nil, fmt.Errorf("exclude pattern %q would exclude the whole image", s.Text())
+		}
+		if strings.HasPrefix(p, "/") {
+			patterns = append(patterns, strings.TrimLeft(p, "/"))
+			continue
+		}
+		if !strings.Contains(p, "/") {
+			p = "... " + p
+		}
+		patterns = append(patterns, p)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return patterns, nil
+}
+
+// ReadIgnoreFile returns the exclude patterns listed in the ignore file at
+// path. If the file does not exist, no patterns are returned.
+func ReadIgnoreFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	patterns, err := ParseExcludes(f)
+	if err != nil {
+		return nil, fmt.Errorf("while reading %s: %w", path, err)
+	}
+	return patterns, nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package squashfs
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseExcludes(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "Empty",
+			in:   "",
+		},
+		{
+			name: "CommentsAndBlankLines",
+			in:   "# caches\n\n   \n# more\n",
+		},
+		{
+			name: "Unanchored",
+			in:   "*.pyc\n__pycache__/\n",
+			want: []string{"... *.pyc", "... __pycache__"},
+		},
+		{
+			name: "Anchored",
+			in:   "/var/cache/*\nroot/.cache\n/tmp/\n",
+			want: []string{"var/cache/*", "root/.cache", "tmp"},
+		},
+		{
+			name: "Whitespace",
+			in:   "  /var/lib/apt/lists/*  \n",
+			want: []string{"var/lib/apt/lists/*"},
+		},
+		{
+			name:    "Negated",
+			in:      "*.log\n!keep.log\n",
+			wantErr: true,
+		},
+		{
+			name:    "Root",
+			in:      "/\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExcludes(strings.NewReader(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExcludes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseExcludes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+
+	got, err := ReadIgnoreFile(filepath.Join(dir, IgnoreFile))
+	if err != nil {
+		t.Fatalf("ReadIgnoreFile() with missing file, error = %v", err)
+	}
+	if got != nil {
+		t.Errorf("ReadIgnoreFile() with missing file = %q, want nil", got)
+	}
+
+	path := filepath.Join(dir, IgnoreFile)
+	if err := os.WriteFile(path, []byte("# test\n*.o\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = ReadIgnoreFile(path)
+	if err != nil {
+		t.Fatalf("ReadIgnoreFile() error = %v", err)
+	}
+	if want := []string{"... *.o"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadIgnoreFile() = %q, want %q", got, want)
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2024, Sylabs Inc. All rights reserved.
+// Copyright (c) 2019-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -10,7 +10,12 @@ package squashfs
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/sylabs/singularity/v4/internal/pkg/buildcfg"
 	"github.com/sylabs/singularity/v4/internal/pkg/util/bin"
@@ -40,9 +45,11 @@ type mksquashfsOpts struct {
 	procs     uint
 	mem       string
 	comp      string
+	compLevel int
 	allRoot   bool
 	wildcards bool
 	excludes  []string
+	mkfsTime  *time.Time
 }
 
 func defaultPath() (string, error) {
@@ -71,6 +78,21 @@ func defaultMem() (string, error) {
 	return mem, err
 }
 
+func defaultComp() (Compression, error) {
+	c, err := getConfig()
+	if err != nil {
+		return Compression{}, err
+	}
+	if c.MksquashfsCompression == "" {
+		return Compression{Algorithm: DefaultCompression}, nil
+	}
+	comp, err := ParseCompression(c.MksquashfsCompression)
+	if err != nil {
+		return Compression{}, fmt.Errorf("invalid mksquashfs compression in singularity.conf: %w", err)
+	}
+	return comp, nil
+}
+
 func defaultOpts() (*mksquashfsOpts, error) {
 	path, err := defaultPath()
 	if err != nil {
@@ -84,12 +106,17 @@ func defaultOpts() (*mksquashfsOpts, error) {
 	if err != nil {
 		return nil, err
 	}
+	comp, err := defaultComp()
+	if err != nil {
+		return nil, err
+	}
 	opts := &mksquashfsOpts{
-		path:    path,
-		procs:   procs,
-		mem:     mem,
-		comp:    "gzip",
-		allRoot: false,
+		path:      path,
+		procs:     procs,
+		mem:       mem,
+		comp:      comp.Algorithm,
+		compLevel: comp.Level,
+		allRoot:   false,
 	}
 	return opts, nil
 }
@@ -121,10 +148,22 @@ func OptMem(m string) MksquashfsOpt {
 	}
 }
 
-// OptComp sets the compression algorithm to use when creating a squashfs.
+// OptComp sets the compression algorithm to use when creating a squashfs, at
+// the default level for the algorithm.
 func OptComp(c string) MksquashfsOpt {
 	return func(o *mksquashfsOpts) error {
 		o.comp = c
+		o.compLevel = 0
+		return nil
+	}
+}
+
+// OptCompression sets the compression algorithm and level to use when creating
+// a squashfs.
+func OptCompression(c Compression) MksquashfsOpt {
+	return func(o *mksquashfsOpts) error {
+		o.comp = c.Algorithm
+		o.compLevel = c.Level
 		return nil
 	}
 }
@@ -153,12 +192,23 @@ func OptExcludes(e []string) MksquashfsOpt {
 	}
 }
 
+// OptReproducible configures mksquashfs to create a reproducible squashfs, for
+// the same input files. The sources are added in sorted order, and the
+// filesystem creation time is set to t. File timestamps are stored as found,
+// so should be clamped to t by the caller.
+func OptReproducible(t time.Time) MksquashfsOpt {
+	return func(o *mksquashfsOpts) error {
+		o.mkfsTime = &t
+		return nil
+	}
+}
+
 // Mksquashfs calls the mksquashfs binary to create a squashfs image at dest,
-// containing items listed in files. By default, zlib compression is used, and
-// the processor and memory resource limits specified in singularity.conf are
-// applied. To override the defaults, use the OptX functions. If running as
-// non-root, consider using OptAllRoot to squash ownership of files to root, to
-// avoid uid/gid mismatch when moving images between systems.
+// containing items listed in files. By default, the compression, processor and
+// memory resource limits specified in singularity.conf are applied. To
+// override the defaults, use the OptX functions. If running as non-root,
+// consider using OptAllRoot to squash ownership of files to root, to avoid
+// uid/gid mismatch when moving images between systems.
 func Mksquashfs(files []string, dest string, opts ...MksquashfsOpt) error {
 	mo, err := defaultOpts()
 	if err != nil {
@@ -178,15 +228,18 @@ func Mksquashfs(files []string, dest string, opts ...MksquashfsOpt) error {
 	if mo.mem != "" {
 		flags = append(flags, "-mem", mo.mem)
 	}
-	if mo.comp != "" {
-		flags = append(flags, "-comp", mo.comp)
-	}
+	flags = append(flags, Compression{Algorithm: mo.comp, Level: mo.compLevel}.Args()...)
 	if mo.allRoot {
 		flags = append(flags, "-all-root")
 	}
 	if mo.wildcards {
 		flags = append(flags, "-wildcards")
 	}
+	if mo.mkfsTime != nil {
+		flags = append(flags, "-mkfs-time", strconv.FormatInt(mo.mkfsTime.Unix(), 10))
+		files = append([]string(nil), files...)
+		sort.Strings(files)
+	}
 	if len(mo.excludes) > 0 {
 		flags = append(flags, "-e")
 		flags = append(flags, mo.excludes...)
@@ -201,8 +254,23 @@ func Mksquashfs(files []string, dest string, opts ...MksquashfsOpt) error {
 	sylog.Debugf("Executing %q with args: %v", mo.path, args)
 	cmd := exec.Command(mo.path, args...)
 	cmd.Stderr = &stderr
+	if mo.mkfsTime != nil {
+		// mksquashfs refuses -mkfs-time if SOURCE_DATE_EPOCH is also set.
+		cmd.Env = withoutEnv(os.Environ(), "SOURCE_DATE_EPOCH")
+	}
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("create command failed: %v: %s", err, stderr.String())
 	}
 	return nil
 }
+
+// withoutEnv returns env without the variable named name.
+func withoutEnv(env []string, name string) []string {
+	out := make([]string, 0, len(env))
+	for _, e := range env {
+		if !strings.HasPrefix(e, name+"=") {
+			out = append(out, e)
+		}
+	}
+	return out
+}
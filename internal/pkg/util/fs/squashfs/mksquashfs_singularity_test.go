@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2024, Sylabs Inc. All rights reserved.
+// Copyright (c) 2019-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -10,6 +10,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/sylabs/singularity/v4/pkg/image"
 )
@@ -116,6 +117,20 @@ func TestMksquashfs(t *testing.T) {
 			expectComp:    "xz",
 			expectPresent: testFiles,
 		},
+		{
+			name:          "OptCompression",
+			files:         []string{"."},
+			opts:          []MksquashfsOpt{OptCompression(Compression{Algorithm: "gzip", Level: 1})},
+			expectError:   false,
+			expectComp:    "gzip",
+			expectPresent: testFiles,
+		},
+		{
+			name:        "BadCompLevel",
+			files:       []string{"."},
+			opts:        []MksquashfsOpt{OptCompression(Compression{Algorithm: "gzip", Level: 99})},
+			expectError: true,
+		},
 		{
 			name:        "BadComp",
 			files:       []string{"."},
@@ -153,6 +168,14 @@ func TestMksquashfs(t *testing.T) {
 			expectPresent: []string{"mksquashfs_singularity.go"},
 			expectAbsent:  []string{"mksquashfs_singularity_test.go"},
 		},
+		{
+			name:          "OptReproducible",
+			files:         testFiles,
+			opts:          []MksquashfsOpt{OptReproducible(time.Unix(1700000000, 0))},
+			expectError:   false,
+			expectComp:    "gzip",
+			expectPresent: testFiles,
+		},
 	}
 
 	for _, tt := range tests {
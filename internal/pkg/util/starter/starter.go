@@ -237,7 +237,7 @@ func (c *Command) init(config *config.Common, ops ...CommandOp) error {
 		return fmt.Errorf("while copying engine configuration: %s", err)
 	}
 
-	c.env = append(c.env, sylog.GetEnvVar())
+	c.env = append(c.env, sylog.GetEnvVar(), sylog.GetFormatEnvVar())
 	c.env = append(c.env, envConfig...)
 
 	return nil
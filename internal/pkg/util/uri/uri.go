@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2022, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -67,7 +67,8 @@ func Filename(uri string, suffix string) string {
 	refSplit := strings.Split(ref, "/") // Split ref into parts
 
 	if transport == HTTP || transport == HTTPS {
-		imageName := refSplit[len(refSplit)-1]
+		// Drop any fragment, e.g. a #sha256= checksum.
+		imageName, _, _ := strings.Cut(refSplit[len(refSplit)-1], "#")
 		return imageName
 	}
 
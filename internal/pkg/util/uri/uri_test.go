@@ -1,4 +1,4 @@
-// Copyright (c) 2018, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -20,6 +20,8 @@ func Test_GetName(t *testing.T) {
 		{"docker scoped", "docker://user/image", "oci.sif", "image_latest.oci.sif"},
 		{"dave's magical lolcow", "docker://sylabs.io/lolcow", "sif", "lolcow_latest.sif"},
 		{"docker w/ tags", "docker://sylabs.io/lolcow:3.7", "sif", "lolcow_3.7.sif"},
+		{"https", "https://example.com/images/lolcow.sif", "sif", "lolcow.sif"},
+		{"https w/ checksum", "https://example.com/lolcow.sif#sha256=abcd", "sif", "lolcow.sif"},
 	}
 
 	for _, tt := range tests {
@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2022, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -11,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	ocitypes "github.com/containers/image/v5/types"
 	"github.com/google/go-containerregistry/pkg/authn"
@@ -61,6 +62,10 @@ type Options struct {
 	// encryption if applicable.
 	// A nil value indicates encryption should not occur.
 	EncryptionKeyInfo *cryptkey.KeyInfo
+	// EncryptionRecipients specifies additional PEM or PGP keys that the
+	// filesystem encryption key is stored for, when EncryptionKeyInfo is
+	// also a PEM or PGP key.
+	EncryptionRecipients []cryptkey.KeyInfo
 	// ImgCache stores a pointer to the image cache to use.
 	ImgCache *cache.Handle
 	// NoTest indicates if build should skip running the test script.
@@ -87,6 +92,23 @@ type Options struct {
 	Platform ggcrv1.Platform
 	// Authentication file for registry credentials
 	DockerAuthFile string
+	// SBOMFormat is the format of an SBOM to generate and embed in the image,
+	// from the packages installed in its rootfs. No SBOM is generated if empty.
+	SBOMFormat string `json:"sbomFormat"`
+	// Reproducible requests that identical inputs build a bit-for-bit identical
+	// image, with all timestamps set from SourceDate, and a SIF ID derived from
+	// the content of the image.
+	Reproducible bool `json:"reproducible"`
+	// SourceDate is the time recorded in a reproducible build, usually taken
+	// from SOURCE_DATE_EPOCH. File modification times later than SourceDate are
+	// clamped to it.
+	SourceDate time.Time `json:"sourceDate"`
+	// Compression is the squashfs compression of the root filesystem, as
+	// algorithm[:level]. The singularity.conf default is used if empty.
+	Compression string `json:"compression"`
+	// Excludes are patterns of paths to exclude from the root filesystem, in
+	// addition to those listed in the %exclude section of the definition.
+	Excludes []string `json:"excludes"`
 }
 
 // NewEncryptedBundle creates an Encrypted Bundle environment.
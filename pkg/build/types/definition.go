@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // Copyright (c) Contributors to the Apptainer project, established as
 //   Apptainer a Series of LF Projects LLC.
 // This software is licensed under a 3-clause BSD license. Please consult the
@@ -67,6 +67,8 @@ type ImageScripts struct {
 type Data struct {
 	Files   []Files `json:"files"`
 	Scripts `json:"buildScripts"`
+	// Exclude lists patterns of paths to exclude from the image, one per line.
+	Exclude Script `json:"exclude"`
 }
 
 // Scripts defines scripts that are used at build time.
@@ -243,4 +245,5 @@ func populateRaw(d *Definition, w io.Writer) {
 	writeSectionIfExists(w, "setup", d.BuildData.Setup)
 	writeSectionIfExists(w, "post", d.BuildData.Post)
 	writeSectionIfExists(w, "arguments", d.BuildData.Arguments)
+	writeSectionIfExists(w, "exclude", d.BuildData.Exclude)
 }
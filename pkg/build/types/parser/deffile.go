@@ -314,6 +314,7 @@ func populateDefinition(sections map[string]*types.Script, files *[]types.Files,
 		Post:      *sections["post"],
 		Test:      *sections["test"],
 	}
+	d.BuildData.Exclude = *sections["exclude"]
 
 	// remove standard sections from map
 	for s := range validSections {
@@ -549,6 +550,7 @@ var validSections = map[string]bool{
 	"test":        true,
 	"startscript": true,
 	"arguments":   true,
+	"exclude":     true,
 }
 
 var appSections = map[string]bool{
@@ -565,24 +567,26 @@ var appSections = map[string]bool{
 // validHeaders just contains a list of all the valid headers a definition file
 // could contain. If any others are found, an error will generate
 var validHeaders = map[string]bool{
-	"bootstrap":    true,
-	"from":         true,
-	"includecmd":   true,
-	"mirrorurl":    true,
-	"updateurl":    true,
-	"osversion":    true,
-	"include":      true,
-	"library":      true,
-	"registry":     true,
-	"namespace":    true,
-	"stage":        true,
-	"product":      true,
-	"user":         true,
-	"regcode":      true,
-	"productpgp":   true,
-	"registerurl":  true,
-	"modules":      true,
-	"otherurl&n":   true,
-	"fingerprints": true,
-	"setopt":       true,
+	"bootstrap":      true,
+	"from":           true,
+	"includecmd":     true,
+	"mirrorurl":      true,
+	"updateurl":      true,
+	"osversion":      true,
+	"include":        true,
+	"library":        true,
+	"registry":       true,
+	"namespace":      true,
+	"stage":          true,
+	"product":        true,
+	"user":           true,
+	"regcode":        true,
+	"productpgp":     true,
+	"registerurl":    true,
+	"modules":        true,
+	"otherurl&n":     true,
+	"fingerprints":   true,
+	"setopt":         true,
+	"keysdir":        true,
+	"allowuntrusted": true,
 }
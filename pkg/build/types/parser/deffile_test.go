@@ -192,6 +192,7 @@ func TestParseDefinitionFile(t *testing.T) {
 		{"QuotedFiles", "testdata_good/quotedfiles/quotedfiles", "testdata_good/quotedfiles/quotedfiles.json"},
 		{"Shebang", "testdata_good/shebang/shebang", "testdata_good/shebang/shebang.json"},
 		{"ShebangTest", "testdata_good/shebang_test/shebang_test", "testdata_good/shebang_test/shebang_test.json"},
+		{"Exclude", "testdata_good/exclude/exclude", "testdata_good/exclude/exclude.json"},
 	}
 
 	for _, tt := range tests {
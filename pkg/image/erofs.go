@@ -0,0 +1,122 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"unsafe"
+
+	"github.com/ccoveille/go-safecast/v2"
+)
+
+const (
+	erofsSuperOffset = 1024
+	erofsMagic       = 0xE0F5E1E2
+	// Block sizes from 512 bytes to 64KiB are valid.
+	erofsMinBlkSzBits = 9
+	erofsMaxBlkSzBits = 16
+)
+
+// erofsInfo represents the start of an EROFS superblock.
+type erofsInfo struct {
+	Magic         uint32
+	Checksum      uint32
+	FeatureCompat uint32
+	BlkSzBits     uint8
+	SbExtSlots    uint8
+	RootNid       uint16
+	Inos          uint64
+	BuildTime     uint64
+	BuildTimeNsec uint32
+	Blocks        uint32
+}
+
+type erofsFormat struct{}
+
+// CheckErofsHeader checks if byte content contains a valid EROFS superblock
+// and returns offset where the EROFS partition starts.
+func CheckErofsHeader(b []byte) (uint64, error) {
+	var offset uint64
+
+	launchStart := bytes.Index(b, []byte(launchString))
+	if launchStart > 0 {
+		launchEnd, err := safecast.Convert[uint64](launchStart + len(launchString) + 1)
+		if err != nil {
+			return 0, err
+		}
+		offset += launchEnd
+	}
+	einfo := &erofsInfo{}
+
+	if uintptr(offset)+erofsSuperOffset+unsafe.Sizeof(*einfo) >= uintptr(len(b)) {
+		return offset, debugError("can't find erofs superblock")
+	}
+	buffer := bytes.NewReader(b[offset+erofsSuperOffset:])
+
+	if err := binary.Read(buffer, binary.LittleEndian, einfo); err != nil {
+		return offset, debugError("can't read the top of the image")
+	}
+	if einfo.Magic != erofsMagic {
+		return offset, debugError("not a valid erofs image")
+	}
+	if einfo.BlkSzBits < erofsMinBlkSzBits || einfo.BlkSzBits > erofsMaxBlkSzBits {
+		return offset, fmt.Errorf("corrupted image: invalid erofs block size bits %d", einfo.BlkSzBits)
+	}
+	return offset, nil
+}
+
+func (f *erofsFormat) initializer(img *Image, fileinfo os.FileInfo) error {
+	if fileinfo.IsDir() {
+		return debugError("not an erofs image")
+	}
+	b := make([]byte, bufferSize)
+	if n, err := img.File.Read(b); err != nil || n != bufferSize {
+		return debugErrorf("can't read first %d bytes: %v", bufferSize, err)
+	}
+	offset, err := CheckErofsHeader(b)
+	if err != nil {
+		return err
+	}
+	fSize, err := safecast.Convert[uint64](fileinfo.Size())
+	if err != nil {
+		return err
+	}
+	img.Type = EROFS
+	img.Partitions = []Section{
+		{
+			Offset:       offset,
+			Size:         fSize - offset,
+			ID:           1,
+			Type:         EROFS,
+			Name:         RootFs,
+			AllowedUsage: RootFsUsage | OverlayUsage | DataUsage,
+		},
+	}
+
+	if img.Writable {
+		// we set Writable to appropriate value to match the
+		// image open mode as some code may want to ignore this
+		// error by using IsReadOnlyFilesytem check
+		img.Writable = false
+
+		return &readOnlyFilesystemError{
+			"could not set " + img.Path + " image writable: erofs is a read-only filesystem",
+		}
+	}
+
+	return nil
+}
+
+func (f *erofsFormat) openMode(bool) int {
+	return os.O_RDONLY
+}
+
+func (f *erofsFormat) lock(*Image) error {
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package image
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// erofsHeader returns the first 4KiB of an EROFS image, with a superblock of
+// the given block size bits.
+func erofsHeader(blkSzBits uint8) []byte {
+	b := make([]byte, 4096)
+	binary.LittleEndian.PutUint32(b[erofsSuperOffset:], erofsMagic)
+	b[erofsSuperOffset+12] = blkSzBits
+	return b
+}
+
+func TestCheckErofsHeader(t *testing.T) {
+	launch := []byte("#!/usr/bin/env" + launchString + "\n")
+
+	tests := []struct {
+		name       string
+		b          []byte
+		wantOffset uint64
+		wantErr    bool
+	}{
+		{
+			name: "Valid",
+			b:    erofsHeader(12),
+		},
+		{
+			name:       "ValidLaunchScript",
+			b:          append(append([]byte{}, launch...), erofsHeader(12)...)[:bufferSize],
+			wantOffset: uint64(len(launch)),
+		},
+		{
+			name:    "BadMagic",
+			b:       make([]byte, 4096),
+			wantErr: true,
+		},
+		{
+			name:    "BadBlockSize",
+			b:       erofsHeader(20),
+			wantErr: true,
+		},
+		{
+			name:    "Short",
+			b:       erofsHeader(12)[:erofsSuperOffset+8],
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset, err := CheckErofsHeader(tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckErofsHeader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && offset != tt.wantOffset {
+				t.Errorf("CheckErofsHeader() offset = %d, want %d", offset, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestErofsInitializer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.erofs")
+	if err := os.WriteFile(path, erofsHeader(12), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var erofsfmt erofsFormat
+	if erofsfmt.openMode(true) != os.O_RDONLY {
+		t.Fatal("openMode(true) returned the wrong value")
+	}
+
+	for _, writable := range []bool{true, false} {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		img := &Image{Path: path, Name: "test", File: f, Writable: writable}
+		err = erofsfmt.initializer(img, fi)
+		if writable {
+			if !IsReadOnlyFilesytem(err) {
+				t.Errorf("initializer() with writable image, error = %v, want read-only filesystem error", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("initializer() error = %v", err)
+		}
+		if img.Type != EROFS || len(img.Partitions) != 1 || img.Partitions[0].Type != EROFS {
+			t.Errorf("initializer() image type %d, partitions %v", img.Type, img.Partitions)
+		}
+	}
+
+	dir, err := os.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dir.Close()
+	fi, err := dir.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := erofsfmt.initializer(&Image{File: dir}, fi); err == nil {
+		t.Error("initializer() succeeded with a directory")
+	}
+}
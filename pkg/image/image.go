@@ -35,6 +35,8 @@ const (
 	RAW
 	// OCISIF constant for OCI-SIF images
 	OCISIF
+	// EROFS constant for erofs format
+	EROFS
 )
 
 type Usage uint8
@@ -105,6 +107,7 @@ var registeredFormats = []struct {
 	{"ocisif", &ociSifFormat{}},
 	{"squashfs", &squashfsFormat{}},
 	{"ext3", &ext3Format{}},
+	{"erofs", &erofsFormat{}},
 }
 
 // format describes the interface that an image format type must implement.
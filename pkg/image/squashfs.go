@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -23,6 +23,12 @@ const (
 	squashfsLzoComp  = 3
 	squashfsXzComp   = 4
 	squashfsLz4Comp  = 5
+
+	// squashfsBytesUsedOffset is the offset of the bytes_used field in a v4
+	// squashfs superblock.
+	squashfsBytesUsedOffset = 40
+	// squashfsPadding is the boundary to which mksquashfs pads a filesystem.
+	squashfsPadding = 4096
 )
 
 // this represents the superblock of a v4 squashfs image
@@ -137,6 +143,27 @@ func GetSquashfsComp(b []byte) (string, error) {
 	return "", fmt.Errorf("not a valid squashfs image")
 }
 
+// GetSquashfsSize checks if byte content contains a valid v4 squashfs header
+// and returns the size of the filesystem, padded to a 4KiB boundary as written
+// by mksquashfs.
+func GetSquashfsSize(b []byte) (uint64, error) {
+	sb, offset, err := parseSquashfsHeader(b)
+	if err != nil {
+		return 0, fmt.Errorf("while parsing squashfs super block: %v", err)
+	}
+	if sb.Major != 4 {
+		return 0, fmt.Errorf("unsupported squashfs version %d", sb.Major)
+	}
+
+	start := offset + squashfsBytesUsedOffset
+	if start+8 > uint64(len(b)) {
+		return 0, fmt.Errorf("can't find squashfs size in header")
+	}
+	used := binary.LittleEndian.Uint64(b[start : start+8])
+
+	return (used + squashfsPadding - 1) &^ (squashfsPadding - 1), nil
+}
+
 func (f *squashfsFormat) initializer(img *Image, fileinfo os.FileInfo) error {
 	if fileinfo.IsDir() {
 		return debugError("not a squashfs image")
@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2019-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -52,6 +52,38 @@ func TestCheckSquashfsHeader(t *testing.T) {
 	}
 }
 
+func TestGetSquashfsSize(t *testing.T) {
+	sqshFilePath := createSquashfs(t)
+
+	b, err := os.ReadFile(sqshFilePath)
+	if err != nil {
+		t.Fatalf("cannot read file: %s\n", err)
+	}
+
+	size, err := GetSquashfsSize(b)
+	if err != nil {
+		t.Fatalf("cannot get size of a valid image: %s", err)
+	}
+	// mksquashfs pads the image file to the size recorded in the header.
+	if size != uint64(len(b)) {
+		t.Errorf("GetSquashfsSize() = %d, want %d", size, len(b))
+	}
+
+	// Trailing data, such as the unused space of a decrypted volume, is not
+	// included in the size.
+	size, err = GetSquashfsSize(append(b, make([]byte, 8192)...))
+	if err != nil {
+		t.Fatalf("cannot get size of a padded image: %s", err)
+	}
+	if size != uint64(len(b)) {
+		t.Errorf("GetSquashfsSize() with trailing data = %d, want %d", size, len(b))
+	}
+
+	if _, err := GetSquashfsSize(make([]byte, bufferSize)); err == nil {
+		t.Error("GetSquashfsSize() succeeded with an invalid header")
+	}
+}
+
 func TestSquashfsInitializer(t *testing.T) {
 	// Valid image test
 	sqshFilePath := createSquashfs(t)
@@ -1,4 +1,4 @@
-// Copyright (c) 2023-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2023-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -28,6 +28,7 @@ import (
 	"github.com/sylabs/singularity/v4/pkg/ocibundle"
 	"github.com/sylabs/singularity/v4/pkg/ocibundle/tools"
 	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/cryptkey"
 )
 
 // UnavailableError is used to wrap an Underlying error, while indicating that
@@ -54,6 +55,10 @@ type Bundle struct {
 	bundlePath string
 	// paths to squashfs layers that have been mounted
 	mountedLayers []string
+	// encrypted squashfs layers that have been mounted
+	mountedCryptLayers []cryptLayer
+	// keyInfo is the key material used to decrypt encrypted layers
+	keyInfo *cryptkey.KeyInfo
 	// assembled rootfs, from overlay mount of mountedLayers
 	rootfsOverlaySet overlay.Set
 	// Has the image been mounted onto the bundle rootfs?
@@ -84,6 +89,14 @@ func OptImageRef(ref string) Option {
 	}
 }
 
+// OptKeyInfo sets the key material used to decrypt encrypted layers.
+func OptKeyInfo(ki *cryptkey.KeyInfo) Option {
+	return func(b *Bundle) error {
+		b.keyInfo = ki
+		return nil
+	}
+}
+
 // New returns a bundle interface to create/delete an OCI bundle from an oci-sif image ref.
 func New(opts ...Option) (ocibundle.Bundle, error) {
 	b := Bundle{
@@ -112,12 +125,19 @@ func (b *Bundle) Delete(ctx context.Context) error {
 	}
 
 	for _, layerPath := range b.mountedLayers {
+		if b.isCryptLayer(layerPath) {
+			continue
+		}
 		sylog.Debugf("Unmounting layer fs from %q", layerPath)
 		if err := squashfs.FUSEUnmount(ctx, layerPath); err != nil {
 			return err
 		}
 	}
 
+	if err := b.unmountCryptLayers(); err != nil {
+		return err
+	}
+
 	return tools.DeleteBundle(b.bundlePath)
 }
 
@@ -129,14 +149,14 @@ func (b *Bundle) Create(ctx context.Context, ociConfig *specs.Spec) error {
 		return err
 	}
 
-	// Retrieve and check the index manifest, which lists the images in the oci-sif file.
-	// We currently only support oci-sif files containing exactly 1 image.
+	// Retrieve the image to run from the oci-sif file. This is the single image
+	// in the file or, for a multi-platform oci-sif, the image for this host.
 	fi, err := sif.LoadContainerFromPath(imgFile, sif.OptLoadWithFlag(os.O_RDONLY))
 	if err != nil {
 		return fmt.Errorf("while loading SIF: %w", err)
 	}
 
-	img, err := ocisif.GetSingleImage(fi)
+	img, err := ocisif.GetRuntimeImage(fi)
 	if err != nil {
 		return fmt.Errorf("while initializing image: %w", err)
 	}
@@ -188,6 +208,10 @@ func (b *Bundle) mountLayers(ctx context.Context, img v1.Image, imgFile string)
 	if err != nil {
 		return fmt.Errorf("while obtaining layers: %s", err)
 	}
+	mf, err := img.Manifest()
+	if err != nil {
+		return fmt.Errorf("while obtaining manifest: %s", err)
+	}
 
 	for i, l := range layers {
 		mt, err := l.MediaType()
@@ -198,7 +222,7 @@ func (b *Bundle) mountLayers(ctx context.Context, img v1.Image, imgFile string)
 		if mt == ocisif.Ext3LayerMediaType && i == len(layers)-1 {
 			continue
 		}
-		if mt != ocisif.SquashfsLayerMediaType {
+		if mt != ocisif.SquashfsLayerMediaType && mt != ocisif.EncryptedSquashfsLayerMediaType {
 			return fmt.Errorf("unsupported layer mediaType %q", mt)
 		}
 		ol, ok := l.(*ocitsif.Layer)
@@ -216,6 +240,13 @@ func (b *Bundle) mountLayers(ctx context.Context, img v1.Image, imgFile string)
 			return fmt.Errorf("while creating layer directory: %w", err)
 		}
 
+		if mt == ocisif.EncryptedSquashfsLayerMediaType {
+			if err := b.mountCryptLayer(imgFile, offset, mf.Layers[i], layerPath); err != nil {
+				return fmt.Errorf("while mounting encrypted layer %d: %w", i, err)
+			}
+			continue
+		}
+
 		fuseOffset, err := safecast.Convert[uint64](offset)
 		if err != nil {
 			return err
@@ -0,0 +1,139 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package ocisif
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ccoveille/go-safecast/v2"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/sylabs/singularity/v4/internal/pkg/ocisif"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/crypt"
+	"github.com/sylabs/singularity/v4/internal/pkg/util/rootless"
+	"github.com/sylabs/singularity/v4/pkg/sylog"
+	"github.com/sylabs/singularity/v4/pkg/util/loop"
+	"golang.org/x/sys/unix"
+)
+
+// cryptLayer is an encrypted squashfs layer that has been mounted, via a
+// dm-crypt device, onto path.
+type cryptLayer struct {
+	path       string
+	deviceName string
+}
+
+// mountCryptLayer mounts the encrypted squashfs layer described by d, located
+// at offset in imgFile, onto layerPath. The layer is attached to a loop
+// device, which is opened as a dm-crypt device, and the resulting squashfs
+// filesystem mounted by the kernel. This requires root on the host.
+func (b *Bundle) mountCryptLayer(imgFile string, offset int64, d v1.Descriptor, layerPath string) error {
+	if b.keyInfo == nil {
+		return fmt.Errorf("image is encrypted, a key must be provided with --pem-path or --passphrase")
+	}
+
+	// The bundle is created in a user namespace for an unprivileged user, so
+	// we must check against the host uid.
+	uid, err := rootless.Getuid()
+	if err != nil {
+		return err
+	}
+	if uid != 0 {
+		return fmt.Errorf("running encrypted OCI-SIF images requires root")
+	}
+
+	key, err := ocisif.LayerKey(*b.keyInfo, d)
+	if err != nil {
+		return fmt.Errorf("while obtaining layer key: %w", err)
+	}
+
+	loopOffset, err := safecast.Convert[uint64](offset)
+	if err != nil {
+		return err
+	}
+	loopSize, err := safecast.Convert[uint64](d.Size)
+	if err != nil {
+		return err
+	}
+	maxLoopDev, err := loop.GetMaxLoopDevices()
+	if err != nil {
+		return err
+	}
+	loopDev := &loop.Device{
+		MaxLoopDevices: maxLoopDev,
+		Shared:         true,
+		Info: &unix.LoopInfo64{
+			Offset:    loopOffset,
+			Sizelimit: loopSize,
+			Flags:     unix.LO_FLAGS_AUTOCLEAR | unix.LO_FLAGS_READ_ONLY,
+		},
+	}
+	idx := 0
+	if err := loopDev.AttachFromPath(imgFile, os.O_RDONLY, &idx); err != nil {
+		return fmt.Errorf("while attaching layer to loop device: %w", err)
+	}
+	loopPath := fmt.Sprintf("/dev/loop%d", idx)
+
+	// The loop device is detached automatically once it is no longer held
+	// open. If the crypt device is opened, it holds the loop device until it is
+	// closed. Otherwise, closing our fd releases the loop device immediately.
+	defer func() {
+		if err := loopDev.Close(); err != nil {
+			sylog.Debugf("Couldn't close loop device %s: %v", loopPath, err)
+		}
+	}()
+	cryptDev := &crypt.Device{}
+	deviceName, err := cryptDev.Open(key, loopPath)
+	if err != nil {
+		if errors.Is(err, crypt.ErrInvalidPassphrase) {
+			return fmt.Errorf("failed to decrypt layer, the key is not valid for this image")
+		}
+		return fmt.Errorf("while opening encrypted layer: %w", err)
+	}
+
+	devicePath := filepath.Join("/dev/mapper", deviceName)
+	sylog.Debugf("Mounting decrypted layer %s to %q", devicePath, layerPath)
+	if err := unix.Mount(devicePath, layerPath, "squashfs", unix.MS_RDONLY|unix.MS_NODEV|unix.MS_NOSUID, ""); err != nil {
+		if closeErr := cryptDev.CloseCryptDevice(deviceName); closeErr != nil {
+			sylog.Errorf("Couldn't close crypt device %s: %v", deviceName, closeErr)
+		}
+		return fmt.Errorf("while mounting decrypted layer: %w", err)
+	}
+
+	b.mountedCryptLayers = append(b.mountedCryptLayers, cryptLayer{path: layerPath, deviceName: deviceName})
+	b.mountedLayers = append(b.mountedLayers, layerPath)
+	return nil
+}
+
+// isCryptLayer returns true if layerPath is the mount point of an encrypted
+// layer.
+func (b *Bundle) isCryptLayer(layerPath string) bool {
+	for _, cl := range b.mountedCryptLayers {
+		if cl.path == layerPath {
+			return true
+		}
+	}
+	return false
+}
+
+// unmountCryptLayers unmounts all encrypted layers, and closes the associated
+// crypt devices.
+func (b *Bundle) unmountCryptLayers() error {
+	cryptDev := &crypt.Device{}
+	for _, cl := range b.mountedCryptLayers {
+		sylog.Debugf("Unmounting encrypted layer fs from %q", cl.path)
+		if err := unix.Unmount(cl.path, unix.MNT_DETACH); err != nil {
+			return fmt.Errorf("while unmounting encrypted layer: %w", err)
+		}
+		if err := cryptDev.CloseCryptDevice(cl.deviceName); err != nil {
+			return fmt.Errorf("while closing crypt device %s: %w", cl.deviceName, err)
+		}
+	}
+	b.mountedCryptLayers = nil
+	return nil
+}
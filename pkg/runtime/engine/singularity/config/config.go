@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/sylabs/singularity/v4/internal/pkg/runtime/engine/config/oci"
 	"github.com/sylabs/singularity/v4/pkg/image"
@@ -142,6 +143,9 @@ type JSONConfig struct {
 	NoEval                bool              `json:"noEval,omitempty"`
 	UserInfo              UserInfo          `json:"userInfo"`
 	NoSetgroups           bool              `json:"noSetgroups,omitempty"`
+	RestartPolicy         string            `json:"restartPolicy,omitempty"`
+	HealthCmd             string            `json:"healthCmd,omitempty"`
+	HealthInterval        time.Duration     `json:"healthInterval,omitempty"`
 }
 
 // SetImage sets the container image path to be used by EngineConfig.JSON.
@@ -878,3 +882,37 @@ func (e *EngineConfig) SetNoSetgroups(noSetgroups bool) {
 func (e *EngineConfig) GetNoSetgroups() bool {
 	return e.JSON.NoSetgroups
 }
+
+// SetRestartPolicy sets the policy applied when the process of an instance
+// exits, e.g. on-failure:3.
+func (e *EngineConfig) SetRestartPolicy(policy string) {
+	e.JSON.RestartPolicy = policy
+}
+
+// GetRestartPolicy gets the policy applied when the process of an instance
+// exits.
+func (e *EngineConfig) GetRestartPolicy() string {
+	return e.JSON.RestartPolicy
+}
+
+// SetHealthCmd sets a command that is run periodically in an instance to
+// check its health.
+func (e *EngineConfig) SetHealthCmd(cmd string) {
+	e.JSON.HealthCmd = cmd
+}
+
+// GetHealthCmd gets the command that is run periodically in an instance to
+// check its health.
+func (e *EngineConfig) GetHealthCmd() string {
+	return e.JSON.HealthCmd
+}
+
+// SetHealthInterval sets the interval between instance health checks.
+func (e *EngineConfig) SetHealthInterval(interval time.Duration) {
+	e.JSON.HealthInterval = interval
+}
+
+// GetHealthInterval gets the interval between instance health checks.
+func (e *EngineConfig) GetHealthInterval() time.Duration {
+	return e.JSON.HealthInterval
+}
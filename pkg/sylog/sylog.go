@@ -8,15 +8,26 @@
 package sylog
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 )
 
-const messageLevelEnv = "SINGULARITY_MESSAGELEVEL"
+const (
+	messageLevelEnv = "SINGULARITY_MESSAGELEVEL"
+	logFormatEnv    = "SINGULARITY_LOG_FORMAT"
+)
+
+// timestampFormat is the format of the timestamp of a JSON log message, which
+// matches the messages written by the starter.
+const timestampFormat = "2006-01-02T15:04:05.000000000Z07:00"
 
 var messageColors = map[messageLevel]string{
 	FatalLevel: "\x1b[31m",
@@ -32,11 +43,19 @@ var (
 
 var logWriter = io.Writer(os.Stderr)
 
+var (
+	logFormat = TextFormat
+	component = filepath.Base(os.Args[0])
+)
+
 func init() {
 	level, err := strconv.Atoi(os.Getenv(messageLevelEnv))
 	if err == nil {
 		loggerLevel = messageLevel(level)
 	}
+	if f := os.Getenv(logFormatEnv); f == JSONFormat {
+		logFormat = f
+	}
 }
 
 func prefix(logLevel, msgLevel messageLevel) string {
@@ -70,6 +89,15 @@ func prefix(logLevel, msgLevel messageLevel) string {
 	return fmt.Sprintf("%s%-8s%s%-19s%-30s", messageColor, msgLevel, colorReset, uidStr, funcName)
 }
 
+// jsonMessage is a log message written in the JSON format.
+type jsonMessage struct {
+	Level     string `json:"level"`
+	Timestamp string `json:"timestamp"`
+	PID       int    `json:"pid"`
+	Component string `json:"component"`
+	Message   string `json:"message"`
+}
+
 func writef(msgLevel messageLevel, format string, a ...any) {
 	logLevel := getLoggerLevel()
 	if logLevel < msgLevel {
@@ -79,6 +107,24 @@ func writef(msgLevel messageLevel, format string, a ...any) {
 	message := fmt.Sprintf(format, a...)
 	message = strings.TrimRight(message, "\n")
 
+	if logFormat == JSONFormat {
+		// Each message is written with a single call, as a line of its own.
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		err := enc.Encode(jsonMessage{
+			Level:     msgLevel.String(),
+			Timestamp: time.Now().UTC().Format(timestampFormat),
+			PID:       os.Getpid(),
+			Component: component,
+			Message:   message,
+		})
+		if err == nil {
+			fmt.Fprint(logWriter, buf.String())
+			return
+		}
+	}
+
 	fmt.Fprintf(logWriter, "%s%s\n", prefix(logLevel, msgLevel), message)
 }
 
@@ -149,6 +195,30 @@ func GetEnvVar() string {
 	return fmt.Sprintf("%s=%d", messageLevelEnv, loggerLevel)
 }
 
+// SetFormat sets the format of log messages, which must be TextFormat or
+// JSONFormat.
+func SetFormat(format string) error {
+	switch format {
+	case TextFormat, JSONFormat:
+		logFormat = format
+		return nil
+	default:
+		return fmt.Errorf("unsupported log format %q, must be one of: %s, %s", format, TextFormat, JSONFormat)
+	}
+}
+
+// GetFormatEnvVar returns a formatted environment variable string, holding the
+// log format, which can later be interpreted by init() in a child proc.
+func GetFormatEnvVar() string {
+	return fmt.Sprintf("%s=%s", logFormatEnv, logFormat)
+}
+
+// SetComponent sets the name of the component, recorded in JSON log messages,
+// that is writing them. It defaults to the name of the executable.
+func SetComponent(name string) {
+	component = name
+}
+
 // Writer returns an io.Writer to pass to an external packages logging utility.
 // i.e when --quiet option is set, this function returns io.Discard writer to ignore output
 func Writer() io.Writer {
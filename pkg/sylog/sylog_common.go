@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2021, Sylabs Inc. All rights reserved.
+// Copyright (c) 2019-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -11,6 +11,15 @@ import (
 
 type messageLevel int
 
+// Log formats.
+const (
+	// TextFormat writes each log message as a line of text, with a prefix
+	// that indicates its level.
+	TextFormat = "text"
+	// JSONFormat writes each log message as a JSON object, on a single line.
+	JSONFormat = "json"
+)
+
 // Log levels.
 const (
 	FatalLevel    messageLevel = iota - 4 // FatalLevel    : -4
@@ -50,6 +50,20 @@ func GetEnvVar() string {
 	return "SINGULARITY_MESSAGELEVEL=-1"
 }
 
+// SetFormat is a dummy function doing nothing.
+func SetFormat(format string) error {
+	return nil
+}
+
+// GetFormatEnvVar is a dummy function returning environment variable
+// with the text log format.
+func GetFormatEnvVar() string {
+	return "SINGULARITY_LOG_FORMAT=text"
+}
+
+// SetComponent is a dummy function doing nothing.
+func SetComponent(name string) {}
+
 // Writer is a dummy function returning io.Discard writer.
 func Writer() io.Writer {
 	return io.Discard
@@ -9,12 +9,15 @@ package sylog
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sylabs/singularity/v4/internal/pkg/test"
 )
@@ -213,6 +216,54 @@ func TestWritef(t *testing.T) {
 	}
 }
 
+func TestWritefJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logWriter = &buf
+
+	defer func() {
+		logWriter = defaultWriter
+		logFormat = TextFormat
+		component = filepath.Base(os.Args[0])
+	}()
+
+	if err := SetFormat("xml"); err == nil {
+		t.Fatalf("unexpected success setting unsupported format")
+	}
+	if err := SetFormat(JSONFormat); err != nil {
+		t.Fatalf("failed to set format: %v", err)
+	}
+	SetComponent("test")
+	SetLevel(int(DebugLevel), true)
+
+	writef(WarnLevel, "a <%s> \"message\"\n", "test")
+
+	var msg jsonMessage
+	if err := json.Unmarshal(buf.Bytes(), &msg); err != nil {
+		t.Fatalf("failed to parse %q: %v", buf.String(), err)
+	}
+	if !strings.HasSuffix(buf.String(), "}\n") || strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("message %q is not a single line", buf.String())
+	}
+
+	want := jsonMessage{
+		Level:     "WARNING",
+		Timestamp: msg.Timestamp,
+		PID:       os.Getpid(),
+		Component: "test",
+		Message:   `a <test> "message"`,
+	}
+	if msg != want {
+		t.Errorf("got message %+v, want %+v", msg, want)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, msg.Timestamp); err != nil {
+		t.Errorf("invalid timestamp %q: %v", msg.Timestamp, err)
+	}
+
+	if got, want := GetFormatEnvVar(), "SINGULARITY_LOG_FORMAT=json"; got != want {
+		t.Errorf("GetFormatEnvVar() = %q, want %q", got, want)
+	}
+}
+
 func TestGetLevel(t *testing.T) {
 	tests := []struct {
 		name           string
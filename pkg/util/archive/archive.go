@@ -98,6 +98,13 @@ func (u *unpacker) close() error {
 	return nil
 }
 
+// UnpackWithRoot unpacks the uncompressed tar archive read from r to destPath,
+// with options. All created files will be under destPath, and links must not
+// target outside rootPath.
+func UnpackWithRoot(r io.Reader, destPath, rootPath string, options *mobyarchive.TarOptions) error {
+	return unpackWithRoot(r, destPath, rootPath, options)
+}
+
 // unpackWithRoot unpacks the decompressedArchive to dest with options.  All
 // created files will be under destPath, and links must not target outside
 // rootPath.
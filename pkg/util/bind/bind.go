@@ -200,10 +200,19 @@ func newBindPath(bind string) (Path, error) {
 var dataBindOptions = map[string]*Option{"image-src": {"/"}}
 
 // ParseDataBindPath parses a single data container bind spec in
-// <src_sif>:<dest> format into an image bind specification, with image-src=/
+// <src_sif>:<dest>, or docker://<ref>:<dest>, format into an image bind
+// specification, with image-src=/
 func ParseDataBindPath(dataBind string) (Path, error) {
 	var bp Path
 	parts := strings.Split(dataBind, ":")
+	// A docker:// source may contain a registry port and tag, so the
+	// destination follows the last colon.
+	if strings.HasPrefix(dataBind, "docker://") {
+		parts = []string{dataBind}
+		if i := strings.LastIndex(dataBind, ":"); i >= len("docker://") {
+			parts = []string{dataBind[:i], dataBind[i+1:]}
+		}
+	}
 	if len(parts) != 2 {
 		return bp, fmt.Errorf("data container bind %q not in <src sif>:<dest> format", dataBind)
 	}
@@ -1,4 +1,4 @@
-// Copyright (c) 2022, Sylabs Inc. All rights reserved.
+// Copyright (c) 2022-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -217,6 +217,20 @@ func TestParseDataBindPath(t *testing.T) {
 				Options:     map[string]*Option{"image-src": {"/"}},
 			},
 		},
+		{
+			name:      "docker",
+			bindpaths: "docker://registry:5000/dataset:v1:/data",
+			want: Path{
+				Source:      "docker://registry:5000/dataset:v1",
+				Destination: "/data",
+				Options:     map[string]*Option{"image-src": {"/"}},
+			},
+		},
+		{
+			name:      "dockerNoDest",
+			bindpaths: "docker://dataset",
+			wantErr:   true,
+		},
 		{
 			name:      "srcOnly",
 			bindpaths: "data.oci.sif",
@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2025, Sylabs Inc. All rights reserved.
+// Copyright (c) 2019-2026, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -12,12 +12,14 @@ import (
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/asn1"
+	"encoding/hex"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/sylabs/sif/v2/pkg/sif"
 )
 
@@ -30,6 +32,8 @@ var (
 	ErrNoEncryptedKeyData = errors.New("no encrypted key data")
 	// ErrNoPEMData indicates there is no PEM data.
 	ErrNoPEMData = errors.New("no PEM data")
+	// ErrNoKey indicates no key was provided.
+	ErrNoKey = errors.New("no key provided")
 )
 
 const (
@@ -39,6 +43,8 @@ const (
 	Passphrase
 	// PEM indicates the key material is formatted as a PEM file.
 	PEM
+	// PGP indicates the key material is an OpenPGP entity.
+	PGP
 )
 
 // KeyInfo contains information for passing around
@@ -47,6 +53,9 @@ type KeyInfo struct {
 	Format   int
 	Material string
 	Path     string
+	// Entity is the OpenPGP entity of a PGP key. Where it holds a private
+	// key, that key must already be decrypted.
+	Entity *openpgp.Entity
 }
 
 func getRandomBytes(size int) ([]byte, error) {
@@ -60,7 +69,7 @@ func getRandomBytes(size int) ([]byte, error) {
 
 func NewPlaintextKey(k KeyInfo) ([]byte, error) {
 	switch k.Format {
-	case PEM:
+	case PEM, PGP:
 		// in this case we will generate a random secret and
 		// encrypt it using the PEM key.use the PEM key to
 		// encrypt a secret
@@ -96,6 +105,14 @@ func EncryptKey(k KeyInfo, plaintext []byte) ([]byte, error) {
 
 		return buf.Bytes(), nil
 
+	case PGP:
+		message, err := encryptPGPMessage(k.Entity, plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("encrypting key: %v", err)
+		}
+
+		return message, nil
+
 	case Passphrase:
 		return nil, nil
 
@@ -104,6 +121,10 @@ func EncryptKey(k KeyInfo, plaintext []byte) ([]byte, error) {
 	}
 }
 
+// PlaintextKey returns the plaintext key for k, used to encrypt the primary
+// system partition of the SIF image. For a PEM or PGP key, the plaintext is
+// decrypted from the first encrypted key, stored in the image, that k can
+// decrypt.
 func PlaintextKey(k KeyInfo, image string) ([]byte, error) {
 	switch k.Format {
 	case PEM:
@@ -112,24 +133,56 @@ func PlaintextKey(k KeyInfo, image string) ([]byte, error) {
 			return nil, fmt.Errorf("could not load PEM private key: %v", err)
 		}
 
-		pemKey, err := getEncryptionKeyFromImage(image)
+		pemKeys, err := getEncryptionKeysFromImage(image, sif.FormatPEM, sif.MessageRSAOAEP)
 		if err != nil {
 			return nil, fmt.Errorf("could not get encryption information from SIF: %v", err)
 		}
 
-		pemBuf := bytes.NewReader(pemKey)
+		return firstPlaintextKey(pemKeys, func(message []byte) ([]byte, error) {
+			return decryptKeyMessage(privateKey, message)
+		})
 
-		encKey, err := loadPEMMessage(pemBuf)
+	case PGP:
+		pgpKeys, err := getEncryptionKeysFromImage(image, sif.FormatOpenPGP, MessageOpenPGP)
 		if err != nil {
-			return nil, fmt.Errorf("could not unpack LUKS PEM from SIF: %v", err)
+			return nil, fmt.Errorf("could not get encryption information from SIF: %v", err)
 		}
 
-		plaintext, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, encKey, nil)
+		return firstPlaintextKey(pgpKeys, func(message []byte) ([]byte, error) {
+			return decryptPGPMessage(k.Entity, message)
+		})
+
+	case Passphrase:
+		return []byte(k.Material), nil
+
+	default:
+		return nil, ErrUnsupportedKeyURI
+	}
+}
+
+// PlaintextKeyFromMessage returns the plaintext key for k. For a PEM key, the
+// plaintext is decrypted from message, which holds the encrypted key as
+// returned by EncryptKey.
+func PlaintextKeyFromMessage(k KeyInfo, message []byte) ([]byte, error) {
+	switch k.Format {
+	case PEM:
+		privateKey, err := LoadPEMPrivateKey(k.Path)
 		if err != nil {
-			return nil, fmt.Errorf("could not decrypt LUKS key: %v", err)
+			return nil, fmt.Errorf("could not load PEM private key: %v", err)
+		}
+
+		if len(message) == 0 {
+			return nil, ErrNoEncryptedKeyData
 		}
 
-		return plaintext, nil
+		return decryptKeyMessage(privateKey, message)
+
+	case PGP:
+		if len(message) == 0 {
+			return nil, ErrNoEncryptedKeyData
+		}
+
+		return decryptPGPMessage(k.Entity, message)
 
 	case Passphrase:
 		return []byte(k.Material), nil
@@ -139,6 +192,133 @@ func PlaintextKey(k KeyInfo, image string) ([]byte, error) {
 	}
 }
 
+// PlaintextKeyFromKeys tries each of ks in turn, and returns the plaintext
+// key, used to encrypt the primary system partition of the SIF image, for the
+// first that succeeds.
+func PlaintextKeyFromKeys(ks []KeyInfo, image string) ([]byte, error) {
+	err := ErrNoKey
+	for _, k := range ks {
+		var plaintext []byte
+		if plaintext, err = PlaintextKey(k, image); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, err
+}
+
+// firstPlaintextKey returns the plaintext key from the first of messages that
+// decrypt can decrypt, or the error from the last that could not be decrypted.
+func firstPlaintextKey(messages [][]byte, decrypt func([]byte) ([]byte, error)) ([]byte, error) {
+	var err error
+	for _, m := range messages {
+		var plaintext []byte
+		if plaintext, err = decrypt(m); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, err
+}
+
+// RecipientID returns an identifier for the public key of k. It is recorded
+// as the name of the key message for k in a SIF image, so that a recipient
+// can be identified and removed.
+func RecipientID(k KeyInfo) (string, error) {
+	switch k.Format {
+	case PEM:
+		pubKey, err := LoadPEMPublicKey(k.Path)
+		if err != nil {
+			// A private key file also holds the public key.
+			privateKey, privErr := LoadPEMPrivateKey(k.Path)
+			if privErr != nil {
+				return "", fmt.Errorf("loading public key: %v", err)
+			}
+			pubKey = &privateKey.PublicKey
+		}
+
+		sum := sha256.Sum256(x509.MarshalPKCS1PublicKey(pubKey))
+		return "rsa-sha256:" + hex.EncodeToString(sum[:]), nil
+
+	case PGP:
+		if k.Entity == nil {
+			return "", ErrNoPGPEntity
+		}
+		return PGPRecipientID(k.Entity.PrimaryKey.Fingerprint), nil
+
+	default:
+		return "", ErrUnsupportedKeyURI
+	}
+}
+
+// PGPRecipientID returns the RecipientID of the PGP key with the provided
+// fingerprint.
+func PGPRecipientID(fingerprint []byte) string {
+	return fmt.Sprintf("openpgp:%X", fingerprint)
+}
+
+// LegacyRecipientID identifies key messages, written by earlier versions of
+// Singularity, that do not record the RecipientID of their recipient.
+const LegacyRecipientID = "legacy/unnamed"
+
+// KeyMessages returns descriptor inputs for SIF crypto messages that hold
+// plaintext, encrypted for each of ks, and linked to the partition with ID
+// partID. A passphrase is not stored in the image, so has no message.
+func KeyMessages(ks []KeyInfo, plaintext []byte, partID uint32) ([]sif.DescriptorInput, error) {
+	var dis []sif.DescriptorInput
+
+	for _, k := range ks {
+		var format sif.FormatType
+		var messageType sif.MessageType
+
+		switch k.Format {
+		case Passphrase:
+			continue
+		case PEM:
+			format, messageType = sif.FormatPEM, sif.MessageRSAOAEP
+		case PGP:
+			format, messageType = sif.FormatOpenPGP, MessageOpenPGP
+		default:
+			return nil, ErrUnsupportedKeyURI
+		}
+
+		data, err := EncryptKey(k, plaintext)
+		if err != nil {
+			return nil, err
+		}
+		id, err := RecipientID(k)
+		if err != nil {
+			return nil, err
+		}
+
+		di, err := sif.NewDescriptorInput(sif.DataCryptoMessage, bytes.NewReader(data),
+			sif.OptObjectName(id),
+			sif.OptLinkedID(partID),
+			sif.OptCryptoMessageMetadata(format, messageType),
+		)
+		if err != nil {
+			return nil, err
+		}
+		dis = append(dis, di)
+	}
+
+	return dis, nil
+}
+
+// decryptKeyMessage decrypts the key held in a PEM message, as created by
+// EncryptKey, using privateKey.
+func decryptKeyMessage(privateKey *rsa.PrivateKey, message []byte) ([]byte, error) {
+	encKey, err := loadPEMMessage(bytes.NewReader(message))
+	if err != nil {
+		return nil, fmt.Errorf("could not unpack LUKS PEM: %v", err)
+	}
+
+	plaintext, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, encKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt LUKS key: %v", err)
+	}
+
+	return plaintext, nil
+}
+
 func LoadPEMPrivateKey(fn string) (*rsa.PrivateKey, error) {
 	b, err := os.ReadFile(fn)
 	if err != nil {
@@ -200,7 +380,10 @@ func savePEMMessage(w io.Writer, msg []byte) error {
 	return pem.Encode(w, b)
 }
 
-func getEncryptionKeyFromImage(fn string) ([]byte, error) {
+// getEncryptionKeysFromImage returns the encrypted keys, of the specified
+// format and message type, that are linked to the primary system partition of
+// the SIF image fn.
+func getEncryptionKeysFromImage(fn string, format sif.FormatType, messageType sif.MessageType) ([][]byte, error) {
 	img, err := sif.LoadContainerFromPath(fn, sif.OptLoadWithFlag(os.O_RDONLY))
 	if err != nil {
 		return nil, fmt.Errorf("could not load container: %w", err)
@@ -220,27 +403,28 @@ func getEncryptionKeyFromImage(fn string) ([]byte, error) {
 		return nil, fmt.Errorf("could not retrieve linked descriptors for primary system partition from %s: %w", fn, err)
 	}
 
+	// An image encrypted for multiple recipients holds a message for each.
+	var keys [][]byte
 	for _, d := range descr {
-		format, message, err := d.CryptoMessageMetadata()
+		f, m, err := d.CryptoMessageMetadata()
 		if err != nil {
 			return nil, fmt.Errorf("could not get crypto message metadata: %w", err)
 		}
 
-		// currently only support one type of message
-		if format != sif.FormatPEM || message != sif.MessageRSAOAEP {
+		if f != format || m != messageType {
 			continue
 		}
 
-		// TODO(ian): For now, assume the first linked message is what we
-		// are looking for. We should consider what we want to do in the
-		// case of multiple linked messages
 		key, err := d.GetData()
 		if err != nil {
 			return nil, fmt.Errorf("could not retrieve LUKS key data from %s: %w", fn, err)
 		}
 
-		return key, nil
+		keys = append(keys, key)
 	}
 
-	return nil, fmt.Errorf("could not read LUKS key from %s: %v", fn, ErrEncryptedKeyNotFound)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("could not read LUKS key from %s: %v", fn, ErrEncryptedKeyNotFound)
+	}
+	return keys, nil
 }
@@ -6,9 +6,13 @@
 package cryptkey
 
 import (
+	"bytes"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/sylabs/singularity/v4/internal/pkg/test"
 )
 
@@ -143,3 +147,172 @@ func TestPlaintextKey(t *testing.T) {
 		})
 	}
 }
+
+func TestPlaintextKeyFromMessage(t *testing.T) {
+	test.DropPrivilege(t)
+	defer test.ResetPrivilege(t)
+
+	tmpDir := t.TempDir()
+	pubPath := filepath.Join(tmpDir, "public.pem")
+	privPath := filepath.Join(tmpDir, "private.pem")
+
+	key, err := GenerateRSAKey(2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	if err := SavePublicPEM(pubPath, key); err != nil {
+		t.Fatalf("failed to save public key: %v", err)
+	}
+	if err := SavePrivatePEM(privPath, key); err != nil {
+		t.Fatalf("failed to save private key: %v", err)
+	}
+
+	plaintext := []byte("0123456789abcdef")
+	message, err := EncryptKey(KeyInfo{Format: PEM, Path: pubPath}, plaintext)
+	if err != nil {
+		t.Fatalf("failed to encrypt key: %v", err)
+	}
+
+	entity, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("failed to generate PGP entity: %v", err)
+	}
+	otherEntity, err := openpgp.NewEntity("other", "", "other@example.com", nil)
+	if err != nil {
+		t.Fatalf("failed to generate PGP entity: %v", err)
+	}
+	pgpMessage, err := EncryptKey(KeyInfo{Format: PGP, Entity: entity}, plaintext)
+	if err != nil {
+		t.Fatalf("failed to encrypt key: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		keyInfo       KeyInfo
+		message       []byte
+		wantPlaintext []byte
+		wantErr       bool
+	}{
+		{
+			name:    "unknown format",
+			keyInfo: KeyInfo{Format: Unknown},
+			wantErr: true,
+		},
+		{
+			name:          "passphrase",
+			keyInfo:       KeyInfo{Format: Passphrase, Material: testPassphrase},
+			wantPlaintext: []byte(testPassphrase),
+		},
+		{
+			name:          "pem",
+			keyInfo:       KeyInfo{Format: PEM, Path: privPath},
+			message:       message,
+			wantPlaintext: plaintext,
+		},
+		{
+			name:    "pem no message",
+			keyInfo: KeyInfo{Format: PEM, Path: privPath},
+			wantErr: true,
+		},
+		{
+			name:    "invalid pem",
+			keyInfo: KeyInfo{Format: PEM, Path: invalidPemPath},
+			message: message,
+			wantErr: true,
+		},
+		{
+			name:          "pgp",
+			keyInfo:       KeyInfo{Format: PGP, Entity: entity},
+			message:       pgpMessage,
+			wantPlaintext: plaintext,
+		},
+		{
+			name:    "pgp other entity",
+			keyInfo: KeyInfo{Format: PGP, Entity: otherEntity},
+			message: pgpMessage,
+			wantErr: true,
+		},
+		{
+			name:    "pgp no entity",
+			keyInfo: KeyInfo{Format: PGP},
+			message: pgpMessage,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PlaintextKeyFromMessage(tt.keyInfo, tt.message)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(got, tt.wantPlaintext) {
+				t.Errorf("got plaintext %q, want %q", got, tt.wantPlaintext)
+			}
+		})
+	}
+}
+
+func TestRecipientID(t *testing.T) {
+	test.DropPrivilege(t)
+	defer test.ResetPrivilege(t)
+
+	tmpDir := t.TempDir()
+	pubPath := filepath.Join(tmpDir, "public.pem")
+	privPath := filepath.Join(tmpDir, "private.pem")
+
+	key, err := GenerateRSAKey(2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	if err := SavePublicPEM(pubPath, key); err != nil {
+		t.Fatalf("failed to save public key: %v", err)
+	}
+	if err := SavePrivatePEM(privPath, key); err != nil {
+		t.Fatalf("failed to save private key: %v", err)
+	}
+
+	pubID, err := RecipientID(KeyInfo{Format: PEM, Path: pubPath})
+	if err != nil {
+		t.Fatalf("failed to get recipient ID of public key: %v", err)
+	}
+	if !strings.HasPrefix(pubID, "rsa-sha256:") {
+		t.Errorf("unexpected recipient ID %q", pubID)
+	}
+	// The recipient is identified by its public key, from either file.
+	privID, err := RecipientID(KeyInfo{Format: PEM, Path: privPath})
+	if err != nil {
+		t.Fatalf("failed to get recipient ID of private key: %v", err)
+	}
+	if privID != pubID {
+		t.Errorf("private key recipient ID %q, want %q", privID, pubID)
+	}
+
+	entity, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("failed to generate PGP entity: %v", err)
+	}
+	pgpID, err := RecipientID(KeyInfo{Format: PGP, Entity: entity})
+	if err != nil {
+		t.Fatalf("failed to get recipient ID of PGP key: %v", err)
+	}
+	if want := PGPRecipientID(entity.PrimaryKey.Fingerprint); pgpID != want {
+		t.Errorf("PGP recipient ID %q, want %q", pgpID, want)
+	}
+
+	if _, err := RecipientID(KeyInfo{Format: Passphrase, Material: testPassphrase}); err != ErrUnsupportedKeyURI {
+		t.Errorf("passphrase recipient ID error %v, want %v", err, ErrUnsupportedKeyURI)
+	}
+
+	dis, err := KeyMessages([]KeyInfo{
+		{Format: PEM, Path: pubPath},
+		{Format: PGP, Entity: entity},
+		{Format: Passphrase, Material: testPassphrase},
+	}, []byte("0123456789abcdef"), 1)
+	if err != nil {
+		t.Fatalf("failed to create key messages: %v", err)
+	}
+	if len(dis) != 2 {
+		t.Errorf("got %d key messages, want 2", len(dis))
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cryptkey
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/sylabs/sif/v2/pkg/sif"
+)
+
+// MessageOpenPGP is the SIF crypto message type of a key encrypted for an
+// OpenPGP recipient, as an armored PGP message. SIF defines message types for
+// the OpenPGP format in the 0x100 range, but only a clear signature among
+// them, so the next value in that range is used.
+//
+// TODO - Replace when defined by SIF
+const MessageOpenPGP sif.MessageType = 0x101
+
+// ErrNoPGPEntity indicates there is no OpenPGP entity for a PGP key.
+var ErrNoPGPEntity = errors.New("no PGP entity")
+
+// encryptPGPMessage encrypts plaintext for the OpenPGP entity e, returning an
+// armored PGP message.
+func encryptPGPMessage(e *openpgp.Entity, plaintext []byte) ([]byte, error) {
+	if e == nil {
+		return nil, ErrNoPGPEntity
+	}
+
+	var buf bytes.Buffer
+
+	aw, err := armor.Encode(&buf, "PGP MESSAGE", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := openpgp.Encrypt(aw, []*openpgp.Entity{e}, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := aw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decryptPGPMessage decrypts the armored PGP message, as created by
+// encryptPGPMessage, using the private key of the OpenPGP entity e.
+func decryptPGPMessage(e *openpgp.Entity, message []byte) ([]byte, error) {
+	if e == nil {
+		return nil, ErrNoPGPEntity
+	}
+
+	block, err := armor.Decode(bytes.NewReader(message))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode PGP message: %v", err)
+	}
+
+	md, err := openpgp.ReadMessage(block.Body, openpgp.EntityList{e}, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt LUKS key: %v", err)
+	}
+
+	plaintext, err := io.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt LUKS key: %v", err)
+	}
+
+	return plaintext, nil
+}
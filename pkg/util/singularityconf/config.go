@@ -44,9 +44,11 @@ type File struct {
 	AllowContainerEncrypted bool     `default:"yes" authorized:"yes,no" directive:"allow container encrypted"`
 	AllowContainerSquashfs  bool     `default:"yes" authorized:"yes,no" directive:"allow container squashfs"`
 	AllowContainerExtfs     bool     `default:"yes" authorized:"yes,no" directive:"allow container extfs"`
+	AllowContainerErofs     bool     `default:"yes" authorized:"yes,no" directive:"allow container erofs"`
 	AllowContainerDir       bool     `default:"yes" authorized:"yes,no" directive:"allow container dir"`
 	AllowKernelSquashfs     bool     `default:"yes" authorized:"yes,no" directive:"allow kernel squashfs"`
 	AllowKernelExtfs        bool     `default:"yes" authorized:"yes,no" directive:"allow kernel extfs"`
+	AllowKernelErofs        bool     `default:"no" authorized:"yes,no" directive:"allow kernel erofs"`
 	AlwaysUseNv             bool     `default:"no" authorized:"yes,no" directive:"always use nv"`
 	UseNvCCLI               bool     `default:"no" authorized:"yes,no" directive:"use nvidia-container-cli"`
 	AlwaysUseRocm           bool     `default:"no" authorized:"yes,no" directive:"always use rocm"`
@@ -65,6 +67,7 @@ type File struct {
 	AllowNetnsPaths         []string `directive:"allow netns paths"`
 	RootDefaultCapabilities string   `default:"full" authorized:"full,file,no" directive:"root default capabilities"`
 	MemoryFSType            string   `default:"tmpfs" authorized:"tmpfs,ramfs" directive:"memory fs type"`
+	SeccompProfile          string   `directive:"seccomp profile"`
 	CniConfPath             string   `directive:"cni configuration path"`
 	CniPluginPath           string   `directive:"cni plugin path"`
 	CryptsetupPath          string   `directive:"cryptsetup path"`
@@ -73,11 +76,15 @@ type File struct {
 	MksquashfsPath          string   `directive:"mksquashfs path"`
 	MksquashfsProcs         uint     `default:"0" directive:"mksquashfs procs"`
 	MksquashfsMem           string   `directive:"mksquashfs mem"`
+	MksquashfsCompression   string   `default:"gzip" directive:"mksquashfs compression"`
 	NvidiaContainerCliPath  string   `directive:"nvidia-container-cli path"`
 	UnsquashfsPath          string   `directive:"unsquashfs path"`
 	DownloadConcurrency     uint     `default:"3" directive:"download concurrency"`
 	DownloadPartSize        uint     `default:"5242880" directive:"download part size"`
 	DownloadBufferSize      uint     `default:"32768" directive:"download buffer size"`
+	CacheMaxSize            string   `directive:"cache max size"`
+	CacheTypeMaxSize        []string `directive:"cache type max size"`
+	SharedCacheDir          string   `directive:"shared cache dir"`
 	SystemdCgroups          bool     `default:"yes" authorized:"yes,no" directive:"systemd cgroups"`
 	SIFFUSE                 bool     `default:"no" authorized:"yes,no" directive:"sif fuse"`
 	OCIMode                 bool     `default:"no" authorized:"yes,no" directive:"oci mode"`
@@ -354,6 +361,7 @@ allow container encrypted = {{ if eq .AllowContainerEncrypted true }}yes{{ else
 # Allow use of non-SIF image formats
 allow container squashfs = {{ if eq .AllowContainerSquashfs true }}yes{{ else }}no{{ end }}
 allow container extfs = {{ if eq .AllowContainerExtfs true }}yes{{ else }}no{{ end }}
+allow container erofs = {{ if eq .AllowContainerErofs true }}yes{{ else }}no{{ end }}
 allow container dir = {{ if eq .AllowContainerDir true }}yes{{ else }}no{{ end }}
 
 # ALLOW KERNEL SQUASHFS: [BOOL]
@@ -371,6 +379,15 @@ allow kernel squashfs = {{ if eq .AllowKernelSquashfs true }}yes{{ else }}no{{ e
 # Applicable to setuid mode only.
 allow kernel extfs = {{ if eq .AllowKernelExtfs true }}yes{{ else }}no{{ end }}
 
+# ALLOW KERNEL EROFS: [BOOL]
+# DEFAULT: no
+# If set to no, Singularity will not perform any kernel mounts of EROFS filesystems.
+# Instead, for EROFS containers, an erofsfuse mount will be attempted. EROFS
+# images cannot be extracted to a temporary sandbox directory, so will fail to
+# run if erofsfuse is not available.
+# Applicable to setuid mode only.
+allow kernel erofs = {{ if eq .AllowKernelErofs true }}yes{{ else }}no{{ end }}
+
 # ALLOW NET USERS: [STRING]
 # DEFAULT: NULL
 # A list of non-root users that are permitted to use the CNI configurations
@@ -380,6 +397,8 @@ allow kernel extfs = {{ if eq .AllowKernelExtfs true }}yes{{ else }}no{{ end }}
 # namespaces, except in the case of a fakeroot execution where only the 
 # 40_fakeroot.conflist CNI configuration is used. The restriction only applies
 # when Singularity is running in SUID mode and the user is non-root.
+# Not applicable to --oci mode, where only root may use CNI configurations, or
+# join existing network namespaces.
 #allow net users = gmk, singularity
 {{ range $index, $owner := .AllowNetUsers }}
 {{- if eq $index 0 }}allow net users = {{ else }}, {{ end }}{{$owner}}
@@ -394,6 +413,8 @@ allow kernel extfs = {{ if eq .AllowKernelExtfs true }}yes{{ else }}no{{ end }}
 # namespaces, except in the case of a fakeroot execution where only the 
 # 40_fakeroot.conflist CNI configuration is used. The restriction only applies
 # when Singularity is running in SUID mode and the user is non-root.
+# Not applicable to --oci mode, where only root may use CNI configurations, or
+# join existing network namespaces.
 #allow net groups = group1, singularity
 {{ range $index, $group := .AllowNetGroups }}
 {{- if eq $index 0 }}allow net groups = {{ else }}, {{ end }}{{$group}}
@@ -404,6 +425,7 @@ allow kernel extfs = {{ if eq .AllowKernelExtfs true }}yes{{ else }}no{{ end }}
 # Specify the names of CNI network configurations that may be used by users and
 # groups listed in the allow net users / allow net groups directives. This restriction
 # only applies when Singularity is running in SUID mode and the user is non-root.
+# Not applicable to --oci mode.
 #allow net networks = bridge
 {{ range $index, $group := .AllowNetNetworks }}
 {{- if eq $index 0 }}allow net networks = {{ else }}, {{ end }}{{$group}}
@@ -414,6 +436,7 @@ allow kernel extfs = {{ if eq .AllowKernelExtfs true }}yes{{ else }}no{{ end }}
 # Specify the paths to network namespaces that may be joined by users and groups
 # listed in the allow net users / allow net groups directives. This restriction
 # only applies when Singularity is running in SUID mode and the user is non-root.
+# Not applicable to --oci mode.
 #allow netns paths = /var/run/netns/my_network
 {{ range $index, $path := .AllowNetnsPaths }}
 {{- if eq $index 0 }}allow netns paths = {{ else }}, {{ end }}{{$path}}
@@ -451,6 +474,16 @@ always use rocm = {{ if eq .AlwaysUseRocm true }}yes{{ else }}no{{ end }}
 # - no: no capabilities (same as --no-privs)
 root default capabilities = {{ .RootDefaultCapabilities }}
 
+# SECCOMP PROFILE: [STRING]
+# DEFAULT: Undefined
+# Path to a seccomp profile, in OCI / Docker JSON format, that is applied to
+# all containers, in both native and --oci mode. This is a default only. Any
+# user can replace it with a profile of their own, specified with
+# --security seccomp:<path> on the command line, so it cannot be used to
+# enforce a seccomp policy.
+#seccomp profile =
+{{ if ne .SeccompProfile "" }}seccomp profile = {{ .SeccompProfile }}{{ end }}
+
 # MEMORY FS TYPE: [tmpfs/ramfs]
 # DEFAULT: tmpfs
 # This feature allow to choose temporary filesystem type used by Singularity.
@@ -538,6 +571,14 @@ mksquashfs procs = {{ .MksquashfsProcs }}
 # mksquashfs mem = 1G
 {{ if ne .MksquashfsMem "" }}mksquashfs mem = {{ .MksquashfsMem }}{{ end }}
 
+# MKSQUASHFS COMPRESSION: [STRING]
+# DEFAULT: gzip
+# The compression used by mksquashfs when building an image, if not specified
+# with 'build --compression'. One of gzip, zstd, xz or lz4, optionally followed
+# by a level for gzip (1-9) or zstd (1-22), e.g. zstd:19.
+# mksquashfs compression = gzip
+mksquashfs compression = {{ .MksquashfsCompression }}
+
 # NVIDIA-CONTAINER-CLI PATH: [STRING]
 # DEFAULT: Undefined
 # Path to the nvidia-container-cli executable, used to find GPU libraries.
@@ -578,6 +619,34 @@ download part size = {{ .DownloadPartSize }}
 # are enabled.
 download buffer size = {{ .DownloadBufferSize }}
 
+# CACHE MAX SIZE: [STRING]
+# DEFAULT: Undefined
+# The maximum total size of a user's image cache, e.g. 20G. When the cache
+# exceeds this size after a pull or build, the least recently used entries are
+# removed. Can be overridden with the SINGULARITY_CACHE_MAX_SIZE environment
+# variable. If undefined, the cache size is not limited.
+{{ if ne .CacheMaxSize "" }}cache max size = {{ .CacheMaxSize }}{{ end }}
+
+# CACHE TYPE MAX SIZE: [STRING]
+# DEFAULT: Undefined
+# The maximum size of an individual cache type, in the form <type>=<size>,
+# e.g. blob=10G. This directive can be specified multiple times, for
+# different cache types. Valid types are those accepted by
+# singularity cache clean --type.
+#cache type max size = blob=10G
+{{ range $size := .CacheTypeMaxSize }}
+{{- if ne $size "" -}}
+cache type max size = {{$size}}
+{{ end -}}
+{{ end }}
+# SHARED CACHE DIR: [STRING]
+# DEFAULT: Undefined
+# The absolute path of a read-only, system-wide image cache. Cached images,
+# OCI-SIF conversions, and OCI blobs are used from the shared cache, if present,
+# before the user's own cache. The shared cache is populated by an
+# administrator with 'singularity cache populate'.
+{{ if ne .SharedCacheDir "" }}shared cache dir = {{ .SharedCacheDir }}{{ end }}
+
 # SYSTEMD CGROUPS: [BOOL]
 # DEFAULT: yes
 # Whether to use systemd to manage container cgroups. Required for rootless cgroups